@@ -10,13 +10,17 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	filesnats "github.com/reggieanim/jot/internal/modules/files/adapters/nats"
+	filespostgres "github.com/reggieanim/jot/internal/modules/files/adapters/postgres"
 	filesapp "github.com/reggieanim/jot/internal/modules/files/app"
 	pagesgrpc "github.com/reggieanim/jot/internal/modules/pages/adapters/grpc"
 	pageshttp "github.com/reggieanim/jot/internal/modules/pages/adapters/http"
 	pagespostgres "github.com/reggieanim/jot/internal/modules/pages/adapters/postgres"
 	pageapp "github.com/reggieanim/jot/internal/modules/pages/app"
 	usershttp "github.com/reggieanim/jot/internal/modules/users/adapters/http"
+	usersmail "github.com/reggieanim/jot/internal/modules/users/adapters/mail"
+	usersnats "github.com/reggieanim/jot/internal/modules/users/adapters/nats"
 	userspostgres "github.com/reggieanim/jot/internal/modules/users/adapters/postgres"
 	userapp "github.com/reggieanim/jot/internal/modules/users/app"
 	"github.com/reggieanim/jot/internal/platform/auth"
@@ -45,18 +49,34 @@ func main() {
 		panic(err)
 	}
 	defer logger.Sync()
+	if cfg.LogRedaction {
+		logger = observability.WithRedaction(logger)
+	}
 
 	tracerProvider, err := observability.SetupTracer(ctx, cfg.AppName, cfg.OTLPEndpoint)
 	if err != nil {
 		logger.Fatal("setup tracer", zap.Error(err))
 	}
 	defer observability.ShutdownTracer(context.Background(), tracerProvider)
+	var metrics *prometheus.Registry
+	if cfg.MetricsEnabled {
+		metrics = observability.NewMetricsRegistry()
+	}
 
-	pool, err := platformpostgres.NewPool(ctx, cfg.DatabaseURL)
+	var poolOpts []platformpostgres.PoolOption
+	if cfg.DBTracingEnabled {
+		poolOpts = append(poolOpts, platformpostgres.WithQueryTracing())
+	}
+	pool, err := platformpostgres.NewPool(ctx, cfg.DatabaseURL, poolOpts...)
 	if err != nil {
 		logger.Fatal("connect postgres", zap.Error(err))
 	}
 	defer pool.Close()
+	if metrics != nil {
+		if err := pool.RegisterMetrics(metrics); err != nil {
+			logger.Fatal("register pool metrics", zap.Error(err))
+		}
+	}
 
 	migrationsDir, err := platformpostgres.ResolveMigrationsDir(cfg.MigrationsDir)
 	if err != nil {
@@ -75,36 +95,171 @@ func main() {
 	}
 	defer natsConn.Close()
 
-	if err := platformnats.EnsureStream(jetstream, cfg.NATSStream, cfg.NATSSubject); err != nil {
+	streamOpts := []platformnats.StreamOption{
+		platformnats.WithStreamSubjects(cfg.NATSFollowSubject),
+		platformnats.WithDuplicateWindow(cfg.FollowNotifyWindow),
+	}
+	if err := platformnats.EnsureStream(jetstream, cfg.NATSStream, cfg.NATSSubject, streamOpts...); err != nil {
 		logger.Fatal("ensure stream", zap.Error(err))
 	}
 
-	repo := pagespostgres.NewRepository(pool.Pool)
-	events := platformnats.NewPageEventsPublisher(jetstream, cfg.NATSSubject)
-	pagesService := pageapp.NewService(repo, events, clock.SystemClock{})
+	repo := pagespostgres.NewRepository(pool.Pool, pagespostgres.WithGlobalSlugs(cfg.GlobalSlugs))
+	// The users service is built below and needs the pages service, so pages
+	// resolve usernames through it lazily.
+	var usersService *userapp.Service
+	publisherOpts := []platformnats.PublisherOption{
+		platformnats.WithSyncAck(cfg.NATSSyncAck, cfg.NATSAckWait),
+	}
+	if metrics != nil {
+		publisherOpts = append(publisherOpts, platformnats.WithPublishMetrics(metrics))
+	}
+	events := platformnats.NewPageEventsPublisher(jetstream, cfg.NATSSubject, publisherOpts...)
+	pagesService := pageapp.NewService(repo, events, clock.SystemClock{},
+		pageapp.WithLogger(logger),
+		pageapp.WithShareCodeLength(cfg.ShareCodeLength),
+		pageapp.WithShareLinkTTL(cfg.ShareLinkTTL),
+		pageapp.WithRevisionRetention(cfg.RevisionRetention),
+		pageapp.WithConflictMerge(cfg.MergeBlockConflicts),
+		pageapp.WithBlockTypes(cfg.StrictBlockTypes, cfg.ExtraBlockTypes),
+		pageapp.WithPublishRateLimit(cfg.PublishLimitPerHour, time.Hour),
+		pageapp.WithPrivatePagesHidden(cfg.HidePrivatePages),
+		pageapp.WithAnonymousPagesUnlisted(cfg.AnonymousPageVisibility == "unlisted"),
+		pageapp.WithSlugRegeneration(cfg.RegenerateSlugs),
+		pageapp.WithBlockNormalization(cfg.NormalizeBlocks),
+		pageapp.WithUserResolver(pageapp.UserResolverFunc(func(ctx context.Context, username string) (string, error) {
+			return usersService.UserIDByUsername(ctx, username)
+		})),
+	)
 	mediaStore, err := platformstorage.NewS3MediaStore(cfg.S3Endpoint, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3Bucket, cfg.S3UseSSL, cfg.S3PublicURL)
 	if err != nil {
 		logger.Fatal("setup media store", zap.Error(err))
 	}
 
 	router := httputil.NewRouter(cfg.CORSOrigins)
+	router.Use(httputil.RequestLogging(logger))
+	if metrics != nil {
+		router.Use(httputil.RequestMetrics(metrics))
+	}
+	router.Use(auth.AuditImpersonation(logger))
+	router.GET("/readyz", httputil.Readiness(2*time.Second, map[string]httputil.Pinger{
+		"postgres": pool.Pool,
+		"nats": httputil.PingerFunc(func(context.Context) error {
+			if !natsConn.IsConnected() {
+				return httputil.ErrDisconnected
+			}
+			return nil
+		}),
+	}))
 
 	// Users module (creates jwtIssuer needed by pages)
-	jwtIssuer := auth.NewJWTIssuer(cfg.JWTSecret)
+	jwtConfig := auth.JWTConfig{
+		Secret:    cfg.JWTSecret,
+		Algorithm: cfg.JWTAlgorithm,
+		TTL:       cfg.JWTTTL,
+		Issuer:    cfg.JWTIssuer,
+		Audience:  cfg.JWTAudience,
+	}
+	if cfg.JWTPrivateKeyPath != "" {
+		if jwtConfig.PrivateKeyPEM, err = os.ReadFile(cfg.JWTPrivateKeyPath); err != nil {
+			logger.Fatal("read jwt private key", zap.Error(err))
+		}
+	}
+	if cfg.JWTPublicKeyPath != "" {
+		if jwtConfig.PublicKeyPEM, err = os.ReadFile(cfg.JWTPublicKeyPath); err != nil {
+			logger.Fatal("read jwt public key", zap.Error(err))
+		}
+	}
+	jwtIssuer, err := auth.NewJWTIssuerWithConfig(jwtConfig)
+	if err != nil {
+		logger.Fatal("setup jwt issuer", zap.Error(err))
+	}
 	usersRepo := userspostgres.NewRepository(pool.Pool)
-	usersService := userapp.NewService(usersRepo, jwtIssuer, clock.SystemClock{})
-	usershttp.RegisterRoutes(router, usersService, jwtIssuer, logger, cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.GoogleCallbackURL, cfg.FrontendURL)
+	usersServiceOpts := []userapp.Option{
+		userapp.WithRefreshTokenTTL(cfg.RefreshTokenTTL),
+		userapp.WithPasswordResetTTL(cfg.PasswordResetTTL),
+		userapp.WithEmailVerificationTTL(cfg.EmailVerificationTTL),
+		userapp.WithProfileLimits(cfg.MaxDisplayNameLength, cfg.MaxBioLength),
+		userapp.WithPageRemover(accountPageRemover{service: pagesService, logger: logger}),
+		userapp.WithFollowListener(platformnats.NewFollowEventsPublisher(jetstream, cfg.NATSFollowSubject, logger)),
+	}
+	if cfg.Environment == "dev" {
+		usersServiceOpts = append(usersServiceOpts, userapp.WithMailer(usersmail.NewLogMailer(logger)))
+	} else {
+		logger.Warn("no mailer configured; account emails are not sent")
+	}
+	usersService = userapp.NewService(usersRepo, jwtIssuer, clock.SystemClock{}, usersServiceOpts...)
+	storageUsage := filespostgres.NewRepository(pool.Pool)
+	storageQuota := filesapp.NewStorageQuota(storageUsage, int64(cfg.StorageQuotaMB)<<20)
+	usersOpts := []usershttp.Option{
+		usershttp.WithRequestTimeout(cfg.RequestTimeout),
+		usershttp.WithUploadTimeout(cfg.UploadTimeout),
+		usershttp.WithAvatarUploads(mediaStore, cfg.MaxImageMegapixels),
+		usershttp.WithSVGSanitizing(cfg.SanitizeSVGUploads),
+		usershttp.WithStorageQuota(storageQuota),
+		usershttp.WithAdminUserIDs(cfg.AdminUserIDs),
+	}
+	if cfg.AuthRatePerMinute > 0 {
+		usersOpts = append(usersOpts, usershttp.WithAuthRateLimiter(httputil.NewTokenBucket(cfg.AuthRatePerMinute/60, cfg.AuthRateBurst)))
+	}
+	usershttp.RegisterRoutes(router, usersService, jwtIssuer, logger, cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.GoogleCallbackURL, cfg.FrontendURL, usersOpts...)
+
+	// Files module: cleans up a deleted page's S3 objects and regenerates
+	// thumbnails.
+	filesOpts := []filesapp.Option{
+		filesapp.WithDeleteConcurrency(cfg.MediaDeleteWorkers),
+		filesapp.WithStorageUsage(storageUsage),
+		filesapp.WithStorageReconciler(storageUsage),
+		filesapp.WithThumbnailStore(mediaStore),
+	}
+	if cfg.KeepSharedMedia {
+		filesOpts = append(filesOpts, filesapp.WithReferenceChecker(repo))
+	}
+	filesService := filesapp.NewService(mediaStore, logger, filesOpts...)
 
 	// Pages module
-	pageshttp.RegisterRoutes(router, pagesService, usersService, natsConn, cfg.NATSSubject, logger, mediaStore, jwtIssuer)
+	if cfg.ReadKeySalt == "" && cfg.Environment != "dev" {
+		logger.Warn("JOT_READ_KEY_SALT is not set; reader keys will change on every restart")
+	}
+	pagesOpts := []pageshttp.Option{
+		pageshttp.WithMaxImageMegapixels(cfg.MaxImageMegapixels),
+		pageshttp.WithSVGSanitizing(cfg.SanitizeSVGUploads),
+		pageshttp.WithSharePreview(cfg.SharePreviewEnabled),
+		pageshttp.WithPublicFeed(cfg.PublicFeedEnabled),
+		pageshttp.WithFeedExcludeSelf(cfg.FeedExcludeSelf),
+		pageshttp.WithPublicContentSecurityPolicy(cfg.PublicCSP),
+		pageshttp.WithAllowedOrigins(cfg.CORSOrigins),
+		pageshttp.WithAudioContentTypes(cfg.AudioContentTypes),
+		pageshttp.WithReadKeySalt(cfg.ReadKeySalt),
+		pageshttp.WithTypingTimeout(cfg.TypingTimeout),
+		pageshttp.WithEventStream(jetstream, cfg.NATSStream),
+		pageshttp.WithAdminUserIDs(cfg.AdminUserIDs),
+		pageshttp.WithRouteTimeouts(cfg.RequestTimeout, cfg.UploadTimeout),
+		pageshttp.WithStorageQuota(storageQuota),
+		pageshttp.WithThumbnailRegenerator(filesService),
+	}
+	if cfg.AnonymousUploadsPerHour > 0 {
+		pagesOpts = append(pagesOpts, pageshttp.WithAnonymousUploadLimiter(httputil.NewTokenBucket(cfg.AnonymousUploadsPerHour/3600, cfg.AnonymousUploadBurst)))
+	}
+	if cfg.PagePasswordAttemptsPerHour > 0 {
+		attempts := cfg.PagePasswordAttemptsPerHour
+		pagesOpts = append(pagesOpts, pageshttp.WithPagePasswordLimiter(httputil.NewTokenBucket(attempts/3600, max(1, int(attempts)))))
+	}
+	pageshttp.RegisterRoutes(router, pagesService, usersService, natsConn, cfg.NATSSubject, logger, mediaStore, jwtIssuer, pagesOpts...)
 
-	// Files module: subscribes to page.deleted events and cleans up S3 objects.
-	filesService := filesapp.NewService(mediaStore, logger)
-	filesSubscriber := filesnats.NewSubscriber(filesService, natsConn, cfg.NATSSubject, logger)
+	// Subscribe the files module to page.deleted events.
+	filesSubscriber := filesnats.NewSubscriber(filesService, jetstream, cfg.NATSStream, cfg.NATSSubject, logger)
 	if err := filesSubscriber.Start(); err != nil {
 		logger.Fatal("start files subscriber", zap.Error(err))
 	}
 	defer filesSubscriber.Stop()
+	go handleFilesMaintenanceSignals(ctx, filesSubscriber)
+
+	// Subscribe the users module to follow events for notifications.
+	usersSubscriber := usersnats.NewSubscriber(userapp.NewFollowNotifier(usersRepo, clock.SystemClock{}), jetstream, cfg.NATSStream, cfg.NATSFollowSubject, logger)
+	if err := usersSubscriber.Start(); err != nil {
+		logger.Fatal("start users subscriber", zap.Error(err))
+	}
+	defer usersSubscriber.Stop()
 
 	httpServer := &http.Server{
 		Addr:         cfg.HTTPAddr,
@@ -113,13 +268,38 @@ func main() {
 		WriteTimeout: cfg.WriteTimeout,
 	}
 
-	grpcServer := platformgrpc.NewServer()
+	grpcServer := platformgrpc.NewServer(jwtIssuer)
 	pagesgrpc.Register(grpcServer, pagesService, natsConn, cfg.NATSSubject, logger)
 	grpcListener, err := platformgrpc.Listen(cfg.GRPCAddr)
 	if err != nil {
 		logger.Fatal("listen grpc", zap.Error(err))
 	}
 
+	if cfg.ArchiveRetentionDays > 0 {
+		retention := time.Duration(cfg.ArchiveRetentionDays) * 24 * time.Hour
+		go runArchiveJanitor(ctx, pagesService, retention, time.Hour, logger)
+	}
+	if cfg.PublishPollInterval > 0 {
+		go runPublishScheduler(ctx, pagesService, cfg.PublishPollInterval, logger)
+	}
+	if cfg.ReconcileInterval > 0 {
+		go runCounterReconciler(ctx, pagesService, filesService, cfg.ReconcileInterval, logger)
+	}
+
+	// Metrics are served on their own listener so they stay off the public
+	// API; bind it to an internal interface.
+	var metricsServer *http.Server
+	if metrics != nil {
+		metricsServer = observability.NewMetricsServer(cfg.MetricsAddr, metrics)
+		go func() {
+			logger.Info("metrics server started", zap.String("addr", cfg.MetricsAddr))
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("metrics server error", zap.Error(err))
+				stop()
+			}
+		}()
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(2)
 	go func() {
@@ -146,7 +326,120 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	_ = httpServer.Shutdown(shutdownCtx)
+	if metricsServer != nil {
+		_ = metricsServer.Shutdown(shutdownCtx)
+	}
 	grpcServer.GracefulStop()
 	wg.Wait()
 	os.Exit(0)
 }
+
+// accountPageRemover deletes a closing account's pages. Pages whose deletion
+// event could not be published are already gone, so that only leaves media
+// behind; it is logged rather than allowed to block the account deletion.
+type accountPageRemover struct {
+	service *pageapp.Service
+	logger  *zap.Logger
+}
+
+func (remover accountPageRemover) DeleteOwnerPages(ctx context.Context, ownerID string) (int, error) {
+	deleted, err := remover.service.DeleteOwnerPages(ctx, ownerID)
+	if errors.Is(err, pageapp.ErrDeleteEventNotPublished) {
+		remover.logger.Error("account pages deleted but their media was not queued for cleanup", zap.Error(err), zap.String("owner_id", ownerID))
+		return deleted, nil
+	}
+	return deleted, err
+}
+
+// runArchiveJanitor periodically purges pages archived longer than retention
+// until ctx is cancelled.
+func runArchiveJanitor(ctx context.Context, service *pageapp.Service, retention, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := service.PurgeArchivedPages(ctx, retention)
+		if err != nil {
+			logger.Error("purge archived pages", zap.Error(err))
+		} else if purged > 0 {
+			logger.Info("purged archived pages", zap.Int("count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runPublishScheduler publishes scheduled pages as they fall due until ctx is
+// cancelled.
+func runPublishScheduler(ctx context.Context, service *pageapp.Service, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := service.PublishDuePages(ctx)
+		if err != nil {
+			logger.Error("publish scheduled pages", zap.Error(err))
+		}
+		if published > 0 {
+			logger.Info("published scheduled pages", zap.Int("count", published))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runCounterReconciler corrects denormalized counters that drifted from their
+// source rows until ctx is cancelled.
+func runCounterReconciler(ctx context.Context, pages *pageapp.Service, files *filesapp.Service, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		corrected, err := pages.ReconcileCounters(ctx, 0)
+		if err != nil {
+			logger.Error("reconcile page counters", zap.Error(err))
+		}
+		storage, err := files.ReconcileStorage(ctx)
+		if err != nil {
+			logger.Error("reconcile storage usage", zap.Error(err))
+		}
+		if corrected+storage > 0 {
+			logger.Info("reconciled counters", zap.Int("corrected", corrected+storage))
+		}
+	}
+}
+
+// handleFilesMaintenanceSignals pauses media cleanup on SIGUSR1 and resumes
+// it on SIGUSR2, so operators can hold deletions during storage maintenance.
+func handleFilesMaintenanceSignals(ctx context.Context, subscriber *filesnats.Subscriber) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			if sig == syscall.SIGUSR1 {
+				subscriber.Pause()
+			} else {
+				subscriber.Resume()
+			}
+		}
+	}
+}
@@ -3,11 +3,15 @@ package nats
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	jnats "github.com/nats-io/nats.go"
 	"github.com/reggieanim/jot/internal/modules/files/app"
+	platformnats "github.com/reggieanim/jot/internal/platform/eventbus/nats"
 	"go.uber.org/zap"
 )
 
@@ -20,49 +24,185 @@ type pageDeletedEnvelope struct {
 	} `json:"page"`
 }
 
+// consumerName is the durable JetStream consumer holding the files module's
+// place in the page event stream.
+const consumerName = "files-page-deleted"
+
+const (
+	fetchBatch = 16
+	// fetchWait bounds how long one fetch waits for events, and so how long
+	// Stop may wait for the fetch loop.
+	fetchWait = 2 * time.Second
+)
+
+// fetcher is the part of a pull subscription the fetch loop uses.
+type fetcher interface {
+	Fetch(batch int, opts ...jnats.PullOpt) ([]*jnats.Msg, error)
+}
+
 type Subscriber struct {
-	service *app.Service
-	conn    *jnats.Conn
-	subject string
-	logger  *zap.Logger
-	sub     *jnats.Subscription
+	service   *app.Service
+	jetstream jnats.JetStreamContext
+	stream    string
+	subject   string
+	logger    *zap.Logger
+	sub       *jnats.Subscription
+	fetcher   fetcher
+	stop      chan struct{}
+	done      chan struct{}
+
+	mu      sync.Mutex
+	paused  bool
+	resumed chan struct{}
 }
 
-func NewSubscriber(service *app.Service, conn *jnats.Conn, subject string, logger *zap.Logger) *Subscriber {
+func NewSubscriber(service *app.Service, jetstream jnats.JetStreamContext, stream, subject string, logger *zap.Logger) *Subscriber {
 	return &Subscriber{
-		service: service,
-		conn:    conn,
-		subject: subject,
-		logger:  logger,
+		service:   service,
+		jetstream: jetstream,
+		stream:    stream,
+		subject:   subject,
+		logger:    logger,
 	}
 }
 
+// Start pulls page events from a durable consumer over the per-page subjects
+// and the legacy shared subject, which older publishers may still use.
+// Events are acknowledged once handled, so those published while the process
+// is down or the subscriber is paused wait in the stream.
 func (s *Subscriber) Start() error {
-	sub, err := s.conn.Subscribe(s.subject, func(msg *jnats.Msg) {
-		envelope, err := parsePageDeleted(msg.Data)
-		if err != nil {
+	subjects := []string{s.subject, platformnats.PageWildcardSubject(s.subject)}
+	if err := platformnats.EnsureDurableConsumer(s.jetstream, s.stream, consumerName, subjects...); err != nil {
+		return err
+	}
+	sub, err := s.jetstream.PullSubscribe("", consumerName, jnats.Bind(s.stream, consumerName))
+	if err != nil {
+		return fmt.Errorf("bind consumer %s: %w", consumerName, err)
+	}
+	s.sub = sub
+	s.run(sub)
+	s.logger.Info("files subscriber started", zap.String("subject", s.subject), zap.String("consumer", consumerName))
+	return nil
+}
+
+func (s *Subscriber) run(fetcher fetcher) {
+	s.fetcher = fetcher
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.fetchLoop()
+}
+
+// Pause stops media cleanup, e.g. during storage maintenance. No events are
+// fetched while paused; they stay in the stream until Resume.
+func (s *Subscriber) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused {
+		s.paused = true
+		s.resumed = make(chan struct{})
+		s.logger.Info("files subscriber paused")
+	}
+}
+
+// Resume goes back to fetching, starting with the events that arrived while
+// paused.
+func (s *Subscriber) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused {
+		s.paused = false
+		close(s.resumed)
+		s.logger.Info("files subscriber resumed")
+	}
+}
+
+// Paused reports whether the subscriber has stopped fetching events.
+func (s *Subscriber) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// pausedWait returns a channel closed on Resume, or nil when not paused.
+func (s *Subscriber) pausedWait() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused {
+		return nil
+	}
+	return s.resumed
+}
+
+func (s *Subscriber) fetchLoop() {
+	defer close(s.done)
+	for {
+		if resumed := s.pausedWait(); resumed != nil {
+			select {
+			case <-resumed:
+			case <-s.stop:
+				return
+			}
+			continue
+		}
+		select {
+		case <-s.stop:
 			return
+		default:
+		}
+
+		msgs, err := s.fetcher.Fetch(fetchBatch, jnats.MaxWait(fetchWait))
+		if err != nil && !errors.Is(err, jnats.ErrTimeout) {
+			s.logger.Warn("fetch page events", zap.Error(err))
+			select {
+			case <-time.After(fetchWait):
+			case <-s.stop:
+				return
+			}
+		}
+		for _, msg := range msgs {
+			s.deliver(msg)
 		}
+	}
+}
 
-		s.logger.Info("received page.deleted event",
-			zap.String("page_id", envelope.Page.ID),
-		)
+// deliver handles msg and acknowledges it. Messages still in a batch when
+// the subscriber is paused are handed back for redelivery after Resume.
+func (s *Subscriber) deliver(msg *jnats.Msg) {
+	if s.Paused() {
+		_ = msg.Nak()
+		return
+	}
+	s.process(msg)
+	_ = msg.Ack()
+}
 
-		s.service.HandlePageDeleted(context.Background(), envelope.Page.Cover, envelope.Page.Blocks)
-	})
+func (s *Subscriber) process(msg *jnats.Msg) {
+	envelope, err := parsePageDeleted(msg.Data)
 	if err != nil {
-		return fmt.Errorf("subscribe to %s: %w", s.subject, err)
+		return
 	}
-	s.sub = sub
-	s.logger.Info("files subscriber started", zap.String("subject", s.subject))
-	return nil
+
+	s.logger.Info("received page.deleted event",
+		zap.String("page_id", envelope.Page.ID),
+	)
+
+	s.service.HandlePageDeleted(context.Background(), envelope.Page.Cover, envelope.Page.Blocks)
 }
 
+// Stop ends the fetch loop and leaves the durable consumer in place, so the
+// next Start resumes where this one stopped.
 func (s *Subscriber) Stop() error {
-	if s.sub != nil {
-		return s.sub.Unsubscribe()
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
 	}
-	return nil
+	if s.sub == nil {
+		return nil
+	}
+	err := s.sub.Unsubscribe()
+	s.sub = nil
+	return err
 }
 
 func parsePageDeleted(data []byte) (pageDeletedEnvelope, error) {
@@ -0,0 +1,143 @@
+package nats
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jnats "github.com/nats-io/nats.go"
+	"github.com/reggieanim/jot/internal/modules/files/app"
+	"go.uber.org/zap"
+)
+
+type recordingStore struct {
+	mu      sync.Mutex
+	deleted []string
+}
+
+func (store *recordingStore) DeleteObject(_ context.Context, objectKey string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.deleted = append(store.deleted, objectKey)
+	return nil
+}
+
+func (store *recordingStore) ObjectKeyFromURL(rawURL string) string {
+	return strings.TrimPrefix(rawURL, "http://s3.local/bucket/")
+}
+
+func (store *recordingStore) count() int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return len(store.deleted)
+}
+
+func pageDeletedMsg(cover string) *jnats.Msg {
+	return &jnats.Msg{Data: []byte(`{"type":"page.deleted","page":{"id":"page-1","cover":"` + cover + `","blocks":[]}}`)}
+}
+
+// queueFetcher hands out queued messages one batch per Fetch and counts
+// fetches made while its subscriber was paused.
+type queueFetcher struct {
+	mu          sync.Mutex
+	queue       []*jnats.Msg
+	subscriber  *Subscriber
+	pausedCalls int
+}
+
+func (fetcher *queueFetcher) Fetch(batch int, _ ...jnats.PullOpt) ([]*jnats.Msg, error) {
+	fetcher.mu.Lock()
+	if fetcher.subscriber.Paused() {
+		fetcher.pausedCalls++
+	}
+	n := min(batch, len(fetcher.queue))
+	msgs := fetcher.queue[:n]
+	fetcher.queue = fetcher.queue[n:]
+	fetcher.mu.Unlock()
+
+	if n == 0 {
+		time.Sleep(time.Millisecond)
+		return nil, jnats.ErrTimeout
+	}
+	return msgs, nil
+}
+
+func (fetcher *queueFetcher) push(msgs ...*jnats.Msg) {
+	fetcher.mu.Lock()
+	defer fetcher.mu.Unlock()
+	fetcher.queue = append(fetcher.queue, msgs...)
+}
+
+func (fetcher *queueFetcher) pending() int {
+	fetcher.mu.Lock()
+	defer fetcher.mu.Unlock()
+	return len(fetcher.queue)
+}
+
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPausedSubscriberStopsFetchingUntilResumed(t *testing.T) {
+	store := &recordingStore{}
+	subscriber := NewSubscriber(app.NewService(store, zap.NewNop()), nil, "JOT_EVENTS", "jot.pages.events", zap.NewNop())
+	fetcher := &queueFetcher{subscriber: subscriber}
+	subscriber.run(fetcher)
+	defer func() { _ = subscriber.Stop() }()
+
+	subscriber.Pause()
+	// Let a fetch that was already waiting when Pause was called finish.
+	time.Sleep(10 * time.Millisecond)
+	fetcher.mu.Lock()
+	fetcher.pausedCalls = 0
+	fetcher.mu.Unlock()
+
+	fetcher.push(pageDeletedMsg("http://s3.local/bucket/images/a.png"), pageDeletedMsg("http://s3.local/bucket/images/b.png"))
+	time.Sleep(20 * time.Millisecond)
+	if got := store.count(); got != 0 {
+		t.Fatalf("expected no deletions while paused, got %d", got)
+	}
+	if got := fetcher.pending(); got != 2 {
+		t.Fatalf("expected events to stay in the stream while paused, got %d pending", got)
+	}
+	fetcher.mu.Lock()
+	pausedCalls := fetcher.pausedCalls
+	fetcher.mu.Unlock()
+	if pausedCalls != 0 {
+		t.Fatalf("expected no fetches while paused, got %d", pausedCalls)
+	}
+
+	subscriber.Resume()
+	waitFor(t, "held deletions after resume", func() bool { return store.count() == 2 })
+
+	fetcher.push(pageDeletedMsg("http://s3.local/bucket/images/c.png"))
+	waitFor(t, "live deletions after resume", func() bool { return store.count() == 3 })
+	if subscriber.Paused() {
+		t.Fatal("expected subscriber to report resumed")
+	}
+}
+
+func TestDeliverHandsBackMessagesWhilePaused(t *testing.T) {
+	store := &recordingStore{}
+	subscriber := NewSubscriber(app.NewService(store, zap.NewNop()), nil, "JOT_EVENTS", "jot.pages.events", zap.NewNop())
+
+	subscriber.Pause()
+	subscriber.deliver(pageDeletedMsg("http://s3.local/bucket/images/a.png"))
+	if got := store.count(); got != 0 {
+		t.Fatalf("expected a message fetched before Pause not to be handled, got %d deletions", got)
+	}
+	subscriber.Resume()
+	subscriber.deliver(pageDeletedMsg("http://s3.local/bucket/images/a.png"))
+	if got := store.count(); got != 1 {
+		t.Fatalf("expected the message to be handled after Resume, got %d deletions", got)
+	}
+}
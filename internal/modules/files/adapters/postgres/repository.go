@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/reggieanim/jot/internal/modules/files/domain"
+)
+
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+func (repository *Repository) Reserve(ctx context.Context, userID string, size, limit int64) (bool, error) {
+	// The conditional upsert checks and adds in one statement so concurrent
+	// uploads cannot overshoot the limit together.
+	var used int64
+	err := repository.pool.QueryRow(ctx, `
+		INSERT INTO user_storage (user_id, used_bytes, updated_at)
+		SELECT $1, $2, now()
+		WHERE $3 <= 0 OR $2 <= $3
+		ON CONFLICT (user_id) DO UPDATE
+		SET used_bytes = user_storage.used_bytes + EXCLUDED.used_bytes, updated_at = now()
+		WHERE $3 <= 0 OR user_storage.used_bytes + EXCLUDED.used_bytes <= $3
+		RETURNING used_bytes
+	`, userID, size, limit).Scan(&used)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reserve storage: %w", err)
+	}
+	return true, nil
+}
+
+func (repository *Repository) Release(ctx context.Context, userID string, size int64) error {
+	_, err := repository.pool.Exec(ctx, `
+		UPDATE user_storage
+		SET used_bytes = GREATEST(used_bytes - $2, 0), updated_at = now()
+		WHERE user_id = $1
+	`, userID, size)
+	if err != nil {
+		return fmt.Errorf("release storage: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) RecordObject(ctx context.Context, userID, objectKey string, size int64) error {
+	_, err := repository.pool.Exec(ctx, `
+		INSERT INTO media_objects (object_key, user_id, size_bytes, created_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (object_key) DO NOTHING
+	`, objectKey, userID, size)
+	if err != nil {
+		return fmt.Errorf("record media object: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) ReleaseObject(ctx context.Context, objectKey string) error {
+	_, err := repository.pool.Exec(ctx, `
+		WITH released AS (
+			DELETE FROM media_objects WHERE object_key = $1
+			RETURNING user_id, size_bytes
+		)
+		UPDATE user_storage s
+		SET used_bytes = GREATEST(s.used_bytes - r.size_bytes, 0), updated_at = now()
+		FROM released r
+		WHERE s.user_id = r.user_id
+	`, objectKey)
+	if err != nil {
+		return fmt.Errorf("release media object: %w", err)
+	}
+	return nil
+}
+
+// UsedBytes returns userID's stored bytes.
+func (repository *Repository) UsedBytes(ctx context.Context, userID string) (int64, error) {
+	var used int64
+	err := repository.pool.QueryRow(ctx, `SELECT used_bytes FROM user_storage WHERE user_id = $1`, userID).Scan(&used)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get used storage: %w", err)
+	}
+	return used, nil
+}
+
+func (repository *Repository) ReconcileUsage(ctx context.Context, idleSince time.Time) ([]domain.StorageCorrection, error) {
+	// Usage touched since idleSince may be a reservation whose object is not
+	// recorded yet, so only settled rows are compared.
+	rows, err := repository.pool.Query(ctx, `
+		WITH actual AS (
+			SELECT user_id, sum(size_bytes)::bigint AS used_bytes
+			FROM media_objects
+			GROUP BY user_id
+		), drift AS (
+			SELECT coalesce(s.user_id, a.user_id) AS user_id,
+				coalesce(s.used_bytes, 0) AS from_bytes,
+				coalesce(a.used_bytes, 0) AS to_bytes
+			FROM user_storage s
+			FULL JOIN actual a ON a.user_id = s.user_id
+			WHERE coalesce(s.used_bytes, 0) <> coalesce(a.used_bytes, 0)
+				AND (s.updated_at IS NULL OR s.updated_at < $1)
+		), reset AS (
+			INSERT INTO user_storage (user_id, used_bytes, updated_at)
+			SELECT user_id, to_bytes, now() FROM drift
+			ON CONFLICT (user_id) DO UPDATE
+			SET used_bytes = EXCLUDED.used_bytes, updated_at = now()
+			WHERE user_storage.updated_at < $1
+		)
+		SELECT user_id, from_bytes, to_bytes FROM drift
+	`, idleSince)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile storage usage: %w", err)
+	}
+	defer rows.Close()
+
+	corrections := make([]domain.StorageCorrection, 0)
+	for rows.Next() {
+		var correction domain.StorageCorrection
+		if err := rows.Scan(&correction.UserID, &correction.From, &correction.To); err != nil {
+			return nil, fmt.Errorf("scan storage correction: %w", err)
+		}
+		corrections = append(corrections, correction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate storage corrections: %w", err)
+	}
+	return corrections, nil
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/reggieanim/jot/internal/modules/files/ports"
+)
+
+// ErrStorageQuotaExceeded is returned when an upload would take a user past
+// their storage quota.
+var ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+
+// StorageQuota meters the media each user uploads against a per-user limit.
+type StorageQuota struct {
+	usage ports.StorageUsage
+	limit int64
+}
+
+// NewStorageQuota allows each user limitBytes of media. Zero still tracks
+// usage but allows any amount.
+func NewStorageQuota(usage ports.StorageUsage, limitBytes int64) *StorageQuota {
+	return &StorageQuota{usage: usage, limit: limitBytes}
+}
+
+// Reserve claims size bytes of userID's quota ahead of an upload. Release
+// them if the upload fails.
+func (q *StorageQuota) Reserve(ctx context.Context, userID string, size int64) error {
+	if q.limit > 0 && size > q.limit {
+		return ErrStorageQuotaExceeded
+	}
+	ok, err := q.usage.Reserve(ctx, userID, size, q.limit)
+	if err != nil {
+		return fmt.Errorf("reserve storage: %w", err)
+	}
+	if !ok {
+		return ErrStorageQuotaExceeded
+	}
+	return nil
+}
+
+// Release returns size bytes reserved for an upload that did not happen.
+func (q *StorageQuota) Release(ctx context.Context, userID string, size int64) error {
+	if err := q.usage.Release(ctx, userID, size); err != nil {
+		return fmt.Errorf("release storage: %w", err)
+	}
+	return nil
+}
+
+// Forget frees the bytes recorded for objectKey once it has been deleted.
+// Untracked keys are ignored.
+func (q *StorageQuota) Forget(ctx context.Context, objectKey string) error {
+	if err := q.usage.ReleaseObject(ctx, objectKey); err != nil {
+		return fmt.Errorf("release stored object: %w", err)
+	}
+	return nil
+}
+
+// Record ties a finished upload to userID so deleting it later frees the
+// bytes reserved for it.
+func (q *StorageQuota) Record(ctx context.Context, userID, objectKey string, size int64) error {
+	if err := q.usage.RecordObject(ctx, userID, objectKey, size); err != nil {
+		return fmt.Errorf("record stored object: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reggieanim/jot/internal/modules/files/domain"
+)
+
+type memoryStorageUsage struct {
+	mu      sync.Mutex
+	used    map[string]int64
+	objects map[string]storedObject
+}
+
+type storedObject struct {
+	userID string
+	size   int64
+}
+
+func newMemoryStorageUsage() *memoryStorageUsage {
+	return &memoryStorageUsage{used: make(map[string]int64), objects: make(map[string]storedObject)}
+}
+
+func (m *memoryStorageUsage) Reserve(_ context.Context, userID string, size, limit int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if limit > 0 && m.used[userID]+size > limit {
+		return false, nil
+	}
+	m.used[userID] += size
+	return true, nil
+}
+
+func (m *memoryStorageUsage) Release(_ context.Context, userID string, size int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.used[userID] = max(m.used[userID]-size, 0)
+	return nil
+}
+
+func (m *memoryStorageUsage) RecordObject(_ context.Context, userID, objectKey string, size int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[objectKey] = storedObject{userID: userID, size: size}
+	return nil
+}
+
+func (m *memoryStorageUsage) ReleaseObject(_ context.Context, objectKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	object, ok := m.objects[objectKey]
+	if !ok {
+		return nil
+	}
+	delete(m.objects, objectKey)
+	m.used[object.userID] = max(m.used[object.userID]-object.size, 0)
+	return nil
+}
+
+func (m *memoryStorageUsage) ReconcileUsage(_ context.Context, _ time.Time) ([]domain.StorageCorrection, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	actual := make(map[string]int64)
+	for _, object := range m.objects {
+		actual[object.userID] += object.size
+	}
+	for userID := range m.used {
+		if _, ok := actual[userID]; !ok {
+			actual[userID] = 0
+		}
+	}
+	corrections := make([]domain.StorageCorrection, 0)
+	for userID, used := range actual {
+		if m.used[userID] != used {
+			corrections = append(corrections, domain.StorageCorrection{UserID: userID, From: m.used[userID], To: used})
+			m.used[userID] = used
+		}
+	}
+	return corrections, nil
+}
+
+func TestStorageQuotaBoundary(t *testing.T) {
+	ctx := context.Background()
+	usage := newMemoryStorageUsage()
+	quota := NewStorageQuota(usage, 100)
+
+	if err := quota.Reserve(ctx, "user-1", 60); err != nil {
+		t.Fatalf("first reserve: %v", err)
+	}
+	if err := quota.Reserve(ctx, "user-1", 40); err != nil {
+		t.Fatalf("reserve up to the limit: %v", err)
+	}
+	if err := quota.Reserve(ctx, "user-1", 1); !errors.Is(err, ErrStorageQuotaExceeded) {
+		t.Fatalf("expected ErrStorageQuotaExceeded one byte over, got %v", err)
+	}
+	if err := quota.Reserve(ctx, "user-2", 100); err != nil {
+		t.Fatalf("quota should be per user: %v", err)
+	}
+	if err := quota.Reserve(ctx, "user-3", 101); !errors.Is(err, ErrStorageQuotaExceeded) {
+		t.Fatalf("expected a single oversized upload to be rejected, got %v", err)
+	}
+
+	if err := quota.Release(ctx, "user-1", 40); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if err := quota.Reserve(ctx, "user-1", 40); err != nil {
+		t.Fatalf("reserve after release: %v", err)
+	}
+}
+
+func TestStorageQuotaUnlimited(t *testing.T) {
+	quota := NewStorageQuota(newMemoryStorageUsage(), 0)
+	if err := quota.Reserve(context.Background(), "user-1", 1<<40); err != nil {
+		t.Fatalf("zero limit should allow any size: %v", err)
+	}
+}
+
+func TestMediaCleanupReleasesStorage(t *testing.T) {
+	ctx := context.Background()
+	usage := newMemoryStorageUsage()
+	quota := NewStorageQuota(usage, 100)
+	if err := quota.Reserve(ctx, "user-1", 100); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if err := quota.Record(ctx, "user-1", "images/abc.png", 100); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	store := newMockMediaStore()
+	store.addMapping("http://s3.local/bucket/images/abc.png", "images/abc.png")
+	svc := NewService(store, testLogger(), WithStorageUsage(usage))
+	svc.HandlePageDeleted(ctx, nil, []json.RawMessage{
+		json.RawMessage(`{"type":"image","data":{"url":"http://s3.local/bucket/images/abc.png"}}`),
+	})
+
+	if err := quota.Reserve(ctx, "user-1", 100); err != nil {
+		t.Fatalf("deleted media should free its bytes: %v", err)
+	}
+}
+
+func TestReconcileStorageCorrectsDriftedUsage(t *testing.T) {
+	ctx := context.Background()
+	usage := newMemoryStorageUsage()
+	if err := usage.RecordObject(ctx, "user-1", "images/a.png", 30); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	usage.used["user-1"] = 500
+	usage.used["user-2"] = 0
+
+	svc := NewService(newMockMediaStore(), testLogger(), WithStorageReconciler(usage))
+	corrected, err := svc.ReconcileStorage(ctx)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if corrected != 1 {
+		t.Fatalf("expected one user corrected, got %d", corrected)
+	}
+	if usage.used["user-1"] != 30 {
+		t.Fatalf("expected usage reset to the recorded object sizes, got %d", usage.used["user-1"])
+	}
+}
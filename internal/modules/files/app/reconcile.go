@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// storageSettleTime is how long a user's usage must go unchanged before it is
+// reconciled, so uploads between reserving and recording are not undone.
+const storageSettleTime = 10 * time.Minute
+
+// ReconcileStorage resets storage usage that drifted from the sizes of the
+// recorded objects and returns how many users it corrected. It does nothing
+// without a StorageReconciler.
+func (s *Service) ReconcileStorage(ctx context.Context) (int, error) {
+	if s.reconciler == nil {
+		return 0, nil
+	}
+	corrections, err := s.reconciler.ReconcileUsage(ctx, time.Now().Add(-storageSettleTime))
+	if err != nil {
+		return 0, err
+	}
+	for _, correction := range corrections {
+		s.logger.Info("corrected storage usage",
+			zap.String("user_id", correction.UserID),
+			zap.Int64("from_bytes", correction.From),
+			zap.Int64("to_bytes", correction.To),
+		)
+	}
+	return len(corrections), nil
+}
@@ -3,19 +3,69 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/reggieanim/jot/internal/modules/files/domain"
 	"github.com/reggieanim/jot/internal/modules/files/ports"
 	"go.uber.org/zap"
 )
 
+const defaultDeleteConcurrency = 8
+
 type Service struct {
-	media  ports.MediaStore
-	logger *zap.Logger
+	media             ports.MediaStore
+	logger            *zap.Logger
+	deleteConcurrency int
+	usage             ports.StorageUsage
+	thumbnails        ports.ThumbnailStore
+	references        ports.ReferenceChecker
+	reconciler        ports.StorageReconciler
+}
+
+// Option configures optional Service behaviour.
+type Option func(*Service)
+
+// WithDeleteConcurrency bounds how many objects are deleted at once when a
+// page's media is cleaned up. Zero keeps the default of 8.
+func WithDeleteConcurrency(workers int) Option {
+	return func(s *Service) {
+		if workers > 0 {
+			s.deleteConcurrency = workers
+		}
+	}
+}
+
+// WithStorageUsage frees a deleted object's bytes from its uploader's
+// storage usage.
+func WithStorageUsage(usage ports.StorageUsage) Option {
+	return func(s *Service) {
+		s.usage = usage
+	}
+}
+
+// WithReferenceChecker keeps a deleted page's objects that another page still
+// references, as happens when uploads share a key.
+func WithReferenceChecker(checker ports.ReferenceChecker) Option {
+	return func(s *Service) {
+		s.references = checker
+	}
 }
 
-func NewService(media ports.MediaStore, logger *zap.Logger) *Service {
-	return &Service{media: media, logger: logger}
+// WithStorageReconciler lets ReconcileStorage correct drifted storage usage.
+func WithStorageReconciler(reconciler ports.StorageReconciler) Option {
+	return func(s *Service) {
+		s.reconciler = reconciler
+	}
+}
+
+func NewService(media ports.MediaStore, logger *zap.Logger, opts ...Option) *Service {
+	s := &Service{media: media, logger: logger, deleteConcurrency: defaultDeleteConcurrency}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *Service) HandlePageDeleted(ctx context.Context, cover *string, rawBlocks []json.RawMessage) {
@@ -28,14 +78,85 @@ func (s *Service) HandlePageDeleted(ctx context.Context, cover *string, rawBlock
 		zap.Int("object_count", len(refs)),
 	)
 
+	if err := s.deleteObjects(ctx, refs); err != nil {
+		s.logger.Warn("media cleanup finished with failures", zap.Error(err))
+	}
+}
+
+// deleteObjects deletes refs, and the thumbnails of those that are images, on
+// a bounded pool of workers. A failed deletion
+// does not stop the others; all failures are joined into the returned error.
+func (s *Service) deleteObjects(ctx context.Context, refs []domain.MediaRef) error {
+	workers := min(s.deleteConcurrency, len(refs))
+	jobs := make(chan domain.MediaRef)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []error
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ref := range jobs {
+				if s.references != nil {
+					shared, err := s.references.IsMediaReferenced(ctx, ref.ObjectKey)
+					if err != nil {
+						// Keep the object rather than risk breaking a page.
+						s.logger.Warn("failed to check stored object references",
+							zap.String("key", ref.ObjectKey),
+							zap.Error(err),
+						)
+						mu.Lock()
+						failures = append(failures, fmt.Errorf("check references to %s: %w", ref.ObjectKey, err))
+						mu.Unlock()
+						continue
+					}
+					if shared {
+						s.logger.Info("keeping stored object referenced by another page",
+							zap.String("key", ref.ObjectKey),
+						)
+						continue
+					}
+				}
+				if err := s.media.DeleteObject(ctx, ref.ObjectKey); err != nil {
+					s.logger.Warn("failed to delete stored object",
+						zap.String("key", ref.ObjectKey),
+						zap.Error(err),
+					)
+					mu.Lock()
+					failures = append(failures, fmt.Errorf("delete %s: %w", ref.ObjectKey, err))
+					mu.Unlock()
+					continue
+				}
+				if thumbnailKey := domain.ThumbnailKey(ref.ObjectKey); s.thumbnails != nil && thumbnailKey != "" {
+					if err := s.media.DeleteObject(ctx, thumbnailKey); err != nil {
+						s.logger.Warn("failed to delete thumbnail",
+							zap.String("key", thumbnailKey),
+							zap.Error(err),
+						)
+						mu.Lock()
+						failures = append(failures, fmt.Errorf("delete %s: %w", thumbnailKey, err))
+						mu.Unlock()
+					}
+				}
+				if s.usage != nil {
+					if err := s.usage.ReleaseObject(ctx, ref.ObjectKey); err != nil {
+						s.logger.Warn("failed to release stored object usage",
+							zap.String("key", ref.ObjectKey),
+							zap.Error(err),
+						)
+					}
+				}
+			}
+		}()
+	}
 	for _, ref := range refs {
-		if err := s.media.DeleteObject(ctx, ref.ObjectKey); err != nil {
-			s.logger.Warn("failed to delete stored object",
-				zap.String("key", ref.ObjectKey),
-				zap.Error(err),
-			)
-		}
+		jobs <- ref
 	}
+	close(jobs)
+	wg.Wait()
+	return errors.Join(failures...)
 }
 
 func (s *Service) extractRefs(cover *string, rawBlocks []json.RawMessage) []domain.MediaRef {
@@ -85,7 +206,9 @@ func (s *Service) extractBlockRefs(blockJSON json.RawMessage) []domain.MediaRef
 		}
 		if json.Unmarshal(raw, &items) == nil {
 			for _, item := range items {
-				if item.Kind == "image" && item.Value != "" {
+				// Only image cards reference stored media; text and embed
+				// cards carry inline text or external URLs.
+				if item.Kind == domain.GalleryItemKindImage && item.Value != "" {
 					if key := s.media.ObjectKeyFromURL(item.Value); key != "" {
 						refs = append(refs, domain.MediaRef{ObjectKey: key})
 					}
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/reggieanim/jot/internal/modules/files/domain"
+	pagesdomain "github.com/reggieanim/jot/internal/modules/pages/domain"
 	"go.uber.org/zap"
 )
 
@@ -109,6 +112,23 @@ func TestHandlePageDeleted_GalleryBlock(t *testing.T) {
 	}
 }
 
+func TestHandlePageDeleted_GalleryOnlyImageKindsDeleted(t *testing.T) {
+	store := newMockMediaStore()
+	store.addMapping("http://s3.local/bucket/images/g1.png", "images/g1.png")
+	store.addMapping("http://s3.local/bucket/images/g2.png", "images/g2.png")
+	svc := NewService(store, testLogger())
+
+	blocks := []json.RawMessage{
+		json.RawMessage(`{"type":"gallery","data":{"items":[{"kind":"image","value":"http://s3.local/bucket/images/g1.png"},{"kind":"text","value":"http://s3.local/bucket/images/g2.png"},{"kind":"embed","value":"http://s3.local/bucket/images/g2.png"}]}}`),
+	}
+	svc.HandlePageDeleted(context.Background(), nil, blocks)
+
+	deleted := store.deletedKeys()
+	if len(deleted) != 1 || deleted[0] != "images/g1.png" {
+		t.Fatalf("expected only the image card to be deleted, got %v", deleted)
+	}
+}
+
 func TestHandlePageDeleted_LegacyImagesArray(t *testing.T) {
 	store := newMockMediaStore()
 	store.addMapping("http://s3.local/bucket/images/old1.png", "images/old1.png")
@@ -230,3 +250,93 @@ func TestHandlePageDeleted_EmptyCoverIgnored(t *testing.T) {
 		t.Fatalf("expected 0 deletions for empty cover, got %d", len(deleted))
 	}
 }
+
+// concurrencyStore records how many deletions run at once.
+type concurrencyStore struct {
+	*mockMediaStore
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+}
+
+func (c *concurrencyStore) DeleteObject(ctx context.Context, objectKey string) error {
+	c.mu.Lock()
+	c.active++
+	c.maxSeen = max(c.maxSeen, c.active)
+	c.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	defer func() {
+		c.mu.Lock()
+		c.active--
+		c.mu.Unlock()
+	}()
+	return c.mockMediaStore.DeleteObject(ctx, objectKey)
+}
+
+func TestHandlePageDeleted_ManyRefsDeletedConcurrently(t *testing.T) {
+	store := &concurrencyStore{mockMediaStore: newMockMediaStore()}
+	urls := make([]string, 0, 50)
+	for i := 0; i < 50; i++ {
+		url := fmt.Sprintf("http://s3.local/bucket/images/%d.png", i)
+		store.addMapping(url, fmt.Sprintf("images/%d.png", i))
+		urls = append(urls, url)
+	}
+	store.failOnDelete["images/7.png"] = true
+	svc := NewService(store, testLogger(), WithDeleteConcurrency(4))
+
+	images, _ := json.Marshal(urls)
+	blocks := []json.RawMessage{
+		json.RawMessage(`{"type":"gallery","data":{"images":` + string(images) + `}}`),
+	}
+	svc.HandlePageDeleted(context.Background(), nil, blocks)
+
+	deleted := store.deletedKeys()
+	if len(deleted) != 49 {
+		t.Fatalf("expected 49 deletions despite one failure, got %d", len(deleted))
+	}
+	for _, key := range deleted {
+		if key == "images/7.png" {
+			t.Fatal("expected failing key not to be recorded as deleted")
+		}
+	}
+	if store.maxSeen > 4 {
+		t.Fatalf("expected at most 4 concurrent deletions, saw %d", store.maxSeen)
+	}
+	if store.maxSeen < 2 {
+		t.Fatalf("expected deletions to run concurrently, saw %d", store.maxSeen)
+	}
+}
+
+type stubReferenceChecker struct {
+	referenced map[string]bool
+}
+
+func (c stubReferenceChecker) IsMediaReferenced(_ context.Context, objectKey string) (bool, error) {
+	return c.referenced[objectKey], nil
+}
+
+func TestHandlePageDeleted_SharedKeyKept(t *testing.T) {
+	store := newMockMediaStore()
+	store.addMapping("http://s3.local/bucket/images/shared.png", "images/shared.png")
+	store.addMapping("http://s3.local/bucket/images/own.png", "images/own.png")
+	// A second page still uses images/shared.png.
+	checker := stubReferenceChecker{referenced: map[string]bool{"images/shared.png": true}}
+	svc := NewService(store, testLogger(), WithReferenceChecker(checker))
+
+	blocks := []json.RawMessage{
+		json.RawMessage(`{"type":"image","data":{"url":"http://s3.local/bucket/images/shared.png"}}`),
+		json.RawMessage(`{"type":"image","data":{"url":"http://s3.local/bucket/images/own.png"}}`),
+	}
+	svc.HandlePageDeleted(context.Background(), nil, blocks)
+
+	deleted := store.deletedKeys()
+	if len(deleted) != 1 || deleted[0] != "images/own.png" {
+		t.Fatalf("expected only images/own.png deleted, got %v", deleted)
+	}
+}
+
+func TestGalleryItemKindMatchesPagesDomain(t *testing.T) {
+	if domain.GalleryItemKindImage != string(pagesdomain.GalleryItemKindImage) {
+		t.Fatalf("files gallery image kind %q, pages writes %q", domain.GalleryItemKindImage, pagesdomain.GalleryItemKindImage)
+	}
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+
+	"github.com/reggieanim/jot/internal/modules/files/domain"
+	"github.com/reggieanim/jot/internal/modules/files/ports"
+)
+
+const (
+	// thumbnailMaxSide bounds a thumbnail's width and height.
+	thumbnailMaxSide = 320
+	// thumbnailMaxPixels bounds the size of the images we decode, so a small
+	// upload declaring huge dimensions cannot exhaust memory.
+	thumbnailMaxPixels = 50_000_000
+)
+
+var (
+	// ErrThumbnailsUnavailable is returned when no thumbnail store is configured.
+	ErrThumbnailsUnavailable = errors.New("thumbnails unavailable")
+	// ErrImageTooLarge is returned for images over thumbnailMaxPixels.
+	ErrImageTooLarge = errors.New("image too large")
+)
+
+// WithThumbnailStore enables thumbnail regeneration against store.
+func WithThumbnailStore(store ports.ThumbnailStore) Option {
+	return func(s *Service) {
+		s.thumbnails = store
+	}
+}
+
+// RegenerateThumbnails generates the missing thumbnails of the images a page
+// references through its cover and blocks, and returns how many it stored.
+// Images that fail are skipped and reported together.
+func (s *Service) RegenerateThumbnails(ctx context.Context, cover *string, rawBlocks []json.RawMessage) (int, error) {
+	if s.thumbnails == nil {
+		return 0, ErrThumbnailsUnavailable
+	}
+
+	generated := 0
+	seen := make(map[string]bool)
+	var failures []error
+	for _, ref := range s.extractRefs(cover, rawBlocks) {
+		if seen[ref.ObjectKey] {
+			continue
+		}
+		seen[ref.ObjectKey] = true
+
+		created, err := regenerateThumbnail(ctx, s.thumbnails, ref.ObjectKey)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("thumbnail %s: %w", ref.ObjectKey, err))
+			continue
+		}
+		if created {
+			generated++
+		}
+	}
+	return generated, errors.Join(failures...)
+}
+
+// regenerateThumbnail stores a thumbnail for the image at objectKey unless it
+// already has one, and reports whether it stored one. Objects that are not
+// uploaded raster images are skipped.
+func regenerateThumbnail(ctx context.Context, store ports.ThumbnailStore, objectKey string) (bool, error) {
+	thumbnailKey := domain.ThumbnailKey(objectKey)
+	if thumbnailKey == "" {
+		return false, nil
+	}
+	exists, err := store.ObjectExists(ctx, thumbnailKey)
+	if err != nil {
+		return false, fmt.Errorf("check thumbnail: %w", err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	content, err := store.GetObject(ctx, objectKey)
+	if err != nil {
+		return false, fmt.Errorf("read image: %w", err)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if errors.Is(err, image.ErrFormat) {
+		// SVGs and other formats we cannot decode have no thumbnail.
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("decode image header: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > thumbnailMaxPixels {
+		return false, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, config.Width, config.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return false, fmt.Errorf("decode image: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaleToFit(src, thumbnailMaxSide)); err != nil {
+		return false, fmt.Errorf("encode thumbnail: %w", err)
+	}
+	if err := store.PutObject(ctx, thumbnailKey, "image/png", buf.Bytes()); err != nil {
+		return false, fmt.Errorf("store thumbnail: %w", err)
+	}
+	return true, nil
+}
+
+// scaleToFit shrinks src by nearest-neighbour sampling so neither side
+// exceeds maxSide. Smaller images are returned unchanged.
+func scaleToFit(src image.Image, maxSide int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return src
+	}
+	scaledWidth, scaledHeight := maxSide, maxSide
+	if width > height {
+		scaledHeight = max(height*maxSide/width, 1)
+	} else {
+		scaledWidth = max(width*maxSide/height, 1)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, scaledWidth, scaledHeight))
+	for y := range scaledHeight {
+		srcY := bounds.Min.Y + y*height/scaledHeight
+		for x := range scaledWidth {
+			dst.Set(x, y, src.At(bounds.Min.X+x*width/scaledWidth, srcY))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"sync"
+	"testing"
+)
+
+type mockThumbnailStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	puts    []string
+}
+
+func (m *mockThumbnailStore) GetObject(_ context.Context, objectKey string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.objects[objectKey], nil
+}
+
+func (m *mockThumbnailStore) ObjectExists(_ context.Context, objectKey string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.objects[objectKey]
+	return ok, nil
+}
+
+func (m *mockThumbnailStore) PutObject(_ context.Context, objectKey, _ string, content []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[objectKey] = content
+	m.puts = append(m.puts, objectKey)
+	return nil
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.White)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRegenerateThumbnails_GeneratesMissingThumbnail(t *testing.T) {
+	media := newMockMediaStore()
+	media.addMapping("http://s3.local/bucket/images/abc.jpg", "images/abc.jpg")
+	media.addMapping("http://s3.local/bucket/images/def.png", "images/def.png")
+	media.addMapping("http://s3.local/bucket/audio/song.mp3", "audio/song.mp3")
+	thumbnails := &mockThumbnailStore{objects: map[string][]byte{
+		"images/abc.jpg":     testPNG(t, 1200, 600),
+		"images/def.png":     testPNG(t, 100, 100),
+		"thumbnails/def.png": testPNG(t, 100, 100),
+		"audio/song.mp3":     []byte("ID3"),
+	}}
+	svc := NewService(media, testLogger(), WithThumbnailStore(thumbnails))
+
+	cover := "http://s3.local/bucket/images/abc.jpg"
+	blocks := []json.RawMessage{
+		json.RawMessage(`{"type":"image","data":{"url":"http://s3.local/bucket/images/abc.jpg"}}`),
+		json.RawMessage(`{"type":"image","data":{"url":"http://s3.local/bucket/images/def.png"}}`),
+		json.RawMessage(`{"type":"audio","data":{"url":"http://s3.local/bucket/audio/song.mp3"}}`),
+	}
+	generated, err := svc.RegenerateThumbnails(context.Background(), &cover, blocks)
+	if err != nil {
+		t.Fatalf("regenerate thumbnails: %v", err)
+	}
+	if generated != 1 {
+		t.Fatalf("expected 1 thumbnail generated, got %d", generated)
+	}
+	if len(thumbnails.puts) != 1 || thumbnails.puts[0] != "thumbnails/abc.png" {
+		t.Fatalf("expected only thumbnails/abc.png stored, got %v", thumbnails.puts)
+	}
+
+	thumbnail, err := png.Decode(bytes.NewReader(thumbnails.objects["thumbnails/abc.png"]))
+	if err != nil {
+		t.Fatalf("decode thumbnail: %v", err)
+	}
+	if size := thumbnail.Bounds().Size(); size.X != 320 || size.Y != 160 {
+		t.Fatalf("expected a 320x160 thumbnail, got %dx%d", size.X, size.Y)
+	}
+}
+
+func TestRegenerateThumbnails_Unconfigured(t *testing.T) {
+	svc := NewService(newMockMediaStore(), testLogger())
+	if _, err := svc.RegenerateThumbnails(context.Background(), nil, nil); !errors.Is(err, ErrThumbnailsUnavailable) {
+		t.Fatalf("expected ErrThumbnailsUnavailable, got %v", err)
+	}
+}
+
+// oversizedPNG returns a PNG header declaring width x height pixels without
+// any image data behind it.
+func oversizedPNG(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12] = 8 // bit depth
+	ihdr[13] = 6 // RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(13))
+	buf.Write(ihdr)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	return buf.Bytes()
+}
+
+func TestRegenerateThumbnails_RejectsOversizedImages(t *testing.T) {
+	media := newMockMediaStore()
+	media.addMapping("http://s3.local/bucket/images/huge.png", "images/huge.png")
+	thumbnails := &mockThumbnailStore{objects: map[string][]byte{
+		"images/huge.png": oversizedPNG(100_000, 100_000),
+	}}
+	svc := NewService(media, testLogger(), WithThumbnailStore(thumbnails))
+
+	cover := "http://s3.local/bucket/images/huge.png"
+	generated, err := svc.RegenerateThumbnails(context.Background(), &cover, nil)
+	if !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("expected ErrImageTooLarge, got %v", err)
+	}
+	if generated != 0 || len(thumbnails.puts) != 0 {
+		t.Fatalf("expected no thumbnail stored, got %v", thumbnails.puts)
+	}
+}
+
+func TestHandlePageDeleted_DeletesThumbnails(t *testing.T) {
+	media := newMockMediaStore()
+	media.addMapping("http://s3.local/bucket/images/abc.jpg", "images/abc.jpg")
+	media.addMapping("http://s3.local/bucket/audio/song.mp3", "audio/song.mp3")
+	svc := NewService(media, testLogger(), WithThumbnailStore(&mockThumbnailStore{objects: map[string][]byte{}}))
+
+	cover := "http://s3.local/bucket/images/abc.jpg"
+	blocks := []json.RawMessage{
+		json.RawMessage(`{"type":"audio","data":{"url":"http://s3.local/bucket/audio/song.mp3"}}`),
+	}
+	svc.HandlePageDeleted(context.Background(), &cover, blocks)
+
+	deleted := media.deletedKeys()
+	slices.Sort(deleted)
+	want := []string{"audio/song.mp3", "images/abc.jpg", "thumbnails/abc.png"}
+	if !slices.Equal(deleted, want) {
+		t.Fatalf("expected %v deleted, got %v", want, deleted)
+	}
+}
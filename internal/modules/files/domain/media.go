@@ -1,7 +1,39 @@
 package domain
 
+import (
+	"path"
+	"strings"
+)
+
 // MediaRef represents a reference to a stored media object.
 type MediaRef struct {
 	// ObjectKey is the storage-relative key (e.g. "images/abc-123.png").
 	ObjectKey string
 }
+
+const (
+	imagePrefix     = "images/"
+	thumbnailPrefix = "thumbnails/"
+)
+
+// ThumbnailKey returns the key of the PNG thumbnail for an uploaded image, or
+// "" for objects that are not uploaded images.
+func ThumbnailKey(objectKey string) string {
+	name, ok := strings.CutPrefix(objectKey, imagePrefix)
+	if !ok || name == "" {
+		return ""
+	}
+	return thumbnailPrefix + strings.TrimSuffix(name, path.Ext(name)) + ".png"
+}
+
+// GalleryItemKindImage is the kind of gallery card whose value is an uploaded
+// image, as the pages module writes it into data.items.
+const GalleryItemKindImage = "image"
+
+// StorageCorrection records a user's storage usage that drifted from the
+// sizes of the objects they have stored.
+type StorageCorrection struct {
+	UserID string
+	From   int64
+	To     int64
+}
@@ -2,6 +2,9 @@ package ports
 
 import (
 	"context"
+	"time"
+
+	"github.com/reggieanim/jot/internal/modules/files/domain"
 )
 
 // MediaStore abstracts object storage operations needed by the files module.
@@ -12,3 +15,44 @@ type MediaStore interface {
 	// Returns empty string if the URL doesn't belong to this store.
 	ObjectKeyFromURL(rawURL string) string
 }
+
+// ReferenceChecker looks up whether stored media is still in use.
+type ReferenceChecker interface {
+	// IsMediaReferenced reports whether any remaining page references
+	// objectKey.
+	IsMediaReferenced(ctx context.Context, objectKey string) (bool, error)
+}
+
+// StorageUsage tracks how many bytes of media each user has stored.
+type StorageUsage interface {
+	// Reserve adds size to userID's usage and reports true, or leaves it
+	// unchanged and reports false when the total would pass limit. A limit of
+	// zero or less is unlimited.
+	Reserve(ctx context.Context, userID string, size, limit int64) (bool, error)
+	// Release subtracts size from userID's usage.
+	Release(ctx context.Context, userID string, size int64) error
+	// RecordObject remembers that userID stored objectKey of size bytes.
+	RecordObject(ctx context.Context, userID, objectKey string, size int64) error
+	// ReleaseObject forgets objectKey and subtracts its size from its
+	// uploader's usage. Untracked keys are ignored.
+	ReleaseObject(ctx context.Context, objectKey string) error
+}
+
+// StorageReconciler corrects storage usage that drifted from the recorded
+// objects.
+type StorageReconciler interface {
+	// ReconcileUsage resets the usage of users whose usage has not changed
+	// since idleSince to the total size of their recorded objects, and
+	// returns the corrections it made.
+	ReconcileUsage(ctx context.Context, idleSince time.Time) ([]domain.StorageCorrection, error)
+}
+
+// ThumbnailStore reads and writes objects by key for thumbnail generation.
+type ThumbnailStore interface {
+	// GetObject returns the object's content.
+	GetObject(ctx context.Context, objectKey string) ([]byte, error)
+	// ObjectExists reports whether an object is stored under objectKey.
+	ObjectExists(ctx context.Context, objectKey string) (bool, error)
+	// PutObject stores content under objectKey, replacing any existing object.
+	PutObject(ctx context.Context, objectKey, contentType string, content []byte) error
+}
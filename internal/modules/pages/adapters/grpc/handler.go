@@ -9,14 +9,19 @@ import (
 	jnats "github.com/nats-io/nats.go"
 	"github.com/reggieanim/jot/internal/modules/pages/app"
 	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	platformnats "github.com/reggieanim/jot/internal/platform/eventbus/nats"
+	platformgrpc "github.com/reggieanim/jot/internal/platform/realtime/grpc"
 	"github.com/reggieanim/jot/internal/shared/errs"
 	pagesv1 "github.com/reggieanim/jot/proto/jot/pages/v1"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+const subscribeBuffer = 64
+
 type Server struct {
 	pagesv1.UnimplementedPagesServer
 	pagesv1.UnimplementedPagesRealtimeServer
@@ -32,6 +37,16 @@ type pageEvent struct {
 	Timestamp time.Time   `json:"timestamp"`
 }
 
+// pageEventTypes lists the page lifecycle events forwarded to realtime
+// subscribers. Typing and presence chatter on the same subject is skipped.
+var pageEventTypes = map[string]bool{
+	"page.created":        true,
+	"page.blocks.updated": true,
+	"page.published":      true,
+	"page.unpublished":    true,
+	"page.deleted":        true,
+}
+
 func Register(server *grpc.Server, service *app.Service, conn *jnats.Conn, subject string, logger *zap.Logger) {
 	handler := &Server{service: service, conn: conn, subject: subject, logger: logger}
 	pagesv1.RegisterPagesServer(server, handler)
@@ -47,8 +62,11 @@ func (server *Server) CreatePage(ctx context.Context, request *pagesv1.CreatePag
 	if c := request.GetCover(); c != "" {
 		cover = &c
 	}
-	// gRPC does not carry user context yet; owner_id left empty for internal use.
-	page, err := server.service.CreatePage(ctx, "", request.GetTitle(), cover, blocks)
+	uid, ok := platformgrpc.UserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	page, err := server.service.CreatePage(ctx, string(uid), request.GetTitle(), cover, blocks)
 	if err != nil {
 		return nil, mapError(err)
 	}
@@ -56,7 +74,7 @@ func (server *Server) CreatePage(ctx context.Context, request *pagesv1.CreatePag
 }
 
 func (server *Server) GetPage(ctx context.Context, request *pagesv1.GetPageRequest) (*pagesv1.GetPageResponse, error) {
-	page, err := server.service.GetPage(ctx, domain.PageID(request.GetPageId()))
+	page, err := server.resolveView(ctx, domain.PageID(request.GetPageId()))
 	if err != nil {
 		return nil, mapError(err)
 	}
@@ -69,32 +87,80 @@ func (server *Server) UpdateBlocks(ctx context.Context, request *pagesv1.UpdateB
 		return nil, err
 	}
 	pageID := domain.PageID(request.GetPageId())
-	// gRPC does not carry user context yet; ownership not checked for internal use.
-	if err := server.service.UpdateBlocks(ctx, "", pageID, blocks); err != nil {
-		return nil, mapError(err)
+
+	var expectedUpdatedAt *time.Time
+	if raw := request.GetBaseUpdatedAt(); raw != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "base_updated_at must be RFC3339Nano")
+		}
+		expectedUpdatedAt = &parsed
 	}
-	page, err := server.service.GetPage(ctx, pageID)
+
+	uid, _ := platformgrpc.UserIDFromContext(ctx)
+	page, err := server.service.UpdateBlocksRealtime(ctx, string(uid), pageID, blocks, expectedUpdatedAt)
 	if err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return nil, server.conflictError(ctx, pageID)
+		}
 		return nil, mapError(err)
 	}
 	return &pagesv1.UpdateBlocksResponse{Page: pageToProto(page)}, nil
 }
 
+// resolveView checks the caller's view access as the HTTP handlers do, taking
+// a share token from the "jot_share" metadata.
+func (server *Server) resolveView(ctx context.Context, pageID domain.PageID) (domain.Page, error) {
+	uid, _ := platformgrpc.UserIDFromContext(ctx)
+	var shareToken string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("jot_share"); len(values) > 0 {
+			shareToken = values[0]
+		}
+	}
+	page, _, err := server.service.ResolvePageAccess(ctx, string(uid), pageID, shareToken, domain.ShareAccessView)
+	return page, err
+}
+
+// conflictError builds an ABORTED status carrying the latest page as an
+// UpdateBlocksResponse detail so the client can rebase without a round trip.
+func (server *Server) conflictError(ctx context.Context, pageID domain.PageID) error {
+	conflict := status.New(codes.Aborted, "page was modified concurrently")
+	latest, err := server.service.GetPage(ctx, pageID)
+	if err != nil {
+		return conflict.Err()
+	}
+	withPage, err := conflict.WithDetails(&pagesv1.UpdateBlocksResponse{Page: pageToProto(latest)})
+	if err != nil {
+		return conflict.Err()
+	}
+	return withPage.Err()
+}
+
 func (server *Server) SubscribePage(request *pagesv1.SubscribePageRequest, stream pagesv1.PagesRealtime_SubscribePageServer) error {
-	subscription, err := server.conn.SubscribeSync(server.subject)
+	ctx := stream.Context()
+	msgs := make(chan *jnats.Msg, subscribeBuffer)
+	var unsubscribe func()
+	var err error
+	if pageID := request.GetPageId(); pageID != "" {
+		if _, err := server.resolveView(ctx, domain.PageID(pageID)); err != nil {
+			return mapError(err)
+		}
+		unsubscribe, err = platformnats.SubscribePage(server.conn, server.subject, pageID, msgs)
+	} else {
+		unsubscribe, err = platformnats.SubscribeAllPages(server.conn, server.subject, msgs)
+	}
 	if err != nil {
 		return status.Errorf(codes.Unavailable, "subscribe nats: %v", err)
 	}
-	defer subscription.Unsubscribe()
+	defer unsubscribe()
 
-	ctx := stream.Context()
 	for {
-		msg, err := subscription.NextMsgWithContext(ctx)
-		if err != nil {
-			if ctx.Err() != nil {
-				return nil
-			}
-			return status.Errorf(codes.Unavailable, "stream nats: %v", err)
+		var msg *jnats.Msg
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg = <-msgs:
 		}
 
 		var event pageEvent
@@ -102,9 +168,15 @@ func (server *Server) SubscribePage(request *pagesv1.SubscribePageRequest, strea
 			server.logger.Warn("invalid page event payload", zap.Error(err))
 			continue
 		}
+		if !pageEventTypes[event.Type] {
+			continue
+		}
 		if request.GetPageId() != "" && string(event.Page.ID) != request.GetPageId() {
 			continue
 		}
+		if request.GetPageId() == "" && !server.canView(ctx, event) {
+			continue
+		}
 
 		if err := stream.Send(&pagesv1.PageEvent{
 			Type:      event.Type,
@@ -116,6 +188,17 @@ func (server *Server) SubscribePage(request *pagesv1.SubscribePageRequest, strea
 	}
 }
 
+// canView reports whether a subscriber to every page may see event. Deleted
+// pages can no longer be resolved, so only their owner hears of them.
+func (server *Server) canView(ctx context.Context, event pageEvent) bool {
+	if event.Type == "page.deleted" {
+		uid, _ := platformgrpc.UserIDFromContext(ctx)
+		return uid != "" && event.Page.OwnerID != nil && *event.Page.OwnerID == string(uid)
+	}
+	_, err := server.resolveView(ctx, event.Page.ID)
+	return err == nil
+}
+
 func blocksFromProto(blocks []*pagesv1.Block) ([]domain.Block, error) {
 	result := make([]domain.Block, 0, len(blocks))
 	for _, block := range blocks {
@@ -187,5 +270,8 @@ func mapError(err error) error {
 	if errors.Is(err, errs.ErrNotFound) {
 		return status.Error(codes.NotFound, "not found")
 	}
+	if errors.Is(err, errs.ErrForbidden) {
+		return status.Error(codes.PermissionDenied, "forbidden")
+	}
 	return status.Error(codes.Internal, "internal error")
 }
@@ -0,0 +1,161 @@
+package grpcadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reggieanim/jot/internal/modules/pages/app"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/modules/pages/ports"
+	platformgrpc "github.com/reggieanim/jot/internal/platform/realtime/grpc"
+	"github.com/reggieanim/jot/internal/shared/errs"
+	pagesv1 "github.com/reggieanim/jot/proto/jot/pages/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// singlePageRepo serves one page and implements just enough of
+// ports.PageRepository for block updates; other methods panic.
+type singlePageRepo struct {
+	ports.PageRepository
+	page domain.Page
+}
+
+func (repo *singlePageRepo) GetByID(_ context.Context, pageID domain.PageID) (domain.Page, error) {
+	if pageID != repo.page.ID {
+		return domain.Page{}, errs.ErrNotFound
+	}
+	return repo.page, nil
+}
+
+func (repo *singlePageRepo) UpdateBlocksOptimistic(_ context.Context, _ domain.PageID, blocks []domain.Block, _ *domain.ReadingStats, expectedUpdatedAt *time.Time) error {
+	if expectedUpdatedAt != nil && !expectedUpdatedAt.Equal(repo.page.UpdatedAt) {
+		return errs.ErrConflict
+	}
+	repo.page.Blocks = blocks
+	repo.page.UpdatedAt = repo.page.UpdatedAt.Add(time.Second)
+	return nil
+}
+
+func (repo *singlePageRepo) SaveRevision(_ context.Context, _ domain.PageID, _ []domain.Block, _ string, _ time.Time) error {
+	return nil
+}
+
+type noOpEvents struct{}
+
+func (noOpEvents) PageCreated(_ context.Context, _ domain.Page) error   { return nil }
+func (noOpEvents) BlocksUpdated(_ context.Context, _ domain.Page) error { return nil }
+func (noOpEvents) PagePublished(_ context.Context, _ domain.Page) error { return nil }
+func (noOpEvents) PageDeleted(_ context.Context, _ domain.Page) error   { return nil }
+
+type fixedClock struct{ now time.Time }
+
+func (clock fixedClock) Now() time.Time { return clock.now }
+
+func TestUpdateBlocksRejectsStaleBaseUpdatedAt(t *testing.T) {
+	owner := "owner-1"
+	updatedAt := time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)
+	repo := &singlePageRepo{page: domain.Page{ID: "page-1", OwnerID: &owner, Title: "Draft", UpdatedAt: updatedAt}}
+	server := &Server{
+		service: app.NewService(repo, noOpEvents{}, fixedClock{now: updatedAt}),
+		logger:  zap.NewNop(),
+	}
+	ctx := platformgrpc.ContextWithUserID(context.Background(), "owner-1")
+	request := func(base time.Time) *pagesv1.UpdateBlocksRequest {
+		return &pagesv1.UpdateBlocksRequest{
+			PageId:        "page-1",
+			Blocks:        []*pagesv1.Block{{Id: "b1", Type: "paragraph", DataJson: `{"text":"hi"}`}},
+			BaseUpdatedAt: base.Format(time.RFC3339Nano),
+		}
+	}
+
+	// First writer is up to date and wins.
+	response, err := server.UpdateBlocks(ctx, request(updatedAt))
+	if err != nil {
+		t.Fatalf("expected fresh update to succeed, got %v", err)
+	}
+	latestUpdatedAt := response.GetPage().GetUpdatedAt()
+
+	// Second writer still holds the original timestamp.
+	_, err = server.UpdateBlocks(ctx, request(updatedAt))
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted for stale base_updated_at, got %v", err)
+	}
+	details := status.Convert(err).Details()
+	if len(details) != 1 {
+		t.Fatalf("expected latest page attached to the error, got %v", details)
+	}
+	latest, ok := details[0].(*pagesv1.UpdateBlocksResponse)
+	if !ok || latest.GetPage().GetUpdatedAt() != latestUpdatedAt {
+		t.Fatalf("expected latest page with updated_at %s, got %v", latestUpdatedAt, details[0])
+	}
+
+	if _, err := server.UpdateBlocks(ctx, &pagesv1.UpdateBlocksRequest{PageId: "page-1", BaseUpdatedAt: "yesterday"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for malformed base_updated_at, got %v", err)
+	}
+}
+
+// subscribeStream is a SubscribePage stream that records what is sent.
+type subscribeStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*pagesv1.PageEvent
+}
+
+func (stream *subscribeStream) Context() context.Context { return stream.ctx }
+
+func (stream *subscribeStream) Send(event *pagesv1.PageEvent) error {
+	stream.sent = append(stream.sent, event)
+	return nil
+}
+
+func TestPrivatePagesRequireAccess(t *testing.T) {
+	owner := "owner-1"
+	repo := &singlePageRepo{page: domain.Page{ID: "page-1", OwnerID: &owner, Title: "Private"}}
+	server := &Server{
+		service: app.NewService(repo, noOpEvents{}, fixedClock{now: time.Now()}),
+		logger:  zap.NewNop(),
+	}
+
+	anonymous := context.Background()
+	if _, err := server.GetPage(anonymous, &pagesv1.GetPageRequest{PageId: "page-1"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected anonymous GetPage to be denied, got %v", err)
+	}
+	err := server.SubscribePage(&pagesv1.SubscribePageRequest{PageId: "page-1"}, &subscribeStream{ctx: anonymous})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected anonymous SubscribePage to be denied, got %v", err)
+	}
+
+	response, err := server.GetPage(platformgrpc.ContextWithUserID(anonymous, "owner-1"), &pagesv1.GetPageRequest{PageId: "page-1"})
+	if err != nil {
+		t.Fatalf("expected the owner to get the page, got %v", err)
+	}
+	if response.GetPage().GetTitle() != "Private" {
+		t.Fatalf("unexpected page %+v", response.GetPage())
+	}
+}
+
+func TestSubscribeAllPagesOnlyForwardsViewablePages(t *testing.T) {
+	owner := "owner-1"
+	repo := &singlePageRepo{page: domain.Page{ID: "page-1", OwnerID: &owner}}
+	server := &Server{
+		service: app.NewService(repo, noOpEvents{}, fixedClock{now: time.Now()}),
+		logger:  zap.NewNop(),
+	}
+	event := pageEvent{Type: "page.blocks.updated", Page: repo.page}
+
+	if server.canView(context.Background(), event) {
+		t.Fatal("expected an anonymous subscriber not to see a private page")
+	}
+	ownerCtx := platformgrpc.ContextWithUserID(context.Background(), "owner-1")
+	if !server.canView(ownerCtx, event) {
+		t.Fatal("expected the owner to see their page")
+	}
+	deleted := pageEvent{Type: "page.deleted", Page: domain.Page{ID: "gone", OwnerID: &owner}}
+	if !server.canView(ownerCtx, deleted) || server.canView(platformgrpc.ContextWithUserID(context.Background(), "someone"), deleted) {
+		t.Fatal("expected only the owner to hear that their page was deleted")
+	}
+}
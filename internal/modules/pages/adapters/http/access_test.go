@@ -0,0 +1,156 @@
+package httpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reggieanim/jot/internal/modules/pages/app"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/modules/pages/ports"
+	usersdomain "github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/platform/auth"
+	"github.com/reggieanim/jot/internal/shared/clock"
+	"github.com/reggieanim/jot/internal/shared/errs"
+	"go.uber.org/zap"
+)
+
+// sharedPageRepo serves one owned page with a single edit share link and
+// implements just enough of ports.PageRepository to resolve access.
+type sharedPageRepo struct {
+	ports.PageRepository
+	page  domain.Page
+	share domain.PageShareLink
+}
+
+func (repo *sharedPageRepo) GetByID(_ context.Context, pageID domain.PageID) (domain.Page, error) {
+	if pageID != repo.page.ID {
+		return domain.Page{}, errs.ErrNotFound
+	}
+	return repo.page, nil
+}
+
+func (repo *sharedPageRepo) GetCollaboratorAccess(_ context.Context, _ domain.PageID, _ string) (domain.ShareAccess, error) {
+	return "", errs.ErrNotFound
+}
+
+func (repo *sharedPageRepo) GetShareLinkByToken(_ context.Context, token string) (domain.PageShareLink, error) {
+	if token != repo.share.Token {
+		return domain.PageShareLink{}, errs.ErrNotFound
+	}
+	return repo.share, nil
+}
+
+func (repo *sharedPageRepo) GetShareLinkByCode(_ context.Context, _ string) (domain.PageShareLink, error) {
+	return domain.PageShareLink{}, errs.ErrNotFound
+}
+
+func (repo *sharedPageRepo) RecordShareLinkUse(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (repo *sharedPageRepo) UpsertCollabUser(_ context.Context, _ domain.PageID, _ string, _ string) error {
+	return nil
+}
+
+func TestGetPageAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	owner := "owner-1"
+	repo := &sharedPageRepo{
+		page:  domain.Page{ID: "page-1", Title: "Draft", OwnerID: &owner},
+		share: domain.PageShareLink{Token: "edit-token", PageID: "page-1", Access: domain.ShareAccessEdit},
+	}
+	handler := &Handler{
+		logger:  zap.NewNop(),
+		service: app.NewService(repo, nil, clock.SystemClock{}),
+	}
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		if userID := ctx.GetHeader("X-Test-User"); userID != "" {
+			ctx.Set(auth.UserIDKey, usersdomain.UserID(userID))
+		}
+	})
+	router.GET("/pages/:pageID/access", handler.getPageAccess)
+
+	tests := []struct {
+		name string
+		user string
+		path string
+		want string
+	}{
+		{name: "owner", user: owner, path: "/pages/page-1/access", want: "owner"},
+		{name: "share edit", user: "guest-1", path: "/pages/page-1/access?share=edit-token", want: "edit"},
+		{name: "anonymous share edit", path: "/pages/page-1/access?share=edit-token", want: "edit"},
+		{name: "no access", user: "stranger-1", path: "/pages/page-1/access", want: "none"},
+		{name: "bad token", path: "/pages/page-1/access?share=wrong", want: "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.user != "" {
+				req.Header.Set("X-Test-User", tt.user)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if string(body["access"]) != `"`+tt.want+`"` {
+				t.Fatalf("expected access %q, got %s", tt.want, recorder.Body.String())
+			}
+			if _, ok := body["blocks"]; ok {
+				t.Fatalf("expected no page content, got %s", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetPageAccessWithPrivatePagesHidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	owner := "owner-1"
+	repo := &sharedPageRepo{
+		page:  domain.Page{ID: "page-1", Title: "Draft", OwnerID: &owner},
+		share: domain.PageShareLink{Token: "edit-token", PageID: "page-1", Access: domain.ShareAccessEdit},
+	}
+	handler := &Handler{
+		logger:  zap.NewNop(),
+		service: app.NewService(repo, nil, clock.SystemClock{}, app.WithPrivatePagesHidden(true)),
+	}
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		if userID := ctx.GetHeader("X-Test-User"); userID != "" {
+			ctx.Set(auth.UserIDKey, usersdomain.UserID(userID))
+		}
+	})
+	router.GET("/pages/:pageID/access", handler.getPageAccess)
+
+	for _, tt := range []struct {
+		name string
+		user string
+		path string
+		want string
+	}{
+		{name: "owner", user: owner, path: "/pages/page-1/access", want: `{"access":"owner"}`},
+		{name: "stranger", user: "stranger-1", path: "/pages/page-1/access", want: `{"access":"none"}`},
+		{name: "anonymous", path: "/pages/page-1/access", want: `{"access":"none"}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.user != "" {
+				req.Header.Set("X-Test-User", tt.user)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			if recorder.Code != http.StatusOK || recorder.Body.String() != tt.want {
+				t.Fatalf("expected 200 %s, got %d %s", tt.want, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
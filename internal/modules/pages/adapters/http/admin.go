@@ -0,0 +1,155 @@
+package httpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jnats "github.com/nats-io/nats.go"
+	filesapp "github.com/reggieanim/jot/internal/modules/files/app"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	platformnats "github.com/reggieanim/jot/internal/platform/eventbus/nats"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultEventHistoryWindow = time.Hour
+	defaultEventHistoryLimit  = 100
+	maxEventHistoryLimit      = 500
+)
+
+// eventHistoryReader returns up to limit of a page's stored realtime
+// messages, oldest first, beginning at start.
+type eventHistoryReader func(pageID string, start jnats.SubOpt, limit int) ([]*jnats.Msg, error)
+
+type historyEvent struct {
+	Seq   uint64      `json:"seq,omitempty"`
+	Event string      `json:"event"`
+	Data  streamEvent `json:"data"`
+}
+
+// thumbnailRegenerator generates missing thumbnails for the images a page
+// references.
+type thumbnailRegenerator interface {
+	RegenerateThumbnails(ctx context.Context, cover *string, rawBlocks []json.RawMessage) (int, error)
+}
+
+// WithThumbnailRegenerator enables the admin endpoint that regenerates a
+// page's missing thumbnails.
+func WithThumbnailRegenerator(regenerator thumbnailRegenerator) Option {
+	return func(handler *Handler) {
+		handler.thumbnails = regenerator
+	}
+}
+
+// WithAdminUserIDs grants the comma-separated users access to the admin
+// debugging endpoints.
+func WithAdminUserIDs(userIDs string) Option {
+	return func(handler *Handler) {
+		handler.adminUserIDs = userIDs
+	}
+}
+
+// listPageEventHistory returns a page's events stored in JetStream for
+// debugging realtime issues. since is a stream sequence to read after or an
+// RFC3339 time; without it the last hour is read.
+func (handler *Handler) listPageEventHistory(ctx *gin.Context) {
+	pageID := ctx.Param("pageID")
+	if handler.readEventHistory == nil {
+		ctx.JSON(503, gin.H{"error": "event history unavailable"})
+		return
+	}
+
+	start := jnats.StartTime(time.Now().Add(-defaultEventHistoryWindow))
+	if since := strings.TrimSpace(ctx.Query("since")); since != "" {
+		if seq, err := strconv.ParseUint(since, 10, 64); err == nil {
+			start = jnats.StartSequence(seq + 1)
+		} else if at, err := time.Parse(time.RFC3339Nano, since); err == nil {
+			start = jnats.StartTime(at)
+		} else {
+			ctx.JSON(400, gin.H{"error": "since must be a stream sequence or RFC3339 time"})
+			return
+		}
+	}
+	limit := defaultEventHistoryLimit
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 {
+			limit = min(v, maxEventHistoryLimit)
+		}
+	}
+
+	msgs, err := handler.readEventHistory(pageID, start, limit)
+	if err != nil {
+		handler.logger.Warn("read event history failed", zap.Error(err))
+		ctx.JSON(503, gin.H{"error": "event history unavailable"})
+		return
+	}
+
+	items := make([]historyEvent, 0, len(msgs))
+	for _, msg := range msgs {
+		event, err := decodeStreamEvent(msg.Data)
+		if err != nil {
+			continue
+		}
+		name, ok := streamEventName(event, pageID)
+		if !ok {
+			continue
+		}
+		item := historyEvent{Event: name, Data: event}
+		item.Seq, _ = platformnats.StreamSequence(msg)
+		items = append(items, item)
+	}
+	ctx.JSON(200, gin.H{"items": items})
+}
+
+// regenerateThumbnails generates thumbnails for a page's images that lack
+// them, such as images uploaded before thumbnails existed.
+func (handler *Handler) regenerateThumbnails(ctx *gin.Context) {
+	if handler.thumbnails == nil {
+		ctx.JSON(503, gin.H{"error": "thumbnails unavailable"})
+		return
+	}
+	page, err := handler.service.GetPage(ctx.Request.Context(), domain.PageID(ctx.Param("pageID")))
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+
+	rawBlocks := make([]json.RawMessage, 0, len(page.Blocks))
+	for _, block := range page.Blocks {
+		raw, err := json.Marshal(block)
+		if err != nil {
+			handler.handleError(ctx, err)
+			return
+		}
+		rawBlocks = append(rawBlocks, raw)
+	}
+
+	generated, err := handler.thumbnails.RegenerateThumbnails(ctx.Request.Context(), page.Cover, rawBlocks)
+	if errors.Is(err, filesapp.ErrThumbnailsUnavailable) {
+		ctx.JSON(503, gin.H{"error": "thumbnails unavailable"})
+		return
+	}
+	if err != nil {
+		handler.logger.Warn("some thumbnails were not regenerated", zap.Error(err), zap.String("page_id", string(page.ID)))
+		ctx.JSON(200, gin.H{"generated": generated, "error": "some thumbnails could not be generated"})
+		return
+	}
+	ctx.JSON(200, gin.H{"generated": generated})
+}
+
+// getPlatformStats returns site-wide totals for the admin dashboard. Event
+// throughput is not included; it is exported as jot_nats_publish_total when
+// metrics are enabled.
+func (handler *Handler) getPlatformStats(ctx *gin.Context) {
+	stats, err := handler.service.PlatformStats(ctx.Request.Context())
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, stats)
+}
@@ -0,0 +1,71 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	jnats "github.com/nats-io/nats.go"
+	usersdomain "github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/platform/auth"
+	"go.uber.org/zap"
+)
+
+func TestListPageEventHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stream := &seededStream{}
+	stream.append(t, "page-1", "page.created")
+	stream.append(t, "page-2", "page.updated")
+	stream.append(t, "page-1", "page.updated")
+	stream.append(t, "page-1", "page.published")
+
+	handler := &Handler{
+		logger: zap.NewNop(),
+		readEventHistory: func(_ string, _ jnats.SubOpt, limit int) ([]*jnats.Msg, error) {
+			return stream.msgs[:min(limit, len(stream.msgs))], nil
+		},
+	}
+	issuer := auth.NewJWTIssuer("test-secret")
+	router := gin.New()
+	router.GET("/admin/pages/:pageID/events", auth.Middleware(issuer), auth.RequireAdmin("admin-1"), handler.listPageEventHistory)
+
+	request := func(userID string) *httptest.ResponseRecorder {
+		t.Helper()
+		token, err := issuer.Issue(usersdomain.UserID(userID), userID+"@example.com")
+		if err != nil {
+			t.Fatalf("issue token: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/admin/pages/page-1/events", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	if code := request("reader-1").Code; code != http.StatusForbidden {
+		t.Fatalf("expected non-admin to get 403, got %d", code)
+	}
+
+	recorder := request("admin-1")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var body struct {
+		Items []struct {
+			Seq   uint64 `json:"seq"`
+			Event string `json:"event"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var got []uint64
+	for _, item := range body.Items {
+		got = append(got, item.Seq)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 3 || got[2] != 4 {
+		t.Fatalf("expected page-1 events 1, 3, 4 in stream order, got %v", got)
+	}
+}
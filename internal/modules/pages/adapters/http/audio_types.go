@@ -0,0 +1,74 @@
+package httpadapter
+
+import (
+	"bytes"
+	"strings"
+)
+
+// audioTypeAliases maps common non-canonical audio content types onto the
+// names sniffAudioType reports.
+var audioTypeAliases = map[string]string{
+	"audio/mp3":    "audio/mpeg",
+	"audio/x-mp3":  "audio/mpeg",
+	"audio/m4a":    "audio/mp4",
+	"audio/x-m4a":  "audio/mp4",
+	"audio/x-wav":  "audio/wav",
+	"audio/wave":   "audio/wav",
+	"audio/x-flac": "audio/flac",
+}
+
+// WithAudioContentTypes restricts audio uploads to the given comma-separated
+// content types. The format is sniffed from the file itself, so a renamed
+// file is rejected regardless of its extension or declared type. When unset,
+// any audio/* upload is accepted.
+func WithAudioContentTypes(types string) Option {
+	return func(handler *Handler) {
+		allowed := make(map[string]bool)
+		for _, contentType := range strings.Split(types, ",") {
+			contentType = canonicalAudioType(contentType)
+			if contentType != "" {
+				allowed[contentType] = true
+			}
+		}
+		if len(allowed) > 0 {
+			handler.audioTypes = allowed
+		}
+	}
+}
+
+func canonicalAudioType(contentType string) string {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if alias, ok := audioTypeAliases[contentType]; ok {
+		return alias
+	}
+	return contentType
+}
+
+// sniffAudioType identifies common audio containers from their leading
+// bytes. It returns "" when the content is not a recognised audio format.
+func sniffAudioType(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, []byte("ID3")):
+		return "audio/mpeg"
+	case bytes.HasPrefix(content, []byte("OggS")):
+		return "audio/ogg"
+	case bytes.HasPrefix(content, []byte("fLaC")):
+		return "audio/flac"
+	case bytes.HasPrefix(content, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "audio/webm"
+	case len(content) >= 12 && bytes.Equal(content[0:4], []byte("RIFF")) && bytes.Equal(content[8:12], []byte("WAVE")):
+		return "audio/wav"
+	case len(content) >= 12 && bytes.Equal(content[4:8], []byte("ftyp")):
+		switch string(content[8:12]) {
+		case "M4A ", "M4B ", "M4P ", "mp41", "mp42", "isom", "iso2", "dash":
+			return "audio/mp4"
+		}
+	case len(content) >= 2 && content[0] == 0xFF && content[1]&0xE0 == 0xE0:
+		// Frame sync. ADTS AAC marks layer 0; MPEG audio uses layers 1-3.
+		if content[1]&0x06 == 0 {
+			return "audio/aac"
+		}
+		return "audio/mpeg"
+	}
+	return ""
+}
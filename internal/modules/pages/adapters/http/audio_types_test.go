@@ -0,0 +1,125 @@
+package httpadapter
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type recordingMediaStore struct {
+	audioTypes []string
+}
+
+func (store *recordingMediaStore) UploadImage(_ context.Context, _ string, _ string, _ []byte) (string, string, error) {
+	return "https://cdn.test/image", "image", nil
+}
+
+func (store *recordingMediaStore) UploadAudio(_ context.Context, _ string, contentType string, _ []byte) (string, string, error) {
+	store.audioTypes = append(store.audioTypes, contentType)
+	return "https://cdn.test/audio", "audio", nil
+}
+
+func (store *recordingMediaStore) UploadAvatar(_ context.Context, _ string, _ string, _ []byte) (string, string, error) {
+	return "https://cdn.test/avatar", "avatar", nil
+}
+
+func (store *recordingMediaStore) DeleteObject(_ context.Context, _ string) error { return nil }
+
+func (store *recordingMediaStore) ObjectKeyFromURL(rawURL string) string { return rawURL }
+
+func audioUploadRequest(t *testing.T, fileName, contentType string, content []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+fileName+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		t.Fatalf("create part: %v", err)
+	}
+	_, _ = part.Write(content)
+	_ = writer.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/audio", &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+func TestAudioUploadEnforcesSniffedAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &recordingMediaStore{}
+	handler := &Handler{logger: zap.NewNop(), media: store}
+	WithAudioContentTypes("audio/mp3, audio/ogg")(handler)
+
+	router := gin.New()
+	router.POST("/audio", handler.uploadPublicAudio)
+
+	mp3 := append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), bytes.Repeat([]byte{0}, 64)...)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, audioUploadRequest(t, "song.mp3", "audio/mpeg", mp3))
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected mp3 to be accepted, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if len(store.audioTypes) != 1 || store.audioTypes[0] != "audio/mpeg" {
+		t.Fatalf("expected upload stored as audio/mpeg, got %v", store.audioTypes)
+	}
+
+	renamed := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, audioUploadRequest(t, "song.mp3", "audio/mpeg", renamed))
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected renamed file to be rejected with 415, got %d", recorder.Code)
+	}
+
+	wav := append([]byte("RIFF\x24\x00\x00\x00WAVEfmt "), bytes.Repeat([]byte{0}, 64)...)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, audioUploadRequest(t, "clip.wav", "audio/wav", wav))
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected wav outside the allowlist to be rejected with 415, got %d", recorder.Code)
+	}
+	if len(store.audioTypes) != 1 {
+		t.Fatalf("expected rejected uploads not to reach storage, got %v", store.audioTypes)
+	}
+}
+
+func TestDefaultAudioContentTypesAcceptEditorFormats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &recordingMediaStore{}
+	handler := &Handler{logger: zap.NewNop(), media: store}
+	// The JOT_AUDIO_CONTENT_TYPES default; the editor offers all of these.
+	WithAudioContentTypes("audio/mpeg,audio/mp4,audio/ogg,audio/wav,audio/flac,audio/aac")(handler)
+
+	router := gin.New()
+	router.POST("/audio", handler.uploadPublicAudio)
+
+	uploads := []struct {
+		name        string
+		contentType string
+		content     []byte
+		want        string
+	}{
+		{name: "clip.wav", contentType: "audio/wav", content: []byte("RIFF\x24\x00\x00\x00WAVEfmt "), want: "audio/wav"},
+		{name: "clip.flac", contentType: "audio/flac", content: []byte("fLaC\x00\x00\x00\x22"), want: "audio/flac"},
+		{name: "clip.aac", contentType: "audio/aac", content: []byte{0xFF, 0xF1, 0x50, 0x80}, want: "audio/aac"},
+		{name: "clip.m4a", contentType: "audio/x-m4a", content: []byte("\x00\x00\x00\x20ftypM4A "), want: "audio/mp4"},
+	}
+	for _, upload := range uploads {
+		content := append(upload.content, bytes.Repeat([]byte{0}, 64)...)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, audioUploadRequest(t, upload.name, upload.contentType, content))
+		if recorder.Code != http.StatusCreated {
+			t.Fatalf("expected %s to be accepted, got %d: %s", upload.name, recorder.Code, recorder.Body.String())
+		}
+		if got := store.audioTypes[len(store.audioTypes)-1]; got != upload.want {
+			t.Fatalf("expected %s stored as %s, got %s", upload.name, upload.want, got)
+		}
+	}
+}
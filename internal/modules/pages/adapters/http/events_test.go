@@ -0,0 +1,189 @@
+package httpadapter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jnats "github.com/nats-io/nats.go"
+	"github.com/reggieanim/jot/internal/modules/pages/app"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/clock"
+	"go.uber.org/zap"
+)
+
+// seededStream holds messages as a JetStream stream would, delivering those
+// after the requested sequence the way an ordered consumer does.
+type seededStream struct {
+	msgs     []*jnats.Msg
+	afterSeq uint64
+}
+
+func (stream *seededStream) append(t *testing.T, pageID domain.PageID, eventType string) {
+	t.Helper()
+	payload, err := json.Marshal(pageEvent{Type: eventType, Page: domain.Page{ID: pageID}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	seq := len(stream.msgs) + 1
+	stream.msgs = append(stream.msgs, &jnats.Msg{
+		Data:  payload,
+		Reply: fmt.Sprintf("$JS.ACK.jot.cons.1.%d.%d.%d.0", seq, seq, time.Now().UnixNano()),
+		Sub:   &jnats.Subscription{},
+	})
+}
+
+func (stream *seededStream) subscribe(_ string, afterSeq uint64, msgs chan *jnats.Msg) (func(), error) {
+	stream.afterSeq = afterSeq
+	for i := afterSeq; i < uint64(len(stream.msgs)); i++ {
+		msgs <- stream.msgs[i]
+	}
+	return func() {}, nil
+}
+
+// newEventsPageRepo serves page-1 with a view share link, view-token.
+func newEventsPageRepo() *sharedPageRepo {
+	owner := "owner-1"
+	return &sharedPageRepo{
+		page:  domain.Page{ID: "page-1", Title: "Draft", OwnerID: &owner},
+		share: domain.PageShareLink{Token: "view-token", PageID: "page-1", Access: domain.ShareAccessView},
+	}
+}
+
+// readFrames requests the page's SSE stream and returns the first n frames
+// as raw "id|event" pairs.
+func readFrames(t *testing.T, stream *seededStream, lastEventID string, n int) []string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	handler := &Handler{
+		logger:          zap.NewNop(),
+		service:         app.NewService(newEventsPageRepo(), nil, clock.SystemClock{}),
+		subscribeEvents: stream.subscribe,
+	}
+	router := gin.New()
+	router.GET("/pages/:pageID/events", handler.subscribePageEvents)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/pages/page-1/events?share=view-token", nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if lastEventID != "" {
+		request.Header.Set("Last-Event-ID", lastEventID)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer response.Body.Close()
+
+	var frames []string
+	var id, event string
+	scanner := bufio.NewScanner(response.Body)
+	for len(frames) < n && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case line == "" && event != "":
+			frames = append(frames, id+"|"+event)
+			id, event = "", ""
+		}
+	}
+	if len(frames) < n {
+		t.Fatalf("expected %d frames, got %v", n, frames)
+	}
+	return frames
+}
+
+func TestSubscribePageEventsReplaysAfterLastEventID(t *testing.T) {
+	stream := &seededStream{}
+	stream.append(t, "page-1", "page.created")
+	stream.append(t, "page-2", "page.created")
+	stream.append(t, "page-1", "page.blocks.updated")
+	stream.append(t, "page-1", "page.published")
+
+	frames := readFrames(t, stream, "1", 2)
+
+	want := []string{"3|page", "4|page"}
+	for i := range want {
+		if frames[i] != want[i] {
+			t.Fatalf("frame %d: expected %q, got %q", i, want[i], frames[i])
+		}
+	}
+}
+
+func TestSubscribePageEventsIgnoresUnparseableLastEventID(t *testing.T) {
+	stream := &seededStream{}
+	stream.append(t, "page-1", "page.created")
+
+	readFrames(t, stream, "not-a-sequence", 1)
+
+	if stream.afterSeq != 0 {
+		t.Fatalf("expected live-only subscription, got replay after %d", stream.afterSeq)
+	}
+}
+
+func TestSubscribePageEventsRejectsDisallowedOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	subscribed := false
+	handler := &Handler{logger: zap.NewNop(), subscribeEvents: func(string, uint64, chan *jnats.Msg) (func(), error) {
+		subscribed = true
+		return func() {}, nil
+	}}
+	WithAllowedOrigins("https://app.example.com")(handler)
+	router := gin.New()
+	router.GET("/pages/:pageID/events", handler.subscribePageEvents)
+
+	request := httptest.NewRequest(http.MethodGet, "/pages/page-1/events", nil)
+	request.Header.Set("Origin", "https://evil.example.net")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a disallowed origin, got %d", recorder.Code)
+	}
+	if subscribed {
+		t.Fatalf("expected no NATS subscription for a disallowed origin")
+	}
+}
+
+func TestSubscribePageEventsRequiresPageAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	subscribed := false
+	handler := &Handler{
+		logger:  zap.NewNop(),
+		service: app.NewService(newEventsPageRepo(), nil, clock.SystemClock{}),
+		subscribeEvents: func(string, uint64, chan *jnats.Msg) (func(), error) {
+			subscribed = true
+			return func() {}, nil
+		},
+		presence: newPresenceTracker(),
+	}
+	router := gin.New()
+	router.GET("/pages/:pageID/events", handler.subscribePageEvents)
+
+	for _, path := range []string{"/pages/page-1/events", "/pages/page-1/events?share=wrong"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if recorder.Code == http.StatusOK {
+			t.Fatalf("%s: expected access to be refused, got 200", path)
+		}
+		if strings.Contains(recorder.Body.String(), "presence_snapshot") {
+			t.Fatalf("%s: expected no presence snapshot, got %s", path, recorder.Body.String())
+		}
+	}
+	if subscribed {
+		t.Fatalf("expected no NATS subscription without page access")
+	}
+}
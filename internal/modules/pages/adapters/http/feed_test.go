@@ -0,0 +1,39 @@
+package httpadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestPublicFeedCanBeDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hasFeedRoute := func(router *gin.Engine) bool {
+		for _, route := range router.Routes() {
+			if route.Method == http.MethodGet && route.Path == "/v1/public/feed" {
+				return true
+			}
+		}
+		return false
+	}
+
+	enabled := gin.New()
+	RegisterRoutes(enabled, nil, nil, nil, "", zap.NewNop(), nil, nil)
+	if !hasFeedRoute(enabled) {
+		t.Fatalf("expected the public feed to be registered by default")
+	}
+
+	disabled := gin.New()
+	RegisterRoutes(disabled, nil, nil, nil, "", zap.NewNop(), nil, nil, WithPublicFeed(false))
+	if hasFeedRoute(disabled) {
+		t.Fatalf("expected no public feed route when disabled")
+	}
+	recorder := httptest.NewRecorder()
+	disabled.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/public/feed", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for the disabled feed, got %d", recorder.Code)
+	}
+}
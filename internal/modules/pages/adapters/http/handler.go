@@ -1,12 +1,15 @@
 package httpadapter
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -14,23 +17,69 @@ import (
 
 	"github.com/gin-gonic/gin"
 	jnats "github.com/nats-io/nats.go"
+	filesapp "github.com/reggieanim/jot/internal/modules/files/app"
 	"github.com/reggieanim/jot/internal/modules/pages/app"
 	"github.com/reggieanim/jot/internal/modules/pages/domain"
 	usersapp "github.com/reggieanim/jot/internal/modules/users/app"
 	usersdomain "github.com/reggieanim/jot/internal/modules/users/domain"
 	"github.com/reggieanim/jot/internal/platform/auth"
+	platformnats "github.com/reggieanim/jot/internal/platform/eventbus/nats"
+	"github.com/reggieanim/jot/internal/platform/httputil"
 	"github.com/reggieanim/jot/internal/platform/storage"
 	"github.com/reggieanim/jot/internal/shared/errs"
 	"go.uber.org/zap"
 )
 
+const sseEventBuffer = 64
+
 type Handler struct {
-	service      *app.Service
-	usersService *usersapp.Service
-	logger       *zap.Logger
-	conn         *jnats.Conn
-	subject      string
-	media        storage.MediaStore
+	service            *app.Service
+	usersService       *usersapp.Service
+	logger             *zap.Logger
+	conn               *jnats.Conn
+	subject            string
+	media              storage.MediaStore
+	storageQuota       *filesapp.StorageQuota
+	anonymousUploads   httputil.Limiter
+	passwords          *passwordGuard
+	maxImageMegapixels float64
+	sanitizeSVG        bool
+	sharePreview       bool
+	feedExcludeSelf    bool
+	feedDisabled       bool
+	allowedOrigins     map[string]bool
+	audioTypes         map[string]bool
+	readKeySalt        []byte
+	typingTimeout      time.Duration
+	typing             *typingTracker
+	presence           *presenceTracker
+	requestTimeout     time.Duration
+	uploadTimeout      time.Duration
+	jetstream          jnats.JetStreamContext
+	stream             string
+	subscribeEvents    eventSubscriber
+	readEventHistory   eventHistoryReader
+	adminUserIDs       string
+	thumbnails         thumbnailRegenerator
+	publicCSP          string
+}
+
+// eventSubscriber resumes after stream sequence afterSeq when it is non-zero.
+type eventSubscriber func(pageID string, afterSeq uint64, msgs chan *jnats.Msg) (unsubscribe func(), err error)
+
+type Option func(*Handler)
+
+// WithMaxImageMegapixels of zero disables the check.
+func WithMaxImageMegapixels(megapixels float64) Option {
+	return func(handler *Handler) {
+		handler.maxImageMegapixels = megapixels
+	}
+}
+
+func WithSVGSanitizing(enabled bool) Option {
+	return func(handler *Handler) {
+		handler.sanitizeSVG = enabled
+	}
 }
 
 type pageEvent struct {
@@ -57,11 +106,13 @@ type pagePresence struct {
 }
 
 type streamEvent struct {
-	Type      string          `json:"type"`
-	Page      *domain.Page    `json:"page,omitempty"`
-	Typing    *typingPresence `json:"typing,omitempty"`
-	Presence  *pagePresence   `json:"presence,omitempty"`
-	Timestamp time.Time       `json:"timestamp"`
+	Type     string          `json:"type"`
+	Page     *domain.Page    `json:"page,omitempty"`
+	Typing   *typingPresence `json:"typing,omitempty"`
+	Presence *pagePresence   `json:"presence,omitempty"`
+	// Collaborators is only set on the presence snapshot sent on join.
+	Collaborators []pagePresence `json:"collaborators,omitempty"`
+	Timestamp     time.Time      `json:"timestamp"`
 }
 
 type createPageRequest struct {
@@ -72,6 +123,7 @@ type createPageRequest struct {
 	Cinematic bool           `json:"cinematic"`
 	Mood      int            `json:"mood"`
 	BgColor   string         `json:"bg_color"`
+	Tags      []string       `json:"tags,omitempty"`
 }
 
 type updateBlocksRequest struct {
@@ -91,11 +143,31 @@ type updatePageMetaRequest struct {
 	Mood          int     `json:"mood"`
 	BgColor       string  `json:"bg_color"`
 	BaseUpdatedAt *string `json:"base_updated_at,omitempty"`
+	// Tags replaces the page's tags when present; omit it to keep them.
+	Tags []string `json:"tags,omitempty"`
 }
 
 type publishPageRequest struct {
 	Published bool  `json:"published"`
 	Unlisted  *bool `json:"unlisted,omitempty"`
+	// Password of "" removes the protection; omit it to keep the current one.
+	Password *string `json:"password,omitempty"`
+	// PublishAt is ignored when Published is false.
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+}
+
+type addCollaboratorRequest struct {
+	Username string `json:"username"`
+	Access   string `json:"access"`
+}
+
+type transferPageRequest struct {
+	Username string `json:"username"`
+}
+
+type schedulePublishRequest struct {
+	PublishAt *time.Time `json:"publish_at"`
+	Unlisted  *bool      `json:"unlisted,omitempty"`
 }
 
 type createProofreadRequest struct {
@@ -106,6 +178,13 @@ type createProofreadRequest struct {
 	Annotations []domain.ProofreadAnnotation `json:"annotations"`
 }
 
+type createCommentRequest struct {
+	// AuthorName defaults to the signed-in user's name.
+	AuthorName string            `json:"author_name"`
+	Body       string            `json:"body"`
+	ParentID   *domain.CommentID `json:"parent_id,omitempty"`
+}
+
 type publishTypingRequest struct {
 	BlockID       string `json:"block_id"`
 	SessionID     string `json:"session_id"`
@@ -121,71 +200,236 @@ type publishPresenceRequest struct {
 	IsOnline      bool   `json:"is_online"`
 }
 
+type pageBatchRequest struct {
+	PageIDs []domain.PageID `json:"page_ids"`
+	// IDs is accepted as a shorter alias of PageIDs.
+	IDs []domain.PageID `json:"ids"`
+}
+
+func (request pageBatchRequest) pageIDs() []domain.PageID {
+	return append(request.PageIDs, request.IDs...)
+}
+
 type createShareLinkRequest struct {
-	Access string `json:"access"`
+	Access    string     `json:"access"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	MaxUses   *int       `json:"max_uses"`
+}
+
+// Without WithReadKeySalt reader keys change on every restart.
+func WithReadKeySalt(salt string) Option {
+	return func(handler *Handler) {
+		if salt != "" {
+			handler.readKeySalt = []byte(salt)
+		}
+	}
+}
+
+// WithRouteTimeouts never bounds streaming routes; zero disables a timeout.
+func WithRouteTimeouts(request, upload time.Duration) Option {
+	return func(handler *Handler) {
+		handler.requestTimeout = request
+		handler.uploadTimeout = upload
+	}
+}
+
+// Without WithEventStream, SSE is live-only and cannot resume.
+func WithEventStream(jetstream jnats.JetStreamContext, stream string) Option {
+	return func(handler *Handler) {
+		handler.jetstream = jetstream
+		handler.stream = stream
+	}
+}
+
+func WithPublicContentSecurityPolicy(policy string) Option {
+	return func(handler *Handler) {
+		handler.publicCSP = policy
+	}
+}
+
+func WithSharePreview(enabled bool) Option {
+	return func(handler *Handler) {
+		handler.sharePreview = enabled
+	}
+}
+
+// WithStorageQuota charges uploads into a shared page to its owner.
+func WithStorageQuota(quota *filesapp.StorageQuota) Option {
+	return func(handler *Handler) {
+		handler.storageQuota = quota
+	}
 }
 
-func RegisterRoutes(router *gin.Engine, service *app.Service, usersService *usersapp.Service, conn *jnats.Conn, subject string, logger *zap.Logger, media storage.MediaStore, jwtIssuer *auth.JWTIssuer) {
+func WithAnonymousUploadLimiter(limiter httputil.Limiter) Option {
+	return func(handler *Handler) {
+		handler.anonymousUploads = limiter
+	}
+}
+
+func WithPublicFeed(enabled bool) Option {
+	return func(handler *Handler) {
+		handler.feedDisabled = !enabled
+	}
+}
+
+func WithFeedExcludeSelf(enabled bool) Option {
+	return func(handler *Handler) {
+		handler.feedExcludeSelf = enabled
+	}
+}
+
+func RegisterRoutes(router *gin.Engine, service *app.Service, usersService *usersapp.Service, conn *jnats.Conn, subject string, logger *zap.Logger, media storage.MediaStore, jwtIssuer *auth.JWTIssuer, opts ...Option) {
 	handler := &Handler{service: service, usersService: usersService, logger: logger, conn: conn, subject: subject, media: media}
-	v1 := router.Group("/v1")
+	for _, opt := range opts {
+		opt(handler)
+	}
+	if len(handler.readKeySalt) == 0 {
+		handler.readKeySalt = make([]byte, 32)
+		_, _ = rand.Read(handler.readKeySalt)
+	}
+	switch {
+	case handler.jetstream != nil && handler.stream != "":
+		handler.subscribeEvents = func(pageID string, afterSeq uint64, msgs chan *jnats.Msg) (func(), error) {
+			return platformnats.SubscribePageStream(handler.jetstream, handler.stream, handler.subject, pageID, afterSeq, msgs)
+		}
+		handler.readEventHistory = func(pageID string, start jnats.SubOpt, limit int) ([]*jnats.Msg, error) {
+			return platformnats.ReadPageHistory(handler.jetstream, handler.stream, handler.subject, pageID, start, limit)
+		}
+	case handler.conn != nil:
+		handler.subscribeEvents = func(pageID string, _ uint64, msgs chan *jnats.Msg) (func(), error) {
+			return platformnats.SubscribePage(handler.conn, handler.subject, pageID, msgs)
+		}
+	}
+	if handler.conn != nil && handler.typingTimeout > 0 {
+		handler.typing = newTypingTracker(handler.typingTimeout, handler.publishTypingCleared)
+	}
+	if handler.conn != nil {
+		handler.presence = newPresenceTracker()
+	}
+	v1 := router.Group("/v1", handler.tagShareVisitor)
+	api := v1.Group("", httputil.Timeout(handler.requestTimeout))
+	uploads := v1.Group("", httputil.Timeout(handler.uploadTimeout))
+	public := api.Group("", httputil.ContentSecurityPolicy(handler.publicCSP))
 
 	// Public endpoints (no auth required)
-	v1.GET("/public/pages/:pageID", handler.getPublicPage)
-	v1.GET("/public/pages/:pageID/blocks/:blockID", handler.getPublicBlock)
-	v1.GET("/public/pages/:pageID/proofreads", handler.listProofreads)
-	v1.POST("/public/pages/:pageID/proofreads", handler.createProofread)
-	v1.GET("/public/proofreads/:proofreadID", handler.getProofread)
-	v1.GET("/public/pages/:pageID/collaborators", handler.listPublicCollabUsers)
-	v1.POST("/public/media/images", handler.uploadPublicImage)
-	v1.POST("/public/media/audio", handler.uploadPublicAudio)
-	v1.POST("/public/pages", handler.createAnonymousPage)
-	v1.GET("/users/:userID/pages", handler.listPublishedPagesByUser)
-	v1.GET("/public/feed", auth.OptionalMiddleware(jwtIssuer), handler.listFeed)
-
-	// SSE + realtime (EventSource can't send cookies/headers)
-	v1.GET("/pages/:pageID/events", handler.subscribePageEvents)
+	public.GET("/public/pages/:pageID", handler.getPublicPage)
+	public.GET("/public/u/:username/:slug", handler.getPublicPageBySlug)
+	public.GET("/public/p/:slug", handler.getPublicPageByGlobalSlug)
+	public.GET("/public/pages/:pageID/blocks/:blockID", handler.getPublicBlock)
+	public.GET("/public/pages/:pageID/block-types", handler.listPublicBlockTypes)
+	public.GET("/public/pages/:pageID/proofreads", handler.listProofreads)
+	api.POST("/public/pages/:pageID/proofreads", handler.createProofread)
+	public.GET("/public/proofreads/:proofreadID", handler.getProofread)
+	api.POST("/public/proofreads/:proofreadID/vote", auth.OptionalMiddleware(jwtIssuer), handler.voteProofread)
+	public.GET("/public/pages/:pageID/comments", handler.listComments)
+	api.POST("/public/pages/:pageID/comments", auth.OptionalMiddleware(jwtIssuer), handler.createComment)
+	public.GET("/public/pages/:pageID/collaborators", handler.listPublicCollabUsers)
+	publicUploads := uploads.Group("", auth.OptionalMiddleware(jwtIssuer))
+	if handler.anonymousUploads != nil {
+		publicUploads.Use(httputil.RateLimit(handler.anonymousUploads, anonymousUploadKeys))
+	}
+	publicUploads.POST("/public/media/images", handler.uploadPublicImage)
+	publicUploads.POST("/public/media/audio", handler.uploadPublicAudio)
+	api.POST("/public/pages", handler.createAnonymousPage)
+	public.GET("/users/:userID/pages", handler.listPublishedPagesByUser)
+	if !handler.feedDisabled {
+		public.GET("/public/feed", auth.OptionalMiddleware(jwtIssuer), handler.listFeed)
+		public.GET("/public/tags/trending", handler.listTrendingTags)
+	}
+	if handler.sharePreview {
+		public.GET("/share/:token", handler.previewShareLink)
+	}
+	public.GET("/share/:token/validate", handler.validateShareLink)
+
+	// SSE + realtime (EventSource can't send headers, so share tokens come in
+	// the query); long-lived, so no request timeout
+	v1.GET("/pages/:pageID/events", auth.OptionalMiddleware(jwtIssuer), handler.subscribePageEvents)
+	v1.GET("/pages/:pageID/ws", auth.OptionalMiddleware(jwtIssuer), handler.subscribePageWebSocket)
 
 	// Collaboration endpoints (allow guest access via share token)
-	collab := v1.Group("")
+	uploads.POST("/pages/:pageID/media/images", auth.OptionalMiddleware(jwtIssuer), handler.uploadPageImage)
+	uploads.POST("/pages/:pageID/media/audio", auth.OptionalMiddleware(jwtIssuer), handler.uploadPageAudio)
+	collab := api.Group("")
 	collab.Use(auth.OptionalMiddleware(jwtIssuer))
 	{
-		collab.POST("/pages/:pageID/media/images", handler.uploadPageImage)
-		collab.POST("/pages/:pageID/media/audio", handler.uploadPageAudio)
 		collab.POST("/pages/:pageID/presence", handler.publishPresence)
 		collab.POST("/pages/:pageID/typing", handler.publishTyping)
 		collab.GET("/pages/:pageID", handler.getPage)
+		collab.GET("/pages/:pageID/access", handler.getPageAccess)
 		collab.PUT("/pages/:pageID/blocks", handler.updateBlocks)
 		collab.PUT("/pages/:pageID/realtime-blocks", handler.updateBlocksRealtime)
 		collab.PUT("/pages/:pageID/meta", handler.updatePageMeta)
 	}
 
+	// Admin debugging endpoints
+	if handler.adminUserIDs != "" {
+		admin := api.Group("/admin", auth.Middleware(jwtIssuer), auth.RequireAdmin(handler.adminUserIDs))
+		admin.GET("/pages/:pageID/events", handler.listPageEventHistory)
+		admin.POST("/pages/:pageID/regenerate-thumbnails", handler.regenerateThumbnails)
+		admin.GET("/stats", handler.getPlatformStats)
+	}
+
 	// Protected endpoints (require auth)
-	protected := v1.Group("")
+	uploads.POST("/media/images", auth.Middleware(jwtIssuer), handler.uploadImage)
+	uploads.POST("/media/audio", auth.Middleware(jwtIssuer), handler.uploadAudio)
+	protected := api.Group("")
 	protected.Use(auth.Middleware(jwtIssuer))
 	{
-		protected.POST("/media/images", handler.uploadImage)
-		protected.POST("/media/audio", handler.uploadAudio)
 		protected.POST("/pages", handler.createPage)
 		protected.GET("/pages", handler.listPages)
 		protected.GET("/pages/archived", handler.listArchivedPages)
+		protected.GET("/me/trending", handler.listTrendingPages)
+		protected.GET("/me/bookmarks", handler.listBookmarks)
 		protected.DELETE("/pages/:pageID", handler.deletePage)
+		protected.POST("/pages/delete-batch", handler.deletePages)
+		protected.POST("/pages/bulk-delete", handler.deletePages)
+		protected.POST("/pages/bulk-archive", handler.archivePages)
 		protected.PUT("/pages/:pageID/archive", handler.archivePage)
 		protected.PUT("/pages/:pageID/restore", handler.restorePage)
 		protected.PUT("/pages/:pageID/publish", handler.setPagePublished)
+		protected.POST("/pages/:pageID/schedule", handler.schedulePagePublish)
+		protected.POST("/pages/:pageID/transfer", handler.transferPage)
+		protected.POST("/pages/:pageID/clone", handler.clonePage)
+		protected.POST("/pages/:pageID/like", handler.likePage)
+		protected.DELETE("/pages/:pageID/like", handler.unlikePage)
+		protected.POST("/pages/:pageID/bookmark", handler.bookmarkPage)
+		protected.DELETE("/pages/:pageID/bookmark", handler.unbookmarkPage)
 		protected.POST("/pages/:pageID/share", handler.createShareLink)
+		protected.GET("/pages/:pageID/share", handler.listShareLinks)
 		protected.DELETE("/pages/:pageID/share/:access", handler.revokeShareLink)
+		protected.DELETE("/pages/:pageID/share/token/:token", handler.revokeShareLinkByToken)
 		protected.GET("/pages/:pageID/collaborators", handler.listCollabUsers)
+		protected.POST("/pages/:pageID/collaborators", handler.addCollaborator)
+		protected.DELETE("/pages/:pageID/collaborators/:userID", handler.removeCollaborator)
+		protected.GET("/pages/:pageID/revisions", handler.listRevisions)
+		protected.GET("/pages/:pageID/revisions/:revisionID", handler.getRevision)
+		protected.POST("/pages/:pageID/proofreads/:proofreadID/pin", handler.pinProofread)
+		protected.DELETE("/pages/:pageID/proofreads/:proofreadID/pin", handler.unpinProofread)
+		protected.DELETE("/pages/:pageID/proofreads/:proofreadID", handler.deleteProofread)
+		protected.DELETE("/pages/:pageID/comments/:commentID", handler.deleteComment)
 	}
 }
 
 func (handler *Handler) listPages(ctx *gin.Context) {
 	uid, _ := auth.GetUserID(ctx)
-	pages, err := handler.service.ListPages(ctx.Request.Context(), string(uid))
+	limit := 0
+	offset := 0
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 {
+			limit = v
+		}
+	}
+	if o := ctx.Query("offset"); o != "" {
+		if v, err := strconv.Atoi(o); err == nil && v >= 0 {
+			offset = v
+		}
+	}
+	pages, nextOffset, err := handler.service.ListPages(ctx.Request.Context(), string(uid), domain.PageStatus(ctx.Query("status")), limit, offset)
 	if err != nil {
 		handler.handleError(ctx, err)
 		return
 	}
-	ctx.JSON(200, gin.H{"items": pages})
+	ctx.JSON(200, gin.H{"items": pages, "next_offset": nextOffset})
 }
 
 func (handler *Handler) listCollabUsers(ctx *gin.Context) {
@@ -199,8 +443,73 @@ func (handler *Handler) listCollabUsers(ctx *gin.Context) {
 	ctx.JSON(200, gin.H{"collaborators": users})
 }
 
+func (handler *Handler) listRevisions(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	limit := 30
+	offset := 0
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 {
+			limit = v
+		}
+	}
+	if o := ctx.Query("offset"); o != "" {
+		if v, err := strconv.Atoi(o); err == nil && v >= 0 {
+			offset = v
+		}
+	}
+	revisions, err := handler.service.ListRevisions(ctx.Request.Context(), string(uid), pageID, limit, offset)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"items": revisions})
+}
+
+func (handler *Handler) getRevision(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	revisionID := domain.RevisionID(ctx.Param("revisionID"))
+	revision, err := handler.service.GetRevision(ctx.Request.Context(), string(uid), pageID, revisionID)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, revision)
+}
+
+func (handler *Handler) addCollaborator(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	var body addCollaboratorRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid json body"})
+		return
+	}
+	access := domain.ShareAccess(strings.TrimSpace(strings.ToLower(body.Access)))
+	userID, err := handler.service.AddCollaborator(ctx.Request.Context(), string(uid), pageID, body.Username, access)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(201, gin.H{"user_id": userID, "access": access})
+}
+
+func (handler *Handler) removeCollaborator(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	if err := handler.service.RemoveCollaborator(ctx.Request.Context(), string(uid), pageID, ctx.Param("userID")); err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"status": "removed"})
+}
+
 func (handler *Handler) listPublicCollabUsers(ctx *gin.Context) {
 	pageID := domain.PageID(ctx.Param("pageID"))
+	if !handler.unlockPublicPage(ctx, pageID) {
+		return
+	}
 	users, err := handler.service.ListPublicCollabUsers(ctx.Request.Context(), pageID)
 	if err != nil {
 		handler.handleError(ctx, err)
@@ -212,13 +521,48 @@ func (handler *Handler) listPublicCollabUsers(ctx *gin.Context) {
 func (handler *Handler) deletePage(ctx *gin.Context) {
 	uid, _ := auth.GetUserID(ctx)
 	pageID := domain.PageID(ctx.Param("pageID"))
-	if err := handler.service.DeletePage(ctx.Request.Context(), string(uid), pageID); err != nil {
+	err := handler.service.DeletePage(ctx.Request.Context(), string(uid), pageID)
+	if errors.Is(err, app.ErrDeleteEventNotPublished) {
+		handler.logger.Error("page deleted but its media was not queued for cleanup", zap.Error(err), zap.String("page_id", string(pageID)))
+	} else if err != nil {
 		handler.handleError(ctx, err)
 		return
 	}
 	ctx.JSON(200, gin.H{"status": "deleted"})
 }
 
+func (handler *Handler) deletePages(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	var body pageBatchRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid json body"})
+		return
+	}
+	results, err := handler.service.DeletePages(ctx.Request.Context(), string(uid), body.pageIDs())
+	if errors.Is(err, app.ErrDeleteEventNotPublished) {
+		handler.logger.Error("pages deleted but their media was not queued for cleanup", zap.Error(err))
+	} else if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"results": results})
+}
+
+func (handler *Handler) archivePages(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	var body pageBatchRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid json body"})
+		return
+	}
+	results, err := handler.service.ArchivePages(ctx.Request.Context(), string(uid), body.pageIDs())
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"results": results})
+}
+
 func (handler *Handler) archivePage(ctx *gin.Context) {
 	uid, _ := auth.GetUserID(ctx)
 	pageID := domain.PageID(ctx.Param("pageID"))
@@ -229,6 +573,36 @@ func (handler *Handler) archivePage(ctx *gin.Context) {
 	ctx.JSON(200, gin.H{"status": "archived"})
 }
 
+func (handler *Handler) pinProofread(ctx *gin.Context) {
+	handler.setProofreadPinned(ctx, true)
+}
+
+func (handler *Handler) unpinProofread(ctx *gin.Context) {
+	handler.setProofreadPinned(ctx, false)
+}
+
+func (handler *Handler) setProofreadPinned(ctx *gin.Context, pinned bool) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	proofreadID := domain.ProofreadID(ctx.Param("proofreadID"))
+	if err := handler.service.SetProofreadPinned(ctx.Request.Context(), string(uid), pageID, proofreadID, pinned); err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"pinned": pinned})
+}
+
+func (handler *Handler) deleteProofread(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	proofreadID := domain.ProofreadID(ctx.Param("proofreadID"))
+	if err := handler.service.DeleteProofread(ctx.Request.Context(), string(uid), pageID, proofreadID); err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"status": "deleted"})
+}
+
 func (handler *Handler) restorePage(ctx *gin.Context) {
 	uid, _ := auth.GetUserID(ctx)
 	pageID := domain.PageID(ctx.Param("pageID"))
@@ -258,7 +632,13 @@ func (handler *Handler) setPagePublished(ctx *gin.Context) {
 		return
 	}
 
-	page, err := handler.service.SetPagePublished(ctx.Request.Context(), string(uid), pageID, body.Published, body.Unlisted)
+	var page domain.Page
+	var err error
+	if body.Published && body.PublishAt != nil {
+		page, err = handler.service.SchedulePagePublishWithPassword(ctx.Request.Context(), string(uid), pageID, *body.PublishAt, body.Unlisted, body.Password)
+	} else {
+		page, err = handler.service.SetPagePublishedWithPassword(ctx.Request.Context(), string(uid), pageID, body.Published, body.Unlisted, body.Password)
+	}
 	if err != nil {
 		handler.handleError(ctx, err)
 		return
@@ -267,6 +647,47 @@ func (handler *Handler) setPagePublished(ctx *gin.Context) {
 	ctx.JSON(200, gin.H{"status": "updated", "page": page})
 }
 
+func (handler *Handler) transferPage(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	var body transferPageRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid json body"})
+		return
+	}
+	page, err := handler.service.TransferOwnership(ctx.Request.Context(), string(uid), pageID, body.Username)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, page)
+}
+
+func (handler *Handler) schedulePagePublish(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	var body schedulePublishRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid json body"})
+		return
+	}
+	if body.PublishAt == nil {
+		ctx.JSON(400, gin.H{"error": "publish_at is required"})
+		return
+	}
+
+	page, err := handler.service.SchedulePagePublish(ctx.Request.Context(), string(uid), pageID, *body.PublishAt, body.Unlisted)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	status := "scheduled"
+	if page.Published {
+		status = "published"
+	}
+	ctx.JSON(200, gin.H{"status": status, "page": page})
+}
+
 func (handler *Handler) getPublicPage(ctx *gin.Context) {
 	pageID := domain.PageID(ctx.Param("pageID"))
 	page, err := handler.service.GetPublicPage(ctx.Request.Context(), pageID)
@@ -274,27 +695,147 @@ func (handler *Handler) getPublicPage(ctx *gin.Context) {
 		handler.handleError(ctx, err)
 		return
 	}
-	readerKey := makeOrganicReaderKey(ctx)
-	if unique, err := handler.service.RecordPublicRead(ctx.Request.Context(), pageID, readerKey); err != nil {
-		handler.logger.Warn("record organic read failed", zap.Error(err), zap.String("page_id", string(pageID)))
+	handler.respondPublicPage(ctx, page)
+}
+
+const pageUnlockHeader = "X-Page-Unlock"
+
+// pagePassword reads only a header: query strings end up in logs.
+func pagePassword(ctx *gin.Context) string {
+	return ctx.GetHeader("X-Page-Password")
+}
+
+// unlockPage answers 401 with only the title and cover until the page is
+// unlocked, and 429 once the client has guessed wrong too often.
+func (handler *Handler) unlockPage(ctx *gin.Context, page domain.Page) bool {
+	if !page.PasswordProtected {
+		return true
+	}
+	if token := ctx.GetHeader(pageUnlockHeader); token != "" && handler.service.VerifyPageUnlock(ctx.Request.Context(), page.ID, token) {
+		return true
+	}
+	password := pagePassword(ctx)
+	guardKey := "page:" + string(page.ID) + "|ip:" + ctx.ClientIP()
+	if handler.passwords != nil && password != "" {
+		if wait, locked := handler.passwords.Locked(guardKey); locked {
+			handler.tooManyPasswordAttempts(ctx, wait)
+			return false
+		}
+	}
+	token, err := handler.service.UnlockPage(ctx.Request.Context(), page.ID, password)
+	if err == nil {
+		if token != "" {
+			ctx.Header(pageUnlockHeader, token)
+		}
+		return true
+	}
+	if !errors.Is(err, errs.ErrUnauthorized) {
+		handler.handleError(ctx, err)
+		return false
+	}
+	if handler.passwords != nil && password != "" {
+		if wait, locked := handler.passwords.Failed(ctx.Request.Context(), guardKey); locked {
+			handler.tooManyPasswordAttempts(ctx, wait)
+			return false
+		}
+	}
+	ctx.JSON(401, gin.H{
+		"error": err.Error(),
+		"page": gin.H{
+			"id":                 page.ID,
+			"title":              page.Title,
+			"cover":              page.Cover,
+			"password_protected": true,
+		},
+	})
+	return false
+}
+
+func (handler *Handler) tooManyPasswordAttempts(ctx *gin.Context, wait time.Duration) {
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	ctx.JSON(429, gin.H{"error": "too many password attempts"})
+}
+
+func (handler *Handler) unlockPublicPage(ctx *gin.Context, pageID domain.PageID) bool {
+	page, err := handler.service.GetPublicPage(ctx.Request.Context(), pageID)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return false
+	}
+	return handler.unlockPage(ctx, page)
+}
+
+func (handler *Handler) getPublicPageBySlug(ctx *gin.Context) {
+	page, err := handler.service.GetPublicPageBySlug(ctx.Request.Context(), ctx.Param("username"), ctx.Param("slug"))
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	handler.respondPublicPage(ctx, page)
+}
+
+func (handler *Handler) getPublicPageByGlobalSlug(ctx *gin.Context) {
+	page, err := handler.service.GetPublicPageByGlobalSlug(ctx.Request.Context(), ctx.Param("slug"))
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	handler.respondPublicPage(ctx, page)
+}
+
+func (handler *Handler) respondPublicPage(ctx *gin.Context, page domain.Page) {
+	if !handler.unlockPage(ctx, page) {
+		return
+	}
+	readerKey := handler.makeOrganicReaderKey(ctx)
+	if unique, err := handler.service.RecordPublicRead(ctx.Request.Context(), page.ID, readerKey); err != nil {
+		handler.logger.Warn("record organic read failed", zap.Error(err), zap.String("page_id", string(page.ID)))
 	} else if unique {
 		page.ReadCount++
 	}
 	ctx.JSON(200, page)
 }
 
-func makeOrganicReaderKey(ctx *gin.Context) string {
-	ip := strings.TrimSpace(ctx.ClientIP())
-	ua := strings.TrimSpace(ctx.GetHeader("User-Agent"))
+func (handler *Handler) tagShareVisitor(ctx *gin.Context) {
+	if visitor := handler.makeOrganicReaderKey(ctx); visitor != "" {
+		ctx.Request = ctx.Request.WithContext(app.WithShareVisitor(ctx.Request.Context(), visitor))
+	}
+	ctx.Next()
+}
+
+func (handler *Handler) makeOrganicReaderKey(ctx *gin.Context) string {
+	return organicReaderKey(handler.readKeySalt, ctx.ClientIP(), ctx.GetHeader("User-Agent"))
+}
+
+func organicReaderKey(salt []byte, ip, ua string) string {
+	ip = strings.TrimSpace(ip)
+	ua = strings.TrimSpace(ua)
 	if ip == "" && ua == "" {
 		return ""
 	}
-	sum := sha256.Sum256([]byte(ip + "|" + ua))
-	return hex.EncodeToString(sum[:])
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(ip + "|" + ua))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (handler *Handler) listPublicBlockTypes(ctx *gin.Context) {
+	pageID := domain.PageID(ctx.Param("pageID"))
+	if !handler.unlockPublicPage(ctx, pageID) {
+		return
+	}
+	counts, err := handler.service.ListPublicBlockTypes(ctx.Request.Context(), pageID)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"items": counts})
 }
 
 func (handler *Handler) getPublicBlock(ctx *gin.Context) {
 	pageID := domain.PageID(ctx.Param("pageID"))
+	if !handler.unlockPublicPage(ctx, pageID) {
+		return
+	}
 	blockID := ctx.Param("blockID")
 	block, page, err := handler.service.GetPublicBlockWithAuthor(ctx.Request.Context(), pageID, blockID)
 	if err != nil {
@@ -320,6 +861,9 @@ func (handler *Handler) getPublicBlock(ctx *gin.Context) {
 
 func (handler *Handler) createProofread(ctx *gin.Context) {
 	pageID := domain.PageID(ctx.Param("pageID"))
+	if !handler.unlockPublicPage(ctx, pageID) {
+		return
+	}
 	var body createProofreadRequest
 	if err := ctx.ShouldBindJSON(&body); err != nil {
 		ctx.JSON(400, gin.H{"error": "invalid json body"})
@@ -343,24 +887,113 @@ func (handler *Handler) createProofread(ctx *gin.Context) {
 	ctx.JSON(201, proofread)
 }
 
-func (handler *Handler) listProofreads(ctx *gin.Context) {
+func (handler *Handler) listProofreads(ctx *gin.Context) {
+	pageID := domain.PageID(ctx.Param("pageID"))
+	if !handler.unlockPublicPage(ctx, pageID) {
+		return
+	}
+	proofreads, err := handler.service.ListProofreads(ctx.Request.Context(), pageID, ctx.DefaultQuery("sort", "new"))
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"items": proofreads})
+}
+
+func (handler *Handler) getProofread(ctx *gin.Context) {
+	proofreadID := domain.ProofreadID(ctx.Param("proofreadID"))
+	proofread, page, err := handler.service.GetProofread(ctx.Request.Context(), proofreadID)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	if !handler.unlockPage(ctx, page) {
+		return
+	}
+	ctx.JSON(200, gin.H{"proofread": proofread, "page": page})
+}
+
+func (handler *Handler) voteProofread(ctx *gin.Context) {
+	proofreadID := domain.ProofreadID(ctx.Param("proofreadID"))
+	_, page, err := handler.service.GetProofread(ctx.Request.Context(), proofreadID)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	if !handler.unlockPage(ctx, page) {
+		return
+	}
+
+	voterKey := "reader:" + handler.makeOrganicReaderKey(ctx)
+	if uid, ok := auth.GetUserID(ctx); ok {
+		voterKey = "user:" + string(uid)
+	} else if voterKey == "reader:" {
+		ctx.JSON(400, gin.H{"error": "cannot identify voter"})
+		return
+	}
+	count, err := handler.service.VoteProofread(ctx.Request.Context(), proofreadID, voterKey)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"proofread_id": proofreadID, "vote_count": count})
+}
+
+func (handler *Handler) createComment(ctx *gin.Context) {
+	pageID := domain.PageID(ctx.Param("pageID"))
+	if !handler.unlockPublicPage(ctx, pageID) {
+		return
+	}
+	var body createCommentRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid json body"})
+		return
+	}
+
+	uid, signedIn := auth.GetUserID(ctx)
+	authorName := strings.TrimSpace(body.AuthorName)
+	if signedIn && authorName == "" {
+		user, err := handler.usersService.GetProfile(ctx.Request.Context(), uid)
+		if err != nil {
+			handler.handleError(ctx, err)
+			return
+		}
+		authorName = user.DisplayName
+		if authorName == "" {
+			authorName = user.Username
+		}
+	}
+
+	comment, err := handler.service.CreateComment(ctx.Request.Context(), pageID, string(uid), authorName, body.Body, body.ParentID)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(201, comment)
+}
+
+func (handler *Handler) listComments(ctx *gin.Context) {
 	pageID := domain.PageID(ctx.Param("pageID"))
-	proofreads, err := handler.service.ListProofreads(ctx.Request.Context(), pageID)
+	if !handler.unlockPublicPage(ctx, pageID) {
+		return
+	}
+	comments, err := handler.service.ListComments(ctx.Request.Context(), pageID)
 	if err != nil {
 		handler.handleError(ctx, err)
 		return
 	}
-	ctx.JSON(200, gin.H{"items": proofreads})
+	ctx.JSON(200, gin.H{"items": comments})
 }
 
-func (handler *Handler) getProofread(ctx *gin.Context) {
-	proofreadID := domain.ProofreadID(ctx.Param("proofreadID"))
-	proofread, page, err := handler.service.GetProofread(ctx.Request.Context(), proofreadID)
-	if err != nil {
+func (handler *Handler) deleteComment(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	commentID := domain.CommentID(ctx.Param("commentID"))
+	if err := handler.service.DeleteComment(ctx.Request.Context(), string(uid), pageID, commentID); err != nil {
 		handler.handleError(ctx, err)
 		return
 	}
-	ctx.JSON(200, gin.H{"proofread": proofread, "page": page})
+	ctx.JSON(200, gin.H{"status": "deleted"})
 }
 
 func (handler *Handler) publishPresence(ctx *gin.Context) {
@@ -412,11 +1045,14 @@ func (handler *Handler) publishPresence(ctx *gin.Context) {
 		return
 	}
 
-	if err := handler.conn.Publish(handler.subject, payload); err != nil {
+	if err := handler.conn.Publish(platformnats.PageSubject(handler.subject, pageID), payload); err != nil {
 		handler.logger.Warn("publish presence failed", zap.Error(err))
 		ctx.JSON(503, gin.H{"error": "realtime unavailable"})
 		return
 	}
+	if handler.presence != nil {
+		handler.presence.Track(*event.Presence)
+	}
 
 	ctx.JSON(202, gin.H{"status": "accepted"})
 }
@@ -472,116 +1108,100 @@ func (handler *Handler) publishTyping(ctx *gin.Context) {
 		return
 	}
 
-	if err := handler.conn.Publish(handler.subject, payload); err != nil {
+	if err := handler.conn.Publish(platformnats.PageSubject(handler.subject, pageID), payload); err != nil {
 		handler.logger.Warn("publish typing failed", zap.Error(err))
 		ctx.JSON(503, gin.H{"error": "realtime unavailable"})
 		return
 	}
+	if handler.typing != nil {
+		handler.typing.Track(*event.Typing)
+	}
 
 	ctx.JSON(202, gin.H{"status": "accepted"})
 }
 
 func (handler *Handler) uploadImage(ctx *gin.Context) {
-	if _, ok := auth.GetUserID(ctx); !ok {
+	uid, ok := auth.GetUserID(ctx)
+	if !ok {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization token"})
 		return
 	}
-	handler.handleImageUpload(ctx)
+	handler.handleImageUpload(ctx, string(uid))
 }
 
 func (handler *Handler) uploadPageImage(ctx *gin.Context) {
 	uid, _ := auth.GetUserID(ctx)
 	pageID := domain.PageID(ctx.Param("pageID"))
 	shareToken := strings.TrimSpace(ctx.Query("share"))
-	if _, _, err := handler.service.ResolvePageAccess(ctx.Request.Context(), string(uid), pageID, shareToken, domain.ShareAccessEdit); err != nil {
+	page, _, err := handler.service.ResolvePageAccess(ctx.Request.Context(), string(uid), pageID, shareToken, domain.ShareAccessEdit)
+	if err != nil {
 		handler.handleError(ctx, err)
 		return
 	}
-	handler.handleImageUpload(ctx)
+	handler.handleImageUpload(ctx, uploadOwner(page, string(uid)))
 }
 
 func (handler *Handler) uploadPublicImage(ctx *gin.Context) {
-	handler.handleImageUpload(ctx)
+	uid, _ := auth.GetUserID(ctx)
+	handler.handleImageUpload(ctx, string(uid))
 }
 
-func (handler *Handler) handleImageUpload(ctx *gin.Context) {
-	const maxUploadSize = 15 << 20
-
+func (handler *Handler) handleImageUpload(ctx *gin.Context, ownerID string) {
 	if handler.media == nil {
 		ctx.JSON(503, gin.H{"error": "media storage unavailable"})
 		return
 	}
 
-	fileHeader, err := ctx.FormFile("file")
-	if err != nil {
-		ctx.JSON(400, gin.H{"error": "file is required"})
-		return
-	}
-
-	file, err := fileHeader.Open()
-	if err != nil {
-		ctx.JSON(400, gin.H{"error": "invalid file"})
-		return
-	}
-	defer file.Close()
-
-	content, err := io.ReadAll(io.LimitReader(file, maxUploadSize+1))
-	if err != nil {
-		ctx.JSON(400, gin.H{"error": "could not read file"})
-		return
-	}
-	if len(content) > maxUploadSize {
-		ctx.JSON(413, gin.H{"error": "image too large (max 15MB)"})
-		return
-	}
-	if len(content) == 0 {
-		ctx.JSON(400, gin.H{"error": "empty file"})
+	upload, ok := httputil.ReadImageUpload(ctx, handler.maxImageMegapixels, handler.sanitizeSVG)
+	if !ok {
 		return
 	}
 
-	contentType := strings.TrimSpace(fileHeader.Header.Get("Content-Type"))
-	if contentType == "" {
-		contentType = http.DetectContentType(content)
-	}
-	if !strings.HasPrefix(contentType, "image/") {
-		ctx.JSON(400, gin.H{"error": "only image uploads are allowed"})
+	size := int64(len(upload.Content))
+	userID, ok := handler.reserveStorage(ctx, ownerID, size)
+	if !ok {
 		return
 	}
 
-	url, key, err := handler.media.UploadImage(ctx.Request.Context(), fileHeader.Filename, contentType, content)
+	url, key, err := handler.media.UploadImage(ctx.Request.Context(), upload.FileName, upload.ContentType, upload.Content)
 	if err != nil {
+		handler.releaseStorage(ctx, userID, size)
 		handler.logger.Warn("upload image failed", zap.Error(err))
 		ctx.JSON(500, gin.H{"error": "upload failed"})
 		return
 	}
+	handler.recordStorage(ctx, userID, key, size)
 
 	ctx.JSON(201, gin.H{"url": url, "key": key})
 }
 
 func (handler *Handler) uploadAudio(ctx *gin.Context) {
-	if _, ok := auth.GetUserID(ctx); !ok {
+	uid, ok := auth.GetUserID(ctx)
+	if !ok {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization token"})
 		return
 	}
-	handler.handleAudioUpload(ctx)
+	handler.handleAudioUpload(ctx, string(uid))
 }
 
 func (handler *Handler) uploadPageAudio(ctx *gin.Context) {
 	uid, _ := auth.GetUserID(ctx)
 	pageID := domain.PageID(ctx.Param("pageID"))
 	shareToken := strings.TrimSpace(ctx.Query("share"))
-	if _, _, err := handler.service.ResolvePageAccess(ctx.Request.Context(), string(uid), pageID, shareToken, domain.ShareAccessEdit); err != nil {
+	page, _, err := handler.service.ResolvePageAccess(ctx.Request.Context(), string(uid), pageID, shareToken, domain.ShareAccessEdit)
+	if err != nil {
 		handler.handleError(ctx, err)
 		return
 	}
-	handler.handleAudioUpload(ctx)
+	handler.handleAudioUpload(ctx, uploadOwner(page, string(uid)))
 }
 
 func (handler *Handler) uploadPublicAudio(ctx *gin.Context) {
-	handler.handleAudioUpload(ctx)
+	uid, _ := auth.GetUserID(ctx)
+	handler.handleAudioUpload(ctx, string(uid))
 }
 
-func (handler *Handler) handleAudioUpload(ctx *gin.Context) {
+func (handler *Handler) handleAudioUpload(ctx *gin.Context, ownerID string) {
 	const maxUploadSize = 50 << 20 // 50MB for audio
 
 	if handler.media == nil {
@@ -620,40 +1240,121 @@ func (handler *Handler) handleAudioUpload(ctx *gin.Context) {
 	if contentType == "" {
 		contentType = http.DetectContentType(content)
 	}
-	if !strings.HasPrefix(contentType, "audio/") {
+	if handler.audioTypes != nil {
+		sniffed := sniffAudioType(content)
+		if !handler.audioTypes[sniffed] {
+			ctx.JSON(415, gin.H{"error": "unsupported audio format"})
+			return
+		}
+		contentType = sniffed
+	} else if !strings.HasPrefix(contentType, "audio/") {
 		ctx.JSON(400, gin.H{"error": "only audio uploads are allowed"})
 		return
 	}
 
+	size := int64(len(content))
+	userID, ok := handler.reserveStorage(ctx, ownerID, size)
+	if !ok {
+		return
+	}
+
 	url, key, err := handler.media.UploadAudio(ctx.Request.Context(), fileHeader.Filename, contentType, content)
 	if err != nil {
+		handler.releaseStorage(ctx, userID, size)
 		handler.logger.Warn("upload audio failed", zap.Error(err))
 		ctx.JSON(500, gin.H{"error": "upload failed"})
 		return
 	}
+	handler.recordStorage(ctx, userID, key, size)
 
 	ctx.JSON(201, gin.H{"url": url, "key": key})
 }
 
+func uploadOwner(page domain.Page, uploaderID string) string {
+	if page.OwnerID != nil && *page.OwnerID != "" {
+		return *page.OwnerID
+	}
+	return uploaderID
+}
+
+func anonymousUploadKeys(ctx *gin.Context) []string {
+	if uid, ok := auth.GetUserID(ctx); ok && uid != "" {
+		return nil
+	}
+	return httputil.ClientIPKey(ctx)
+}
+
+// reserveStorage writes the error response and reports false when the
+// upload must not go ahead.
+func (handler *Handler) reserveStorage(ctx *gin.Context, userID string, size int64) (string, bool) {
+	if handler.storageQuota == nil || userID == "" {
+		return "", true
+	}
+	err := handler.storageQuota.Reserve(ctx.Request.Context(), userID, size)
+	if errors.Is(err, filesapp.ErrStorageQuotaExceeded) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "storage quota exceeded"})
+		return "", false
+	}
+	if err != nil {
+		handler.logger.Error("reserve storage failed", zap.Error(err))
+		ctx.JSON(500, gin.H{"error": "upload failed"})
+		return "", false
+	}
+	return userID, true
+}
+
+func (handler *Handler) releaseStorage(ctx *gin.Context, userID string, size int64) {
+	if userID == "" {
+		return
+	}
+	if err := handler.storageQuota.Release(ctx.Request.Context(), userID, size); err != nil {
+		handler.logger.Warn("release storage failed", zap.Error(err), zap.String("user_id", userID))
+	}
+}
+
+func (handler *Handler) recordStorage(ctx *gin.Context, userID, key string, size int64) {
+	if userID == "" {
+		return
+	}
+	if err := handler.storageQuota.Record(ctx.Request.Context(), userID, key, size); err != nil {
+		handler.logger.Warn("record stored object failed", zap.Error(err), zap.String("key", key))
+	}
+}
+
+// subscribePageEvents replays the frames after Last-Event-ID before going
+// live when backed by JetStream.
 func (handler *Handler) subscribePageEvents(ctx *gin.Context) {
-	pageID := ctx.Param("pageID")
+	uid, _ := auth.GetUserID(ctx)
+	pageID := strings.TrimSpace(ctx.Param("pageID"))
 	if pageID == "" {
 		ctx.JSON(400, gin.H{"error": "pageID is required"})
 		return
 	}
+	if !handler.checkOrigin(ctx.Request) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "origin not allowed"})
+		return
+	}
+	shareToken := strings.TrimSpace(ctx.Query("share"))
+	if _, _, err := handler.service.ResolvePageAccess(ctx.Request.Context(), string(uid), domain.PageID(pageID), shareToken, domain.ShareAccessView); err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
 
-	if handler.conn == nil {
+	if handler.subscribeEvents == nil {
 		ctx.JSON(503, gin.H{"error": "realtime unavailable"})
 		return
 	}
 
-	subscription, err := handler.conn.SubscribeSync(handler.subject)
+	afterSeq, _ := strconv.ParseUint(strings.TrimSpace(ctx.GetHeader("Last-Event-ID")), 10, 64)
+
+	msgs := make(chan *jnats.Msg, sseEventBuffer)
+	unsubscribe, err := handler.subscribeEvents(pageID, afterSeq, msgs)
 	if err != nil {
 		handler.logger.Warn("subscribe nats failed", zap.Error(err))
 		ctx.JSON(503, gin.H{"error": "realtime unavailable"})
 		return
 	}
-	defer subscription.Unsubscribe()
+	defer unsubscribe()
 
 	ctx.Header("Content-Type", "text/event-stream")
 	ctx.Header("Cache-Control", "no-cache")
@@ -666,60 +1367,40 @@ func (handler *Handler) subscribePageEvents(ctx *gin.Context) {
 		return
 	}
 
-	for {
-		if ctx.Request.Context().Err() != nil {
-			return
-		}
-
-		msg, err := subscription.NextMsg(15 * time.Second)
-		if err != nil {
-			if errors.Is(err, jnats.ErrTimeout) {
-				_, _ = fmt.Fprint(ctx.Writer, ": keepalive\n\n")
-				flusher.Flush()
-				continue
+	if handler.presence != nil {
+		payload, err := json.Marshal(handler.presenceSnapshot(pageID))
+		if err == nil {
+			if _, err := fmt.Fprintf(ctx.Writer, "event: presence_snapshot\ndata: %s\n\n", payload); err != nil {
+				return
 			}
-			handler.logger.Warn("stream nats failed", zap.Error(err))
-			return
+			flusher.Flush()
 		}
+	}
 
-		var event streamEvent
-		if err := json.Unmarshal(msg.Data, &event); err != nil {
-			var legacy pageEvent
-			if legacyErr := json.Unmarshal(msg.Data, &legacy); legacyErr != nil {
-				handler.logger.Warn("invalid page event payload", zap.Error(err))
-				continue
-			}
-			event = streamEvent{
-				Type:      legacy.Type,
-				Page:      &legacy.Page,
-				Timestamp: legacy.Timestamp,
-			}
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		var msg *jnats.Msg
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-keepalive.C:
+			_, _ = fmt.Fprint(ctx.Writer, ": keepalive\n\n")
+			flusher.Flush()
+			continue
+		case msg = <-msgs:
 		}
 
-		eventName := "page"
-		switch {
-		case strings.HasPrefix(event.Type, "page.") && event.Type != "page.typing" && event.Type != "page.presence":
-			eventName = "page"
-		case event.Type == "page.typing":
-			eventName = "typing"
-		case event.Type == "page.presence":
-			eventName = "presence"
-		default:
+		event, err := decodeStreamEvent(msg.Data)
+		if err != nil {
+			handler.logger.Warn("invalid page event payload", zap.Error(err))
 			continue
 		}
 
-		if eventName == "page" {
-			if event.Page == nil || string(event.Page.ID) != pageID {
-				continue
-			}
-		} else if eventName == "typing" {
-			if event.Typing == nil || event.Typing.PageID != pageID {
-				continue
-			}
-		} else {
-			if event.Presence == nil || event.Presence.PageID != pageID {
-				continue
-			}
+		eventName, ok := streamEventName(event, pageID)
+		if !ok {
+			continue
 		}
 
 		if event.Timestamp.IsZero() {
@@ -731,6 +1412,11 @@ func (handler *Handler) subscribePageEvents(ctx *gin.Context) {
 			continue
 		}
 
+		if seq, ok := platformnats.StreamSequence(msg); ok {
+			if _, err := fmt.Fprintf(ctx.Writer, "id: %d\n", seq); err != nil {
+				return
+			}
+		}
 		if _, err := fmt.Fprintf(ctx.Writer, "event: %s\ndata: %s\n\n", eventName, payload); err != nil {
 			return
 		}
@@ -738,6 +1424,36 @@ func (handler *Handler) subscribePageEvents(ctx *gin.Context) {
 	}
 }
 
+// decodeStreamEvent also accepts the legacy page-only envelope.
+func decodeStreamEvent(data []byte) (streamEvent, error) {
+	var event streamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		var legacy pageEvent
+		if legacyErr := json.Unmarshal(data, &legacy); legacyErr != nil {
+			return streamEvent{}, err
+		}
+		event = streamEvent{
+			Type:      legacy.Type,
+			Page:      &legacy.Page,
+			Timestamp: legacy.Timestamp,
+		}
+	}
+	return event, nil
+}
+
+func streamEventName(event streamEvent, pageID string) (name string, ok bool) {
+	switch {
+	case event.Type == "page.typing":
+		return "typing", event.Typing != nil && event.Typing.PageID == pageID
+	case event.Type == "page.presence":
+		return "presence", event.Presence != nil && event.Presence.PageID == pageID
+	case strings.HasPrefix(event.Type, "page."):
+		return "page", event.Page != nil && string(event.Page.ID) == pageID
+	default:
+		return "", false
+	}
+}
+
 func (handler *Handler) createPage(ctx *gin.Context) {
 	uid, _ := auth.GetUserID(ctx)
 	var body createPageRequest
@@ -756,6 +1472,7 @@ func (handler *Handler) createPage(ctx *gin.Context) {
 		body.Cinematic,
 		body.Mood,
 		body.BgColor,
+		body.Tags,
 	)
 	if err != nil {
 		handler.handleError(ctx, err)
@@ -781,6 +1498,7 @@ func (handler *Handler) createAnonymousPage(ctx *gin.Context) {
 		body.Cinematic,
 		body.Mood,
 		body.BgColor,
+		body.Tags,
 	)
 	if err != nil {
 		handler.handleError(ctx, err)
@@ -790,11 +1508,24 @@ func (handler *Handler) createAnonymousPage(ctx *gin.Context) {
 	ctx.JSON(201, page)
 }
 
+func (handler *Handler) clonePage(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	shareToken := strings.TrimSpace(ctx.Query("share"))
+	page, err := handler.service.ClonePageWithShare(ctx.Request.Context(), string(uid), pageID, shareToken)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(201, page)
+}
+
 func (handler *Handler) getPage(ctx *gin.Context) {
 	uid, _ := auth.GetUserID(ctx)
 	pageID := domain.PageID(ctx.Param("pageID"))
 	shareToken := strings.TrimSpace(ctx.Query("share"))
-	page, accessMode, err := handler.service.ResolvePageAccess(ctx.Request.Context(), string(uid), pageID, shareToken, domain.ShareAccessView)
+	includeArchived := ctx.Query("include_archived") == "true"
+	page, accessMode, err := handler.service.GetPageWithAccess(ctx.Request.Context(), string(uid), pageID, shareToken, includeArchived)
 	if err != nil {
 		handler.handleError(ctx, err)
 		return
@@ -804,6 +1535,23 @@ func (handler *Handler) getPage(ctx *gin.Context) {
 	ctx.JSON(200, page)
 }
 
+func (handler *Handler) getPageAccess(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	shareToken := strings.TrimSpace(ctx.Query("share"))
+	_, accessMode, err := handler.service.ResolvePageAccess(ctx.Request.Context(), string(uid), pageID, shareToken, domain.ShareAccessView)
+	// Private pages are reported as not found when they are hidden.
+	if errors.Is(err, errs.ErrForbidden) || errors.Is(err, errs.ErrNotFound) {
+		ctx.JSON(200, gin.H{"access": "none"})
+		return
+	}
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"access": accessMode})
+}
+
 func (handler *Handler) updateBlocks(ctx *gin.Context) {
 	uid, _ := auth.GetUserID(ctx)
 	pageID := domain.PageID(ctx.Param("pageID"))
@@ -892,7 +1640,7 @@ func (handler *Handler) updatePageMeta(ctx *gin.Context) {
 		expectedUpdatedAt = &parsed
 	}
 
-	page, err := handler.service.UpdatePageMetaRealtimeWithShare(ctx.Request.Context(), string(uid), pageID, body.Title, body.Cover, body.DarkMode, body.Cinematic, body.Mood, body.BgColor, expectedUpdatedAt, shareToken)
+	page, err := handler.service.UpdatePageMetaRealtimeWithShare(ctx.Request.Context(), string(uid), pageID, body.Title, body.Cover, body.DarkMode, body.Cinematic, body.Mood, body.BgColor, body.Tags, expectedUpdatedAt, shareToken)
 	if err != nil {
 		if errors.Is(err, errs.ErrConflict) {
 			latest, getErr := handler.service.GetPage(ctx.Request.Context(), pageID)
@@ -919,16 +1667,45 @@ func (handler *Handler) createShareLink(ctx *gin.Context) {
 		return
 	}
 	access := domain.ShareAccess(strings.TrimSpace(strings.ToLower(body.Access)))
-	share, err := handler.service.CreateShareLink(ctx.Request.Context(), string(uid), pageID, access)
+	share, err := handler.service.CreateShareLinkWithLimits(ctx.Request.Context(), string(uid), pageID, access, body.ExpiresAt, body.MaxUses)
 	if err != nil {
 		handler.handleError(ctx, err)
 		return
 	}
-	ctx.JSON(201, gin.H{
+	response := gin.H{
 		"token":  share.Token,
 		"access": share.Access,
 		"url":    fmt.Sprintf("/editor/%s?share=%s", pageID, share.Token),
-	})
+	}
+	if share.Code != "" {
+		response["code"] = share.Code
+		response["short_url"] = fmt.Sprintf("/e/%s", share.Code)
+	}
+	if share.ExpiresAt != nil {
+		response["expires_at"] = share.ExpiresAt
+	}
+	if share.MaxUses != nil {
+		response["max_uses"] = *share.MaxUses
+	}
+	ctx.JSON(201, response)
+}
+
+func (handler *Handler) validateShareLink(ctx *gin.Context) {
+	validation, err := handler.service.ValidateShareLink(ctx.Request.Context(), ctx.Param("token"))
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, validation)
+}
+
+func (handler *Handler) previewShareLink(ctx *gin.Context) {
+	preview, err := handler.service.PreviewShareLink(ctx.Request.Context(), ctx.Param("token"))
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, preview)
 }
 
 func (handler *Handler) revokeShareLink(ctx *gin.Context) {
@@ -942,6 +1719,26 @@ func (handler *Handler) revokeShareLink(ctx *gin.Context) {
 	ctx.JSON(200, gin.H{"status": "revoked", "access": access})
 }
 
+func (handler *Handler) revokeShareLinkByToken(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := domain.PageID(ctx.Param("pageID"))
+	if err := handler.service.RevokeShareLinkByToken(ctx.Request.Context(), string(uid), pageID, ctx.Param("token")); err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"status": "revoked"})
+}
+
+func (handler *Handler) listShareLinks(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	shares, err := handler.service.ListShareLinks(ctx.Request.Context(), string(uid), domain.PageID(ctx.Param("pageID")))
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"items": shares})
+}
+
 func (handler *Handler) listFeed(ctx *gin.Context) {
 	limit := 20
 	offset := 0
@@ -979,7 +1776,36 @@ func (handler *Handler) listFeed(ctx *gin.Context) {
 		}
 	}
 
-	pages, err := handler.service.ListPublishedFeed(ctx.Request.Context(), limit, offset, sort, authorUserIDs)
+	// Leave out authors the viewer has blocked, and optionally the viewer
+	var viewerID string
+	var excludedOwnerIDs []string
+	if userID, exists := auth.GetUserID(ctx); exists {
+		viewerID = string(userID)
+		blockedUsers, err := handler.usersService.ListBlocked(ctx.Request.Context(), usersdomain.UserID(userID))
+		if err != nil {
+			handler.handleError(ctx, err)
+			return
+		}
+		for _, u := range blockedUsers {
+			excludedOwnerIDs = append(excludedOwnerIDs, string(u.ID))
+		}
+	}
+	excludeSelf := handler.feedExcludeSelf
+	if v, err := strconv.ParseBool(ctx.Query("exclude_self")); err == nil {
+		excludeSelf = v
+	}
+
+	pages, err := handler.service.ListPublishedFeed(ctx.Request.Context(), limit, offset, sort, authorUserIDs, excludedOwnerIDs, excludeSelf, ctx.Query("tag"), viewerID)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"items": pages})
+}
+
+func (handler *Handler) listTrendingPages(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pages, err := handler.service.TrendingForOwner(ctx.Request.Context(), string(uid))
 	if err != nil {
 		handler.handleError(ctx, err)
 		return
@@ -987,32 +1813,117 @@ func (handler *Handler) listFeed(ctx *gin.Context) {
 	ctx.JSON(200, gin.H{"items": pages})
 }
 
+func (handler *Handler) likePage(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	count, err := handler.service.LikePage(ctx.Request.Context(), string(uid), domain.PageID(ctx.Param("pageID")))
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"liked": true, "like_count": count})
+}
+
+func (handler *Handler) unlikePage(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	count, err := handler.service.UnlikePage(ctx.Request.Context(), string(uid), domain.PageID(ctx.Param("pageID")))
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"liked": false, "like_count": count})
+}
+
+func (handler *Handler) bookmarkPage(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	if err := handler.service.Bookmark(ctx.Request.Context(), string(uid), domain.PageID(ctx.Param("pageID"))); err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"bookmarked": true})
+}
+
+func (handler *Handler) unbookmarkPage(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	if err := handler.service.Unbookmark(ctx.Request.Context(), string(uid), domain.PageID(ctx.Param("pageID"))); err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"bookmarked": false})
+}
+
+func (handler *Handler) listBookmarks(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	limit := 0
+	offset := 0
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 {
+			limit = v
+		}
+	}
+	if o := ctx.Query("offset"); o != "" {
+		if v, err := strconv.Atoi(o); err == nil && v >= 0 {
+			offset = v
+		}
+	}
+	pages, nextOffset, err := handler.service.ListBookmarks(ctx.Request.Context(), string(uid), limit, offset)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"items": pages, "next_offset": nextOffset})
+}
+
+func (handler *Handler) listTrendingTags(ctx *gin.Context) {
+	tags, err := handler.service.TrendingTags(ctx.Request.Context())
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"items": tags})
+}
+
 func (handler *Handler) listPublishedPagesByUser(ctx *gin.Context) {
 	userID := ctx.Param("userID")
 	if userID == "" {
 		ctx.JSON(400, gin.H{"error": "userID is required"})
 		return
 	}
-	pages, err := handler.service.ListPublishedPagesByOwner(ctx.Request.Context(), userID)
+	limit := 0
+	offset := 0
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 {
+			limit = v
+		}
+	}
+	if o := ctx.Query("offset"); o != "" {
+		if v, err := strconv.Atoi(o); err == nil && v >= 0 {
+			offset = v
+		}
+	}
+	pages, nextOffset, err := handler.service.ListPublishedPagesByOwner(ctx.Request.Context(), userID, ctx.Query("tag"), limit, offset)
 	if err != nil {
 		handler.handleError(ctx, err)
 		return
 	}
-	ctx.JSON(200, gin.H{"items": pages})
+	ctx.JSON(200, gin.H{"items": pages, "next_offset": nextOffset})
 }
 
 func (handler *Handler) handleError(ctx *gin.Context, err error) {
-	handler.logger.Warn("request failed", zap.Error(err))
+	handler.logger.Warn("request failed", zap.Error(err), zap.String("request_id", httputil.RequestID(ctx.Request.Context())))
 
 	switch {
 	case errors.Is(err, errs.ErrInvalidInput):
 		ctx.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrUnauthorized):
+		ctx.JSON(401, gin.H{"error": err.Error()})
 	case errors.Is(err, errs.ErrForbidden):
 		ctx.JSON(403, gin.H{"error": "forbidden"})
 	case errors.Is(err, errs.ErrConflict):
 		ctx.JSON(409, gin.H{"error": err.Error()})
 	case errors.Is(err, errs.ErrNotFound):
 		ctx.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrRateLimited):
+		ctx.JSON(429, gin.H{"error": err.Error()})
 	default:
 		ctx.JSON(500, gin.H{"error": "internal server error"})
 	}
@@ -0,0 +1,68 @@
+package httpadapter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/reggieanim/jot/internal/platform/httputil"
+)
+
+// WithPagePasswordLimiter throttles wrong passwords for protected pages per
+// client IP and page. Without it guesses are unthrottled.
+func WithPagePasswordLimiter(limiter httputil.Limiter) Option {
+	return func(handler *Handler) {
+		handler.passwords = newPasswordGuard(limiter)
+	}
+}
+
+// passwordGuard spends a limiter token on every wrong page password. Once a
+// key runs out, every attempt is refused until the limiter would allow one
+// again, right password included, so guesses cannot continue behind the
+// refusals.
+type passwordGuard struct {
+	limiter httputil.Limiter
+	now     func() time.Time
+
+	mu          sync.Mutex
+	lockedUntil map[string]time.Time
+}
+
+func newPasswordGuard(limiter httputil.Limiter) *passwordGuard {
+	return &passwordGuard{limiter: limiter, now: time.Now, lockedUntil: make(map[string]time.Time)}
+}
+
+// Locked reports how long key must still wait before trying a password.
+func (guard *passwordGuard) Locked(key string) (time.Duration, bool) {
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	until, ok := guard.lockedUntil[key]
+	if !ok {
+		return 0, false
+	}
+	wait := until.Sub(guard.now())
+	if wait <= 0 {
+		delete(guard.lockedUntil, key)
+		return 0, false
+	}
+	return wait, true
+}
+
+// Failed records a wrong password for key and reports the lockout it
+// triggers, if any.
+func (guard *passwordGuard) Failed(ctx context.Context, key string) (time.Duration, bool) {
+	allowed, wait := guard.limiter.Allow(ctx, key)
+	if allowed {
+		return 0, false
+	}
+	now := guard.now()
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	for other, until := range guard.lockedUntil {
+		if !until.After(now) {
+			delete(guard.lockedUntil, other)
+		}
+	}
+	guard.lockedUntil[key] = now.Add(wait)
+	return wait, true
+}
@@ -0,0 +1,221 @@
+package httpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reggieanim/jot/internal/modules/pages/app"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/modules/pages/ports"
+	"github.com/reggieanim/jot/internal/platform/httputil"
+	"github.com/reggieanim/jot/internal/shared/clock"
+	"github.com/reggieanim/jot/internal/shared/errs"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// protectedPageRepo serves one published page behind a password and
+// implements just enough of ports.PageRepository for public reads.
+type protectedPageRepo struct {
+	ports.PageRepository
+	page domain.Page
+	hash string
+}
+
+func (repo *protectedPageRepo) GetByID(_ context.Context, pageID domain.PageID) (domain.Page, error) {
+	if pageID != repo.page.ID {
+		return domain.Page{}, errs.ErrNotFound
+	}
+	return repo.page, nil
+}
+
+func (repo *protectedPageRepo) GetPasswordHash(_ context.Context, _ domain.PageID) (string, error) {
+	return repo.hash, nil
+}
+
+func (repo *protectedPageRepo) RecordOrganicRead(_ context.Context, _ domain.PageID, _ string) (bool, error) {
+	return false, nil
+}
+
+func (repo *protectedPageRepo) ListProofreadsByPageID(_ context.Context, _ domain.PageID, _ string) ([]domain.Proofread, error) {
+	return []domain.Proofread{{ID: "proofread-1", PageID: repo.page.ID}}, nil
+}
+
+func TestPublicPagePasswordGate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hash, err := bcrypt.GenerateFromPassword([]byte("open sesame"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	cover := "https://example.com/cover.png"
+	repo := &protectedPageRepo{
+		page: domain.Page{
+			ID:                "page-1",
+			Title:             "Locked",
+			Cover:             &cover,
+			Published:         true,
+			PasswordProtected: true,
+			Blocks:            []domain.Block{{ID: "b1", Type: "paragraph", Data: json.RawMessage(`{"text":"secret"}`)}},
+		},
+		hash: string(hash),
+	}
+	handler := &Handler{
+		logger:  zap.NewNop(),
+		service: app.NewService(repo, nil, clock.SystemClock{}),
+	}
+	router := gin.New()
+	router.GET("/public/pages/:pageID", handler.getPublicPage)
+	router.GET("/public/pages/:pageID/proofreads", handler.listProofreads)
+
+	request := func(path string, header string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			req.Header.Set("X-Page-Password", header)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{name: "absent", path: "/public/pages/page-1", want: http.StatusUnauthorized},
+		{name: "incorrect", path: "/public/pages/page-1", header: "guess", want: http.StatusUnauthorized},
+		{name: "correct header", path: "/public/pages/page-1", header: "open sesame", want: http.StatusOK},
+		{name: "query ignored", path: "/public/pages/page-1?pw=open+sesame", want: http.StatusUnauthorized},
+		{name: "proofreads absent", path: "/public/pages/page-1/proofreads", want: http.StatusUnauthorized},
+		{name: "proofreads incorrect", path: "/public/pages/page-1/proofreads", header: "guess", want: http.StatusUnauthorized},
+		{name: "proofreads correct", path: "/public/pages/page-1/proofreads", header: "open sesame", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := request(tt.path, tt.header)
+			if recorder.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, recorder.Code, recorder.Body.String())
+			}
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if tt.want != http.StatusUnauthorized {
+				return
+			}
+			var page map[string]json.RawMessage
+			if err := json.Unmarshal(body["page"], &page); err != nil {
+				t.Fatalf("decode page: %v", err)
+			}
+			if _, ok := page["blocks"]; ok {
+				t.Fatalf("expected locked response without blocks, got %s", recorder.Body.String())
+			}
+			if string(page["title"]) != `"Locked"` || string(page["cover"]) != `"`+cover+`"` {
+				t.Fatalf("expected title and cover metadata, got %s", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestPagePasswordGuessesAreThrottled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hash, err := bcrypt.GenerateFromPassword([]byte("open sesame"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	repo := &protectedPageRepo{
+		page: domain.Page{ID: "page-1", Title: "Locked", Published: true, PasswordProtected: true},
+		hash: string(hash),
+	}
+	handler := &Handler{
+		logger:  zap.NewNop(),
+		service: app.NewService(repo, nil, clock.SystemClock{}),
+	}
+	WithPagePasswordLimiter(httputil.NewTokenBucket(1.0/3600, 2))(handler)
+	router := gin.New()
+	router.GET("/public/pages/:pageID", handler.getPublicPage)
+
+	request := func(password, remoteAddr string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/public/pages/page-1", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Page-Password", password)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	for i := 0; i < 2; i++ {
+		if recorder := request("guess", "192.0.2.1:1234"); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("guess %d: expected 401, got %d", i+1, recorder.Code)
+		}
+	}
+	if recorder := request("guess", "192.0.2.1:1234"); recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with Retry-After once guesses run out, got %d", recorder.Code)
+	}
+	if recorder := request("open sesame", "192.0.2.1:1234"); recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected the right password to wait out the lockout, got %d", recorder.Code)
+	}
+	if recorder := request("open sesame", "192.0.2.2:1234"); recorder.Code != http.StatusOK {
+		t.Fatalf("expected another client to be unaffected, got %d", recorder.Code)
+	}
+}
+
+func TestPageUnlockTokenReplacesPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hash, err := bcrypt.GenerateFromPassword([]byte("open sesame"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	repo := &protectedPageRepo{
+		page: domain.Page{ID: "page-1", Title: "Locked", Published: true, PasswordProtected: true},
+		hash: string(hash),
+	}
+	handler := &Handler{
+		logger:  zap.NewNop(),
+		service: app.NewService(repo, nil, clock.SystemClock{}),
+	}
+	router := gin.New()
+	router.GET("/public/pages/:pageID", handler.getPublicPage)
+
+	request := func(header, value string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/public/pages/page-1", nil)
+		req.Header.Set(header, value)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	unlocked := request("X-Page-Password", "open sesame")
+	token := unlocked.Header().Get("X-Page-Unlock")
+	if unlocked.Code != http.StatusOK || token == "" {
+		t.Fatalf("expected 200 with an unlock token, got %d %q", unlocked.Code, token)
+	}
+	if recorder := request("X-Page-Unlock", token); recorder.Code != http.StatusOK {
+		t.Fatalf("expected the unlock token to stand in for the password, got %d", recorder.Code)
+	}
+	if recorder := request("X-Page-Unlock", "forged.token"); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a forged token to be refused, got %d", recorder.Code)
+	}
+}
+
+func TestHandleErrorMapsPagePasswordErrorsTo401(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := &Handler{logger: zap.NewNop()}
+	for _, err := range []error{app.ErrPagePasswordRequired, app.ErrIncorrectPagePassword} {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		handler.handleError(ctx, err)
+		if recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for %v, got %d", err, recorder.Code)
+		}
+	}
+}
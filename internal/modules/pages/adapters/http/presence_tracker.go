@@ -0,0 +1,82 @@
+package httpadapter
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// presenceTTL bounds how long an online announcement counts toward the
+// snapshot without a refresh. WebSocket sessions announce offline when they
+// close, but clients using the POST endpoint may simply vanish.
+const presenceTTL = 2 * time.Minute
+
+type presenceEntry struct {
+	presence pagePresence
+	seenAt   time.Time
+}
+
+// presenceTracker remembers who is online on each page so a client that just
+// joined can be sent everyone already there instead of waiting for their next
+// announcement. It only sees presence published through this instance.
+type presenceTracker struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu    sync.Mutex
+	pages map[string]map[string]presenceEntry
+}
+
+func newPresenceTracker() *presenceTracker {
+	return &presenceTracker{
+		ttl:   presenceTTL,
+		now:   time.Now,
+		pages: make(map[string]map[string]presenceEntry),
+	}
+}
+
+// Track records a presence event: is_online=true adds or refreshes the
+// session and is_online=false removes it.
+func (tracker *presenceTracker) Track(presence pagePresence) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	sessions := tracker.pages[presence.PageID]
+	if !presence.IsOnline {
+		delete(sessions, presence.SessionID)
+		if len(sessions) == 0 {
+			delete(tracker.pages, presence.PageID)
+		}
+		return
+	}
+	if sessions == nil {
+		sessions = make(map[string]presenceEntry)
+		tracker.pages[presence.PageID] = sessions
+	}
+	sessions[presence.SessionID] = presenceEntry{presence: presence, seenAt: tracker.now()}
+}
+
+// Snapshot returns the sessions currently online on pageID, ordered by
+// session ID, dropping any that have gone quiet for longer than the TTL.
+func (tracker *presenceTracker) Snapshot(pageID string) []pagePresence {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	sessions := tracker.pages[pageID]
+	cutoff := tracker.now().Add(-tracker.ttl)
+	snapshot := make([]pagePresence, 0, len(sessions))
+	for sessionID, entry := range sessions {
+		if entry.seenAt.Before(cutoff) {
+			delete(sessions, sessionID)
+			continue
+		}
+		snapshot = append(snapshot, entry.presence)
+	}
+	if len(sessions) == 0 {
+		delete(tracker.pages, pageID)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].SessionID < snapshot[j].SessionID
+	})
+	return snapshot
+}
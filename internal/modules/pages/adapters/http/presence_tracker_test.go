@@ -0,0 +1,31 @@
+package httpadapter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPresenceTrackerSnapshot(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newPresenceTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.Track(pagePresence{PageID: "page-1", SessionID: "s-2", UserName: "Grace", IsOnline: true})
+	tracker.Track(pagePresence{PageID: "page-1", SessionID: "s-1", UserName: "Ada", IsOnline: true})
+	tracker.Track(pagePresence{PageID: "page-2", SessionID: "s-3", UserName: "Linus", IsOnline: true})
+
+	snapshot := tracker.Snapshot("page-1")
+	if len(snapshot) != 2 || snapshot[0].SessionID != "s-1" || snapshot[1].SessionID != "s-2" {
+		t.Fatalf("expected both page-1 sessions ordered by session ID, got %+v", snapshot)
+	}
+
+	tracker.Track(pagePresence{PageID: "page-1", SessionID: "s-1", IsOnline: false})
+	if snapshot := tracker.Snapshot("page-1"); len(snapshot) != 1 || snapshot[0].SessionID != "s-2" {
+		t.Fatalf("expected offline session removed, got %+v", snapshot)
+	}
+
+	now = now.Add(presenceTTL + time.Second)
+	if snapshot := tracker.Snapshot("page-1"); len(snapshot) != 0 {
+		t.Fatalf("expected stale session dropped, got %+v", snapshot)
+	}
+}
@@ -0,0 +1,90 @@
+package httpadapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reggieanim/jot/internal/modules/pages/app"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/modules/pages/ports"
+	usersdomain "github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/platform/auth"
+	"github.com/reggieanim/jot/internal/shared/clock"
+	"github.com/reggieanim/jot/internal/shared/errs"
+	"go.uber.org/zap"
+)
+
+// proofreadPagesRepo serves owned pages and the proofreads left on them.
+type proofreadPagesRepo struct {
+	ports.PageRepository
+	pages      map[domain.PageID]domain.Page
+	proofreads map[domain.ProofreadID]domain.Proofread
+}
+
+func (repo *proofreadPagesRepo) GetByID(_ context.Context, pageID domain.PageID) (domain.Page, error) {
+	page, ok := repo.pages[pageID]
+	if !ok {
+		return domain.Page{}, errs.ErrNotFound
+	}
+	return page, nil
+}
+
+func (repo *proofreadPagesRepo) GetProofreadByID(_ context.Context, proofreadID domain.ProofreadID) (domain.Proofread, error) {
+	proofread, ok := repo.proofreads[proofreadID]
+	if !ok {
+		return domain.Proofread{}, errs.ErrNotFound
+	}
+	return proofread, nil
+}
+
+func (repo *proofreadPagesRepo) DeleteProofread(_ context.Context, pageID domain.PageID, proofreadID domain.ProofreadID) error {
+	if proofread, ok := repo.proofreads[proofreadID]; !ok || proofread.PageID != pageID {
+		return errs.ErrNotFound
+	}
+	delete(repo.proofreads, proofreadID)
+	return nil
+}
+
+func TestDeleteProofreadRejectsMismatchedPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	attacker, victim := "owner-1", "owner-2"
+	repo := &proofreadPagesRepo{
+		pages: map[domain.PageID]domain.Page{
+			"attacker-page": {ID: "attacker-page", OwnerID: &attacker},
+			"victim-page":   {ID: "victim-page", OwnerID: &victim},
+		},
+		proofreads: map[domain.ProofreadID]domain.Proofread{
+			"proofread-1": {ID: "proofread-1", PageID: "victim-page"},
+		},
+	}
+	handler := &Handler{
+		logger:  zap.NewNop(),
+		service: app.NewService(repo, nil, clock.SystemClock{}),
+	}
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(auth.UserIDKey, usersdomain.UserID(ctx.GetHeader("X-Test-User")))
+	})
+	router.DELETE("/pages/:pageID/proofreads/:proofreadID", handler.deleteProofread)
+
+	deleteProofread := func(user string, pageID domain.PageID) int {
+		request := httptest.NewRequest(http.MethodDelete, "/pages/"+string(pageID)+"/proofreads/proofread-1", nil)
+		request.Header.Set("X-Test-User", user)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	if code := deleteProofread(attacker, "attacker-page"); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a proofread on another page, got %d", code)
+	}
+	if _, ok := repo.proofreads["proofread-1"]; !ok {
+		t.Fatal("expected the proofread to survive a delete through another page")
+	}
+	if code := deleteProofread(victim, "victim-page"); code != http.StatusOK {
+		t.Fatalf("expected the owner to delete the proofread, got %d", code)
+	}
+}
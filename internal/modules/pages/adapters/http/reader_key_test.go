@@ -0,0 +1,24 @@
+package httpadapter
+
+import "testing"
+
+func TestOrganicReaderKeyDependsOnSalt(t *testing.T) {
+	const ip, ua = "203.0.113.7", "Mozilla/5.0"
+
+	first := organicReaderKey([]byte("salt-a"), ip, ua)
+	again := organicReaderKey([]byte("salt-a"), ip, ua)
+	other := organicReaderKey([]byte("salt-b"), ip, ua)
+
+	if first == "" {
+		t.Fatal("expected a reader key")
+	}
+	if first != again {
+		t.Fatalf("expected the same salt to produce a stable key, got %q and %q", first, again)
+	}
+	if first == other {
+		t.Fatalf("expected different salts to produce different keys, both were %q", first)
+	}
+	if key := organicReaderKey([]byte("salt-a"), " ", ""); key != "" {
+		t.Fatalf("expected no key without ip or user agent, got %q", key)
+	}
+}
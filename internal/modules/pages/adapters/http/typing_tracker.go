@@ -0,0 +1,88 @@
+package httpadapter
+
+import (
+	"sync"
+	"time"
+)
+
+// WithTypingTimeout sets how long a typing indicator stays up without a
+// refresh before the server clears it. Zero disables the automatic clear.
+func WithTypingTimeout(timeout time.Duration) Option {
+	return func(handler *Handler) {
+		handler.typingTimeout = timeout
+	}
+}
+
+type typingKey struct {
+	pageID    string
+	sessionID string
+	blockID   string
+}
+
+type stopper interface {
+	Stop() bool
+}
+
+type typingTimer struct {
+	timer      stopper
+	generation uint64
+}
+
+// typingTracker emits a synthetic is_typing=false for indicators that are not
+// refreshed within timeout, so a client that disconnects mid-word does not
+// leave a frozen indicator on everyone else's screen.
+type typingTracker struct {
+	timeout   time.Duration
+	afterFunc func(time.Duration, func()) stopper
+	emit      func(typingPresence)
+
+	mu         sync.Mutex
+	timers     map[typingKey]typingTimer
+	generation uint64
+}
+
+func newTypingTracker(timeout time.Duration, emit func(typingPresence)) *typingTracker {
+	return &typingTracker{
+		timeout: timeout,
+		afterFunc: func(d time.Duration, f func()) stopper {
+			return time.AfterFunc(d, f)
+		},
+		emit:   emit,
+		timers: make(map[typingKey]typingTimer),
+	}
+}
+
+// Track records a typing event: is_typing=true (re)arms the expiry timer and
+// is_typing=false cancels it.
+func (tracker *typingTracker) Track(typing typingPresence) {
+	key := typingKey{pageID: typing.PageID, sessionID: typing.SessionID, blockID: typing.BlockID}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if existing, ok := tracker.timers[key]; ok {
+		existing.timer.Stop()
+		delete(tracker.timers, key)
+	}
+	if !typing.IsTyping {
+		return
+	}
+
+	tracker.generation++
+	generation := tracker.generation
+	cleared := typing
+	cleared.IsTyping = false
+	timer := tracker.afterFunc(tracker.timeout, func() {
+		tracker.mu.Lock()
+		current, ok := tracker.timers[key]
+		if !ok || current.generation != generation {
+			// Refreshed or cleared after this timer was armed.
+			tracker.mu.Unlock()
+			return
+		}
+		delete(tracker.timers, key)
+		tracker.mu.Unlock()
+		tracker.emit(cleared)
+	})
+	tracker.timers[key] = typingTimer{timer: timer, generation: generation}
+}
@@ -0,0 +1,112 @@
+package httpadapter
+
+import (
+	"testing"
+	"time"
+)
+
+// manualTimers is a fake clock for typingTracker: timers fire only when the
+// test advances time past their deadline.
+type manualTimers struct {
+	now     time.Duration
+	pending []*manualTimer
+}
+
+type manualTimer struct {
+	deadline time.Duration
+	fn       func()
+	stopped  bool
+}
+
+func (timer *manualTimer) Stop() bool {
+	wasActive := !timer.stopped
+	timer.stopped = true
+	return wasActive
+}
+
+func (timers *manualTimers) afterFunc(d time.Duration, fn func()) stopper {
+	timer := &manualTimer{deadline: timers.now + d, fn: fn}
+	timers.pending = append(timers.pending, timer)
+	return timer
+}
+
+func (timers *manualTimers) advance(d time.Duration) {
+	timers.now += d
+	remaining := timers.pending[:0]
+	due := make([]*manualTimer, 0)
+	for _, timer := range timers.pending {
+		if timer.stopped {
+			continue
+		}
+		if timer.deadline <= timers.now {
+			due = append(due, timer)
+			continue
+		}
+		remaining = append(remaining, timer)
+	}
+	timers.pending = remaining
+	for _, timer := range due {
+		timer.stopped = true
+		timer.fn()
+	}
+}
+
+func newTestTypingTracker(timeout time.Duration) (*typingTracker, *manualTimers, *[]typingPresence) {
+	timers := &manualTimers{}
+	emitted := make([]typingPresence, 0)
+	tracker := newTypingTracker(timeout, func(typing typingPresence) {
+		emitted = append(emitted, typing)
+	})
+	tracker.afterFunc = timers.afterFunc
+	return tracker, timers, &emitted
+}
+
+func TestTypingTrackerClearsStaleIndicator(t *testing.T) {
+	tracker, timers, emitted := newTestTypingTracker(8 * time.Second)
+	typing := typingPresence{PageID: "page-1", BlockID: "b1", SessionID: "s-1", UserName: "Ada", IsTyping: true}
+
+	tracker.Track(typing)
+	timers.advance(5 * time.Second)
+	if len(*emitted) != 0 {
+		t.Fatalf("expected no clear before the timeout, got %+v", *emitted)
+	}
+
+	// A refresh re-arms the timer from now.
+	tracker.Track(typing)
+	timers.advance(5 * time.Second)
+	if len(*emitted) != 0 {
+		t.Fatalf("expected refresh to postpone the clear, got %+v", *emitted)
+	}
+
+	timers.advance(3 * time.Second)
+	if len(*emitted) != 1 {
+		t.Fatalf("expected one clear after the timeout, got %+v", *emitted)
+	}
+	cleared := (*emitted)[0]
+	if cleared.IsTyping || cleared.PageID != "page-1" || cleared.SessionID != "s-1" || cleared.BlockID != "b1" {
+		t.Fatalf("unexpected clear event %+v", cleared)
+	}
+
+	timers.advance(time.Minute)
+	if len(*emitted) != 1 {
+		t.Fatalf("expected the clear to fire once, got %+v", *emitted)
+	}
+}
+
+func TestTypingTrackerExplicitStopCancelsClear(t *testing.T) {
+	tracker, timers, emitted := newTestTypingTracker(8 * time.Second)
+	typing := typingPresence{PageID: "page-1", BlockID: "b1", SessionID: "s-1", UserName: "Ada", IsTyping: true}
+
+	tracker.Track(typing)
+	other := typing
+	other.BlockID = "b2"
+	tracker.Track(other)
+
+	typing.IsTyping = false
+	tracker.Track(typing)
+	timers.advance(10 * time.Second)
+
+	if len(*emitted) != 1 || (*emitted)[0].BlockID != "b2" {
+		t.Fatalf("expected only the untouched block to be cleared, got %+v", *emitted)
+	}
+}
@@ -0,0 +1,97 @@
+package httpadapter
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	filesapp "github.com/reggieanim/jot/internal/modules/files/app"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	userdomain "github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/platform/auth"
+	"github.com/reggieanim/jot/internal/platform/httputil"
+	"go.uber.org/zap"
+)
+
+type memoryStorageUsage struct {
+	used    map[string]int64
+	objects map[string]string
+}
+
+func (usage *memoryStorageUsage) Reserve(_ context.Context, userID string, size, limit int64) (bool, error) {
+	if limit > 0 && usage.used[userID]+size > limit {
+		return false, nil
+	}
+	usage.used[userID] += size
+	return true, nil
+}
+
+func (usage *memoryStorageUsage) Release(_ context.Context, userID string, size int64) error {
+	usage.used[userID] -= size
+	return nil
+}
+
+func (usage *memoryStorageUsage) RecordObject(_ context.Context, userID, objectKey string, _ int64) error {
+	usage.objects[objectKey] = userID
+	return nil
+}
+
+func (usage *memoryStorageUsage) ReleaseObject(_ context.Context, objectKey string) error {
+	delete(usage.objects, objectKey)
+	return nil
+}
+
+func TestPublicUploadsAreThrottledOrMetered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	usage := &memoryStorageUsage{used: map[string]int64{}, objects: map[string]string{}}
+	handler := &Handler{logger: zap.NewNop(), media: &recordingMediaStore{}}
+	WithStorageQuota(filesapp.NewStorageQuota(usage, 0))(handler)
+	WithAnonymousUploadLimiter(httputil.NewTokenBucket(0.0001, 1))(handler)
+
+	router := gin.New()
+	signIn := func(ctx *gin.Context) {
+		if uid := ctx.GetHeader("X-Test-User"); uid != "" {
+			ctx.Set(auth.UserIDKey, userdomain.UserID(uid))
+		}
+	}
+	router.POST("/audio", signIn, httputil.RateLimit(handler.anonymousUploads, anonymousUploadKeys), handler.uploadPublicAudio)
+
+	mp3 := append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), bytes.Repeat([]byte{0}, 64)...)
+	upload := func(userID string) int {
+		request := audioUploadRequest(t, "song.mp3", "audio/mpeg", mp3)
+		if userID != "" {
+			request.Header.Set("X-Test-User", userID)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	if code := upload(""); code != http.StatusCreated {
+		t.Fatalf("expected first anonymous upload to be accepted, got %d", code)
+	}
+	if code := upload(""); code != http.StatusTooManyRequests {
+		t.Fatalf("expected second anonymous upload to be throttled, got %d", code)
+	}
+	for i := 0; i < 2; i++ {
+		if code := upload("user-1"); code != http.StatusCreated {
+			t.Fatalf("expected signed-in upload %d to be accepted, got %d", i+1, code)
+		}
+	}
+	if got := usage.used["user-1"]; got != int64(2*len(mp3)) {
+		t.Fatalf("expected signed-in uploads metered against the uploader, got %d bytes", got)
+	}
+}
+
+func TestUploadOwnerChargesPageOwner(t *testing.T) {
+	owner := "owner-1"
+	if got := uploadOwner(domain.Page{OwnerID: &owner}, "editor-1"); got != owner {
+		t.Fatalf("expected uploads charged to the page owner, got %q", got)
+	}
+	if got := uploadOwner(domain.Page{}, "editor-1"); got != "editor-1" {
+		t.Fatalf("expected uploads to an ownerless page charged to the uploader, got %q", got)
+	}
+}
@@ -0,0 +1,335 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	jnats "github.com/nats-io/nats.go"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/platform/auth"
+	platformnats "github.com/reggieanim/jot/internal/platform/eventbus/nats"
+	"go.uber.org/zap"
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	wsPingInterval = 15 * time.Second
+	wsPongTimeout  = 2 * wsPingInterval
+	wsReadLimit    = 64 * 1024
+	wsEventBuffer  = 64
+)
+
+// WithAllowedOrigins restricts WebSocket upgrades and SSE subscriptions to the
+// given comma-separated origins. When unset, only same-origin requests are
+// accepted. Requests without an Origin header are not cross-origin and are
+// always accepted.
+func WithAllowedOrigins(origins string) Option {
+	return func(handler *Handler) {
+		allowed := make(map[string]bool)
+		for _, origin := range strings.Split(origins, ",") {
+			origin = strings.ToLower(strings.TrimSpace(origin))
+			if origin != "" {
+				allowed[origin] = true
+			}
+		}
+		handler.allowedOrigins = allowed
+	}
+}
+
+// checkOrigin reports whether r comes from an allowed origin. Realtime
+// routes check it before subscribing to NATS, since EventSource and WebSocket
+// clients send no auth header for CORS to gate.
+func (handler *Handler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if handler.allowedOrigins[strings.ToLower(origin)] {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(parsed.Host, r.Host)
+}
+
+// subscribePageWebSocket streams a page's realtime events over a WebSocket
+// and accepts typing and presence frames from the client, replacing the SSE
+// stream plus POSTs with one connection. Clients that identify themselves
+// with session_id and user_name query parameters, or with a presence frame,
+// are announced offline when the socket closes, so presence no longer
+// depends on an explicit offline message.
+func (handler *Handler) subscribePageWebSocket(ctx *gin.Context) {
+	uid, _ := auth.GetUserID(ctx)
+	pageID := strings.TrimSpace(ctx.Param("pageID"))
+	if pageID == "" {
+		ctx.JSON(400, gin.H{"error": "pageID is required"})
+		return
+	}
+	if !handler.checkOrigin(ctx.Request) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "origin not allowed"})
+		return
+	}
+	shareToken := strings.TrimSpace(ctx.Query("share"))
+	_, accessMode, err := handler.service.ResolvePageAccess(ctx.Request.Context(), string(uid), domain.PageID(pageID), shareToken, domain.ShareAccessView)
+	if err != nil {
+		handler.handleError(ctx, err)
+		return
+	}
+	if handler.conn == nil {
+		ctx.JSON(503, gin.H{"error": "realtime unavailable"})
+		return
+	}
+
+	var presence *pagePresence
+	sessionID := strings.TrimSpace(ctx.Query("session_id"))
+	userName := strings.TrimSpace(ctx.Query("user_name"))
+	if sessionID != "" && userName != "" {
+		presence = &pagePresence{
+			PageID:        pageID,
+			SessionID:     sessionID,
+			UserName:      userName,
+			UserAvatarURL: strings.TrimSpace(ctx.Query("user_avatar_url")),
+		}
+	}
+
+	msgs := make(chan *jnats.Msg, wsEventBuffer)
+	unsubscribe, err := platformnats.SubscribePage(handler.conn, handler.subject, pageID, msgs)
+	if err != nil {
+		handler.logger.Warn("subscribe nats failed", zap.Error(err))
+		ctx.JSON(503, gin.H{"error": "realtime unavailable"})
+		return
+	}
+	defer unsubscribe()
+
+	upgrader := websocket.Upgrader{CheckOrigin: handler.checkOrigin}
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error response.
+		return
+	}
+
+	var snapshot *streamEvent
+	if handler.presence != nil {
+		event := handler.presenceSnapshot(pageID)
+		snapshot = &event
+	}
+
+	session := &wsSession{
+		conn:       conn,
+		pageID:     pageID,
+		accessMode: accessMode,
+		presence:   presence,
+		snapshot:   snapshot,
+		publish: func(event streamEvent) error {
+			return handler.publishStreamEvent(pageID, event)
+		},
+		logger: handler.logger,
+	}
+	session.run(msgs)
+}
+
+func (handler *Handler) publishStreamEvent(pageID string, event streamEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if err := handler.conn.Publish(platformnats.PageSubject(handler.subject, pageID), payload); err != nil {
+		return err
+	}
+	if event.Typing != nil && handler.typing != nil {
+		handler.typing.Track(*event.Typing)
+	}
+	if event.Presence != nil && handler.presence != nil {
+		handler.presence.Track(*event.Presence)
+	}
+	return nil
+}
+
+// presenceSnapshot lists the sessions already online on pageID, for a client
+// that has just joined.
+func (handler *Handler) presenceSnapshot(pageID string) streamEvent {
+	return streamEvent{
+		Type:          "page.presence_snapshot",
+		Collaborators: handler.presence.Snapshot(pageID),
+		Timestamp:     time.Now().UTC(),
+	}
+}
+
+// publishTypingCleared broadcasts the synthetic clear for an expired typing
+// indicator.
+func (handler *Handler) publishTypingCleared(typing typingPresence) {
+	event := streamEvent{Type: "page.typing", Typing: &typing, Timestamp: time.Now().UTC()}
+	if err := handler.publishStreamEvent(typing.PageID, event); err != nil {
+		handler.logger.Warn("publish typing clear failed", zap.Error(err))
+	}
+}
+
+// wsSession relays one page's events to a single WebSocket client and
+// republishes the client's typing and presence frames.
+type wsSession struct {
+	conn    *websocket.Conn
+	pageID  string
+	publish func(streamEvent) error
+	logger  *zap.Logger
+
+	// accessMode is the client's access as resolved on connect: "owner",
+	// "edit" or "view". Only editors may announce typing.
+	accessMode string
+
+	// snapshot, when set, is written before anything else so the client sees
+	// who is already on the page without waiting for their next announcement.
+	snapshot *streamEvent
+
+	mu       sync.Mutex
+	presence *pagePresence
+}
+
+// run blocks until the client disconnects, forwarding matching payloads from
+// events. The client's presence is published on entry and cleared on exit.
+func (session *wsSession) run(events <-chan *jnats.Msg) {
+	defer session.conn.Close()
+
+	if session.snapshot != nil {
+		_ = session.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := session.conn.WriteJSON(session.snapshot); err != nil {
+			return
+		}
+	}
+
+	session.announce(true)
+	defer session.announce(false)
+
+	closed := make(chan struct{})
+	go session.readLoop(closed)
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ping.C:
+			deadline := time.Now().Add(wsWriteTimeout)
+			if err := session.conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				return
+			}
+		case msg := <-events:
+			event, err := decodeStreamEvent(msg.Data)
+			if err != nil {
+				session.logger.Warn("invalid page event payload", zap.Error(err))
+				continue
+			}
+			if _, ok := streamEventName(event, session.pageID); !ok {
+				continue
+			}
+			if event.Timestamp.IsZero() {
+				event.Timestamp = time.Now().UTC()
+			}
+			_ = session.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := session.conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop handles client frames, keeps the read deadline alive on pongs,
+// and closes done once the connection is gone.
+func (session *wsSession) readLoop(done chan<- struct{}) {
+	defer close(done)
+
+	session.conn.SetReadLimit(wsReadLimit)
+	_ = session.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	session.conn.SetPongHandler(func(string) error {
+		return session.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+	for {
+		_, data, err := session.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = session.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		session.handleFrame(data)
+	}
+}
+
+// handleFrame republishes a client typing or presence frame. Frames are
+// pinned to the session's page so a client cannot inject events elsewhere;
+// typing from view-only clients and anything else is ignored.
+func (session *wsSession) handleFrame(data []byte) {
+	var event streamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return
+	}
+
+	switch event.Type {
+	case "page.typing":
+		typing := event.Typing
+		if typing == nil || (session.accessMode != "owner" && session.accessMode != "edit") {
+			return
+		}
+		typing.SessionID = strings.TrimSpace(typing.SessionID)
+		typing.UserName = strings.TrimSpace(typing.UserName)
+		typing.BlockID = strings.TrimSpace(typing.BlockID)
+		if typing.SessionID == "" || typing.UserName == "" || typing.BlockID == "" {
+			return
+		}
+		typing.PageID = session.pageID
+		event = streamEvent{Type: event.Type, Typing: typing}
+	case "page.presence":
+		presence := event.Presence
+		if presence == nil {
+			return
+		}
+		presence.SessionID = strings.TrimSpace(presence.SessionID)
+		presence.UserName = strings.TrimSpace(presence.UserName)
+		if presence.SessionID == "" || presence.UserName == "" {
+			return
+		}
+		presence.PageID = session.pageID
+		session.mu.Lock()
+		if presence.IsOnline {
+			remembered := *presence
+			session.presence = &remembered
+		} else {
+			session.presence = nil
+		}
+		session.mu.Unlock()
+		event = streamEvent{Type: event.Type, Presence: presence}
+	default:
+		return
+	}
+
+	event.Timestamp = time.Now().UTC()
+	if err := session.publish(event); err != nil {
+		session.logger.Warn("publish websocket frame failed", zap.Error(err), zap.String("type", event.Type))
+	}
+}
+
+func (session *wsSession) announce(online bool) {
+	session.mu.Lock()
+	current := session.presence
+	session.mu.Unlock()
+	if current == nil {
+		return
+	}
+	presence := *current
+	presence.IsOnline = online
+	event := streamEvent{
+		Type:      "page.presence",
+		Presence:  &presence,
+		Timestamp: time.Now().UTC(),
+	}
+	if err := session.publish(event); err != nil {
+		session.logger.Warn("publish presence failed", zap.Error(err), zap.Bool("is_online", online))
+	}
+}
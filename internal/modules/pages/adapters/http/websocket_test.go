@@ -0,0 +1,204 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	jnats "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// startSession serves a single wsSession for page-1, with the given access
+// mode, on a test server and returns the server plus a channel of every event
+// the session published.
+// Published events are looped back to the session as NATS would deliver them.
+func startSession(t *testing.T, accessMode string) (*httptest.Server, <-chan streamEvent) {
+	t.Helper()
+	published := make(chan streamEvent, 8)
+	events := make(chan *jnats.Msg, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		session := &wsSession{
+			conn:       conn,
+			pageID:     "page-1",
+			accessMode: accessMode,
+			presence:   &pagePresence{PageID: "page-1", SessionID: "s-1", UserName: "Ada"},
+			publish: func(event streamEvent) error {
+				published <- event
+				payload, err := json.Marshal(event)
+				if err != nil {
+					return err
+				}
+				events <- &jnats.Msg{Data: payload}
+				return nil
+			},
+			logger: zap.NewNop(),
+		}
+		session.run(events)
+	}))
+	t.Cleanup(server.Close)
+	return server, published
+}
+
+func nextPublished(t *testing.T, published <-chan streamEvent) streamEvent {
+	t.Helper()
+	select {
+	case event := <-published:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for published event")
+		return streamEvent{}
+	}
+}
+
+func dialSession(t *testing.T, server *httptest.Server) *websocket.Conn {
+	t.Helper()
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestWebSocketSessionPublishesOfflineOnClose(t *testing.T) {
+	server, published := startSession(t, "view")
+
+	client := dialSession(t, server)
+
+	online := nextPublished(t, published)
+	if online.Type != "page.presence" || online.Presence == nil || !online.Presence.IsOnline {
+		t.Fatalf("expected online presence on connect, got %+v", online)
+	}
+
+	_ = client.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	_ = client.Close()
+
+	offline := nextPublished(t, published)
+	if offline.Type != "page.presence" || offline.Presence == nil {
+		t.Fatalf("expected presence event on disconnect, got %+v", offline)
+	}
+	if offline.Presence.IsOnline {
+		t.Fatalf("expected is_online=false on disconnect")
+	}
+	if offline.Presence.SessionID != "s-1" || offline.Presence.PageID != "page-1" {
+		t.Fatalf("unexpected offline presence %+v", offline.Presence)
+	}
+}
+
+func TestWebSocketSessionRelaysTypingFrames(t *testing.T) {
+	server, published := startSession(t, "edit")
+	client := dialSession(t, server)
+	_ = client.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	// The connect-time presence announcement is echoed back first.
+	var online streamEvent
+	if err := client.ReadJSON(&online); err != nil || online.Type != "page.presence" {
+		t.Fatalf("expected presence echo, got %+v (%v)", online, err)
+	}
+	nextPublished(t, published)
+
+	frame := streamEvent{
+		Type: "page.typing",
+		Typing: &typingPresence{
+			PageID:    "page-2",
+			BlockID:   "b1",
+			SessionID: "s-1",
+			UserName:  "Ada",
+			IsTyping:  true,
+		},
+	}
+	if err := client.WriteJSON(frame); err != nil {
+		t.Fatalf("write typing frame: %v", err)
+	}
+
+	republished := nextPublished(t, published)
+	if republished.Type != "page.typing" || republished.Typing == nil {
+		t.Fatalf("expected typing event to be republished, got %+v", republished)
+	}
+	if republished.Typing.PageID != "page-1" {
+		t.Fatalf("expected typing pinned to the session page, got %q", republished.Typing.PageID)
+	}
+
+	var echoed streamEvent
+	if err := client.ReadJSON(&echoed); err != nil {
+		t.Fatalf("read typing event: %v", err)
+	}
+	if echoed.Type != "page.typing" || echoed.Typing == nil || echoed.Typing.BlockID != "b1" || !echoed.Typing.IsTyping {
+		t.Fatalf("expected typing event read back, got %+v", echoed)
+	}
+}
+
+func TestWebSocketSessionDropsTypingFromViewers(t *testing.T) {
+	server, published := startSession(t, "view")
+	client := dialSession(t, server)
+	nextPublished(t, published)
+
+	typing := streamEvent{
+		Type:   "page.typing",
+		Typing: &typingPresence{BlockID: "b1", SessionID: "s-1", UserName: "Ada", IsTyping: true},
+	}
+	if err := client.WriteJSON(typing); err != nil {
+		t.Fatalf("write typing frame: %v", err)
+	}
+	presence := streamEvent{
+		Type:     "page.presence",
+		Presence: &pagePresence{SessionID: "s-1", UserName: "Ada", IsOnline: true},
+	}
+	if err := client.WriteJSON(presence); err != nil {
+		t.Fatalf("write presence frame: %v", err)
+	}
+
+	// Frames are handled in order, so the presence frame arriving first means
+	// the typing frame was dropped.
+	if next := nextPublished(t, published); next.Type != "page.presence" {
+		t.Fatalf("expected a viewer's typing frame to be dropped, got %+v", next)
+	}
+}
+
+func TestWebSocketSessionSendsPresenceSnapshotOnJoin(t *testing.T) {
+	tracker := newPresenceTracker()
+	tracker.Track(pagePresence{PageID: "page-1", SessionID: "s-2", UserName: "Grace", IsOnline: true})
+	handler := &Handler{presence: tracker}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		snapshot := handler.presenceSnapshot("page-1")
+		session := &wsSession{
+			conn:     conn,
+			pageID:   "page-1",
+			snapshot: &snapshot,
+			publish:  func(streamEvent) error { return nil },
+			logger:   zap.NewNop(),
+		}
+		session.run(make(chan *jnats.Msg))
+	}))
+	t.Cleanup(server.Close)
+
+	client := dialSession(t, server)
+	_ = client.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var event streamEvent
+	if err := client.ReadJSON(&event); err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	if event.Type != "page.presence_snapshot" {
+		t.Fatalf("expected presence snapshot first, got %+v", event)
+	}
+	if len(event.Collaborators) != 1 || event.Collaborators[0].SessionID != "s-2" || event.Collaborators[0].UserName != "Grace" {
+		t.Fatalf("expected existing collaborator in snapshot, got %+v", event.Collaborators)
+	}
+}
@@ -5,22 +5,40 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/modules/pages/ports"
 	"github.com/reggieanim/jot/internal/shared/errs"
 )
 
 type Repository struct {
-	pool *pgxpool.Pool
+	pool        *pgxpool.Pool
+	globalSlugs bool
 }
 
-func NewRepository(pool *pgxpool.Pool) *Repository {
-	return &Repository{pool: pool}
+type Option func(*Repository)
+
+// WithGlobalSlugs makes slugs unique across every owner instead of per
+// owner, so a slug alone identifies a page.
+func WithGlobalSlugs(enabled bool) Option {
+	return func(repository *Repository) {
+		repository.globalSlugs = enabled
+	}
+}
+
+func NewRepository(pool *pgxpool.Pool, opts ...Option) *Repository {
+	repository := &Repository{pool: pool}
+	for _, opt := range opts {
+		opt(repository)
+	}
+	return repository
 }
 
 func (repository *Repository) Create(ctx context.Context, page domain.Page) error {
@@ -31,15 +49,21 @@ func (repository *Repository) Create(ctx context.Context, page domain.Page) erro
 	defer tx.Rollback(ctx)
 
 	_, err = tx.Exec(ctx, `
-		INSERT INTO pages (id, title, cover, published, unlisted, dark_mode, cinematic, mood, bg_color, owner_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-	`, string(page.ID), page.Title, page.Cover, page.Published, page.Unlisted, page.DarkMode, page.Cinematic, page.Mood, page.BgColor, page.OwnerID, page.CreatedAt, page.UpdatedAt)
+		INSERT INTO pages (id, title, cover, published, unlisted, dark_mode, cinematic, mood, bg_color, owner_id, created_at, updated_at, published_at, first_published_at, word_count, reading_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, CASE WHEN $4 THEN $11 END, CASE WHEN $4 THEN $11 END, $13, $14)
+	`, string(page.ID), page.Title, page.Cover, page.Published, page.Unlisted, page.DarkMode, page.Cinematic, page.Mood, page.BgColor, page.OwnerID, page.CreatedAt, page.UpdatedAt, page.WordCount, page.ReadingMinutes)
 	if err != nil {
 		return fmt.Errorf("insert page: %w", err)
 	}
 	if err := repository.insertBlocks(ctx, tx, page.ID, page.Blocks); err != nil {
 		return err
 	}
+	if err := syncMediaRefs(ctx, tx, page.ID); err != nil {
+		return err
+	}
+	if err := repository.replaceTags(ctx, tx, page.ID, page.Tags); err != nil {
+		return err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit create page: %w", err)
 	}
@@ -47,10 +71,10 @@ func (repository *Repository) Create(ctx context.Context, page domain.Page) erro
 }
 
 func (repository *Repository) UpdateBlocks(ctx context.Context, pageID domain.PageID, blocks []domain.Block) error {
-	return repository.UpdateBlocksOptimistic(ctx, pageID, blocks, nil)
+	return repository.UpdateBlocksOptimistic(ctx, pageID, blocks, nil, nil)
 }
 
-func (repository *Repository) UpdatePageMetaOptimistic(ctx context.Context, pageID domain.PageID, title string, cover *string, darkMode bool, cinematic bool, mood int, bgColor string, expectedUpdatedAt *time.Time) error {
+func (repository *Repository) UpdatePageMetaOptimistic(ctx context.Context, pageID domain.PageID, title string, cover *string, darkMode bool, cinematic bool, mood int, bgColor string, tags []string, expectedUpdatedAt *time.Time) error {
 	if mood < 0 {
 		mood = 0
 	}
@@ -58,7 +82,13 @@ func (repository *Repository) UpdatePageMetaOptimistic(ctx context.Context, page
 		mood = 100
 	}
 
-	commandTag, err := repository.pool.Exec(ctx, `
+	tx, err := repository.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	commandTag, err := tx.Exec(ctx, `
 		UPDATE pages
 		SET title = $2, cover = $3, dark_mode = $4, cinematic = $5, mood = $6, bg_color = $7, updated_at = now()
 		WHERE id = $1 AND deleted_at IS NULL AND ($8::timestamptz IS NULL OR updated_at = $8)
@@ -68,7 +98,7 @@ func (repository *Repository) UpdatePageMetaOptimistic(ctx context.Context, page
 	}
 	if commandTag.RowsAffected() == 0 {
 		var exists bool
-		if err := repository.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pages WHERE id = $1 AND deleted_at IS NULL)`, string(pageID)).Scan(&exists); err != nil {
+		if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pages WHERE id = $1 AND deleted_at IS NULL)`, string(pageID)).Scan(&exists); err != nil {
 			return fmt.Errorf("check page existence: %w", err)
 		}
 		if !exists {
@@ -76,19 +106,33 @@ func (repository *Repository) UpdatePageMetaOptimistic(ctx context.Context, page
 		}
 		return errs.ErrConflict
 	}
-
+	if tags != nil {
+		if err := repository.replaceTags(ctx, tx, pageID, tags); err != nil {
+			return err
+		}
+	}
+	if err := syncMediaRefs(ctx, tx, pageID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit update page meta: %w", err)
+	}
 	return nil
 }
 
-func (repository *Repository) SetPublished(ctx context.Context, pageID domain.PageID, published bool, unlisted bool) error {
+func (repository *Repository) SetPublished(ctx context.Context, pageID domain.PageID, published bool, unlisted bool, password *ports.PasswordChange) error {
+	setPassword, hash := passwordChangeArgs(password)
 	commandTag, err := repository.pool.Exec(ctx, `
 		UPDATE pages
 		SET published = $2,
 		    unlisted = $3,
 		    published_at = CASE WHEN $2 THEN now() ELSE NULL END,
+		    first_published_at = CASE WHEN $2 THEN COALESCE(first_published_at, now()) ELSE first_published_at END,
+		    publish_at = NULL,
+		    password_hash = CASE WHEN $4 THEN $5 ELSE password_hash END,
 		    updated_at = now()
 		WHERE id = $1 AND deleted_at IS NULL
-	`, string(pageID), published, unlisted)
+	`, string(pageID), published, unlisted, setPassword, hash)
 	if err != nil {
 		return fmt.Errorf("set published: %w", err)
 	}
@@ -98,6 +142,207 @@ func (repository *Repository) SetPublished(ctx context.Context, pageID domain.Pa
 	return nil
 }
 
+func (repository *Repository) SchedulePublish(ctx context.Context, pageID domain.PageID, publishAt time.Time, unlisted bool, password *ports.PasswordChange) error {
+	setPassword, hash := passwordChangeArgs(password)
+	commandTag, err := repository.pool.Exec(ctx, `
+		UPDATE pages
+		SET published = false,
+		    unlisted = $3,
+		    published_at = NULL,
+		    publish_at = $2,
+		    password_hash = CASE WHEN $4 THEN $5 ELSE password_hash END,
+		    updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, string(pageID), publishAt, unlisted, setPassword, hash)
+	if err != nil {
+		return fmt.Errorf("schedule publish: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func passwordChangeArgs(password *ports.PasswordChange) (bool, *string) {
+	if password == nil {
+		return false, nil
+	}
+	return true, password.Hash
+}
+
+func (repository *Repository) ListDuePages(ctx context.Context, now time.Time) ([]domain.Page, error) {
+	rows, err := repository.pool.Query(ctx, `
+		SELECT id, title, cover, published, unlisted, published_at, first_published_at,
+			dark_mode, cinematic, mood, bg_color, owner_id,
+			created_at, updated_at, deleted_at, COALESCE(slug, ''), publish_at
+		FROM pages
+		WHERE publish_at IS NOT NULL AND publish_at <= $1 AND deleted_at IS NULL
+		ORDER BY publish_at, id
+	`, now)
+	if err != nil {
+		return nil, fmt.Errorf("list due pages: %w", err)
+	}
+	defer rows.Close()
+
+	pages := make([]domain.Page, 0)
+	for rows.Next() {
+		var page domain.Page
+		if err := rows.Scan(&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.FirstPublishedAt, &page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt, &page.Slug, &page.PublishAt); err != nil {
+			return nil, fmt.Errorf("scan due page: %w", err)
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate due pages: %w", err)
+	}
+	return pages, nil
+}
+
+func (repository *Repository) PublishScheduled(ctx context.Context, pageID domain.PageID, now time.Time) (bool, error) {
+	commandTag, err := repository.pool.Exec(ctx, `
+		UPDATE pages
+		SET published = true,
+		    published_at = $2,
+		    first_published_at = COALESCE(first_published_at, $2),
+		    publish_at = NULL,
+		    updated_at = now()
+		WHERE id = $1 AND publish_at IS NOT NULL AND publish_at <= $2 AND deleted_at IS NULL
+	`, string(pageID), now)
+	if err != nil {
+		return false, fmt.Errorf("publish scheduled page: %w", err)
+	}
+	return commandTag.RowsAffected() > 0, nil
+}
+
+func (repository *Repository) GetBySlug(ctx context.Context, ownerUsername, slug string) (domain.Page, error) {
+	if ownerUsername == "" && !repository.globalSlugs {
+		return domain.Page{}, errs.ErrNotFound
+	}
+	var pageID string
+	err := repository.pool.QueryRow(ctx, `
+		SELECT p.id
+		FROM pages p
+		JOIN users u ON u.id = p.owner_id
+		WHERE ($1 = '' OR lower(u.username) = lower($1)) AND p.slug = $2
+		ORDER BY p.published DESC, p.first_published_at NULLS LAST, p.id
+		LIMIT 1
+	`, ownerUsername, slug).Scan(&pageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Page{}, errs.ErrNotFound
+		}
+		return domain.Page{}, fmt.Errorf("get page by slug: %w", err)
+	}
+	return repository.GetByID(ctx, domain.PageID(pageID))
+}
+
+func (repository *Repository) ListTakenSlugs(ctx context.Context, ownerID, base string) ([]string, error) {
+	rows, err := repository.pool.Query(ctx, `
+		SELECT slug
+		FROM pages
+		WHERE ($3 OR owner_id = $1) AND (slug = $2 OR slug LIKE $2 || '-%')
+	`, ownerID, base, repository.globalSlugs)
+	if err != nil {
+		return nil, fmt.Errorf("list taken slugs: %w", err)
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, fmt.Errorf("scan slug: %w", err)
+		}
+		slugs = append(slugs, slug)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate slugs: %w", err)
+	}
+	return slugs, nil
+}
+
+// SetSlug relies on the per-owner unique index. Global uniqueness cannot be
+// an index while owners may share slugs in the default mode, so it is checked
+// under a transaction-scoped advisory lock on the slug instead.
+func (repository *Repository) SetSlug(ctx context.Context, pageID domain.PageID, slug string) error {
+	if !repository.globalSlugs {
+		return setSlug(ctx, repository.pool, pageID, slug)
+	}
+	tx, err := repository.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('page-slug:' || $1))`, slug); err != nil {
+		return fmt.Errorf("lock slug: %w", err)
+	}
+	var taken bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pages WHERE slug = $1 AND id <> $2)`, slug, string(pageID)).Scan(&taken); err != nil {
+		return fmt.Errorf("check slug: %w", err)
+	}
+	if taken {
+		return errs.ErrConflict
+	}
+	if err := setSlug(ctx, tx, pageID, slug); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+func setSlug(ctx context.Context, db execer, pageID domain.PageID, slug string) error {
+	commandTag, err := db.Exec(ctx, `UPDATE pages SET slug = $2 WHERE id = $1`, string(pageID), slug)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errs.ErrConflict
+		}
+		return fmt.Errorf("set slug: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (repository *Repository) SetPasswordHash(ctx context.Context, pageID domain.PageID, hash *string) error {
+	commandTag, err := repository.pool.Exec(ctx, `UPDATE pages SET password_hash = $2 WHERE id = $1`, string(pageID), hash)
+	if err != nil {
+		return fmt.Errorf("set password hash: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (repository *Repository) GetPasswordHash(ctx context.Context, pageID domain.PageID) (string, error) {
+	var hash string
+	err := repository.pool.QueryRow(ctx, `SELECT COALESCE(password_hash, '') FROM pages WHERE id = $1`, string(pageID)).Scan(&hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errs.ErrNotFound
+		}
+		return "", fmt.Errorf("get password hash: %w", err)
+	}
+	return hash, nil
+}
+
+func (repository *Repository) CountPublishedSince(ctx context.Context, ownerID string, since time.Time) (int, error) {
+	var count int
+	err := repository.pool.QueryRow(ctx, `
+		SELECT count(*)
+		FROM pages
+		WHERE owner_id = $1 AND first_published_at >= $2
+	`, ownerID, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count published since: %w", err)
+	}
+	return count, nil
+}
+
 func (repository *Repository) DeletePage(ctx context.Context, pageID domain.PageID) error {
 	tx, err := repository.pool.Begin(ctx)
 	if err != nil {
@@ -129,6 +374,95 @@ func (repository *Repository) DeletePage(ctx context.Context, pageID domain.Page
 	return nil
 }
 
+// PurgeArchivedOlderThan permanently deletes every page archived before cutoff
+// and returns the purged pages, with their blocks, so callers can emit
+// deletion events for media cleanup.
+func (repository *Repository) PurgeArchivedOlderThan(ctx context.Context, cutoff time.Time) ([]domain.Page, error) {
+	tx, err := repository.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, title, cover, published, unlisted, published_at, first_published_at,
+			dark_mode, cinematic, mood, bg_color, owner_id,
+			created_at, updated_at, deleted_at
+		FROM pages
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		FOR UPDATE
+	`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("query archived pages: %w", err)
+	}
+	pages := make([]domain.Page, 0)
+	for rows.Next() {
+		var page domain.Page
+		if err := rows.Scan(&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.FirstPublishedAt, &page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan archived page: %w", err)
+		}
+		pages = append(pages, page)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate archived pages: %w", err)
+	}
+	if len(pages) == 0 {
+		return pages, nil
+	}
+
+	pageIDs := make([]string, len(pages))
+	pageMap := make(map[string]*domain.Page, len(pages))
+	for i := range pages {
+		pageIDs[i] = string(pages[i].ID)
+		pageMap[string(pages[i].ID)] = &pages[i]
+	}
+
+	blockRows, err := tx.Query(ctx, `
+		SELECT id, page_id, parent_id, type, position, data
+		FROM blocks
+		WHERE page_id = ANY($1)
+		ORDER BY page_id, position
+	`, pageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query archived blocks: %w", err)
+	}
+	for blockRows.Next() {
+		var block domain.Block
+		var blockType string
+		var data []byte
+		if err := blockRows.Scan(&block.ID, &block.PageID, &block.ParentID, &blockType, &block.Position, &data); err != nil {
+			blockRows.Close()
+			return nil, fmt.Errorf("scan archived block: %w", err)
+		}
+		block.Type = domain.BlockType(blockType)
+		block.Data = json.RawMessage(data)
+		if p, ok := pageMap[string(block.PageID)]; ok {
+			p.Blocks = append(p.Blocks, block)
+		}
+	}
+	blockRows.Close()
+	if err := blockRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate archived blocks: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM blocks WHERE page_id = ANY($1)`, pageIDs); err != nil {
+		return nil, fmt.Errorf("delete blocks: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM proofreads WHERE page_id = ANY($1)`, pageIDs); err != nil {
+		return nil, fmt.Errorf("delete proofreads: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM pages WHERE id = ANY($1)`, pageIDs); err != nil {
+		return nil, fmt.Errorf("delete pages: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit purge archived pages: %w", err)
+	}
+	return pages, nil
+}
+
 func (repository *Repository) ArchivePage(ctx context.Context, pageID domain.PageID) error {
 	commandTag, err := repository.pool.Exec(ctx, `
 		UPDATE pages
@@ -162,7 +496,7 @@ func (repository *Repository) RestorePage(ctx context.Context, pageID domain.Pag
 func (repository *Repository) ListArchivedPages(ctx context.Context, ownerID string) ([]domain.Page, error) {
 	rows, err := repository.pool.Query(ctx, `
 		SELECT
-			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at,
+			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at, p.first_published_at,
 			p.dark_mode, p.cinematic, p.mood, p.bg_color, p.owner_id, p.created_at, p.updated_at, p.deleted_at,
 			(SELECT count(*) FROM proofreads pr WHERE pr.page_id = p.id) AS proofread_count,
 			(SELECT count(*) FROM blocks b WHERE b.page_id = p.id) AS block_count,
@@ -179,7 +513,7 @@ func (repository *Repository) ListArchivedPages(ctx context.Context, ownerID str
 	pages := make([]domain.Page, 0)
 	for rows.Next() {
 		var page domain.Page
-		if err := rows.Scan(&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt, &page.ProofreadCount, &page.BlockCount, &page.ReadCount); err != nil {
+		if err := rows.Scan(&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.FirstPublishedAt, &page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt, &page.ProofreadCount, &page.BlockCount, &page.ReadCount); err != nil {
 			return nil, fmt.Errorf("scan archived page row: %w", err)
 		}
 		pages = append(pages, page)
@@ -190,19 +524,23 @@ func (repository *Repository) ListArchivedPages(ctx context.Context, ownerID str
 	return pages, nil
 }
 
-func (repository *Repository) ListPublishedPagesByOwner(ctx context.Context, ownerID string) ([]domain.Page, error) {
+func (repository *Repository) ListPublishedPagesByOwner(ctx context.Context, ownerID, tag string, limit, offset int) ([]domain.Page, error) {
 	rows, err := repository.pool.Query(ctx, `
 		SELECT
-			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at,
+			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at, p.first_published_at,
 			p.dark_mode, p.cinematic, p.mood, p.bg_color, p.owner_id, p.created_at, p.updated_at, p.deleted_at,
 			(SELECT count(*) FROM proofreads pr WHERE pr.page_id = p.id) AS proofread_count,
 			(SELECT count(*) FROM blocks b WHERE b.page_id = p.id) AS block_count,
 			(SELECT count(*) FROM page_reads r WHERE r.page_id = p.id) AS read_count,
-			EXISTS(SELECT 1 FROM page_share_links s WHERE s.page_id = p.id AND s.revoked = false) AS has_share_links
+			(SELECT count(*) FROM page_likes l WHERE l.page_id = p.id) AS like_count,
+			EXISTS(SELECT 1 FROM page_share_links s WHERE s.page_id = p.id AND s.revoked = false AND (s.expires_at IS NULL OR s.expires_at > now())) AS has_share_links,
+			ARRAY(SELECT t.tag FROM page_tags t WHERE t.page_id = p.id ORDER BY t.tag) AS tags
 		FROM pages p
 		WHERE p.deleted_at IS NULL AND p.published = true AND p.unlisted = false AND p.owner_id = $1
-		ORDER BY p.published_at DESC
-	`, ownerID)
+			AND ($2 = '' OR EXISTS(SELECT 1 FROM page_tags t WHERE t.page_id = p.id AND t.tag = $2))
+		ORDER BY p.first_published_at DESC NULLS LAST, p.id
+		LIMIT $3 OFFSET $4
+	`, ownerID, tag, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("list published pages by owner: %w", err)
 	}
@@ -211,7 +549,7 @@ func (repository *Repository) ListPublishedPagesByOwner(ctx context.Context, own
 	pages := make([]domain.Page, 0)
 	for rows.Next() {
 		var page domain.Page
-		if err := rows.Scan(&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt, &page.ProofreadCount, &page.BlockCount, &page.ReadCount, &page.HasShareLinks); err != nil {
+		if err := rows.Scan(&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.FirstPublishedAt, &page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt, &page.ProofreadCount, &page.BlockCount, &page.ReadCount, &page.LikeCount, &page.HasShareLinks, &page.Tags); err != nil {
 			return nil, fmt.Errorf("scan published page row: %w", err)
 		}
 		pages = append(pages, page)
@@ -230,10 +568,12 @@ func (repository *Repository) ListPublishedPagesByOwner(ctx context.Context, own
 		}
 
 		blockRows, err := repository.pool.Query(ctx, `
-			SELECT DISTINCT ON (page_id) id, page_id, parent_id, type, position, data
-			FROM blocks
-			WHERE page_id = ANY($1) AND type IN ('image', 'embed', 'gallery', 'music')
-			ORDER BY page_id, position
+			SELECT DISTINCT ON (b.page_id) b.id, b.page_id, b.parent_id, b.type, b.position, b.data
+			FROM blocks b
+			JOIN pages p ON p.id = b.page_id
+			WHERE b.page_id = ANY($1) AND b.type IN ('image', 'embed', 'gallery', 'music')
+			  AND p.password_hash IS NULL
+			ORDER BY b.page_id, b.position
 		`, pageIDs)
 		if err != nil {
 			return nil, fmt.Errorf("query preview blocks: %w", err)
@@ -261,7 +601,7 @@ func (repository *Repository) ListPublishedPagesByOwner(ctx context.Context, own
 	return pages, nil
 }
 
-func (repository *Repository) ListPublishedFeed(ctx context.Context, limit, offset int, sort string, authorUserIDs []string) ([]domain.FeedPage, error) {
+func (repository *Repository) ListPublishedFeed(ctx context.Context, limit, offset int, sort string, authorUserIDs, excludedOwnerIDs []string, excludeSelf bool, tag, viewerID string) ([]domain.FeedPage, error) {
 	if limit <= 0 {
 		limit = 30
 	}
@@ -272,17 +612,19 @@ func (repository *Repository) ListPublishedFeed(ctx context.Context, limit, offs
 	var orderClause string
 	switch sort {
 	case "top":
-		orderClause = "ORDER BY (SELECT count(*) FROM proofreads pr WHERE pr.page_id = p.id) DESC, p.published_at DESC"
+		orderClause = "ORDER BY (SELECT count(*) FROM proofreads pr WHERE pr.page_id = p.id) DESC, p.first_published_at DESC NULLS LAST"
+	case "liked":
+		orderClause = "ORDER BY (SELECT count(*) FROM page_likes l WHERE l.page_id = p.id) DESC, p.first_published_at DESC NULLS LAST"
 	case "hot":
 		// Hot = engagement weighted by recency (logarithmic decay over 48h)
-		orderClause = "ORDER BY ((SELECT count(*) FROM proofreads pr WHERE pr.page_id = p.id) + 1) / POWER(EXTRACT(EPOCH FROM (NOW() - COALESCE(p.published_at, p.created_at))) / 3600 + 2, 1.5) DESC"
-	default: // "new"
-		orderClause = "ORDER BY p.published_at DESC"
+		orderClause = "ORDER BY ((SELECT count(*) FROM proofreads pr WHERE pr.page_id = p.id) + 1) / POWER(EXTRACT(EPOCH FROM (NOW() - COALESCE(p.first_published_at, p.created_at))) / 3600 + 2, 1.5) DESC"
+	default: // "new" orders by first publish so republishing does not bump a page
+		orderClause = "ORDER BY p.first_published_at DESC NULLS LAST"
 	}
 
 	var whereClause string
 	var args []interface{}
-	args = append(args, limit, offset)
+	args = append(args, limit, offset, viewerID)
 
 	if len(authorUserIDs) > 0 {
 		placeholders := make([]string, len(authorUserIDs))
@@ -292,19 +634,40 @@ func (repository *Repository) ListPublishedFeed(ctx context.Context, limit, offs
 		}
 		whereClause = fmt.Sprintf("AND p.owner_id IN (%s)", strings.Join(placeholders, ","))
 	}
+	var placeholders []string
+	for _, uid := range excludedOwnerIDs {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)+1))
+		args = append(args, uid)
+	}
+	if excludeSelf && viewerID != "" {
+		placeholders = append(placeholders, "$3")
+	}
+	if len(placeholders) > 0 {
+		// Anonymous pages have no owner and can never be excluded.
+		whereClause += fmt.Sprintf(" AND (p.owner_id IS NULL OR p.owner_id NOT IN (%s))", strings.Join(placeholders, ","))
+	}
+	if tag != "" {
+		args = append(args, tag)
+		whereClause += fmt.Sprintf(" AND EXISTS(SELECT 1 FROM page_tags t WHERE t.page_id = p.id AND t.tag = $%d)", len(args))
+	}
 
 	query := fmt.Sprintf(`
 		SELECT
-			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at,
+			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at, p.first_published_at,
 			p.dark_mode, p.cinematic, p.mood, p.bg_color, p.owner_id,
 			p.created_at, p.updated_at, p.deleted_at,
 			(SELECT count(*) FROM proofreads pr WHERE pr.page_id = p.id) AS proofread_count,
 			(SELECT count(*) FROM blocks b WHERE b.page_id = p.id) AS block_count,
 			(SELECT count(*) FROM page_reads r WHERE r.page_id = p.id) AS read_count,
-			EXISTS(SELECT 1 FROM page_share_links s WHERE s.page_id = p.id AND s.revoked = false) AS has_share_links,
+			(SELECT count(*) FROM page_likes l WHERE l.page_id = p.id) AS like_count,
+			EXISTS(SELECT 1 FROM page_share_links s WHERE s.page_id = p.id AND s.revoked = false AND (s.expires_at IS NULL OR s.expires_at > now())) AS has_share_links,
 			COALESCE(u.username, 'anonymous') AS author_username,
 			COALESCE(NULLIF(u.display_name, ''), 'Anonymous') AS author_display_name,
-			COALESCE(u.avatar_url, '') AS author_avatar_url
+			COALESCE(u.avatar_url, '') AS author_avatar_url,
+			ARRAY(SELECT t.tag FROM page_tags t WHERE t.page_id = p.id ORDER BY t.tag) AS tags,
+			EXISTS(SELECT 1 FROM page_likes l WHERE l.page_id = p.id AND l.user_id = $3) AS liked_by_me,
+			p.word_count, p.reading_minutes, COALESCE(p.slug, ''),
+			(SELECT count(*) FROM page_comments c WHERE c.page_id = p.id) AS comment_count
 		FROM pages p
 		LEFT JOIN users u ON u.id = p.owner_id
 		WHERE p.deleted_at IS NULL AND p.published = true AND p.unlisted = false
@@ -322,57 +685,32 @@ func (repository *Repository) ListPublishedFeed(ctx context.Context, limit, offs
 	pages := make([]domain.FeedPage, 0)
 	for rows.Next() {
 		var fp domain.FeedPage
+		var likedByMe bool
 		if err := rows.Scan(
-			&fp.ID, &fp.Title, &fp.Cover, &fp.Published, &fp.Unlisted, &fp.PublishedAt,
+			&fp.ID, &fp.Title, &fp.Cover, &fp.Published, &fp.Unlisted, &fp.PublishedAt, &fp.FirstPublishedAt,
 			&fp.DarkMode, &fp.Cinematic, &fp.Mood, &fp.BgColor, &fp.OwnerID,
 			&fp.CreatedAt, &fp.UpdatedAt, &fp.DeletedAt,
-			&fp.ProofreadCount, &fp.BlockCount, &fp.ReadCount, &fp.HasShareLinks,
-			&fp.AuthorUsername, &fp.AuthorDisplayName, &fp.AuthorAvatarURL,
+			&fp.ProofreadCount, &fp.BlockCount, &fp.ReadCount, &fp.LikeCount, &fp.HasShareLinks,
+			&fp.AuthorUsername, &fp.AuthorDisplayName, &fp.AuthorAvatarURL, &fp.Tags, &likedByMe,
+			&fp.WordCount, &fp.ReadingMinutes, &fp.Slug, &fp.CommentCount,
 		); err != nil {
 			return nil, fmt.Errorf("scan feed page row: %w", err)
 		}
+		if viewerID != "" {
+			fp.LikedByMe = &likedByMe
+		}
 		pages = append(pages, fp)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterate feed pages rows: %w", err)
 	}
 
-	// Fetch preview blocks
-	if len(pages) > 0 {
-		pageIDs := make([]string, len(pages))
-		pageMap := make(map[string]*domain.FeedPage, len(pages))
-		for i := range pages {
-			pageIDs[i] = string(pages[i].ID)
-			pageMap[string(pages[i].ID)] = &pages[i]
-		}
-
-		blockRows, err := repository.pool.Query(ctx, `
-			SELECT DISTINCT ON (page_id) id, page_id, parent_id, type, position, data
-			FROM blocks
-			WHERE page_id = ANY($1) AND type IN ('image', 'embed', 'gallery', 'music')
-			ORDER BY page_id, position
-		`, pageIDs)
-		if err != nil {
-			return nil, fmt.Errorf("query feed preview blocks: %w", err)
-		}
-		defer blockRows.Close()
-
-		for blockRows.Next() {
-			var block domain.Block
-			var blockType string
-			var data []byte
-			if err := blockRows.Scan(&block.ID, &block.PageID, &block.ParentID, &blockType, &block.Position, &data); err != nil {
-				return nil, fmt.Errorf("scan feed preview block: %w", err)
-			}
-			block.Type = domain.BlockType(blockType)
-			block.Data = json.RawMessage(data)
-			if p, ok := pageMap[string(block.PageID)]; ok {
-				p.Blocks = []domain.Block{block}
-			}
-		}
-		if err := blockRows.Err(); err != nil {
-			return nil, fmt.Errorf("iterate feed preview blocks: %w", err)
-		}
+	previews := make([]*domain.FeedPage, len(pages))
+	for i := range pages {
+		previews[i] = &pages[i]
+	}
+	if err := repository.attachPreviewBlocks(ctx, previews...); err != nil {
+		return nil, err
 	}
 
 	return pages, nil
@@ -380,10 +718,13 @@ func (repository *Repository) ListPublishedFeed(ctx context.Context, limit, offs
 
 func (repository *Repository) CreateShareLink(ctx context.Context, share domain.PageShareLink) error {
 	_, err := repository.pool.Exec(ctx, `
-		INSERT INTO page_share_links (token, page_id, access, created_by, revoked, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, share.Token, string(share.PageID), string(share.Access), share.CreatedBy, share.Revoked, share.CreatedAt)
+		INSERT INTO page_share_links (token, code, page_id, access, created_by, revoked, created_at, expires_at, max_uses)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9)
+	`, share.Token, share.Code, string(share.PageID), string(share.Access), share.CreatedBy, share.Revoked, share.CreatedAt, share.ExpiresAt, share.MaxUses)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return errs.ErrConflict
+		}
 		return fmt.Errorf("create share link: %w", err)
 	}
 	return nil
@@ -392,10 +733,10 @@ func (repository *Repository) CreateShareLink(ctx context.Context, share domain.
 func (repository *Repository) GetShareLinkByToken(ctx context.Context, token string) (domain.PageShareLink, error) {
 	var share domain.PageShareLink
 	err := repository.pool.QueryRow(ctx, `
-		SELECT token, page_id, access, created_by, revoked, created_at
+		SELECT token, COALESCE(code, ''), page_id, access, created_by, revoked, created_at, expires_at, max_uses, use_count
 		FROM page_share_links
 		WHERE token = $1
-	`, token).Scan(&share.Token, &share.PageID, &share.Access, &share.CreatedBy, &share.Revoked, &share.CreatedAt)
+	`, token).Scan(&share.Token, &share.Code, &share.PageID, &share.Access, &share.CreatedBy, &share.Revoked, &share.CreatedAt, &share.ExpiresAt, &share.MaxUses, &share.UseCount)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.PageShareLink{}, errs.ErrNotFound
@@ -405,35 +746,167 @@ func (repository *Repository) GetShareLinkByToken(ctx context.Context, token str
 	return share, nil
 }
 
-func (repository *Repository) RevokeShareLinksByAccess(ctx context.Context, pageID domain.PageID, ownerID string, access domain.ShareAccess) error {
-	_, err := repository.pool.Exec(ctx, `
+func (repository *Repository) GetShareLinkByCode(ctx context.Context, code string) (domain.PageShareLink, error) {
+	var share domain.PageShareLink
+	err := repository.pool.QueryRow(ctx, `
+		SELECT token, COALESCE(code, ''), page_id, access, created_by, revoked, created_at, expires_at, max_uses, use_count
+		FROM page_share_links
+		WHERE code = $1
+	`, code).Scan(&share.Token, &share.Code, &share.PageID, &share.Access, &share.CreatedBy, &share.Revoked, &share.CreatedAt, &share.ExpiresAt, &share.MaxUses, &share.UseCount)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.PageShareLink{}, errs.ErrNotFound
+		}
+		return domain.PageShareLink{}, fmt.Errorf("get share link by code: %w", err)
+	}
+	return share, nil
+}
+
+func (repository *Repository) RevokeShareLinkByToken(ctx context.Context, pageID domain.PageID, ownerID, token string) error {
+	commandTag, err := repository.pool.Exec(ctx, `
 		UPDATE page_share_links
 		SET revoked = true
-		WHERE page_id = $1 AND created_by = $2 AND access = $3 AND revoked = false
-	`, string(pageID), ownerID, string(access))
+		WHERE token = $1 AND page_id = $2 AND created_by = $3
+	`, token, string(pageID), ownerID)
 	if err != nil {
-		return fmt.Errorf("revoke share links: %w", err)
+		return fmt.Errorf("revoke share link: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errs.ErrNotFound
 	}
 	return nil
 }
 
-func (repository *Repository) UpdateBlocksOptimistic(ctx context.Context, pageID domain.PageID, blocks []domain.Block, expectedUpdatedAt *time.Time) error {
-	tx, err := repository.pool.Begin(ctx)
+func (repository *Repository) ListShareLinks(ctx context.Context, pageID domain.PageID, ownerID string) ([]domain.PageShareLink, error) {
+	rows, err := repository.pool.Query(ctx, `
+		SELECT token, COALESCE(code, ''), page_id, access, created_by, revoked, created_at, expires_at, max_uses, use_count
+		FROM page_share_links
+		WHERE page_id = $1 AND created_by = $2
+		ORDER BY created_at DESC
+	`, string(pageID), ownerID)
 	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
+		return nil, fmt.Errorf("list share links: %w", err)
 	}
-	defer tx.Rollback(ctx)
+	defer rows.Close()
 
-	commandTag, err := tx.Exec(ctx, `
-		UPDATE pages
-		SET updated_at = now()
-		WHERE id = $1 AND deleted_at IS NULL AND ($2::timestamptz IS NULL OR updated_at = $2)
-	`, string(pageID), expectedUpdatedAt)
-	if err != nil {
-		return fmt.Errorf("touch page: %w", err)
+	var shares []domain.PageShareLink
+	for rows.Next() {
+		var share domain.PageShareLink
+		if err := rows.Scan(&share.Token, &share.Code, &share.PageID, &share.Access, &share.CreatedBy, &share.Revoked, &share.CreatedAt, &share.ExpiresAt, &share.MaxUses, &share.UseCount); err != nil {
+			return nil, fmt.Errorf("scan share link: %w", err)
+		}
+		shares = append(shares, share)
 	}
-	if commandTag.RowsAffected() == 0 {
-		var exists bool
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate share links: %w", err)
+	}
+	return shares, nil
+}
+
+func (repository *Repository) RecordShareLinkUse(ctx context.Context, token, actorKey string) error {
+	var allowed bool
+	err := repository.pool.QueryRow(ctx, `
+		WITH seen AS (
+			SELECT 1 FROM page_share_link_uses WHERE token = $1 AND actor_key = $2 AND $2 <> ''
+		), counted AS (
+			UPDATE page_share_links
+			SET use_count = use_count + 1
+			WHERE token = $1
+				AND NOT EXISTS (SELECT 1 FROM seen)
+				AND (max_uses IS NULL OR use_count < max_uses)
+			RETURNING token
+		), recorded AS (
+			INSERT INTO page_share_link_uses (token, actor_key)
+			SELECT token, $2 FROM counted WHERE $2 <> ''
+			ON CONFLICT DO NOTHING
+		)
+		SELECT EXISTS (SELECT 1 FROM seen) OR EXISTS (SELECT 1 FROM counted)
+	`, token, actorKey).Scan(&allowed)
+	if err != nil {
+		return fmt.Errorf("record share link use: %w", err)
+	}
+	if !allowed {
+		return errs.ErrConflict
+	}
+	return nil
+}
+
+func (repository *Repository) TransferOwnership(ctx context.Context, pageID domain.PageID, fromOwnerID, toOwnerID string) error {
+	tx, err := repository.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The new owner decides whether the page goes on their profile, and the
+	// slug is unique per owner, so it is assigned when they publish.
+	commandTag, err := tx.Exec(ctx, `
+		UPDATE pages
+		SET owner_id = $3, slug = NULL,
+		    published = false, published_at = NULL, publish_at = NULL,
+		    updated_at = now()
+		WHERE id = $1 AND owner_id = $2
+	`, string(pageID), fromOwnerID, toOwnerID)
+	if err != nil {
+		return fmt.Errorf("transfer page owner: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE page_share_links SET revoked = true
+		WHERE page_id = $1 AND revoked = false
+	`, string(pageID)); err != nil {
+		return fmt.Errorf("revoke share links: %w", err)
+	}
+	// Collaborators were chosen by the previous owner, and share link
+	// visitors came through links that are now revoked.
+	if _, err := tx.Exec(ctx, `DELETE FROM page_collab_users WHERE page_id = $1`, string(pageID)); err != nil {
+		return fmt.Errorf("clear collaborators: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) RevokeShareLinksByAccess(ctx context.Context, pageID domain.PageID, ownerID string, access domain.ShareAccess) error {
+	_, err := repository.pool.Exec(ctx, `
+		UPDATE page_share_links
+		SET revoked = true
+		WHERE page_id = $1 AND created_by = $2 AND access = $3 AND revoked = false
+	`, string(pageID), ownerID, string(access))
+	if err != nil {
+		return fmt.Errorf("revoke share links: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) UpdateBlocksOptimistic(ctx context.Context, pageID domain.PageID, blocks []domain.Block, stats *domain.ReadingStats, expectedUpdatedAt *time.Time) error {
+	var wordCount, readingMinutes *int
+	if stats != nil {
+		wordCount, readingMinutes = &stats.WordCount, &stats.ReadingMinutes
+	}
+
+	tx, err := repository.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	commandTag, err := tx.Exec(ctx, `
+		UPDATE pages
+		SET updated_at = now(),
+		    word_count = COALESCE($3, word_count),
+		    reading_minutes = COALESCE($4, reading_minutes)
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::timestamptz IS NULL OR updated_at = $2)
+	`, string(pageID), expectedUpdatedAt, wordCount, readingMinutes)
+	if err != nil {
+		return fmt.Errorf("touch page: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		var exists bool
 		if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pages WHERE id = $1 AND deleted_at IS NULL)`, string(pageID)).Scan(&exists); err != nil {
 			return fmt.Errorf("check page existence: %w", err)
 		}
@@ -451,6 +924,9 @@ func (repository *Repository) UpdateBlocksOptimistic(ctx context.Context, pageID
 	if err := repository.insertBlocks(ctx, tx, pageID, blocks); err != nil {
 		return err
 	}
+	if err := syncMediaRefs(ctx, tx, pageID); err != nil {
+		return err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit update blocks: %w", err)
 	}
@@ -461,14 +937,16 @@ func (repository *Repository) GetByID(ctx context.Context, pageID domain.PageID)
 	var page domain.Page
 	err := repository.pool.QueryRow(ctx, `
 		SELECT
-			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at,
+			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at, p.first_published_at,
 			p.dark_mode, p.cinematic, p.mood, p.bg_color, p.owner_id,
 			p.created_at, p.updated_at, p.deleted_at,
 			(SELECT count(*) FROM page_reads r WHERE r.page_id = p.id) AS read_count,
-			EXISTS(SELECT 1 FROM page_share_links s WHERE s.page_id = p.id AND s.revoked = false) AS has_share_links
+			EXISTS(SELECT 1 FROM page_share_links s WHERE s.page_id = p.id AND s.revoked = false AND (s.expires_at IS NULL OR s.expires_at > now())) AS has_share_links,
+			ARRAY(SELECT t.tag FROM page_tags t WHERE t.page_id = p.id ORDER BY t.tag) AS tags,
+			p.word_count, p.reading_minutes, COALESCE(p.slug, ''), p.password_hash IS NOT NULL, p.publish_at
 		FROM pages p
 		WHERE p.id = $1
-	`, string(pageID)).Scan(&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt, &page.ReadCount, &page.HasShareLinks)
+	`, string(pageID)).Scan(&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.FirstPublishedAt, &page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt, &page.ReadCount, &page.HasShareLinks, &page.Tags, &page.WordCount, &page.ReadingMinutes, &page.Slug, &page.PasswordProtected, &page.PublishAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.Page{}, errs.ErrNotFound
@@ -503,6 +981,7 @@ func (repository *Repository) GetByID(ctx context.Context, pageID domain.PageID)
 		return domain.Page{}, fmt.Errorf("iterate blocks rows: %w", err)
 	}
 	page.Blocks = blocks
+	page.ContentHash = domain.ContentHash(page)
 	return page, nil
 }
 
@@ -510,23 +989,27 @@ func (repository *Repository) GetByIDWithAuthor(ctx context.Context, pageID doma
 	var fp domain.FeedPage
 	err := repository.pool.QueryRow(ctx, `
 		SELECT
-			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at,
+			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at, p.first_published_at,
 			p.dark_mode, p.cinematic, p.mood, p.bg_color, p.owner_id,
 			p.created_at, p.updated_at, p.deleted_at,
 			(SELECT count(*) FROM page_reads r WHERE r.page_id = p.id) AS read_count,
-			EXISTS(SELECT 1 FROM page_share_links s WHERE s.page_id = p.id AND s.revoked = false) AS has_share_links,
+			(SELECT count(*) FROM page_likes l WHERE l.page_id = p.id) AS like_count,
+			EXISTS(SELECT 1 FROM page_share_links s WHERE s.page_id = p.id AND s.revoked = false AND (s.expires_at IS NULL OR s.expires_at > now())) AS has_share_links,
 			COALESCE(u.username, 'anonymous') AS author_username,
 			COALESCE(NULLIF(u.display_name, ''), 'Anonymous') AS author_display_name,
-			COALESCE(u.avatar_url, '') AS author_avatar_url
+			COALESCE(u.avatar_url, '') AS author_avatar_url,
+			p.word_count, p.reading_minutes,
+			ARRAY(SELECT t.tag FROM page_tags t WHERE t.page_id = p.id ORDER BY t.tag) AS tags
 		FROM pages p
 		LEFT JOIN users u ON u.id = p.owner_id
 		WHERE p.id = $1
 	`, string(pageID)).Scan(
-		&fp.ID, &fp.Title, &fp.Cover, &fp.Published, &fp.Unlisted, &fp.PublishedAt,
+		&fp.ID, &fp.Title, &fp.Cover, &fp.Published, &fp.Unlisted, &fp.PublishedAt, &fp.FirstPublishedAt,
 		&fp.DarkMode, &fp.Cinematic, &fp.Mood, &fp.BgColor, &fp.OwnerID,
 		&fp.CreatedAt, &fp.UpdatedAt, &fp.DeletedAt,
-		&fp.ReadCount, &fp.HasShareLinks,
+		&fp.ReadCount, &fp.LikeCount, &fp.HasShareLinks,
 		&fp.AuthorUsername, &fp.AuthorDisplayName, &fp.AuthorAvatarURL,
+		&fp.WordCount, &fp.ReadingMinutes, &fp.Tags,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -562,22 +1045,93 @@ func (repository *Repository) GetByIDWithAuthor(ctx context.Context, pageID doma
 		return domain.FeedPage{}, fmt.Errorf("iterate blocks rows: %w", err)
 	}
 	fp.Blocks = blocks
+	fp.ContentHash = domain.ContentHash(fp.Page)
 	return fp, nil
 }
 
-func (repository *Repository) ListPages(ctx context.Context, ownerID string) ([]domain.Page, error) {
+// GetSummaryWithAuthor loads page metadata and author info without blocks or counters.
+func (repository *Repository) GetSummaryWithAuthor(ctx context.Context, pageID domain.PageID) (domain.FeedPage, error) {
+	var fp domain.FeedPage
+	err := repository.pool.QueryRow(ctx, `
+		SELECT
+			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at, p.first_published_at, p.owner_id,
+			p.created_at, p.updated_at, p.deleted_at,
+			COALESCE(u.username, 'anonymous') AS author_username,
+			COALESCE(NULLIF(u.display_name, ''), 'Anonymous') AS author_display_name,
+			COALESCE(u.avatar_url, '') AS author_avatar_url
+		FROM pages p
+		LEFT JOIN users u ON u.id = p.owner_id
+		WHERE p.id = $1
+	`, string(pageID)).Scan(
+		&fp.ID, &fp.Title, &fp.Cover, &fp.Published, &fp.Unlisted, &fp.PublishedAt, &fp.FirstPublishedAt, &fp.OwnerID,
+		&fp.CreatedAt, &fp.UpdatedAt, &fp.DeletedAt,
+		&fp.AuthorUsername, &fp.AuthorDisplayName, &fp.AuthorAvatarURL,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.FeedPage{}, errs.ErrNotFound
+		}
+		return domain.FeedPage{}, fmt.Errorf("get page summary with author: %w", err)
+	}
+	return fp, nil
+}
+
+// CountBlockTypes counts the page's blocks by type, ordered by type.
+func (repository *Repository) CountBlockTypes(ctx context.Context, pageID domain.PageID) ([]domain.BlockTypeCount, error) {
+	rows, err := repository.pool.Query(ctx, `
+		SELECT type, count(*)
+		FROM blocks
+		WHERE page_id = $1
+		GROUP BY type
+		ORDER BY type
+	`, string(pageID))
+	if err != nil {
+		return nil, fmt.Errorf("count block types: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]domain.BlockTypeCount, 0)
+	for rows.Next() {
+		var count domain.BlockTypeCount
+		var blockType string
+		if err := rows.Scan(&blockType, &count.Count); err != nil {
+			return nil, fmt.Errorf("scan block type count: %w", err)
+		}
+		count.Type = domain.BlockType(blockType)
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate block type counts: %w", err)
+	}
+	return counts, nil
+}
+
+func (repository *Repository) ListPages(ctx context.Context, ownerID string, status domain.PageStatus, limit, offset int) ([]domain.Page, error) {
+	if limit <= 0 {
+		limit = 30
+	}
+	var published *bool
+	if status != domain.PageStatusAny {
+		wantPublished := status == domain.PageStatusPublished
+		published = &wantPublished
+	}
+
 	rows, err := repository.pool.Query(ctx, `
 		SELECT
-			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at,
+			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at, p.first_published_at,
 			p.dark_mode, p.cinematic, p.mood, p.bg_color, p.owner_id, p.created_at, p.updated_at, p.deleted_at,
 			(SELECT count(*) FROM proofreads pr WHERE pr.page_id = p.id) AS proofread_count,
 			(SELECT count(*) FROM blocks b WHERE b.page_id = p.id) AS block_count,
 			(SELECT count(*) FROM page_reads r WHERE r.page_id = p.id) AS read_count,
-			EXISTS(SELECT 1 FROM page_share_links s WHERE s.page_id = p.id AND s.revoked = false) AS has_share_links
+			(SELECT count(*) FROM page_likes l WHERE l.page_id = p.id) AS like_count,
+			EXISTS(SELECT 1 FROM page_share_links s WHERE s.page_id = p.id AND s.revoked = false AND (s.expires_at IS NULL OR s.expires_at > now())) AS has_share_links,
+			p.publish_at
 		FROM pages p
 		WHERE p.deleted_at IS NULL AND p.owner_id = $1
-		ORDER BY p.updated_at DESC
-	`, ownerID)
+			AND ($2::boolean IS NULL OR p.published = $2)
+		ORDER BY p.updated_at DESC, p.id
+		LIMIT $3 OFFSET $4
+	`, ownerID, published, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("list pages: %w", err)
 	}
@@ -586,7 +1140,7 @@ func (repository *Repository) ListPages(ctx context.Context, ownerID string) ([]
 	pages := make([]domain.Page, 0)
 	for rows.Next() {
 		var page domain.Page
-		if err := rows.Scan(&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt, &page.ProofreadCount, &page.BlockCount, &page.ReadCount, &page.HasShareLinks); err != nil {
+		if err := rows.Scan(&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.FirstPublishedAt, &page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt, &page.ProofreadCount, &page.BlockCount, &page.ReadCount, &page.LikeCount, &page.HasShareLinks, &page.PublishAt); err != nil {
 			return nil, fmt.Errorf("scan page row: %w", err)
 		}
 		pages = append(pages, page)
@@ -652,13 +1206,17 @@ func (repository *Repository) CreateProofread(ctx context.Context, proofread dom
 	return nil
 }
 
-func (repository *Repository) ListProofreadsByPageID(ctx context.Context, pageID domain.PageID) ([]domain.Proofread, error) {
+func (repository *Repository) ListProofreadsByPageID(ctx context.Context, pageID domain.PageID, sort string) ([]domain.Proofread, error) {
+	orderClause := "ORDER BY pinned DESC, created_at DESC"
+	if sort == "top" {
+		orderClause = "ORDER BY pinned DESC, vote_count DESC, created_at DESC"
+	}
 	rows, err := repository.pool.Query(ctx, `
-		SELECT id, page_id, author_name, title, summary, stance, annotations, created_at, updated_at
+		SELECT id, page_id, author_name, title, summary, stance, annotations, pinned, created_at, updated_at,
+			(SELECT count(*) FROM proofread_votes v WHERE v.proofread_id = proofreads.id) AS vote_count
 		FROM proofreads
 		WHERE page_id = $1
-		ORDER BY created_at DESC
-	`, string(pageID))
+		`+orderClause, string(pageID))
 	if err != nil {
 		return nil, fmt.Errorf("query proofreads: %w", err)
 	}
@@ -679,9 +1237,57 @@ func (repository *Repository) ListProofreadsByPageID(ctx context.Context, pageID
 	return proofreads, nil
 }
 
+func (repository *Repository) SetProofreadPinned(ctx context.Context, pageID domain.PageID, proofreadID domain.ProofreadID, pinned bool) error {
+	tag, err := repository.pool.Exec(ctx, `
+		UPDATE proofreads SET pinned = $3
+		WHERE id = $1 AND page_id = $2
+	`, string(proofreadID), string(pageID), pinned)
+	if err != nil {
+		return fmt.Errorf("set proofread pinned: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (repository *Repository) DeleteProofread(ctx context.Context, pageID domain.PageID, proofreadID domain.ProofreadID) error {
+	commandTag, err := repository.pool.Exec(ctx, `DELETE FROM proofreads WHERE id = $1 AND page_id = $2`, string(proofreadID), string(pageID))
+	if err != nil {
+		return fmt.Errorf("delete proofread: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (repository *Repository) VoteProofread(ctx context.Context, proofreadID domain.ProofreadID, voterKey string) (bool, error) {
+	tag, err := repository.pool.Exec(ctx, `
+		INSERT INTO proofread_votes (proofread_id, voter_key, created_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (proofread_id, voter_key) DO NOTHING
+	`, string(proofreadID), voterKey)
+	if err != nil {
+		return false, fmt.Errorf("vote proofread: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (repository *Repository) CountVotes(ctx context.Context, proofreadID domain.ProofreadID) (int, error) {
+	var count int
+	if err := repository.pool.QueryRow(ctx, `
+		SELECT count(*) FROM proofread_votes WHERE proofread_id = $1
+	`, string(proofreadID)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count proofread votes: %w", err)
+	}
+	return count, nil
+}
+
 func (repository *Repository) GetProofreadByID(ctx context.Context, proofreadID domain.ProofreadID) (domain.Proofread, error) {
 	row := repository.pool.QueryRow(ctx, `
-		SELECT id, page_id, author_name, title, summary, stance, annotations, created_at, updated_at
+		SELECT id, page_id, author_name, title, summary, stance, annotations, pinned, created_at, updated_at,
+			(SELECT count(*) FROM proofread_votes v WHERE v.proofread_id = proofreads.id) AS vote_count
 		FROM proofreads
 		WHERE id = $1
 	`, string(proofreadID))
@@ -697,12 +1303,86 @@ func (repository *Repository) GetProofreadByID(ctx context.Context, proofreadID
 	return proofread, nil
 }
 
+func (repository *Repository) CreateComment(ctx context.Context, comment domain.Comment) error {
+	_, err := repository.pool.Exec(ctx, `
+		INSERT INTO page_comments (id, page_id, parent_id, user_id, author_name, body, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, string(comment.ID), string(comment.PageID), comment.ParentID, comment.UserID, comment.AuthorName, comment.Body, comment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert comment: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) GetComment(ctx context.Context, commentID domain.CommentID) (domain.Comment, error) {
+	comment, err := scanComment(repository.pool.QueryRow(ctx, `
+		SELECT id, page_id, parent_id, user_id, author_name, body, created_at
+		FROM page_comments
+		WHERE id = $1
+	`, string(commentID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Comment{}, errs.ErrNotFound
+		}
+		return domain.Comment{}, err
+	}
+	return comment, nil
+}
+
+func (repository *Repository) ListComments(ctx context.Context, pageID domain.PageID) ([]domain.Comment, error) {
+	rows, err := repository.pool.Query(ctx, `
+		SELECT id, page_id, parent_id, user_id, author_name, body, created_at
+		FROM page_comments
+		WHERE page_id = $1
+		ORDER BY created_at, id
+	`, string(pageID))
+	if err != nil {
+		return nil, fmt.Errorf("query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]domain.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate comments rows: %w", err)
+	}
+	return comments, nil
+}
+
+func (repository *Repository) DeleteComment(ctx context.Context, commentID domain.CommentID) error {
+	commandTag, err := repository.pool.Exec(ctx, `DELETE FROM page_comments WHERE id = $1`, string(commentID))
+	if err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
 func (repository *Repository) RecordOrganicRead(ctx context.Context, pageID domain.PageID, readerKey string) (bool, error) {
 	if readerKey == "" {
 		return false, nil
 	}
 	var inserted bool
 	err := repository.pool.QueryRow(ctx, `
+		WITH day AS (
+			INSERT INTO page_read_days (page_id, day, reads)
+			VALUES ($1, (now() AT TIME ZONE 'UTC')::date, 1)
+			ON CONFLICT (page_id, day)
+			DO UPDATE SET reads = page_read_days.reads + 1
+		), total AS (
+			INSERT INTO platform_counters (name, value)
+			VALUES ('reads', 1)
+			ON CONFLICT (name)
+			DO UPDATE SET value = platform_counters.value + 1
+		)
 		INSERT INTO page_reads (page_id, reader_key, read_count, first_read_at, last_read_at)
 		VALUES ($1, $2, 1, now(), now())
 		ON CONFLICT (page_id, reader_key)
@@ -717,10 +1397,344 @@ func (repository *Repository) RecordOrganicRead(ctx context.Context, pageID doma
 	return inserted, nil
 }
 
+func (repository *Repository) TrendingForOwner(ctx context.Context, ownerID string, since time.Time, limit int) ([]domain.TrendingPage, error) {
+	rows, err := repository.pool.Query(ctx, `
+		WITH recent AS (
+			SELECT page_id, sum(reads) AS reads
+			FROM page_read_days
+			WHERE day >= ($2::timestamptz AT TIME ZONE 'UTC')::date
+			GROUP BY page_id
+		)
+		SELECT
+			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at, p.first_published_at,
+			p.dark_mode, p.cinematic, p.mood, p.bg_color, p.owner_id, p.created_at, p.updated_at, p.deleted_at,
+			(SELECT count(*) FROM proofreads pr WHERE pr.page_id = p.id) AS proofread_count,
+			(SELECT count(*) FROM blocks b WHERE b.page_id = p.id) AS block_count,
+			(SELECT count(*) FROM page_reads r WHERE r.page_id = p.id) AS read_count,
+			recent.reads
+		FROM pages p
+		JOIN recent ON recent.page_id = p.id
+		WHERE p.deleted_at IS NULL AND p.published = true AND p.owner_id = $1
+		ORDER BY recent.reads DESC, p.first_published_at DESC NULLS LAST
+		LIMIT $3
+	`, ownerID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list trending pages: %w", err)
+	}
+	defer rows.Close()
+
+	pages := make([]domain.TrendingPage, 0)
+	for rows.Next() {
+		var page domain.TrendingPage
+		if err := rows.Scan(
+			&page.ID, &page.Title, &page.Cover, &page.Published, &page.Unlisted, &page.PublishedAt, &page.FirstPublishedAt,
+			&page.DarkMode, &page.Cinematic, &page.Mood, &page.BgColor, &page.OwnerID, &page.CreatedAt, &page.UpdatedAt, &page.DeletedAt,
+			&page.ProofreadCount, &page.BlockCount, &page.ReadCount, &page.RecentReads,
+		); err != nil {
+			return nil, fmt.Errorf("scan trending page row: %w", err)
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate trending pages rows: %w", err)
+	}
+	return pages, nil
+}
+
+// PlatformStats reads the site-wide totals without scanning the large
+// tables: users and pages are the planner's row estimates, published pages
+// are counted from the published pages partial index, and reads come from the
+// counter RecordOrganicRead maintains.
+func (repository *Repository) PlatformStats(ctx context.Context) (domain.PlatformStats, error) {
+	var stats domain.PlatformStats
+	err := repository.pool.QueryRow(ctx, `
+		SELECT
+			(SELECT greatest(reltuples, 0)::bigint FROM pg_class WHERE oid = 'users'::regclass),
+			(SELECT greatest(reltuples, 0)::bigint FROM pg_class WHERE oid = 'pages'::regclass),
+			(SELECT count(*) FROM pages WHERE deleted_at IS NULL AND published = true),
+			coalesce((SELECT value FROM platform_counters WHERE name = 'reads'), 0)
+	`).Scan(&stats.TotalUsers, &stats.TotalPages, &stats.PublishedPages, &stats.TotalReads)
+	if err != nil {
+		return domain.PlatformStats{}, fmt.Errorf("platform stats: %w", err)
+	}
+	return stats, nil
+}
+
+func (repository *Repository) ListPageIDsAfter(ctx context.Context, after domain.PageID, limit int) ([]domain.PageID, error) {
+	rows, err := repository.pool.Query(ctx, `
+		SELECT id FROM pages WHERE id > $1 ORDER BY id LIMIT $2
+	`, string(after), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list page ids: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]domain.PageID, 0, limit)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan page id: %w", err)
+		}
+		ids = append(ids, domain.PageID(id))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate page ids: %w", err)
+	}
+	return ids, nil
+}
+
+func (repository *Repository) CorrectReadingStats(ctx context.Context, pageID domain.PageID, updatedAt time.Time, stats domain.ReadingStats) (domain.ReadingStats, bool, error) {
+	// Matching updated_at leaves pages saved since they were read alone; their
+	// save already stored fresh stats.
+	var previous domain.ReadingStats
+	err := repository.pool.QueryRow(ctx, `
+		UPDATE pages p
+		SET word_count = $3, reading_minutes = $4
+		FROM pages old
+		WHERE p.id = $1 AND old.id = p.id AND p.updated_at = $2
+			AND (p.word_count <> $3 OR p.reading_minutes <> $4)
+		RETURNING old.word_count, old.reading_minutes
+	`, string(pageID), updatedAt, stats.WordCount, stats.ReadingMinutes).Scan(&previous.WordCount, &previous.ReadingMinutes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ReadingStats{}, false, nil
+	}
+	if err != nil {
+		return domain.ReadingStats{}, false, fmt.Errorf("correct reading stats: %w", err)
+	}
+	return previous, true, nil
+}
+
+func (repository *Repository) ReconcileReadCounter(ctx context.Context) (int64, int64, error) {
+	var previous, actual int64
+	err := repository.pool.QueryRow(ctx, `
+		WITH actual AS (
+			SELECT coalesce(sum(read_count), 0)::bigint AS value FROM page_reads
+		), previous AS (
+			SELECT coalesce((SELECT value FROM platform_counters WHERE name = 'reads'), 0) AS value
+		), reset AS (
+			INSERT INTO platform_counters (name, value)
+			SELECT 'reads', value FROM actual
+			ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value
+		)
+		SELECT previous.value, actual.value FROM previous, actual
+	`).Scan(&previous, &actual)
+	if err != nil {
+		return 0, 0, fmt.Errorf("reconcile read counter: %w", err)
+	}
+	return previous, actual, nil
+}
+
+func (repository *Repository) TrendingTags(ctx context.Context, since time.Time, limit int) ([]domain.TagCount, error) {
+	rows, err := repository.pool.Query(ctx, `
+		SELECT t.tag, count(*) AS pages
+		FROM page_tags t
+		JOIN pages p ON p.id = t.page_id
+		WHERE p.deleted_at IS NULL AND p.published = true AND p.unlisted = false AND p.first_published_at >= $1
+		GROUP BY t.tag
+		ORDER BY pages DESC, t.tag
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list trending tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]domain.TagCount, 0)
+	for rows.Next() {
+		var tag domain.TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Pages); err != nil {
+			return nil, fmt.Errorf("scan trending tag row: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate trending tags rows: %w", err)
+	}
+	return tags, nil
+}
+
+func (repository *Repository) AddBookmark(ctx context.Context, userID string, pageID domain.PageID, at time.Time) error {
+	_, err := repository.pool.Exec(ctx, `
+		INSERT INTO page_bookmarks (user_id, page_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, page_id) DO NOTHING
+	`, userID, string(pageID), at)
+	if err != nil {
+		return fmt.Errorf("add bookmark: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) RemoveBookmark(ctx context.Context, userID string, pageID domain.PageID) error {
+	_, err := repository.pool.Exec(ctx, `DELETE FROM page_bookmarks WHERE user_id = $1 AND page_id = $2`, userID, string(pageID))
+	if err != nil {
+		return fmt.Errorf("remove bookmark: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) ListBookmarks(ctx context.Context, userID string, limit, offset int) ([]domain.BookmarkedPage, error) {
+	rows, err := repository.pool.Query(ctx, `
+		SELECT
+			p.id, p.title, p.cover, p.published, p.unlisted, p.published_at, p.first_published_at,
+			p.dark_mode, p.cinematic, p.mood, p.bg_color, p.owner_id,
+			p.created_at, p.updated_at, p.deleted_at,
+			(SELECT count(*) FROM proofreads pr WHERE pr.page_id = p.id) AS proofread_count,
+			(SELECT count(*) FROM blocks b WHERE b.page_id = p.id) AS block_count,
+			(SELECT count(*) FROM page_reads r WHERE r.page_id = p.id) AS read_count,
+			(SELECT count(*) FROM page_likes l WHERE l.page_id = p.id) AS like_count,
+			COALESCE(u.username, 'anonymous') AS author_username,
+			COALESCE(NULLIF(u.display_name, ''), 'Anonymous') AS author_display_name,
+			COALESCE(u.avatar_url, '') AS author_avatar_url,
+			ARRAY(SELECT t.tag FROM page_tags t WHERE t.page_id = p.id ORDER BY t.tag) AS tags,
+			p.word_count, p.reading_minutes,
+			bm.created_at
+		FROM page_bookmarks bm
+		JOIN pages p ON p.id = bm.page_id
+		LEFT JOIN users u ON u.id = p.owner_id
+		WHERE bm.user_id = $1 AND p.deleted_at IS NULL AND (p.published = true OR p.owner_id = $1)
+		ORDER BY bm.created_at DESC, p.id
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	bookmarks := make([]domain.BookmarkedPage, 0)
+	for rows.Next() {
+		var bookmark domain.BookmarkedPage
+		if err := rows.Scan(
+			&bookmark.ID, &bookmark.Title, &bookmark.Cover, &bookmark.Published, &bookmark.Unlisted, &bookmark.PublishedAt, &bookmark.FirstPublishedAt,
+			&bookmark.DarkMode, &bookmark.Cinematic, &bookmark.Mood, &bookmark.BgColor, &bookmark.OwnerID,
+			&bookmark.CreatedAt, &bookmark.UpdatedAt, &bookmark.DeletedAt,
+			&bookmark.ProofreadCount, &bookmark.BlockCount, &bookmark.ReadCount, &bookmark.LikeCount,
+			&bookmark.AuthorUsername, &bookmark.AuthorDisplayName, &bookmark.AuthorAvatarURL, &bookmark.Tags,
+			&bookmark.WordCount, &bookmark.ReadingMinutes,
+			&bookmark.BookmarkedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan bookmark row: %w", err)
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate bookmark rows: %w", err)
+	}
+
+	previews := make([]*domain.FeedPage, len(bookmarks))
+	for i := range bookmarks {
+		previews[i] = &bookmarks[i].FeedPage
+	}
+	if err := repository.attachPreviewBlocks(ctx, previews...); err != nil {
+		return nil, err
+	}
+	return bookmarks, nil
+}
+
+func (repository *Repository) LikePage(ctx context.Context, pageID domain.PageID, userID string) error {
+	_, err := repository.pool.Exec(ctx, `
+		INSERT INTO page_likes (page_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (page_id, user_id) DO NOTHING
+	`, string(pageID), userID)
+	if err != nil {
+		return fmt.Errorf("like page: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) UnlikePage(ctx context.Context, pageID domain.PageID, userID string) error {
+	_, err := repository.pool.Exec(ctx, `DELETE FROM page_likes WHERE page_id = $1 AND user_id = $2`, string(pageID), userID)
+	if err != nil {
+		return fmt.Errorf("unlike page: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) CountLikes(ctx context.Context, pageID domain.PageID) (int, error) {
+	var count int
+	if err := repository.pool.QueryRow(ctx, `SELECT count(*) FROM page_likes WHERE page_id = $1`, string(pageID)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count page likes: %w", err)
+	}
+	return count, nil
+}
+
+func (repository *Repository) HasLiked(ctx context.Context, pageID domain.PageID, userID string) (bool, error) {
+	var liked bool
+	err := repository.pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM page_likes WHERE page_id = $1 AND user_id = $2)
+	`, string(pageID), userID).Scan(&liked)
+	if err != nil {
+		return false, fmt.Errorf("check page like: %w", err)
+	}
+	return liked, nil
+}
+
+// attachPreviewBlocks sets each page's Blocks to its first media block, the
+// preview shown in feeds. Password-protected pages get no preview.
+func (repository *Repository) attachPreviewBlocks(ctx context.Context, pages ...*domain.FeedPage) error {
+	if len(pages) == 0 {
+		return nil
+	}
+	pageIDs := make([]string, len(pages))
+	pageMap := make(map[string]*domain.FeedPage, len(pages))
+	for i, page := range pages {
+		pageIDs[i] = string(page.ID)
+		pageMap[string(page.ID)] = page
+	}
+
+	blockRows, err := repository.pool.Query(ctx, `
+		SELECT DISTINCT ON (b.page_id) b.id, b.page_id, b.parent_id, b.type, b.position, b.data
+		FROM blocks b
+		JOIN pages p ON p.id = b.page_id
+		WHERE b.page_id = ANY($1) AND b.type IN ('image', 'embed', 'gallery', 'music')
+		  AND p.password_hash IS NULL
+		ORDER BY b.page_id, b.position
+	`, pageIDs)
+	if err != nil {
+		return fmt.Errorf("query feed preview blocks: %w", err)
+	}
+	defer blockRows.Close()
+
+	for blockRows.Next() {
+		var block domain.Block
+		var blockType string
+		var data []byte
+		if err := blockRows.Scan(&block.ID, &block.PageID, &block.ParentID, &blockType, &block.Position, &data); err != nil {
+			return fmt.Errorf("scan feed preview block: %w", err)
+		}
+		block.Type = domain.BlockType(blockType)
+		block.Data = json.RawMessage(data)
+		if p, ok := pageMap[string(block.PageID)]; ok {
+			p.Blocks = []domain.Block{block}
+		}
+	}
+	if err := blockRows.Err(); err != nil {
+		return fmt.Errorf("iterate feed preview blocks: %w", err)
+	}
+	return nil
+}
+
 type rowScanner interface {
 	Scan(dest ...any) error
 }
 
+func scanComment(scanner rowScanner) (domain.Comment, error) {
+	var comment domain.Comment
+	if err := scanner.Scan(
+		&comment.ID,
+		&comment.PageID,
+		&comment.ParentID,
+		&comment.UserID,
+		&comment.AuthorName,
+		&comment.Body,
+		&comment.CreatedAt,
+	); err != nil {
+		return domain.Comment{}, fmt.Errorf("scan comment row: %w", err)
+	}
+	return comment, nil
+}
+
 func scanProofread(scanner rowScanner) (domain.Proofread, error) {
 	var proofread domain.Proofread
 	var annotationsRaw []byte
@@ -732,8 +1746,10 @@ func scanProofread(scanner rowScanner) (domain.Proofread, error) {
 		&proofread.Summary,
 		&proofread.Stance,
 		&annotationsRaw,
+		&proofread.Pinned,
 		&proofread.CreatedAt,
 		&proofread.UpdatedAt,
+		&proofread.VoteCount,
 	); err != nil {
 		return domain.Proofread{}, fmt.Errorf("scan proofread row: %w", err)
 	}
@@ -750,8 +1766,20 @@ func scanProofread(scanner rowScanner) (domain.Proofread, error) {
 	return proofread, nil
 }
 
+func (repository *Repository) replaceTags(ctx context.Context, tx pgx.Tx, pageID domain.PageID, tags []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM page_tags WHERE page_id = $1`, string(pageID)); err != nil {
+		return fmt.Errorf("clear page tags: %w", err)
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec(ctx, `INSERT INTO page_tags (page_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING`, string(pageID), tag); err != nil {
+			return fmt.Errorf("insert page tag: %w", err)
+		}
+	}
+	return nil
+}
+
 func (repository *Repository) insertBlocks(ctx context.Context, tx pgx.Tx, pageID domain.PageID, blocks []domain.Block) error {
-	for index, block := range blocks {
+	for _, block := range blocks {
 		blockID := block.ID
 		if blockID == "" {
 			blockID = uuid.NewString()
@@ -767,14 +1795,10 @@ func (repository *Repository) insertBlocks(ctx context.Context, tx pgx.Tx, pageI
 			}
 			blockID = uuid.NewString()
 		}
-		position := block.Position
-		if position < 0 {
-			position = index
-		}
 		_, err := tx.Exec(ctx, `
 			INSERT INTO blocks (id, page_id, parent_id, type, position, data, created_at, updated_at)
 			VALUES ($1, $2, $3, $4, $5, $6::jsonb, now(), now())
-		`, blockID, string(pageID), block.ParentID, string(block.Type), position, block.Data)
+		`, blockID, string(pageID), block.ParentID, string(block.Type), block.Position, block.Data)
 		if err != nil {
 			return fmt.Errorf("insert block %s: %w", blockID, err)
 		}
@@ -787,7 +1811,9 @@ func (repository *Repository) UpsertCollabUser(ctx context.Context, pageID domai
 		INSERT INTO page_collab_users (page_id, user_id, access, last_seen_at)
 		VALUES ($1, $2, $3, now())
 		ON CONFLICT (page_id, user_id)
-		DO UPDATE SET access = EXCLUDED.access, last_seen_at = now()
+		DO UPDATE SET
+			access = CASE WHEN page_collab_users.invited THEN page_collab_users.access ELSE EXCLUDED.access END,
+			last_seen_at = now()
 	`, string(pageID), userID, access)
 	if err != nil {
 		return fmt.Errorf("upsert collab user: %w", err)
@@ -795,9 +1821,50 @@ func (repository *Repository) UpsertCollabUser(ctx context.Context, pageID domai
 	return nil
 }
 
+func (repository *Repository) AddCollaborator(ctx context.Context, pageID domain.PageID, userID string, access domain.ShareAccess) error {
+	_, err := repository.pool.Exec(ctx, `
+		INSERT INTO page_collab_users (page_id, user_id, access, last_seen_at, invited)
+		VALUES ($1, $2, $3, now(), true)
+		ON CONFLICT (page_id, user_id)
+		DO UPDATE SET access = EXCLUDED.access, invited = true
+	`, string(pageID), userID, string(access))
+	if err != nil {
+		return fmt.Errorf("add collaborator: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) RemoveCollaborator(ctx context.Context, pageID domain.PageID, userID string) error {
+	commandTag, err := repository.pool.Exec(ctx, `
+		DELETE FROM page_collab_users WHERE page_id = $1 AND user_id = $2
+	`, string(pageID), userID)
+	if err != nil {
+		return fmt.Errorf("remove collaborator: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (repository *Repository) GetCollaboratorAccess(ctx context.Context, pageID domain.PageID, userID string) (domain.ShareAccess, error) {
+	var access string
+	err := repository.pool.QueryRow(ctx, `
+		SELECT access FROM page_collab_users
+		WHERE page_id = $1 AND user_id = $2 AND invited
+	`, string(pageID), userID).Scan(&access)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", errs.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("get collaborator access: %w", err)
+	}
+	return domain.ShareAccess(access), nil
+}
+
 func (repository *Repository) ListCollabUsers(ctx context.Context, pageID domain.PageID) ([]domain.CollabUser, error) {
 	rows, err := repository.pool.Query(ctx, `
-		SELECT u.id, u.username, u.display_name, u.avatar_url, pcu.access, pcu.last_seen_at
+		SELECT u.id, u.username, u.display_name, u.avatar_url, pcu.access, pcu.last_seen_at, pcu.invited
 		FROM page_collab_users pcu
 		JOIN users u ON u.id = pcu.user_id
 		WHERE pcu.page_id = $1
@@ -811,7 +1878,7 @@ func (repository *Repository) ListCollabUsers(ctx context.Context, pageID domain
 	users := make([]domain.CollabUser, 0)
 	for rows.Next() {
 		var cu domain.CollabUser
-		if err := rows.Scan(&cu.UserID, &cu.Username, &cu.DisplayName, &cu.AvatarURL, &cu.Access, &cu.LastSeenAt); err != nil {
+		if err := rows.Scan(&cu.UserID, &cu.Username, &cu.DisplayName, &cu.AvatarURL, &cu.Access, &cu.LastSeenAt, &cu.Invited); err != nil {
 			return nil, fmt.Errorf("scan collab user: %w", err)
 		}
 		users = append(users, cu)
@@ -821,3 +1888,171 @@ func (repository *Repository) ListCollabUsers(ctx context.Context, pageID domain
 	}
 	return users, nil
 }
+
+func (repository *Repository) SaveRevision(ctx context.Context, pageID domain.PageID, blocks []domain.Block, editorID string, pageUpdatedAt time.Time) error {
+	if blocks == nil {
+		blocks = []domain.Block{}
+	}
+	snapshot, err := json.Marshal(blocks)
+	if err != nil {
+		return fmt.Errorf("marshal revision blocks: %w", err)
+	}
+	_, err = repository.pool.Exec(ctx, `
+		INSERT INTO page_revisions (id, page_id, editor_id, blocks, block_count, page_updated_at, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4::jsonb, $5, $6, now())
+	`, uuid.NewString(), string(pageID), editorID, snapshot, len(blocks), pageUpdatedAt)
+	if err != nil {
+		return fmt.Errorf("insert page revision: %w", err)
+	}
+	return nil
+}
+
+func (repository *Repository) ListRevisions(ctx context.Context, pageID domain.PageID, limit, offset int) ([]domain.PageRevision, error) {
+	if limit <= 0 {
+		limit = 30
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	rows, err := repository.pool.Query(ctx, `
+		SELECT id, page_id, editor_id, block_count, created_at
+		FROM page_revisions
+		WHERE page_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, string(pageID), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list page revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]domain.PageRevision, 0)
+	for rows.Next() {
+		var revision domain.PageRevision
+		if err := rows.Scan(&revision.ID, &revision.PageID, &revision.EditorID, &revision.BlockCount, &revision.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan page revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate page revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+func (repository *Repository) GetRevision(ctx context.Context, pageID domain.PageID, revisionID domain.RevisionID) (domain.PageRevision, error) {
+	return repository.getRevision(ctx, `
+		SELECT id, page_id, editor_id, block_count, blocks, created_at
+		FROM page_revisions
+		WHERE id = $1 AND page_id = $2
+	`, string(revisionID), string(pageID))
+}
+
+func (repository *Repository) GetRevisionAt(ctx context.Context, pageID domain.PageID, at time.Time) (domain.PageRevision, error) {
+	return repository.getRevision(ctx, `
+		SELECT id, page_id, editor_id, block_count, blocks, created_at
+		FROM page_revisions
+		WHERE page_id = $1 AND page_updated_at <= $2
+		ORDER BY page_updated_at DESC
+		LIMIT 1
+	`, string(pageID), at)
+}
+
+func (repository *Repository) getRevision(ctx context.Context, query string, args ...any) (domain.PageRevision, error) {
+	var revision domain.PageRevision
+	var snapshot []byte
+	err := repository.pool.QueryRow(ctx, query, args...).Scan(&revision.ID, &revision.PageID, &revision.EditorID, &revision.BlockCount, &snapshot, &revision.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.PageRevision{}, errs.ErrNotFound
+		}
+		return domain.PageRevision{}, fmt.Errorf("get page revision: %w", err)
+	}
+	if err := json.Unmarshal(snapshot, &revision.Blocks); err != nil {
+		return domain.PageRevision{}, fmt.Errorf("unmarshal revision blocks: %w", err)
+	}
+	return revision, nil
+}
+
+// PruneRevisions keeps only the newest keep revisions for a page.
+func (repository *Repository) PruneRevisions(ctx context.Context, pageID domain.PageID, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	_, err := repository.pool.Exec(ctx, `
+		DELETE FROM page_revisions
+		WHERE page_id = $1 AND id NOT IN (
+			SELECT id FROM page_revisions
+			WHERE page_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		)
+	`, string(pageID), keep)
+	if err != nil {
+		return fmt.Errorf("prune page revisions: %w", err)
+	}
+	return nil
+}
+
+// execer is satisfied by both the pool and a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint
+// violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// IsMediaReferenced reports whether any page's cover or blocks still point at
+// the stored object objectKey. It reads page_media_refs by URL suffix through
+// the reverse(url) index.
+func (repository *Repository) IsMediaReferenced(ctx context.Context, objectKey string) (bool, error) {
+	var referenced bool
+	err := repository.pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM page_media_refs WHERE reverse(url) LIKE $1)
+	`, likePrefix(reverseString("/"+objectKey))).Scan(&referenced)
+	if err != nil {
+		return false, fmt.Errorf("check media references: %w", err)
+	}
+	return referenced, nil
+}
+
+// syncMediaRefs rebuilds page_media_refs for pageID from its current cover
+// and blocks. Every write to either calls it in the same transaction.
+func syncMediaRefs(ctx context.Context, tx pgx.Tx, pageID domain.PageID) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM page_media_refs WHERE page_id = $1`, string(pageID)); err != nil {
+		return fmt.Errorf("clear media refs: %w", err)
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO page_media_refs (page_id, url)
+		SELECT $1, cover FROM pages WHERE id = $1 AND cover ~ '^https?://'
+		UNION
+		SELECT $1, value #>> '{}'
+		FROM blocks b, jsonb_path_query(b.data, 'strict $.**') AS value
+		WHERE b.page_id = $1 AND jsonb_typeof(value) = 'string' AND value #>> '{}' ~ '^https?://'
+		ON CONFLICT DO NOTHING
+	`, string(pageID))
+	if err != nil {
+		return fmt.Errorf("record media refs: %w", err)
+	}
+	return nil
+}
+
+// likePrefix returns a LIKE pattern matching strings that start with prefix.
+func likePrefix(prefix string) string {
+	return likeEscaper.Replace(prefix) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func reverseString(value string) string {
+	runes := []rune(value)
+	slices.Reverse(runes)
+	return string(runes)
+}
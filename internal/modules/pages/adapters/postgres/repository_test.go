@@ -0,0 +1,1346 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/reggieanim/jot/internal/modules/pages/app"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	platformpostgres "github.com/reggieanim/jot/internal/platform/db/postgres"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+// newTestRepository connects to JOT_TEST_DATABASE_URL and applies the
+// migrations. Tests are skipped when it is unset.
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	databaseURL := os.Getenv("JOT_TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("JOT_TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	dir, err := platformpostgres.ResolveMigrationsDir("../../../../../migrations")
+	if err != nil {
+		t.Fatalf("resolve migrations: %v", err)
+	}
+	if err := platformpostgres.RunMigrations(ctx, pool, dir); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+	return NewRepository(pool)
+}
+
+func TestSetPublishedPreservesFirstPublishedAt(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Republished", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	first, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if first.FirstPublishedAt == nil || first.PublishedAt == nil {
+		t.Fatalf("expected publish timestamps after first publish, got %+v", first)
+	}
+
+	if err := repo.SetPublished(ctx, page.ID, false, false, nil); err != nil {
+		t.Fatalf("unpublish: %v", err)
+	}
+	unpublished, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if unpublished.PublishedAt != nil {
+		t.Fatalf("expected published_at cleared on unpublish, got %v", unpublished.PublishedAt)
+	}
+	if unpublished.FirstPublishedAt == nil || !unpublished.FirstPublishedAt.Equal(*first.FirstPublishedAt) {
+		t.Fatalf("expected first_published_at kept on unpublish, got %v", unpublished.FirstPublishedAt)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+		t.Fatalf("republish: %v", err)
+	}
+	republished, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !republished.FirstPublishedAt.Equal(*first.FirstPublishedAt) {
+		t.Fatalf("expected first_published_at %v preserved, got %v", first.FirstPublishedAt, republished.FirstPublishedAt)
+	}
+	if !republished.PublishedAt.After(*first.PublishedAt) {
+		t.Fatalf("expected published_at to advance on republish, got %v then %v", first.PublishedAt, republished.PublishedAt)
+	}
+}
+
+// createTestOwner inserts a throwaway user for pages to belong to.
+func createTestOwner(t *testing.T, repo *Repository) string {
+	t.Helper()
+	id := uuid.NewString()
+	if _, err := repo.pool.Exec(context.Background(), `
+		INSERT INTO users (id, email, username, password_hash)
+		VALUES ($1, $2, $3, '')
+	`, id, id+"@example.com", "u"+id[:8]); err != nil {
+		t.Fatalf("create owner: %v", err)
+	}
+	t.Cleanup(func() { _, _ = repo.pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, id) })
+	return id
+}
+
+func TestListPagesFiltersByStatus(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	ownerID := createTestOwner(t, repo)
+
+	now := time.Now().UTC()
+	created := map[string]bool{"Draft A": false, "Draft B": false, "Published": true}
+	ids := make(map[string]domain.PageID, len(created))
+	for title, published := range created {
+		page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: title, Published: published, OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, page); err != nil {
+			t.Fatalf("create %q: %v", title, err)
+		}
+		t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+		ids[title] = page.ID
+	}
+
+	tests := []struct {
+		status domain.PageStatus
+		want   []string
+	}{
+		{status: domain.PageStatusAny, want: []string{"Draft A", "Draft B", "Published"}},
+		{status: domain.PageStatusDraft, want: []string{"Draft A", "Draft B"}},
+		{status: domain.PageStatusPublished, want: []string{"Published"}},
+	}
+	for _, tt := range tests {
+		pages, err := repo.ListPages(ctx, ownerID, tt.status, 10, 0)
+		if err != nil {
+			t.Fatalf("list %q: %v", tt.status, err)
+		}
+		got := make(map[domain.PageID]bool, len(pages))
+		for _, page := range pages {
+			got[page.ID] = true
+		}
+		if len(pages) != len(tt.want) {
+			t.Fatalf("status %q: expected %d pages, got %d", tt.status, len(tt.want), len(pages))
+		}
+		for _, title := range tt.want {
+			if !got[ids[title]] {
+				t.Fatalf("status %q: expected %q in results", tt.status, title)
+			}
+		}
+	}
+
+	window, err := repo.ListPages(ctx, ownerID, domain.PageStatusDraft, 1, 1)
+	if err != nil {
+		t.Fatalf("list draft window: %v", err)
+	}
+	if len(window) != 1 || window[0].Published {
+		t.Fatalf("expected one draft in the second window, got %+v", window)
+	}
+}
+
+func TestListProofreadsByPageIDSortsPinnedFirst(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Reviewed", Published: true, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	var ids []domain.ProofreadID
+	for i := 0; i < 3; i++ {
+		createdAt := now.Add(time.Duration(i) * time.Minute)
+		proofread := domain.Proofread{
+			ID:        domain.ProofreadID(uuid.NewString()),
+			PageID:    page.ID,
+			Title:     "Review",
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}
+		if err := repo.CreateProofread(ctx, proofread); err != nil {
+			t.Fatalf("create proofread: %v", err)
+		}
+		ids = append(ids, proofread.ID)
+	}
+
+	if err := repo.SetProofreadPinned(ctx, page.ID, ids[0], true); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+	proofreads, err := repo.ListProofreadsByPageID(ctx, page.ID, "new")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := []domain.ProofreadID{ids[0], ids[2], ids[1]}
+	if len(proofreads) != len(want) {
+		t.Fatalf("expected %d proofreads, got %d", len(want), len(proofreads))
+	}
+	for i := range want {
+		if proofreads[i].ID != want[i] {
+			t.Fatalf("position %d: expected %s, got %s", i, want[i], proofreads[i].ID)
+		}
+	}
+	if !proofreads[0].Pinned {
+		t.Fatal("expected first proofread to be marked pinned")
+	}
+
+	if err := repo.SetProofreadPinned(ctx, domain.PageID(uuid.NewString()), ids[1], true); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for a proofread on another page, got %v", err)
+	}
+}
+
+func TestVoteProofreadCountsOncePerVoterAndSortsTop(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Voted", Published: true, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	var ids []domain.ProofreadID
+	for i := 0; i < 2; i++ {
+		createdAt := now.Add(time.Duration(i) * time.Minute)
+		proofread := domain.Proofread{
+			ID:        domain.ProofreadID(uuid.NewString()),
+			PageID:    page.ID,
+			Title:     "Review",
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}
+		if err := repo.CreateProofread(ctx, proofread); err != nil {
+			t.Fatalf("create proofread: %v", err)
+		}
+		ids = append(ids, proofread.ID)
+	}
+
+	for i, voterKey := range []string{"user:a", "user:a", "reader:b"} {
+		inserted, err := repo.VoteProofread(ctx, ids[0], voterKey)
+		if err != nil {
+			t.Fatalf("vote %s: %v", voterKey, err)
+		}
+		if inserted != (i != 1) {
+			t.Fatalf("vote %d by %s: expected inserted=%v, got %v", i, voterKey, i != 1, inserted)
+		}
+	}
+	count, err := repo.CountVotes(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("count votes: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 votes, got %d", count)
+	}
+
+	newest, err := repo.ListProofreadsByPageID(ctx, page.ID, "new")
+	if err != nil {
+		t.Fatalf("list new: %v", err)
+	}
+	if len(newest) != 2 || newest[0].ID != ids[1] {
+		t.Fatalf("expected newest proofread first, got %+v", newest)
+	}
+	top, err := repo.ListProofreadsByPageID(ctx, page.ID, "top")
+	if err != nil {
+		t.Fatalf("list top: %v", err)
+	}
+	if len(top) != 2 || top[0].ID != ids[0] || top[0].VoteCount != 2 || top[1].VoteCount != 0 {
+		t.Fatalf("expected most voted proofread first with counts, got %+v", top)
+	}
+}
+
+func TestListPublishedFeedExcludesBlockedAuthors(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	allowedOwner := createTestOwner(t, repo)
+	blockedOwner := createTestOwner(t, repo)
+
+	now := time.Now().UTC()
+	pageIDs := make(map[string]domain.PageID, 2)
+	for _, ownerID := range []string{allowedOwner, blockedOwner} {
+		page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Feed page", OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, page); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+		if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		pageIDs[ownerID] = page.ID
+	}
+
+	feed, err := repo.ListPublishedFeed(ctx, 100, 0, "new", nil, []string{blockedOwner}, false, "", "")
+	if err != nil {
+		t.Fatalf("list feed: %v", err)
+	}
+	seen := make(map[domain.PageID]bool, len(feed))
+	for _, page := range feed {
+		seen[page.ID] = true
+	}
+	if !seen[pageIDs[allowedOwner]] {
+		t.Fatal("expected page by unblocked author in feed")
+	}
+	if seen[pageIDs[blockedOwner]] {
+		t.Fatal("expected page by blocked author to be excluded")
+	}
+}
+
+func TestListPublishedFeedExcludesOwnersAndSelf(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	viewer := createTestOwner(t, repo)
+	excluded := createTestOwner(t, repo)
+	other := createTestOwner(t, repo)
+
+	now := time.Now().UTC()
+	pageIDs := make(map[string]domain.PageID, 3)
+	for _, ownerID := range []string{viewer, excluded, other} {
+		page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Feed page", OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, page); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+		if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		pageIDs[ownerID] = page.ID
+	}
+
+	feedOwners := func(excludeSelf bool) map[string]bool {
+		t.Helper()
+		feed, err := repo.ListPublishedFeed(ctx, 100, 0, "new", []string{viewer, excluded, other}, []string{excluded}, excludeSelf, "", viewer)
+		if err != nil {
+			t.Fatalf("list feed: %v", err)
+		}
+		seen := make(map[string]bool, len(feed))
+		for ownerID, pageID := range pageIDs {
+			for _, page := range feed {
+				if page.ID == pageID {
+					seen[ownerID] = true
+				}
+			}
+		}
+		return seen
+	}
+
+	withSelf := feedOwners(false)
+	if !withSelf[viewer] || !withSelf[other] || withSelf[excluded] {
+		t.Fatalf("expected viewer and other author but not the excluded one, got %v", withSelf)
+	}
+	withoutSelf := feedOwners(true)
+	if withoutSelf[viewer] || !withoutSelf[other] || withoutSelf[excluded] {
+		t.Fatalf("expected only the other author when excluding self, got %v", withoutSelf)
+	}
+}
+
+func TestTrendingForOwnerRanksByRecentReads(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	ownerID := createTestOwner(t, repo)
+
+	now := time.Now().UTC()
+	pageIDs := make(map[string]domain.PageID, 3)
+	for _, title := range []string{"Evergreen", "Rising", "Dormant"} {
+		page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: title, OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, page); err != nil {
+			t.Fatalf("create %q: %v", title, err)
+		}
+		t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+		if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+			t.Fatalf("publish %q: %v", title, err)
+		}
+		pageIDs[title] = page.ID
+	}
+
+	// Evergreen and Dormant were read heavily last week; only Evergreen and
+	// Rising are being read now, Rising more so.
+	for _, title := range []string{"Evergreen", "Dormant"} {
+		if _, err := repo.pool.Exec(ctx, `
+			INSERT INTO page_read_days (page_id, day, reads) VALUES ($1, (now() AT TIME ZONE 'UTC')::date - 10, 50)
+		`, string(pageIDs[title])); err != nil {
+			t.Fatalf("seed old reads: %v", err)
+		}
+	}
+	recent := map[string]int{"Evergreen": 1, "Rising": 5}
+	for title, reads := range recent {
+		for i := 0; i < reads; i++ {
+			if _, err := repo.RecordOrganicRead(ctx, pageIDs[title], uuid.NewString()); err != nil {
+				t.Fatalf("record read: %v", err)
+			}
+		}
+	}
+
+	pages, err := repo.TrendingForOwner(ctx, ownerID, now.Add(-24*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("trending: %v", err)
+	}
+	if len(pages) != 2 || pages[0].ID != pageIDs["Rising"] || pages[1].ID != pageIDs["Evergreen"] {
+		t.Fatalf("expected Rising then Evergreen, got %+v", pages)
+	}
+	if pages[0].RecentReads != 5 || pages[1].RecentReads != 1 {
+		t.Fatalf("expected recent reads 5 and 1, got %d and %d", pages[0].RecentReads, pages[1].RecentReads)
+	}
+}
+
+func TestPageTagsRoundTrip(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Tagged", Tags: []string{"go", "databases"}, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	created, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !slices.Equal(created.Tags, []string{"databases", "go"}) {
+		t.Fatalf("expected tags to round-trip, got %v", created.Tags)
+	}
+
+	if err := repo.UpdatePageMetaOptimistic(ctx, page.ID, "Tagged", nil, false, false, 0, "", nil, nil); err != nil {
+		t.Fatalf("update meta without tags: %v", err)
+	}
+	kept, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !slices.Equal(kept.Tags, created.Tags) {
+		t.Fatalf("expected nil tags to keep existing tags, got %v", kept.Tags)
+	}
+
+	if err := repo.UpdatePageMetaOptimistic(ctx, page.ID, "Tagged", nil, false, false, 0, "", []string{"postgres"}, nil); err != nil {
+		t.Fatalf("update meta with tags: %v", err)
+	}
+	if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	feed, err := repo.ListPublishedFeed(ctx, 100, 0, "new", nil, nil, false, "postgres", "")
+	if err != nil {
+		t.Fatalf("list feed: %v", err)
+	}
+	if len(feed) == 0 || !slices.Contains(feed[0].Tags, "postgres") {
+		t.Fatalf("expected tag-filtered feed to return the retagged page, got %v", feed)
+	}
+	for _, fp := range feed {
+		if !slices.Contains(fp.Tags, "postgres") {
+			t.Fatalf("expected only pages tagged postgres, got %v", fp.Tags)
+		}
+	}
+	stale, err := repo.ListPublishedFeed(ctx, 100, 0, "new", nil, nil, false, "go", "")
+	if err != nil {
+		t.Fatalf("list feed: %v", err)
+	}
+	for _, fp := range stale {
+		if fp.ID == page.ID {
+			t.Fatal("expected replaced tag to no longer match the page")
+		}
+	}
+}
+
+func TestListPublishedPagesByOwnerFiltersByTag(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	ownerID := createTestOwner(t, repo)
+	otherID := createTestOwner(t, repo)
+
+	now := time.Now().UTC()
+	create := func(ownerID, title string, tags []string) domain.PageID {
+		t.Helper()
+		page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: title, Tags: tags, OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, page); err != nil {
+			t.Fatalf("create %q: %v", title, err)
+		}
+		t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+		if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+			t.Fatalf("publish %q: %v", title, err)
+		}
+		return page.ID
+	}
+	tagged := create(ownerID, "Tagged", []string{"go", "databases"})
+	create(ownerID, "Untagged", []string{"travel"})
+	create(otherID, "Someone else's", []string{"go"})
+
+	pages, err := repo.ListPublishedPagesByOwner(ctx, ownerID, "go", 10, 0)
+	if err != nil {
+		t.Fatalf("list tagged: %v", err)
+	}
+	if len(pages) != 1 || pages[0].ID != tagged {
+		t.Fatalf("expected only the owner's page tagged go, got %+v", pages)
+	}
+	if !slices.Equal(pages[0].Tags, []string{"databases", "go"}) {
+		t.Fatalf("expected the page's tags, got %v", pages[0].Tags)
+	}
+
+	all, err := repo.ListPublishedPagesByOwner(ctx, ownerID, "", 10, 0)
+	if err != nil {
+		t.Fatalf("list all: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected both of the owner's pages without a tag, got %d", len(all))
+	}
+	window, err := repo.ListPublishedPagesByOwner(ctx, ownerID, "", 1, 1)
+	if err != nil {
+		t.Fatalf("list window: %v", err)
+	}
+	if len(window) != 1 || window[0].ID != all[1].ID {
+		t.Fatalf("expected the second page in the second window, got %+v", window)
+	}
+}
+
+func TestPreviewBlocksSkipPasswordProtectedPages(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	ownerID := createTestOwner(t, repo)
+
+	now := time.Now().UTC()
+	create := func(title string) domain.PageID {
+		t.Helper()
+		blocks := []domain.Block{{ID: uuid.NewString(), Type: domain.BlockTypeImage, Data: json.RawMessage(`{"url":"https://example.com/a.png"}`)}}
+		page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: title, Blocks: blocks, OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, page); err != nil {
+			t.Fatalf("create %q: %v", title, err)
+		}
+		t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+		if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+			t.Fatalf("publish %q: %v", title, err)
+		}
+		return page.ID
+	}
+	open := create("Open")
+	locked := create("Locked")
+	hash := "not-a-real-hash"
+	if err := repo.SetPasswordHash(ctx, locked, &hash); err != nil {
+		t.Fatalf("set password: %v", err)
+	}
+
+	pages, err := repo.ListPublishedPagesByOwner(ctx, ownerID, "", 10, 0)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	previews := make(map[domain.PageID]int, len(pages))
+	for _, page := range pages {
+		previews[page.ID] = len(page.Blocks)
+	}
+	if previews[open] != 1 {
+		t.Fatalf("expected the open page's preview block, got %d blocks", previews[open])
+	}
+	if previews[locked] != 0 {
+		t.Fatalf("expected no preview for the protected page, got %d blocks", previews[locked])
+	}
+
+	feed, err := repo.ListPublishedFeed(ctx, 100, 0, "new", nil, nil, false, "", "")
+	if err != nil {
+		t.Fatalf("list feed: %v", err)
+	}
+	for _, page := range feed {
+		if page.ID == locked && len(page.Blocks) != 0 {
+			t.Fatalf("expected no feed preview for the protected page, got %+v", page.Blocks)
+		}
+	}
+}
+
+func TestPageLikesAreIdempotentAndShownInFeed(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	ownerID := createTestOwner(t, repo)
+	readerID := createTestOwner(t, repo)
+
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Liked", OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+	if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := repo.LikePage(ctx, page.ID, readerID); err != nil {
+			t.Fatalf("like %d: %v", i+1, err)
+		}
+	}
+	if count, err := repo.CountLikes(ctx, page.ID); err != nil || count != 1 {
+		t.Fatalf("expected one like after liking twice, got %d, %v", count, err)
+	}
+	if liked, err := repo.HasLiked(ctx, page.ID, readerID); err != nil || !liked {
+		t.Fatalf("expected reader to have liked the page, got %v, %v", liked, err)
+	}
+
+	feed, err := repo.ListPublishedFeed(ctx, 100, 0, "liked", []string{ownerID}, nil, false, "", readerID)
+	if err != nil {
+		t.Fatalf("list feed: %v", err)
+	}
+	if len(feed) != 1 || feed[0].LikeCount != 1 || feed[0].LikedByMe == nil || !*feed[0].LikedByMe {
+		t.Fatalf("expected feed to show the like for the reader, got %+v", feed)
+	}
+	anonymous, err := repo.ListPublishedFeed(ctx, 100, 0, "liked", []string{ownerID}, nil, false, "", "")
+	if err != nil {
+		t.Fatalf("list feed: %v", err)
+	}
+	if len(anonymous) != 1 || anonymous[0].LikedByMe != nil {
+		t.Fatalf("expected liked_by_me to be omitted for anonymous viewers, got %+v", anonymous)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := repo.UnlikePage(ctx, page.ID, readerID); err != nil {
+			t.Fatalf("unlike %d: %v", i+1, err)
+		}
+	}
+	if count, err := repo.CountLikes(ctx, page.ID); err != nil || count != 0 {
+		t.Fatalf("expected no likes after unliking twice, got %d, %v", count, err)
+	}
+}
+
+func TestPageSlugsAreUniquePerOwner(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	ownerID := createTestOwner(t, repo)
+	otherOwnerID := createTestOwner(t, repo)
+
+	newPage := func(owner string) domain.PageID {
+		t.Helper()
+		now := time.Now().UTC()
+		page := domain.Page{ID: domain.PageID(uuid.NewString()), OwnerID: &owner, Title: "Slugged", CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, page); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+		return page.ID
+	}
+	first, second, other := newPage(ownerID), newPage(ownerID), newPage(otherOwnerID)
+
+	if err := repo.SetSlug(ctx, first, "café-été"); err != nil {
+		t.Fatalf("set slug: %v", err)
+	}
+	if err := repo.SetSlug(ctx, second, "café-été"); !errors.Is(err, errs.ErrConflict) {
+		t.Fatalf("expected duplicate slug for the same owner to conflict, got %v", err)
+	}
+	if err := repo.SetSlug(ctx, other, "café-été"); err != nil {
+		t.Fatalf("expected another owner to reuse the slug, got %v", err)
+	}
+	if err := repo.SetSlug(ctx, second, "café-été-2"); err != nil {
+		t.Fatalf("set suffixed slug: %v", err)
+	}
+
+	slugs, err := repo.ListTakenSlugs(ctx, ownerID, "café-été")
+	if err != nil {
+		t.Fatalf("list slugs: %v", err)
+	}
+	slices.Sort(slugs)
+	if !slices.Equal(slugs, []string{"café-été", "café-été-2"}) {
+		t.Fatalf("expected both of the owner's slugs, got %v", slugs)
+	}
+
+	var username string
+	if err := repo.pool.QueryRow(ctx, `SELECT username FROM users WHERE id = $1`, ownerID).Scan(&username); err != nil {
+		t.Fatalf("get username: %v", err)
+	}
+	found, err := repo.GetBySlug(ctx, strings.ToUpper(username), "café-été-2")
+	if err != nil {
+		t.Fatalf("get by slug: %v", err)
+	}
+	if found.ID != second || found.Slug != "café-été-2" {
+		t.Fatalf("expected the second page, got %s with slug %q", found.ID, found.Slug)
+	}
+	if _, err := repo.GetBySlug(ctx, username, "missing"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for unknown slug, got %v", err)
+	}
+}
+
+func TestGlobalSlugsAreUniqueAcrossOwners(t *testing.T) {
+	perOwner := newTestRepository(t)
+	global := NewRepository(perOwner.pool, WithGlobalSlugs(true))
+	ctx := context.Background()
+	ownerID := createTestOwner(t, perOwner)
+	otherOwnerID := createTestOwner(t, perOwner)
+
+	newPage := func(owner string) domain.PageID {
+		t.Helper()
+		now := time.Now().UTC()
+		page := domain.Page{ID: domain.PageID(uuid.NewString()), OwnerID: &owner, Title: "Slugged", CreatedAt: now, UpdatedAt: now}
+		if err := perOwner.Create(ctx, page); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		t.Cleanup(func() { _ = perOwner.DeletePage(context.Background(), page.ID) })
+		if err := perOwner.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		return page.ID
+	}
+	base := "global-" + uuid.NewString()[:8]
+	first, other := newPage(ownerID), newPage(otherOwnerID)
+
+	if err := global.SetSlug(ctx, first, base); err != nil {
+		t.Fatalf("set slug: %v", err)
+	}
+	if err := global.SetSlug(ctx, other, base); !errors.Is(err, errs.ErrConflict) {
+		t.Fatalf("expected another owner's duplicate slug to conflict in global mode, got %v", err)
+	}
+	if err := global.SetSlug(ctx, first, base); err != nil {
+		t.Fatalf("expected a page to keep its own slug, got %v", err)
+	}
+	taken, err := global.ListTakenSlugs(ctx, otherOwnerID, base)
+	if err != nil {
+		t.Fatalf("list taken slugs: %v", err)
+	}
+	if !slices.Equal(taken, []string{base}) {
+		t.Fatalf("expected the other owner's slug to count as taken in global mode, got %v", taken)
+	}
+	found, err := global.GetBySlug(ctx, "", base)
+	if err != nil {
+		t.Fatalf("get by global slug: %v", err)
+	}
+	if found.ID != first {
+		t.Fatalf("expected %s, got %s", first, found.ID)
+	}
+
+	if taken, err := perOwner.ListTakenSlugs(ctx, otherOwnerID, base); err != nil || len(taken) != 0 {
+		t.Fatalf("expected no taken slugs for the other owner in per-owner mode, got %v, %v", taken, err)
+	}
+	if err := perOwner.SetSlug(ctx, other, base); err != nil {
+		t.Fatalf("expected another owner to reuse the slug in per-owner mode, got %v", err)
+	}
+	if _, err := perOwner.GetBySlug(ctx, "", base); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected slug-only lookups to find nothing in per-owner mode, got %v", err)
+	}
+}
+
+func TestGetByIDSetsContentHash(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	page := domain.Page{
+		ID:        domain.PageID(uuid.NewString()),
+		Title:     "Hashed",
+		Blocks:    []domain.Block{{ID: uuid.NewString(), Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":"one"}`)}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	first, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	again, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if first.ContentHash == "" || first.ContentHash != again.ContentHash {
+		t.Fatalf("expected a stable content hash, got %q and %q", first.ContentHash, again.ContentHash)
+	}
+
+	edited := first.Blocks
+	edited[0].Data = json.RawMessage(`{"text":"two"}`)
+	if err := repo.UpdateBlocks(ctx, page.ID, edited); err != nil {
+		t.Fatalf("update blocks: %v", err)
+	}
+	updated, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if updated.ContentHash == first.ContentHash {
+		t.Fatal("expected the content hash to change after a block edit")
+	}
+}
+
+func TestBlockPositionsRoundTrip(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	positions := []int{domain.MaxBlockPosition, 0, 1 << 24, (1 << 24) + 1}
+	blocks := make([]domain.Block, 0, len(positions))
+	for _, position := range positions {
+		blocks = append(blocks, domain.Block{ID: uuid.NewString(), Type: domain.BlockTypeParagraph, Position: position, Data: json.RawMessage(`{}`)})
+	}
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Positions", Blocks: blocks, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	stored, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	payload, err := json.Marshal(stored.Blocks)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded []domain.Block
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	want := []int{0, 1 << 24, (1 << 24) + 1, domain.MaxBlockPosition}
+	if len(decoded) != len(want) {
+		t.Fatalf("expected %d blocks, got %d", len(want), len(decoded))
+	}
+	for i, block := range decoded {
+		if block.Position != want[i] {
+			t.Fatalf("block %d: expected position %d, got %d", i, want[i], block.Position)
+		}
+	}
+}
+
+func TestCountBlockTypesGroupsByType(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	blockTypes := []domain.BlockType{domain.BlockTypeParagraph, domain.BlockTypeImage, domain.BlockTypeParagraph}
+	blocks := make([]domain.Block, 0, len(blockTypes))
+	for i, blockType := range blockTypes {
+		blocks = append(blocks, domain.Block{ID: uuid.NewString(), Type: blockType, Position: i, Data: json.RawMessage(`{}`)})
+	}
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Counts", Blocks: blocks, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	counts, err := repo.CountBlockTypes(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	want := []domain.BlockTypeCount{{Type: domain.BlockTypeImage, Count: 1}, {Type: domain.BlockTypeParagraph, Count: 2}}
+	if !slices.Equal(counts, want) {
+		t.Fatalf("expected %+v, got %+v", want, counts)
+	}
+
+	empty, err := repo.CountBlockTypes(ctx, domain.PageID(uuid.NewString()))
+	if err != nil {
+		t.Fatalf("count missing page: %v", err)
+	}
+	if empty == nil || len(empty) != 0 {
+		t.Fatalf("expected an empty, non-nil slice for a page without blocks, got %#v", empty)
+	}
+}
+
+func TestPublishDuePagesBoundary(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	// A cutoff in the past keeps pages scheduled by other tests out of the way.
+	cutoff := time.Date(2001, 1, 1, 12, 0, 0, 0, time.UTC)
+	schedule := func(title string, publishAt time.Time) domain.PageID {
+		t.Helper()
+		now := time.Now().UTC()
+		page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: title, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, page); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+		if err := repo.SchedulePublish(ctx, page.ID, publishAt, true, nil); err != nil {
+			t.Fatalf("schedule: %v", err)
+		}
+		return page.ID
+	}
+	atCutoff := schedule("At cutoff", cutoff)
+	afterCutoff := schedule("After cutoff", cutoff.Add(time.Second))
+
+	scheduled, err := repo.GetByID(ctx, atCutoff)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if scheduled.Published || scheduled.PublishAt == nil || !scheduled.PublishAt.Equal(cutoff) {
+		t.Fatalf("expected unpublished page scheduled for %v, got published=%v publish_at=%v", cutoff, scheduled.Published, scheduled.PublishAt)
+	}
+
+	due, err := repo.ListDuePages(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("list due pages: %v", err)
+	}
+	var gotAt, gotAfter bool
+	for _, page := range due {
+		gotAt = gotAt || page.ID == atCutoff
+		gotAfter = gotAfter || page.ID == afterCutoff
+	}
+	if !gotAt || gotAfter {
+		t.Fatalf("expected only the page scheduled at the cutoff to be due, got at=%v after=%v", gotAt, gotAfter)
+	}
+	if ok, err := repo.PublishScheduled(ctx, afterCutoff, cutoff); err != nil || ok {
+		t.Fatalf("expected a page not yet due to stay scheduled, got %v, %v", ok, err)
+	}
+	if ok, err := repo.PublishScheduled(ctx, atCutoff, cutoff); err != nil || !ok {
+		t.Fatalf("expected the due page to publish, got %v, %v", ok, err)
+	}
+	if ok, err := repo.PublishScheduled(ctx, atCutoff, cutoff); err != nil || ok {
+		t.Fatalf("expected a page to publish only once, got %v, %v", ok, err)
+	}
+
+	published, err := repo.GetByID(ctx, atCutoff)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !published.Published || !published.Unlisted || published.PublishAt != nil || published.FirstPublishedAt == nil {
+		t.Fatalf("expected published unlisted page without schedule, got %+v", published)
+	}
+	pending, err := repo.GetByID(ctx, afterCutoff)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if pending.Published || pending.PublishAt == nil {
+		t.Fatalf("expected later page to stay scheduled, got %+v", pending)
+	}
+
+	if err := repo.SetPublished(ctx, afterCutoff, false, false, nil); err != nil {
+		t.Fatalf("unpublish: %v", err)
+	}
+	cancelled, err := repo.GetByID(ctx, afterCutoff)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if cancelled.PublishAt != nil {
+		t.Fatalf("expected SetPublished to cancel the schedule, got %v", cancelled.PublishAt)
+	}
+}
+
+func TestPageCommentsAreListedOldestFirstAndCountedInFeed(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	ownerID := createTestOwner(t, repo)
+
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Discussed", OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+	if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	// Inserted newest first so the listing has to sort by created_at.
+	later := domain.Comment{ID: domain.CommentID(uuid.NewString()), PageID: page.ID, AuthorName: "Bo", Body: "Second", CreatedAt: now.Add(time.Minute)}
+	root := domain.Comment{ID: domain.CommentID(uuid.NewString()), PageID: page.ID, AuthorName: "Ann", Body: "First", CreatedAt: now}
+	reply := domain.Comment{ID: domain.CommentID(uuid.NewString()), PageID: page.ID, ParentID: &root.ID, UserID: &ownerID, AuthorName: "Owner", Body: "Reply", CreatedAt: now.Add(time.Second)}
+	for _, comment := range []domain.Comment{later, root, reply} {
+		if err := repo.CreateComment(ctx, comment); err != nil {
+			t.Fatalf("create comment: %v", err)
+		}
+	}
+
+	comments, err := repo.ListComments(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("list comments: %v", err)
+	}
+	if len(comments) != 3 || comments[0].ID != root.ID || comments[1].ID != reply.ID || comments[2].ID != later.ID {
+		t.Fatalf("expected comments oldest first, got %+v", comments)
+	}
+	if comments[1].ParentID == nil || *comments[1].ParentID != root.ID || comments[1].UserID == nil || *comments[1].UserID != ownerID {
+		t.Fatalf("expected reply to keep its parent and author, got %+v", comments[1])
+	}
+
+	feed, err := repo.ListPublishedFeed(ctx, 100, 0, "new", []string{ownerID}, nil, false, "", "")
+	if err != nil {
+		t.Fatalf("list feed: %v", err)
+	}
+	if len(feed) != 1 || feed[0].CommentCount != 3 {
+		t.Fatalf("expected the page with 3 comments in the feed, got %+v", feed)
+	}
+
+	if err := repo.DeleteComment(ctx, root.ID); err != nil {
+		t.Fatalf("delete comment: %v", err)
+	}
+	if _, err := repo.GetComment(ctx, reply.ID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected reply to be deleted with its parent, got %v", err)
+	}
+}
+
+func TestCollaboratorAccessIsOnlyForInvitedUsers(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	ownerID := createTestOwner(t, repo)
+	invitedID := createTestOwner(t, repo)
+	visitorID := createTestOwner(t, repo)
+
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Shared", OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	if err := repo.UpsertCollabUser(ctx, page.ID, visitorID, "edit"); err != nil {
+		t.Fatalf("record share link visitor: %v", err)
+	}
+	if _, err := repo.GetCollaboratorAccess(ctx, page.ID, visitorID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected a share link visitor to have no collaborator access, got %v", err)
+	}
+
+	if err := repo.AddCollaborator(ctx, page.ID, invitedID, domain.ShareAccessEdit); err != nil {
+		t.Fatalf("add collaborator: %v", err)
+	}
+	if err := repo.UpsertCollabUser(ctx, page.ID, invitedID, "view"); err != nil {
+		t.Fatalf("record share link visit: %v", err)
+	}
+	access, err := repo.GetCollaboratorAccess(ctx, page.ID, invitedID)
+	if err != nil {
+		t.Fatalf("get collaborator access: %v", err)
+	}
+	if access != domain.ShareAccessEdit {
+		t.Fatalf("expected a view link visit to keep edit access, got %q", access)
+	}
+
+	if err := repo.RemoveCollaborator(ctx, page.ID, invitedID); err != nil {
+		t.Fatalf("remove collaborator: %v", err)
+	}
+	if _, err := repo.GetCollaboratorAccess(ctx, page.ID, invitedID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected a removed collaborator to have no access, got %v", err)
+	}
+	if err := repo.RemoveCollaborator(ctx, page.ID, invitedID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found removing twice, got %v", err)
+	}
+}
+
+func TestTransferOwnershipClearsCollaborators(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	ownerID := createTestOwner(t, repo)
+	recipientID := createTestOwner(t, repo)
+	invitedID := createTestOwner(t, repo)
+	visitorID := createTestOwner(t, repo)
+
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Handed off", OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+	if err := repo.SetPublished(ctx, page.ID, true, false, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if err := repo.AddCollaborator(ctx, page.ID, invitedID, domain.ShareAccessEdit); err != nil {
+		t.Fatalf("add collaborator: %v", err)
+	}
+	if err := repo.UpsertCollabUser(ctx, page.ID, visitorID, "view"); err != nil {
+		t.Fatalf("record share link visitor: %v", err)
+	}
+
+	if err := repo.TransferOwnership(ctx, page.ID, ownerID, recipientID); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if _, err := repo.GetCollaboratorAccess(ctx, page.ID, invitedID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected the invited collaborator removed, got %v", err)
+	}
+	users, err := repo.ListCollabUsers(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("list collab users: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("expected no collab users after transfer, got %+v", users)
+	}
+	transferred, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if transferred.OwnerID == nil || *transferred.OwnerID != recipientID || transferred.Published {
+		t.Fatalf("expected an unpublished page owned by the recipient, got owner=%v published=%v", transferred.OwnerID, transferred.Published)
+	}
+	if err := repo.TransferOwnership(ctx, page.ID, ownerID, recipientID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected the previous owner unable to transfer again, got %v", err)
+	}
+}
+
+func TestIsMediaReferencedFindsCoversAndBlocks(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	coverKey := "images/" + uuid.NewString() + ".png"
+	blockKey := "images/" + uuid.NewString() + ".jpg"
+	cover := "http://s3.local/bucket/" + coverKey
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Media", Cover: &cover, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+	blocks := []domain.Block{{ID: uuid.NewString(), Type: domain.BlockTypeImage, Data: json.RawMessage(`{"url":"http://s3.local/bucket/` + blockKey + `"}`)}}
+	if err := repo.UpdateBlocks(ctx, page.ID, blocks); err != nil {
+		t.Fatalf("update blocks: %v", err)
+	}
+
+	for _, key := range []string{coverKey, blockKey} {
+		referenced, err := repo.IsMediaReferenced(ctx, key)
+		if err != nil {
+			t.Fatalf("check %s: %v", key, err)
+		}
+		if !referenced {
+			t.Fatalf("expected %s to be referenced", key)
+		}
+	}
+	referenced, err := repo.IsMediaReferenced(ctx, "images/"+uuid.NewString()+".png")
+	if err != nil {
+		t.Fatalf("check unused key: %v", err)
+	}
+	if referenced {
+		t.Fatalf("expected an unused key not to be referenced")
+	}
+
+	if err := repo.UpdateBlocks(ctx, page.ID, nil); err != nil {
+		t.Fatalf("clear blocks: %v", err)
+	}
+	if referenced, err := repo.IsMediaReferenced(ctx, blockKey); err != nil || referenced {
+		t.Fatalf("expected a removed block's media to be unreferenced, got %v, %v", referenced, err)
+	}
+	if err := repo.DeletePage(ctx, page.ID); err != nil {
+		t.Fatalf("delete page: %v", err)
+	}
+	if referenced, err := repo.IsMediaReferenced(ctx, coverKey); err != nil || referenced {
+		t.Fatalf("expected a deleted page's cover to be unreferenced, got %v, %v", referenced, err)
+	}
+}
+
+func TestPlatformStatsCountsSeededData(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	// The database is shared with other tests, so compare against a baseline.
+	// User and page totals are estimates; ANALYZE makes them exact here.
+	analyze := func() {
+		t.Helper()
+		if _, err := repo.pool.Exec(ctx, `ANALYZE users, pages`); err != nil {
+			t.Fatalf("analyze: %v", err)
+		}
+	}
+	analyze()
+	before, err := repo.PlatformStats(ctx)
+	if err != nil {
+		t.Fatalf("stats before: %v", err)
+	}
+
+	ownerID := createTestOwner(t, repo)
+	createTestOwner(t, repo)
+	now := time.Now().UTC()
+	var published, archived domain.PageID
+	for _, title := range []string{"Draft", "Published", "Archived"} {
+		page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: title, OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, page); err != nil {
+			t.Fatalf("create %q: %v", title, err)
+		}
+		t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+		switch title {
+		case "Published":
+			published = page.ID
+		case "Archived":
+			archived = page.ID
+		}
+	}
+	if err := repo.SetPublished(ctx, published, true, false, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if err := repo.ArchivePage(ctx, archived); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	reader := uuid.NewString()
+	for _, readerKey := range []string{reader, reader, uuid.NewString()} {
+		if _, err := repo.RecordOrganicRead(ctx, published, readerKey); err != nil {
+			t.Fatalf("record read: %v", err)
+		}
+	}
+
+	analyze()
+	after, err := repo.PlatformStats(ctx)
+	if err != nil {
+		t.Fatalf("stats after: %v", err)
+	}
+	delta := domain.PlatformStats{
+		TotalUsers:     after.TotalUsers - before.TotalUsers,
+		TotalPages:     after.TotalPages - before.TotalPages,
+		PublishedPages: after.PublishedPages - before.PublishedPages,
+		TotalReads:     after.TotalReads - before.TotalReads,
+	}
+	want := domain.PlatformStats{TotalUsers: 2, TotalPages: 3, PublishedPages: 1, TotalReads: 3}
+	if delta != want {
+		t.Fatalf("expected stats to grow by %+v, got %+v", want, delta)
+	}
+}
+
+func TestCountPublishedSinceKeepsUnpublishedPages(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	ownerID := createTestOwner(t, repo)
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Flip", OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	since := now.Add(-time.Minute)
+	for _, published := range []bool{true, false, true, false} {
+		if err := repo.SetPublished(ctx, page.ID, published, false, nil); err != nil {
+			t.Fatalf("set published %v: %v", published, err)
+		}
+	}
+	count, err := repo.CountPublishedSince(ctx, ownerID, since)
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the unpublished page to count once, got %d", count)
+	}
+}
+
+func TestContentHashMatchesAcrossLoaders(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	page := domain.Page{
+		ID:    domain.PageID(uuid.NewString()),
+		Title: "Canonical",
+		Tags:  []string{"notes", "go"},
+		// jsonb stores keys reordered, so the saved page must hash the same.
+		Blocks:    []domain.Block{{ID: uuid.NewString(), Type: domain.BlockTypeHeading, Data: json.RawMessage(`{"text":"Hi","level":2}`)}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	byID, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	withAuthor, err := repo.GetByIDWithAuthor(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get with author: %v", err)
+	}
+	want := domain.ContentHash(page)
+	if byID.ContentHash != want || withAuthor.ContentHash != want {
+		t.Fatalf("expected every loader to hash to %s, got %s and %s", want, byID.ContentHash, withAuthor.ContentHash)
+	}
+}
+
+func TestShareLinkCodesResolveAndCollide(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	ownerID := createTestOwner(t, repo)
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Shared", OwnerID: &ownerID, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+
+	service := app.NewService(repo, nil, wallClock{}, app.WithShareCodeLength(8))
+	share, err := service.CreateShareLink(ctx, ownerID, page.ID, domain.ShareAccessView)
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+	if len(share.Code) != 8 {
+		t.Fatalf("expected an 8 character code, got %q", share.Code)
+	}
+
+	byCode, err := repo.GetShareLinkByCode(ctx, share.Code)
+	if err != nil {
+		t.Fatalf("get by code: %v", err)
+	}
+	if byCode.Token != share.Token || byCode.PageID != page.ID {
+		t.Fatalf("expected the code to find link %s on %s, got %+v", share.Token, page.ID, byCode)
+	}
+	for _, key := range []string{share.Code, share.Token} {
+		if _, access, err := service.ResolvePageAccess(ctx, "", page.ID, key, domain.ShareAccessView); err != nil || access != "view" {
+			t.Fatalf("expected %q to grant view access, got %q, %v", key, access, err)
+		}
+	}
+
+	// A taken code is refused by the unique index, which is what makes
+	// generation retry with a fresh one.
+	duplicate := domain.PageShareLink{
+		Token:     uuid.NewString(),
+		Code:      share.Code,
+		PageID:    page.ID,
+		Access:    domain.ShareAccessView,
+		CreatedBy: ownerID,
+		CreatedAt: now,
+	}
+	if err := repo.CreateShareLink(ctx, duplicate); !errors.Is(err, errs.ErrConflict) {
+		t.Fatalf("expected a duplicate code to conflict, got %v", err)
+	}
+	if _, err := repo.GetShareLinkByCode(ctx, "zzzzzzzz"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected an unused code not to be found, got %v", err)
+	}
+}
+
+type wallClock struct{}
+
+func (wallClock) Now() time.Time { return time.Now().UTC() }
+
+func TestCorrectReadingStatsFixesDriftUnlessPageChanged(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	page := domain.Page{ID: domain.PageID(uuid.NewString()), Title: "Drifted", CreatedAt: now, UpdatedAt: now, ReadingStats: domain.ReadingStats{WordCount: 40, ReadingMinutes: 1}}
+	if err := repo.Create(ctx, page); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePage(context.Background(), page.ID) })
+	stored, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	if _, fixed, err := repo.CorrectReadingStats(ctx, page.ID, stored.UpdatedAt.Add(-time.Second), domain.ReadingStats{}); err != nil || fixed {
+		t.Fatalf("expected a page changed since it was read to be left alone, got fixed=%v err=%v", fixed, err)
+	}
+	previous, fixed, err := repo.CorrectReadingStats(ctx, page.ID, stored.UpdatedAt, domain.ReadingStats{})
+	if err != nil || !fixed {
+		t.Fatalf("expected drifted stats corrected, got fixed=%v err=%v", fixed, err)
+	}
+	if previous.WordCount != 40 {
+		t.Fatalf("expected the replaced word count returned, got %d", previous.WordCount)
+	}
+	corrected, err := repo.GetByID(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get corrected: %v", err)
+	}
+	if corrected.WordCount != 0 || corrected.ReadingMinutes != 0 {
+		t.Fatalf("expected stats recomputed, got %+v", corrected.ReadingStats)
+	}
+	if _, fixed, err := repo.CorrectReadingStats(ctx, page.ID, stored.UpdatedAt, domain.ReadingStats{}); err != nil || fixed {
+		t.Fatalf("expected matching stats left alone, got fixed=%v err=%v", fixed, err)
+	}
+}
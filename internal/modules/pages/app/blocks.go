@@ -0,0 +1,118 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+// validateBlocks rejects block payloads the renderers and media cleanup
+// cannot interpret, positions that are negative or too large to store and,
+// in strict mode, blocks of unregistered types.
+func (service *Service) validateBlocks(blocks []domain.Block) error {
+	for _, block := range blocks {
+		if block.Position < 0 {
+			return fmt.Errorf("%w: block %s position %d is negative", errs.ErrInvalidInput, block.ID, block.Position)
+		}
+		if block.Position > domain.MaxBlockPosition {
+			return fmt.Errorf("%w: block %s position %d exceeds %d", errs.ErrInvalidInput, block.ID, block.Position, domain.MaxBlockPosition)
+		}
+		if service.strictBlockTypes && !block.Type.Known() && !service.extraBlockTypes[block.Type] {
+			return fmt.Errorf("%w: block %s has unknown type %q", errs.ErrInvalidInput, block.ID, block.Type)
+		}
+		if block.Type == domain.BlockTypeGallery {
+			if err := validateGalleryItems(block); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateGalleryItems(block domain.Block) error {
+	var data struct {
+		Items []struct {
+			Kind domain.GalleryItemKind `json:"kind"`
+		} `json:"items"`
+	}
+	if len(block.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(block.Data, &data); err != nil {
+		return fmt.Errorf("%w: gallery block %s has malformed data", errs.ErrInvalidInput, block.ID)
+	}
+	for i, item := range data.Items {
+		if !item.Kind.Valid() {
+			return fmt.Errorf("%w: gallery block %s item %d has unknown kind %q", errs.ErrInvalidInput, block.ID, i, item.Kind)
+		}
+	}
+	return nil
+}
+
+// hasContent reports whether any block carries something to read or see:
+// non-blank text in a textual block, or data in any other block but a
+// divider.
+func hasContent(blocks []domain.Block) bool {
+	for _, block := range blocks {
+		if block.Type == domain.BlockTypeDivider {
+			continue
+		}
+		if !textualBlockTypes[block.Type] {
+			data := bytes.TrimSpace(block.Data)
+			if len(data) > 0 && !bytes.Equal(data, []byte("{}")) && !bytes.Equal(data, []byte("null")) {
+				return true
+			}
+			continue
+		}
+		var data struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(block.Data, &data); err == nil && strings.TrimSpace(data.Text) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// wordsPerMinute is the reading speed behind reading-time estimates.
+const wordsPerMinute = 220
+
+// textualBlockTypes are the block types whose "text" field is read as prose.
+var textualBlockTypes = map[domain.BlockType]bool{
+	domain.BlockTypeParagraph: true,
+	domain.BlockTypeHeading:   true,
+	domain.BlockTypeHeading2:  true,
+	domain.BlockTypeHeading3:  true,
+	domain.BlockTypeBullet:    true,
+	domain.BlockTypeNumbered:  true,
+	domain.BlockTypeQuote:     true,
+	domain.BlockTypeCode:      true,
+}
+
+// EstimateReadingStats counts the words in textual blocks and estimates the
+// minutes needed to read them, rounding up. Blocks whose data has no string
+// "text" field are skipped.
+func EstimateReadingStats(blocks []domain.Block) (words int, minutes int) {
+	for _, block := range blocks {
+		if !textualBlockTypes[block.Type] || len(block.Data) == 0 {
+			continue
+		}
+		var data struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(block.Data, &data); err != nil {
+			continue
+		}
+		words += len(strings.Fields(data.Text))
+	}
+	return words, (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+func readingStats(blocks []domain.Block) domain.ReadingStats {
+	words, minutes := EstimateReadingStats(blocks)
+	return domain.ReadingStats{WordCount: words, ReadingMinutes: minutes}
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+// Bookmark saves a page to userID's bookmarks. Published pages can be saved
+// by anyone; other pages need access as resolved by ResolvePageAccess.
+// Bookmarking a page twice keeps the original bookmark.
+func (service *Service) Bookmark(ctx context.Context, userID string, pageID domain.PageID) error {
+	if userID == "" {
+		return errs.ErrForbidden
+	}
+	if _, err := service.GetPublicPage(ctx, pageID); err != nil {
+		if _, _, err := service.ResolvePageAccess(ctx, userID, pageID, "", domain.ShareAccessView); err != nil {
+			return err
+		}
+	}
+	if err := service.repo.AddBookmark(ctx, userID, pageID, service.clock.Now()); err != nil {
+		return fmt.Errorf("add bookmark: %w", err)
+	}
+	return nil
+}
+
+// Unbookmark removes a page from userID's bookmarks. Removing a page that
+// was not bookmarked is a no-op.
+func (service *Service) Unbookmark(ctx context.Context, userID string, pageID domain.PageID) error {
+	if userID == "" {
+		return errs.ErrForbidden
+	}
+	if pageID == "" {
+		return errs.ErrInvalidInput
+	}
+	if err := service.repo.RemoveBookmark(ctx, userID, pageID); err != nil {
+		return fmt.Errorf("remove bookmark: %w", err)
+	}
+	return nil
+}
+
+// ListBookmarks returns one window of userID's bookmarked pages, most
+// recently bookmarked first. nextOffset is nil when there are no further
+// pages.
+func (service *Service) ListBookmarks(ctx context.Context, userID string, limit, offset int) ([]domain.BookmarkedPage, *int, error) {
+	if userID == "" {
+		return nil, nil, errs.ErrForbidden
+	}
+	if limit <= 0 {
+		limit = defaultPageListLimit
+	}
+	if limit > maxPageListLimit {
+		limit = maxPageListLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	pages, err := service.repo.ListBookmarks(ctx, userID, limit+1, offset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list bookmarks: %w", err)
+	}
+	if len(pages) <= limit {
+		return pages, nil, nil
+	}
+	next := offset + limit
+	return pages[:limit], &next, nil
+}
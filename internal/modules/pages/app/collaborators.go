@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+var errCollaboratorIsOwner = fmt.Errorf("%w: the owner cannot be a collaborator", errs.ErrInvalidInput)
+
+// AddCollaborator gives the user with username view or edit access to the
+// owner's page without a share link. Adding an existing collaborator changes
+// their access.
+func (service *Service) AddCollaborator(ctx context.Context, ownerID string, pageID domain.PageID, username string, access domain.ShareAccess) (string, error) {
+	username = strings.TrimSpace(username)
+	if pageID == "" || username == "" {
+		return "", errs.ErrInvalidInput
+	}
+	if access != domain.ShareAccessView && access != domain.ShareAccessEdit {
+		return "", fmt.Errorf("%w: access must be view or edit", errs.ErrInvalidInput)
+	}
+	if service.users == nil {
+		return "", errUsersUnconfigured
+	}
+	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
+		return "", err
+	}
+	userID, err := service.users.UserIDByUsername(ctx, username)
+	if errors.Is(err, errs.ErrNotFound) {
+		return "", errUnknownUsername
+	}
+	if err != nil {
+		return "", fmt.Errorf("resolve collaborator: %w", err)
+	}
+	if userID == ownerID {
+		return "", errCollaboratorIsOwner
+	}
+	if err := service.repo.AddCollaborator(ctx, pageID, userID, access); err != nil {
+		return "", fmt.Errorf("add collaborator: %w", err)
+	}
+	return userID, nil
+}
+
+// RemoveCollaborator takes away userID's access to the owner's page. Share
+// links they hold keep working until revoked.
+func (service *Service) RemoveCollaborator(ctx context.Context, ownerID string, pageID domain.PageID, userID string) error {
+	if pageID == "" || userID == "" {
+		return errs.ErrInvalidInput
+	}
+	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
+		return err
+	}
+	if err := service.repo.RemoveCollaborator(ctx, pageID, userID); err != nil {
+		return fmt.Errorf("remove collaborator: %w", err)
+	}
+	return nil
+}
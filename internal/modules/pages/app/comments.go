@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+const (
+	maxCommentLength    = 2000
+	maxCommentAuthorLen = 80
+)
+
+var (
+	errCommentTooLong       = fmt.Errorf("%w: comment must be at most %d characters", errs.ErrInvalidInput, maxCommentLength)
+	errCommentAuthorTooLong = fmt.Errorf("%w: author name must be at most %d characters", errs.ErrInvalidInput, maxCommentAuthorLen)
+)
+
+// CreateComment adds a comment to a published page, or a reply when parentID
+// is set. Anonymous comments, with an empty userID, need an author name.
+func (service *Service) CreateComment(ctx context.Context, pageID domain.PageID, userID, authorName, body string, parentID *domain.CommentID) (domain.Comment, error) {
+	authorName = strings.TrimSpace(authorName)
+	body = strings.TrimSpace(body)
+	if pageID == "" || body == "" || (userID == "" && authorName == "") {
+		return domain.Comment{}, errs.ErrInvalidInput
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		return domain.Comment{}, errCommentTooLong
+	}
+	if utf8.RuneCountInString(authorName) > maxCommentAuthorLen {
+		return domain.Comment{}, errCommentAuthorTooLong
+	}
+	if _, err := service.GetPublicPage(ctx, pageID); err != nil {
+		return domain.Comment{}, err
+	}
+	if parentID != nil {
+		parent, err := service.repo.GetComment(ctx, *parentID)
+		if err != nil {
+			return domain.Comment{}, fmt.Errorf("get parent comment: %w", err)
+		}
+		if parent.PageID != pageID {
+			return domain.Comment{}, fmt.Errorf("%w: parent comment is on another page", errs.ErrInvalidInput)
+		}
+	}
+
+	comment := domain.Comment{
+		ID:         domain.CommentID(uuid.NewString()),
+		PageID:     pageID,
+		ParentID:   parentID,
+		AuthorName: authorName,
+		Body:       body,
+		CreatedAt:  service.clock.Now(),
+	}
+	if userID != "" {
+		comment.UserID = &userID
+	}
+	if err := service.repo.CreateComment(ctx, comment); err != nil {
+		return domain.Comment{}, fmt.Errorf("create comment: %w", err)
+	}
+	return comment, nil
+}
+
+// ListComments returns a published page's comments as threads: top-level
+// comments oldest first, each with its replies nested oldest first.
+func (service *Service) ListComments(ctx context.Context, pageID domain.PageID) ([]domain.Comment, error) {
+	if pageID == "" {
+		return nil, errs.ErrInvalidInput
+	}
+	if _, err := service.GetPublicPage(ctx, pageID); err != nil {
+		return nil, err
+	}
+	comments, err := service.repo.ListComments(ctx, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+	return threadComments(comments), nil
+}
+
+// threadComments nests comments, which must be sorted oldest first, under
+// their parents. Replies to missing parents are dropped.
+func threadComments(comments []domain.Comment) []domain.Comment {
+	children := make(map[domain.CommentID][]domain.Comment)
+	var roots []domain.Comment
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			roots = append(roots, comment)
+			continue
+		}
+		children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+	}
+	var attach func(comments []domain.Comment) []domain.Comment
+	attach = func(comments []domain.Comment) []domain.Comment {
+		for i := range comments {
+			comments[i].Replies = attach(children[comments[i].ID])
+		}
+		return comments
+	}
+	if roots == nil {
+		return []domain.Comment{}
+	}
+	return attach(roots)
+}
+
+// DeleteComment removes a comment and its replies. Only the comment's author
+// and the page's owner may delete it.
+func (service *Service) DeleteComment(ctx context.Context, requesterID string, pageID domain.PageID, commentID domain.CommentID) error {
+	if requesterID == "" {
+		return errs.ErrForbidden
+	}
+	if pageID == "" || commentID == "" {
+		return errs.ErrInvalidInput
+	}
+	comment, err := service.repo.GetComment(ctx, commentID)
+	if err != nil {
+		return fmt.Errorf("get comment: %w", err)
+	}
+	if comment.PageID != pageID {
+		return errs.ErrNotFound
+	}
+	if comment.UserID == nil || *comment.UserID != requesterID {
+		if err := service.checkOwnership(ctx, pageID, requesterID); err != nil {
+			return err
+		}
+	}
+	if err := service.repo.DeleteComment(ctx, commentID); err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	return nil
+}
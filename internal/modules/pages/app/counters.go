@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"go.uber.org/zap"
+)
+
+const defaultReconcileBatchSize = 200
+
+// ReconcileCounters recomputes the denormalized page counters from their
+// source rows, batchSize pages at a time, and corrects any that drifted: each
+// page's word count and reading time, and the site-wide read total. It
+// returns how many counters it corrected.
+func (service *Service) ReconcileCounters(ctx context.Context, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = defaultReconcileBatchSize
+	}
+
+	corrected := 0
+	var after domain.PageID
+	for {
+		pageIDs, err := service.repo.ListPageIDsAfter(ctx, after, batchSize)
+		if err != nil {
+			return corrected, err
+		}
+		for _, pageID := range pageIDs {
+			fixed, err := service.reconcileReadingStats(ctx, pageID)
+			if err != nil {
+				return corrected, err
+			}
+			if fixed {
+				corrected++
+			}
+		}
+		if len(pageIDs) < batchSize {
+			break
+		}
+		after = pageIDs[len(pageIDs)-1]
+	}
+
+	previous, actual, err := service.repo.ReconcileReadCounter(ctx)
+	if err != nil {
+		return corrected, err
+	}
+	if previous != actual {
+		service.logger.Info("corrected read counter", zap.Int64("from", previous), zap.Int64("to", actual))
+		corrected++
+	}
+	return corrected, nil
+}
+
+func (service *Service) reconcileReadingStats(ctx context.Context, pageID domain.PageID) (bool, error) {
+	page, err := service.repo.GetByID(ctx, pageID)
+	if err != nil {
+		return false, fmt.Errorf("load page %s: %w", pageID, err)
+	}
+	stats := readingStats(page.Blocks)
+	previous, fixed, err := service.repo.CorrectReadingStats(ctx, pageID, page.UpdatedAt, stats)
+	if err != nil {
+		return false, err
+	}
+	if fixed {
+		service.logger.Info("corrected page reading stats",
+			zap.String("page_id", string(pageID)),
+			zap.Int("from_words", previous.WordCount),
+			zap.Int("to_words", stats.WordCount),
+			zap.Int("from_minutes", previous.ReadingMinutes),
+			zap.Int("to_minutes", stats.ReadingMinutes),
+		)
+	}
+	return fixed, nil
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+// LikePage records userID's like on a published page and returns the page's
+// like count. Liking a page twice is a no-op.
+func (service *Service) LikePage(ctx context.Context, userID string, pageID domain.PageID) (int, error) {
+	if userID == "" {
+		return 0, errs.ErrForbidden
+	}
+	if _, err := service.GetPublicPage(ctx, pageID); err != nil {
+		return 0, err
+	}
+	if err := service.repo.LikePage(ctx, pageID, userID); err != nil {
+		return 0, fmt.Errorf("like page: %w", err)
+	}
+	return service.countLikes(ctx, pageID)
+}
+
+// UnlikePage removes userID's like and returns the page's like count.
+// Unliking a page that was not liked is a no-op.
+func (service *Service) UnlikePage(ctx context.Context, userID string, pageID domain.PageID) (int, error) {
+	if userID == "" {
+		return 0, errs.ErrForbidden
+	}
+	if pageID == "" {
+		return 0, errs.ErrInvalidInput
+	}
+	if err := service.repo.UnlikePage(ctx, pageID, userID); err != nil {
+		return 0, fmt.Errorf("unlike page: %w", err)
+	}
+	return service.countLikes(ctx, pageID)
+}
+
+func (service *Service) countLikes(ctx context.Context, pageID domain.PageID) (int, error) {
+	count, err := service.repo.CountLikes(ctx, pageID)
+	if err != nil {
+		return 0, fmt.Errorf("count page likes: %w", err)
+	}
+	return count, nil
+}
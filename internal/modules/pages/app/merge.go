@@ -0,0 +1,120 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+// mergeConflictingBlocks retries a block update rejected as stale by merging
+// it into the current page. The client's base is the latest revision at or
+// before baseUpdatedAt; when no such revision exists or both sides changed the
+// same block differently the conflict stands.
+func (service *Service) mergeConflictingBlocks(ctx context.Context, pageID domain.PageID, blocks []domain.Block, baseUpdatedAt time.Time) error {
+	base, err := service.repo.GetRevisionAt(ctx, pageID, baseUpdatedAt)
+	if err != nil {
+		return errs.ErrConflict
+	}
+	current, err := service.repo.GetByID(ctx, pageID)
+	if err != nil {
+		return fmt.Errorf("fetch current page: %w", err)
+	}
+	merged, ok := mergeBlocks(base.Blocks, current.Blocks, blocks)
+	if !ok {
+		return errs.ErrConflict
+	}
+	stats := readingStats(merged)
+	return service.repo.UpdateBlocksOptimistic(ctx, pageID, merged, &stats, &current.UpdatedAt)
+}
+
+// mergeBlocks applies the changes client made to base on top of server,
+// matching blocks by ID. Blocks added by the client are placed after the
+// block that precedes them in the client's list; otherwise the server's
+// order wins. ok is false when both sides changed a block differently.
+func mergeBlocks(base, server, client []domain.Block) ([]domain.Block, bool) {
+	baseByID := blocksByID(base)
+	serverByID := blocksByID(server)
+	clientByID := blocksByID(client)
+
+	clientChanged := make(map[string]bool)
+	for id, block := range baseByID {
+		if changed, ok := clientByID[id]; !ok || !sameBlock(block, changed) {
+			clientChanged[id] = true
+		}
+	}
+	for id := range clientChanged {
+		serverBlock, onServer := serverByID[id]
+		if onServer && sameBlock(serverBlock, baseByID[id]) {
+			continue
+		}
+		clientBlock, onClient := clientByID[id]
+		if onServer != onClient || (onServer && !sameBlock(serverBlock, clientBlock)) {
+			return nil, false
+		}
+	}
+
+	merged := make([]domain.Block, 0, len(server)+len(client))
+	for _, block := range server {
+		if !clientChanged[block.ID] {
+			merged = append(merged, block)
+			continue
+		}
+		if changed, ok := clientByID[block.ID]; ok {
+			merged = append(merged, changed)
+		}
+	}
+
+	insertAt := 0
+	for _, block := range client {
+		_, inBase := baseByID[block.ID]
+		_, onServer := serverByID[block.ID]
+		if block.ID == "" || (!inBase && !onServer) {
+			merged = slices.Insert(merged, insertAt, block)
+			insertAt++
+			continue
+		}
+		if index := slices.IndexFunc(merged, func(candidate domain.Block) bool { return candidate.ID == block.ID }); index >= 0 {
+			insertAt = index + 1
+		}
+	}
+
+	for index := range merged {
+		merged[index].Position = index
+	}
+	return merged, true
+}
+
+func blocksByID(blocks []domain.Block) map[string]domain.Block {
+	byID := make(map[string]domain.Block, len(blocks))
+	for _, block := range blocks {
+		if block.ID != "" {
+			byID[block.ID] = block
+		}
+	}
+	return byID
+}
+
+// sameBlock compares content, ignoring position.
+func sameBlock(a, b domain.Block) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	if (a.ParentID == nil) != (b.ParentID == nil) || (a.ParentID != nil && *a.ParentID != *b.ParentID) {
+		return false
+	}
+	return bytes.Equal(compactJSON(a.Data), compactJSON(b.Data))
+}
+
+func compactJSON(data json.RawMessage) []byte {
+	var buffer bytes.Buffer
+	if err := json.Compact(&buffer, data); err != nil {
+		return data
+	}
+	return buffer.Bytes()
+}
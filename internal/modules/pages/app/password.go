@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/modules/pages/ports"
+	"github.com/reggieanim/jot/internal/shared/errs"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	pagePasswordCost = 12
+	// How long an unlock token spares a reader the password check.
+	pageUnlockTTL = time.Hour
+)
+
+var (
+	// ErrPagePasswordRequired is returned when a protected page is requested
+	// without a password.
+	ErrPagePasswordRequired = fmt.Errorf("%w: page password required", errs.ErrUnauthorized)
+	// ErrIncorrectPagePassword is returned when the supplied password does not
+	// match.
+	ErrIncorrectPagePassword = fmt.Errorf("%w: incorrect page password", errs.ErrUnauthorized)
+	errPagePasswordTooLong   = fmt.Errorf("%w: page password must be at most 72 bytes", errs.ErrInvalidInput)
+)
+
+// SetPagePassword protects the owner's page with password, or removes the
+// protection when password is empty.
+func (service *Service) SetPagePassword(ctx context.Context, ownerID string, pageID domain.PageID, password string) error {
+	if pageID == "" {
+		return errs.ErrInvalidInput
+	}
+	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
+		return err
+	}
+	change, err := newPasswordChange(&password)
+	if err != nil {
+		return err
+	}
+	if err := service.repo.SetPasswordHash(ctx, pageID, change.Hash); err != nil {
+		return fmt.Errorf("set page password: %w", err)
+	}
+	return nil
+}
+
+// newPasswordChange hashes password for storage; an empty password removes
+// the protection and a nil one changes nothing.
+func newPasswordChange(password *string) (*ports.PasswordChange, error) {
+	if password == nil {
+		return nil, nil
+	}
+	if *password == "" {
+		return &ports.PasswordChange{}, nil
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(*password), pagePasswordCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return nil, errPagePasswordTooLong
+	}
+	if err != nil {
+		return nil, fmt.Errorf("hash page password: %w", err)
+	}
+	hash := string(hashed)
+	return &ports.PasswordChange{Hash: &hash}, nil
+}
+
+// UnlockPage returns nil when pageID has no password or password matches
+// it. For a protected page it also returns an unlock token that
+// VerifyPageUnlock accepts in place of the password until the token expires
+// or the password changes, so readers pay for one bcrypt compare, not one
+// per read.
+func (service *Service) UnlockPage(ctx context.Context, pageID domain.PageID, password string) (string, error) {
+	if pageID == "" {
+		return "", errs.ErrInvalidInput
+	}
+	hash, err := service.repo.GetPasswordHash(ctx, pageID)
+	if err != nil {
+		return "", fmt.Errorf("get page password: %w", err)
+	}
+	if hash == "" {
+		return "", nil
+	}
+	if password == "" {
+		return "", ErrPagePasswordRequired
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return "", ErrIncorrectPagePassword
+	}
+	return service.signPageUnlock(pageID, hash, service.clock.Now().Add(pageUnlockTTL)), nil
+}
+
+// VerifyPageUnlock reports whether token is a live unlock token for pageID's
+// current password.
+func (service *Service) VerifyPageUnlock(ctx context.Context, pageID domain.PageID, token string) bool {
+	encodedExpiry, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	rawExpiry, err := base64.RawURLEncoding.DecodeString(encodedExpiry)
+	if err != nil || len(rawExpiry) != 8 {
+		return false
+	}
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(rawExpiry)), 0)
+	if !service.clock.Now().Before(expiresAt) {
+		return false
+	}
+	hash, err := service.repo.GetPasswordHash(ctx, pageID)
+	if err != nil || hash == "" {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(service.signPageUnlock(pageID, hash, expiresAt)))
+}
+
+// signPageUnlock binds a token to the page, its password hash and an expiry.
+func (service *Service) signPageUnlock(pageID domain.PageID, hash string, expiresAt time.Time) string {
+	expiry := make([]byte, 8)
+	binary.BigEndian.PutUint64(expiry, uint64(expiresAt.Unix()))
+	mac := hmac.New(sha256.New, service.unlockKey)
+	mac.Write(expiry)
+	mac.Write([]byte(pageID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(hash))
+	return base64.RawURLEncoding.EncodeToString(expiry) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newUnlockKey returns a per-process signing key. Tokens do not survive a
+// restart or carry across instances; readers then fall back to the password.
+func newUnlockKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("generate page unlock key: %v", err))
+	}
+	return key
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+var errAlreadyPublished = fmt.Errorf("%w: page is already published", errs.ErrConflict)
+
+// SchedulePagePublish keeps the owner's page unpublished until publishAt.
+// A publishAt that is not in the future publishes the page right away.
+func (service *Service) SchedulePagePublish(ctx context.Context, ownerID string, pageID domain.PageID, publishAt time.Time, unlisted *bool) (domain.Page, error) {
+	return service.SchedulePagePublishWithPassword(ctx, ownerID, pageID, publishAt, unlisted, nil)
+}
+
+// SchedulePagePublishWithPassword also sets the page password unless
+// password is nil, only if the schedule is saved.
+func (service *Service) SchedulePagePublishWithPassword(ctx context.Context, ownerID string, pageID domain.PageID, publishAt time.Time, unlisted *bool, password *string) (domain.Page, error) {
+	if pageID == "" || publishAt.IsZero() {
+		return domain.Page{}, errs.ErrInvalidInput
+	}
+	if !publishAt.After(service.clock.Now()) {
+		return service.SetPagePublishedWithPassword(ctx, ownerID, pageID, true, unlisted, password)
+	}
+	passwordChange, err := newPasswordChange(password)
+	if err != nil {
+		return domain.Page{}, err
+	}
+	current, err := service.ownedPage(ctx, pageID, ownerID)
+	if err != nil {
+		return domain.Page{}, err
+	}
+	if current.Published {
+		return domain.Page{}, errAlreadyPublished
+	}
+	nextUnlisted := current.Unlisted
+	if unlisted != nil {
+		nextUnlisted = *unlisted
+	}
+	if err := service.repo.SchedulePublish(ctx, pageID, publishAt.UTC(), nextUnlisted, passwordChange); err != nil {
+		return domain.Page{}, fmt.Errorf("schedule publish: %w", err)
+	}
+	page, err := service.repo.GetByID(ctx, pageID)
+	if err != nil {
+		return domain.Page{}, fmt.Errorf("fetch scheduled page: %w", err)
+	}
+	return page, nil
+}
+
+// PublishDuePages publishes the pages whose scheduled time has come, giving
+// them slugs and emitting PagePublished events, and returns how many were
+// published. The publish rate limit applies as each page falls due: pages
+// over their owner's limit stay scheduled and are retried on a later call.
+// Pages stay published when a slug or event fails.
+func (service *Service) PublishDuePages(ctx context.Context) (int, error) {
+	now := service.clock.Now()
+	due, err := service.repo.ListDuePages(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("list due pages: %w", err)
+	}
+	published := 0
+	var failures []error
+	for _, page := range due {
+		if page.OwnerID != nil && page.FirstPublishedAt == nil {
+			err := service.checkPublishRate(ctx, *page.OwnerID)
+			if errors.Is(err, errs.ErrRateLimited) {
+				continue
+			}
+			if err != nil {
+				failures = append(failures, fmt.Errorf("check publish rate for %s: %w", page.ID, err))
+				continue
+			}
+		}
+		ok, err := service.repo.PublishScheduled(ctx, page.ID, now)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("publish %s: %w", page.ID, err))
+			continue
+		}
+		if !ok {
+			// Rescheduled, published or archived since it was listed.
+			continue
+		}
+		published++
+		if page.Slug == "" {
+			if err := service.assignSlug(ctx, page); err != nil {
+				failures = append(failures, fmt.Errorf("assign slug to %s: %w", page.ID, err))
+			}
+		}
+		current, err := service.repo.GetByID(ctx, page.ID)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("fetch published page %s: %w", page.ID, err))
+			continue
+		}
+		if err := service.events.PagePublished(ctx, current); err != nil {
+			failures = append(failures, fmt.Errorf("publish page published %s: %w", page.ID, err))
+		}
+	}
+	return published, errors.Join(failures...)
+}
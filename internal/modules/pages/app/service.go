@@ -2,7 +2,10 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"time"
 
@@ -10,24 +13,186 @@ import (
 	"github.com/reggieanim/jot/internal/modules/pages/domain"
 	"github.com/reggieanim/jot/internal/modules/pages/ports"
 	"github.com/reggieanim/jot/internal/shared/errs"
+	"go.uber.org/zap"
 )
 
 type Clock interface {
 	Now() time.Time
 }
 
+const (
+	// shareCodeAlphabet omits look-alike characters (0/O, 1/l/I) so codes
+	// survive being read aloud or retyped.
+	shareCodeAlphabet    = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789"
+	maxShareCodeAttempts = 5
+
+	defaultPageListLimit = 30
+	maxPageListLimit     = 100
+
+	maxPageBatchSize = 100
+
+	minAnonymousTitleLength = 3
+
+	// Reads are bucketed per UTC day, so a one-day window covers today and
+	// yesterday: the last 24 to 48 hours.
+	trendingWindow   = 24 * time.Hour
+	maxTrendingPages = 20
+)
+
+var (
+	errAnonymousTitleTooShort = fmt.Errorf("%w: title must have at least %d letters or digits", errs.ErrInvalidInput, minAnonymousTitleLength)
+	errAnonymousPageEmpty     = fmt.Errorf("%w: page must have at least one block with content", errs.ErrInvalidInput)
+)
+
+var ErrAnonymousPageShare = fmt.Errorf("%w: anonymous pages are public and cannot have share links", errs.ErrInvalidInput)
+
 type Service struct {
-	repo   ports.PageRepository
-	events ports.PageEvents
-	clock  Clock
+	repo            ports.PageRepository
+	events          ports.PageEvents
+	clock           Clock
+	shareCodeLength int
+	newShareCode    func(length int) (string, error)
+	revisionLimit   int
+	publishLimit    int
+	publishWindow   time.Duration
+	hidePrivate     bool
+	shareLinkTTL    time.Duration
+	unlockKey       []byte
+
+	strictBlockTypes bool
+	extraBlockTypes  map[domain.BlockType]bool
+	mergeConflicts   bool
+	normalizeBlocks  bool
+
+	anonymousUnlisted bool
+	regenerateSlugs   bool
+
+	users UserResolver
+
+	logger *zap.Logger
+}
+
+type UserResolver interface {
+	UserIDByUsername(ctx context.Context, username string) (string, error)
+}
+
+type UserResolverFunc func(ctx context.Context, username string) (string, error)
+
+func (fn UserResolverFunc) UserIDByUsername(ctx context.Context, username string) (string, error) {
+	return fn(ctx, username)
+}
+
+type Option func(*Service)
+
+func WithUserResolver(users UserResolver) Option {
+	return func(service *Service) {
+		service.users = users
+	}
+}
+
+// WithShareCodeLength of zero disables short codes.
+func WithShareCodeLength(length int) Option {
+	return func(service *Service) {
+		if length < 0 {
+			length = 0
+		}
+		service.shareCodeLength = length
+	}
+}
+
+// WithShareLinkTTL of zero creates links that never expire.
+func WithShareLinkTTL(ttl time.Duration) Option {
+	return func(service *Service) {
+		if ttl < 0 {
+			ttl = 0
+		}
+		service.shareLinkTTL = ttl
+	}
 }
 
-func NewService(repo ports.PageRepository, events ports.PageEvents, clock Clock) *Service {
-	return &Service{repo: repo, events: events, clock: clock}
+// WithBlockTypes in strict mode rejects writes with unregistered block types.
+func WithBlockTypes(strict bool, extra string) Option {
+	return func(service *Service) {
+		service.strictBlockTypes = strict
+		service.extraBlockTypes = make(map[domain.BlockType]bool)
+		for _, blockType := range strings.Split(extra, ",") {
+			if blockType = strings.TrimSpace(blockType); blockType != "" {
+				service.extraBlockTypes[domain.BlockType(blockType)] = true
+			}
+		}
+	}
+}
+
+// WithRevisionRetention of zero keeps every revision.
+func WithRevisionRetention(keep int) Option {
+	return func(service *Service) {
+		if keep < 0 {
+			keep = 0
+		}
+		service.revisionLimit = keep
+	}
+}
+
+func WithConflictMerge(enabled bool) Option {
+	return func(service *Service) {
+		service.mergeConflicts = enabled
+	}
+}
+
+func WithAnonymousPagesUnlisted(unlisted bool) Option {
+	return func(service *Service) {
+		service.anonymousUnlisted = unlisted
+	}
+}
+
+func WithBlockNormalization(enabled bool) Option {
+	return func(service *Service) {
+		service.normalizeBlocks = enabled
+	}
+}
+
+// Without WithSlugRegeneration a page keeps its first slug, so shared links
+// keep working after a rename.
+func WithSlugRegeneration(enabled bool) Option {
+	return func(service *Service) {
+		service.regenerateSlugs = enabled
+	}
+}
+
+// WithPublishRateLimit only counts first publishes; zero disables it.
+func WithPublishRateLimit(limit int, window time.Duration) Option {
+	return func(service *Service) {
+		if limit < 0 || window <= 0 {
+			limit = 0
+		}
+		service.publishLimit = limit
+		service.publishWindow = window
+	}
+}
+
+// WithPrivatePagesHidden still answers forbidden for a view link used to edit.
+func WithPrivatePagesHidden(enabled bool) Option {
+	return func(service *Service) {
+		service.hidePrivate = enabled
+	}
+}
+
+func WithLogger(logger *zap.Logger) Option {
+	return func(service *Service) {
+		service.logger = logger
+	}
+}
+
+func NewService(repo ports.PageRepository, events ports.PageEvents, clock Clock, opts ...Option) *Service {
+	service := &Service{repo: repo, events: events, clock: clock, newShareCode: randomShareCode, logger: zap.NewNop(), unlockKey: newUnlockKey()}
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service
 }
 
 func (service *Service) CreatePage(ctx context.Context, ownerID string, title string, cover *string, blocks []domain.Block) (domain.Page, error) {
-	return service.CreatePageWithSettings(ctx, ownerID, title, cover, blocks, false, true, 65, "")
+	return service.CreatePageWithSettings(ctx, ownerID, title, cover, blocks, false, true, 65, "", nil)
 }
 
 func (service *Service) CreatePageWithSettings(
@@ -40,13 +205,16 @@ func (service *Service) CreatePageWithSettings(
 	cinematic bool,
 	mood int,
 	bgColor string,
+	tags []string,
 ) (domain.Page, error) {
 	if ownerID == "" {
 		return domain.Page{}, errs.ErrInvalidInput
 	}
-	return service.createPageWithSettings(ctx, &ownerID, title, cover, blocks, darkMode, cinematic, mood, bgColor)
+	return service.createPageWithSettings(ctx, &ownerID, title, cover, blocks, darkMode, cinematic, mood, bgColor, tags)
 }
 
+// CreateAnonymousPublishedPage needs a title with minAnonymousTitleLength
+// letters or digits and at least one block with content.
 func (service *Service) CreateAnonymousPublishedPage(
 	ctx context.Context,
 	title string,
@@ -56,20 +224,30 @@ func (service *Service) CreateAnonymousPublishedPage(
 	cinematic bool,
 	mood int,
 	bgColor string,
+	tags []string,
 ) (domain.Page, error) {
-	created, err := service.createPageWithSettings(ctx, nil, title, cover, blocks, darkMode, cinematic, mood, bgColor)
+	if countAlphanumeric(title) < minAnonymousTitleLength {
+		return domain.Page{}, errAnonymousTitleTooShort
+	}
+	if err := service.validateBlocks(blocks); err != nil {
+		return domain.Page{}, err
+	}
+	if !hasContent(blocks) {
+		return domain.Page{}, errAnonymousPageEmpty
+	}
+	created, err := service.createPageWithSettings(ctx, nil, title, cover, blocks, darkMode, cinematic, mood, bgColor, tags)
 	if err != nil {
 		return domain.Page{}, err
 	}
-	if err := service.repo.SetPublished(ctx, created.ID, true, false); err != nil {
+	if err := service.repo.SetPublished(ctx, created.ID, true, service.anonymousUnlisted, nil); err != nil {
 		return domain.Page{}, fmt.Errorf("set anonymous page published: %w", err)
 	}
 	published, err := service.repo.GetByID(ctx, created.ID)
 	if err != nil {
 		return domain.Page{}, fmt.Errorf("fetch anonymous published page: %w", err)
 	}
-	if err := service.events.BlocksUpdated(ctx, published); err != nil {
-		return domain.Page{}, fmt.Errorf("publish anonymous page updated: %w", err)
+	if err := service.events.PagePublished(ctx, published); err != nil {
+		return domain.Page{}, fmt.Errorf("publish anonymous page published: %w", err)
 	}
 	return published, nil
 }
@@ -84,10 +262,21 @@ func (service *Service) createPageWithSettings(
 	cinematic bool,
 	mood int,
 	bgColor string,
+	tags []string,
 ) (domain.Page, error) {
 	if title == "" {
 		return domain.Page{}, errs.ErrInvalidInput
 	}
+	if err := service.validateBlocks(blocks); err != nil {
+		return domain.Page{}, err
+	}
+	if service.normalizeBlocks {
+		blocks = domain.NormalizeBlocks(blocks)
+	}
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return domain.Page{}, err
+	}
 	if mood < 0 {
 		mood = 0
 	}
@@ -106,8 +295,11 @@ func (service *Service) createPageWithSettings(
 		Mood:      mood,
 		BgColor:   bgColor,
 		Blocks:    blocks,
+		Tags:      tags,
 		CreatedAt: now,
 		UpdatedAt: now,
+
+		ReadingStats: readingStats(blocks),
 	}
 	if err := service.repo.Create(ctx, page); err != nil {
 		return domain.Page{}, fmt.Errorf("create page: %w", err)
@@ -122,6 +314,63 @@ func (service *Service) createPageWithSettings(
 	return persisted, nil
 }
 
+func (service *Service) ClonePage(ctx context.Context, ownerID string, pageID domain.PageID) (domain.Page, error) {
+	return service.ClonePageWithShare(ctx, ownerID, pageID, "")
+}
+
+// ClonePageWithShare keeps media URLs as shared references.
+func (service *Service) ClonePageWithShare(ctx context.Context, actorID string, pageID domain.PageID, shareToken string) (domain.Page, error) {
+	if actorID == "" || pageID == "" {
+		return domain.Page{}, errs.ErrInvalidInput
+	}
+	source, _, err := service.ResolvePageAccess(ctx, actorID, pageID, shareToken, domain.ShareAccessView)
+	if err != nil {
+		return domain.Page{}, err
+	}
+	return service.createPageWithSettings(
+		ctx,
+		&actorID,
+		"Copy of "+source.Title,
+		source.Cover,
+		cloneBlocks(source.Blocks),
+		source.DarkMode,
+		source.Cinematic,
+		source.Mood,
+		source.BgColor,
+		source.Tags,
+	)
+}
+
+func cloneBlocks(blocks []domain.Block) []domain.Block {
+	ids := make(map[string]string, len(blocks))
+	for _, block := range blocks {
+		if block.ID != "" {
+			ids[block.ID] = uuid.NewString()
+		}
+	}
+	cloned := make([]domain.Block, 0, len(blocks))
+	for _, block := range blocks {
+		copied := domain.Block{
+			ID:       ids[block.ID],
+			Type:     block.Type,
+			Position: block.Position,
+			Data:     append([]byte(nil), block.Data...),
+		}
+		if copied.ID == "" {
+			copied.ID = uuid.NewString()
+		}
+		if block.ParentID != nil {
+			parentID := *block.ParentID
+			if mapped, ok := ids[parentID]; ok {
+				parentID = mapped
+			}
+			copied.ParentID = &parentID
+		}
+		cloned = append(cloned, copied)
+	}
+	return cloned
+}
+
 func (service *Service) UpdateBlocks(ctx context.Context, ownerID string, pageID domain.PageID, blocks []domain.Block) error {
 	_, err := service.UpdateBlocksRealtimeWithShare(ctx, ownerID, pageID, blocks, nil, "")
 	return err
@@ -135,31 +384,90 @@ func (service *Service) UpdateBlocksRealtimeWithShare(ctx context.Context, actor
 	if pageID == "" {
 		return domain.Page{}, errs.ErrInvalidInput
 	}
+	if err := service.validateBlocks(blocks); err != nil {
+		return domain.Page{}, err
+	}
 	if _, _, err := service.ResolvePageAccess(ctx, actorID, pageID, shareToken, domain.ShareAccessEdit); err != nil {
 		return domain.Page{}, err
 	}
-	if err := service.repo.UpdateBlocksOptimistic(ctx, pageID, blocks, expectedUpdatedAt); err != nil {
+	if service.normalizeBlocks {
+		blocks = domain.NormalizeBlocks(blocks)
+	}
+	stats := readingStats(blocks)
+	err := service.repo.UpdateBlocksOptimistic(ctx, pageID, blocks, &stats, expectedUpdatedAt)
+	if errors.Is(err, errs.ErrConflict) && service.mergeConflicts && expectedUpdatedAt != nil {
+		err = service.mergeConflictingBlocks(ctx, pageID, blocks, *expectedUpdatedAt)
+	}
+	if err != nil {
 		return domain.Page{}, fmt.Errorf("update blocks: %w", err)
 	}
 	page, err := service.repo.GetByID(ctx, pageID)
 	if err != nil {
 		return domain.Page{}, fmt.Errorf("fetch updated page: %w", err)
 	}
+	// Best-effort: history must not fail an edit that has already been committed.
+	if err := service.recordRevision(ctx, pageID, page.Blocks, actorID, page.UpdatedAt); err != nil {
+		service.logger.Warn("record page revision failed",
+			zap.String("page_id", string(pageID)),
+			zap.Error(err),
+		)
+	}
 	if err := service.events.BlocksUpdated(ctx, page); err != nil {
 		return domain.Page{}, fmt.Errorf("publish blocks updated: %w", err)
 	}
 	return page, nil
 }
 
-func (service *Service) UpdatePageMetaRealtime(ctx context.Context, ownerID string, pageID domain.PageID, title string, cover *string, darkMode bool, cinematic bool, mood int, bgColor string, expectedUpdatedAt *time.Time) (domain.Page, error) {
-	return service.UpdatePageMetaRealtimeWithShare(ctx, ownerID, pageID, title, cover, darkMode, cinematic, mood, bgColor, expectedUpdatedAt, "")
+func (service *Service) recordRevision(ctx context.Context, pageID domain.PageID, blocks []domain.Block, editorID string, pageUpdatedAt time.Time) error {
+	if err := service.repo.SaveRevision(ctx, pageID, blocks, editorID, pageUpdatedAt); err != nil {
+		return fmt.Errorf("save revision: %w", err)
+	}
+	if service.revisionLimit > 0 {
+		if err := service.repo.PruneRevisions(ctx, pageID, service.revisionLimit); err != nil {
+			return fmt.Errorf("prune revisions: %w", err)
+		}
+	}
+	return nil
+}
+
+func (service *Service) ListRevisions(ctx context.Context, ownerID string, pageID domain.PageID, limit, offset int) ([]domain.PageRevision, error) {
+	if pageID == "" {
+		return nil, errs.ErrInvalidInput
+	}
+	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
+		return nil, err
+	}
+	revisions, err := service.repo.ListRevisions(ctx, pageID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+func (service *Service) GetRevision(ctx context.Context, ownerID string, pageID domain.PageID, revisionID domain.RevisionID) (domain.PageRevision, error) {
+	if pageID == "" || revisionID == "" {
+		return domain.PageRevision{}, errs.ErrInvalidInput
+	}
+	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
+		return domain.PageRevision{}, err
+	}
+	revision, err := service.repo.GetRevision(ctx, pageID, revisionID)
+	if err != nil {
+		return domain.PageRevision{}, fmt.Errorf("get revision: %w", err)
+	}
+	return revision, nil
+}
+
+func (service *Service) UpdatePageMetaRealtime(ctx context.Context, ownerID string, pageID domain.PageID, title string, cover *string, darkMode bool, cinematic bool, mood int, bgColor string, tags []string, expectedUpdatedAt *time.Time) (domain.Page, error) {
+	return service.UpdatePageMetaRealtimeWithShare(ctx, ownerID, pageID, title, cover, darkMode, cinematic, mood, bgColor, tags, expectedUpdatedAt, "")
 }
 
-func (service *Service) UpdatePageMetaRealtimeWithShare(ctx context.Context, actorID string, pageID domain.PageID, title string, cover *string, darkMode bool, cinematic bool, mood int, bgColor string, expectedUpdatedAt *time.Time, shareToken string) (domain.Page, error) {
+func (service *Service) UpdatePageMetaRealtimeWithShare(ctx context.Context, actorID string, pageID domain.PageID, title string, cover *string, darkMode bool, cinematic bool, mood int, bgColor string, tags []string, expectedUpdatedAt *time.Time, shareToken string) (domain.Page, error) {
 	if pageID == "" || title == "" {
 		return domain.Page{}, errs.ErrInvalidInput
 	}
-	if _, _, err := service.ResolvePageAccess(ctx, actorID, pageID, shareToken, domain.ShareAccessEdit); err != nil {
+	current, _, err := service.ResolvePageAccess(ctx, actorID, pageID, shareToken, domain.ShareAccessEdit)
+	if err != nil {
 		return domain.Page{}, err
 	}
 	if mood < 0 {
@@ -168,10 +476,20 @@ func (service *Service) UpdatePageMetaRealtimeWithShare(ctx context.Context, act
 	if mood > 100 {
 		mood = 100
 	}
+	tags, err = normalizeTags(tags)
+	if err != nil {
+		return domain.Page{}, err
+	}
 
-	if err := service.repo.UpdatePageMetaOptimistic(ctx, pageID, title, cover, darkMode, cinematic, mood, bgColor, expectedUpdatedAt); err != nil {
+	if err := service.repo.UpdatePageMetaOptimistic(ctx, pageID, title, cover, darkMode, cinematic, mood, bgColor, tags, expectedUpdatedAt); err != nil {
 		return domain.Page{}, fmt.Errorf("update page meta: %w", err)
 	}
+	if service.regenerateSlugs && current.Slug != "" && !slugMatchesBase(current.Slug, slugifyTitle(title)) {
+		current.Title = title
+		if err := service.assignSlug(ctx, current); err != nil {
+			return domain.Page{}, err
+		}
+	}
 
 	page, err := service.repo.GetByID(ctx, pageID)
 	if err != nil {
@@ -196,9 +514,19 @@ func (service *Service) GetPage(ctx context.Context, pageID domain.PageID) (doma
 }
 
 func (service *Service) SetPagePublished(ctx context.Context, ownerID string, pageID domain.PageID, published bool, unlisted *bool) (domain.Page, error) {
+	return service.SetPagePublishedWithPassword(ctx, ownerID, pageID, published, unlisted, nil)
+}
+
+// SetPagePublishedWithPassword only changes the password if the publish is
+// saved.
+func (service *Service) SetPagePublishedWithPassword(ctx context.Context, ownerID string, pageID domain.PageID, published bool, unlisted *bool, password *string) (domain.Page, error) {
 	if pageID == "" {
 		return domain.Page{}, errs.ErrInvalidInput
 	}
+	passwordChange, err := newPasswordChange(password)
+	if err != nil {
+		return domain.Page{}, err
+	}
 	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
 		return domain.Page{}, err
 	}
@@ -213,27 +541,77 @@ func (service *Service) SetPagePublished(ctx context.Context, ownerID string, pa
 	if !published {
 		nextUnlisted = false
 	}
-	if err := service.repo.SetPublished(ctx, pageID, published, nextUnlisted); err != nil {
+	// Only first publishes count against the limit, so unpublishing a page
+	// neither frees a publish nor makes republishing it cost one.
+	if published && current.FirstPublishedAt == nil {
+		if err := service.checkPublishRate(ctx, ownerID); err != nil {
+			return domain.Page{}, err
+		}
+	}
+	if err := service.repo.SetPublished(ctx, pageID, published, nextUnlisted, passwordChange); err != nil {
 		return domain.Page{}, fmt.Errorf("set page published: %w", err)
 	}
+	if published && current.Slug == "" {
+		if err := service.assignSlug(ctx, current); err != nil {
+			return domain.Page{}, err
+		}
+	}
 	page, err := service.repo.GetByID(ctx, pageID)
 	if err != nil {
 		return domain.Page{}, fmt.Errorf("fetch published page: %w", err)
 	}
-	if err := service.events.BlocksUpdated(ctx, page); err != nil {
-		return domain.Page{}, fmt.Errorf("publish page updated: %w", err)
+	if err := service.events.PagePublished(ctx, page); err != nil {
+		return domain.Page{}, fmt.Errorf("publish page published: %w", err)
 	}
 	return page, nil
 }
 
-func (service *Service) ListPages(ctx context.Context, ownerID string) ([]domain.Page, error) {
-	pages, err := service.repo.ListPages(ctx, ownerID)
+func (service *Service) checkPublishRate(ctx context.Context, ownerID string) error {
+	if service.publishLimit <= 0 {
+		return nil
+	}
+	since := service.clock.Now().Add(-service.publishWindow)
+	count, err := service.repo.CountPublishedSince(ctx, ownerID, since)
 	if err != nil {
-		return nil, fmt.Errorf("list pages: %w", err)
+		return fmt.Errorf("count recent publishes: %w", err)
 	}
-	return pages, nil
+	if count >= service.publishLimit {
+		return fmt.Errorf("%w: at most %d publishes per %s", errs.ErrRateLimited, service.publishLimit, service.publishWindow)
+	}
+	return nil
 }
 
+func (service *Service) ListPages(ctx context.Context, ownerID string, status domain.PageStatus, limit, offset int) ([]domain.Page, *int, error) {
+	switch status {
+	case domain.PageStatusAny, domain.PageStatusDraft, domain.PageStatusPublished:
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown page status %q", errs.ErrInvalidInput, status)
+	}
+	if limit <= 0 {
+		limit = defaultPageListLimit
+	}
+	if limit > maxPageListLimit {
+		limit = maxPageListLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	pages, err := service.repo.ListPages(ctx, ownerID, status, limit+1, offset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list pages: %w", err)
+	}
+	if len(pages) <= limit {
+		return pages, nil, nil
+	}
+	next := offset + limit
+	return pages[:limit], &next, nil
+}
+
+// ErrDeleteEventNotPublished means pages were deleted but their media was
+// not queued for cleanup.
+var ErrDeleteEventNotPublished = errors.New("page deleted but deletion event not published")
+
 func (service *Service) DeletePage(ctx context.Context, ownerID string, pageID domain.PageID) error {
 	if pageID == "" {
 		return errs.ErrInvalidInput
@@ -241,24 +619,141 @@ func (service *Service) DeletePage(ctx context.Context, ownerID string, pageID d
 
 	// Fetch full page (with blocks) before deletion so we can emit an event
 	// carrying the media URLs for downstream cleanup.
-	page, err := service.repo.GetByID(ctx, pageID)
+	page, err := service.ownedPage(ctx, pageID, ownerID)
 	if err != nil {
-		return fmt.Errorf("get page for delete: %w", err)
-	}
-	if page.OwnerID == nil || *page.OwnerID != ownerID {
-		return errs.ErrForbidden
+		return err
 	}
 
 	if err := service.repo.DeletePage(ctx, pageID); err != nil {
 		return fmt.Errorf("delete page: %w", err)
 	}
 
-	// Best-effort: emit event so the files module can clean up S3 objects.
-	_ = service.events.PageDeleted(ctx, page)
-
+	// Emit event so the files module can clean up S3 objects.
+	if err := service.events.PageDeleted(ctx, page); err != nil {
+		return fmt.Errorf("%w: page %s: %w", ErrDeleteEventNotPublished, pageID, err)
+	}
 	return nil
 }
 
+// DeletePages uses one transaction per page and skips pages the owner does
+// not own.
+func (service *Service) DeletePages(ctx context.Context, ownerID string, pageIDs []domain.PageID) ([]domain.PageBatchResult, error) {
+	return runPageBatch(ownerID, pageIDs, func(pageID domain.PageID) error {
+		return service.DeletePage(ctx, ownerID, pageID)
+	})
+}
+
+func (service *Service) ArchivePages(ctx context.Context, ownerID string, pageIDs []domain.PageID) ([]domain.PageBatchResult, error) {
+	return runPageBatch(ownerID, pageIDs, func(pageID domain.PageID) error {
+		return service.ArchivePage(ctx, ownerID, pageID)
+	})
+}
+
+func runPageBatch(ownerID string, pageIDs []domain.PageID, apply func(domain.PageID) error) ([]domain.PageBatchResult, error) {
+	if ownerID == "" || len(pageIDs) == 0 {
+		return nil, errs.ErrInvalidInput
+	}
+	if len(pageIDs) > maxPageBatchSize {
+		return nil, fmt.Errorf("%w: at most %d pages per batch", errs.ErrInvalidInput, maxPageBatchSize)
+	}
+
+	results := make([]domain.PageBatchResult, 0, len(pageIDs))
+	seen := make(map[domain.PageID]bool, len(pageIDs))
+	var eventFailures []error
+	for _, pageID := range pageIDs {
+		if pageID == "" || seen[pageID] {
+			continue
+		}
+		seen[pageID] = true
+
+		result := domain.PageBatchResult{PageID: pageID, Status: domain.PageBatchDone}
+		err := apply(pageID)
+		if errors.Is(err, ErrDeleteEventNotPublished) {
+			eventFailures = append(eventFailures, err)
+		} else if err != nil {
+			result.Status, result.Error = batchStatus(err)
+		}
+		results = append(results, result)
+	}
+	return results, errors.Join(eventFailures...)
+}
+
+func (service *Service) DeleteOwnerPages(ctx context.Context, ownerID string) (int, error) {
+	if ownerID == "" {
+		return 0, errs.ErrInvalidInput
+	}
+	// Collect IDs first: deleting while paging would shift the windows.
+	var pageIDs []domain.PageID
+	for offset := 0; ; offset += maxPageListLimit {
+		pages, err := service.repo.ListPages(ctx, ownerID, domain.PageStatusAny, maxPageListLimit, offset)
+		if err != nil {
+			return 0, fmt.Errorf("list owner pages: %w", err)
+		}
+		for _, page := range pages {
+			pageIDs = append(pageIDs, page.ID)
+		}
+		if len(pages) < maxPageListLimit {
+			break
+		}
+	}
+	archived, err := service.repo.ListArchivedPages(ctx, ownerID)
+	if err != nil {
+		return 0, fmt.Errorf("list archived owner pages: %w", err)
+	}
+	for _, page := range archived {
+		pageIDs = append(pageIDs, page.ID)
+	}
+
+	deleted := 0
+	var eventFailures []error
+	for _, pageID := range pageIDs {
+		err := service.DeletePage(ctx, ownerID, pageID)
+		if errors.Is(err, ErrDeleteEventNotPublished) {
+			eventFailures = append(eventFailures, err)
+		} else if errors.Is(err, errs.ErrNotFound) {
+			continue
+		} else if err != nil {
+			if len(eventFailures) > 0 {
+				return deleted, fmt.Errorf("delete page %s: %w (after: %v)", pageID, err, errors.Join(eventFailures...))
+			}
+			return deleted, fmt.Errorf("delete page %s: %w", pageID, err)
+		}
+		deleted++
+	}
+	return deleted, errors.Join(eventFailures...)
+}
+
+func batchStatus(err error) (domain.PageBatchStatus, string) {
+	switch {
+	case errors.Is(err, errs.ErrForbidden):
+		return domain.PageBatchSkipped, "forbidden"
+	case errors.Is(err, errs.ErrNotFound):
+		return domain.PageBatchNotFound, "page not found"
+	default:
+		return domain.PageBatchFailed, "internal error"
+	}
+}
+
+func (service *Service) PurgeArchivedPages(ctx context.Context, retention time.Duration) (int, error) {
+	if retention <= 0 {
+		return 0, errs.ErrInvalidInput
+	}
+	cutoff := service.clock.Now().Add(-retention)
+	pages, err := service.repo.PurgeArchivedOlderThan(ctx, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purge archived pages: %w", err)
+	}
+
+	// Emit events so the files module can clean up S3 objects.
+	var eventFailures []error
+	for _, page := range pages {
+		if err := service.events.PageDeleted(ctx, page); err != nil {
+			eventFailures = append(eventFailures, fmt.Errorf("%w: page %s: %w", ErrDeleteEventNotPublished, page.ID, err))
+		}
+	}
+	return len(pages), errors.Join(eventFailures...)
+}
+
 func (service *Service) ArchivePage(ctx context.Context, ownerID string, pageID domain.PageID) error {
 	if pageID == "" {
 		return errs.ErrInvalidInput
@@ -276,13 +771,8 @@ func (service *Service) RestorePage(ctx context.Context, ownerID string, pageID
 	if pageID == "" {
 		return errs.ErrInvalidInput
 	}
-	// For restore, the page has deleted_at set, so we look it up and check owner
-	page, err := service.repo.GetByID(ctx, pageID)
-	if err != nil {
-		return fmt.Errorf("get page for restore: %w", err)
-	}
-	if page.OwnerID == nil || *page.OwnerID != ownerID {
-		return errs.ErrForbidden
+	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
+		return err
 	}
 	if err := service.repo.RestorePage(ctx, pageID); err != nil {
 		return fmt.Errorf("restore page: %w", err)
@@ -298,16 +788,40 @@ func (service *Service) ListArchivedPages(ctx context.Context, ownerID string) (
 	return pages, nil
 }
 
-func (service *Service) ListPublishedPagesByOwner(ctx context.Context, ownerID string) ([]domain.Page, error) {
-	pages, err := service.repo.ListPublishedPagesByOwner(ctx, ownerID)
+func (service *Service) ListPublishedPagesByOwner(ctx context.Context, ownerID, tag string, limit, offset int) ([]domain.Page, *int, error) {
+	if tag != "" {
+		if tag = slugTag(tag); tag == "" {
+			return []domain.Page{}, nil, nil
+		}
+	}
+	if limit <= 0 {
+		limit = defaultPageListLimit
+	}
+	if limit > maxPageListLimit {
+		limit = maxPageListLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	pages, err := service.repo.ListPublishedPagesByOwner(ctx, ownerID, tag, limit+1, offset)
 	if err != nil {
-		return nil, fmt.Errorf("list published pages by owner: %w", err)
+		return nil, nil, fmt.Errorf("list published pages by owner: %w", err)
 	}
-	return pages, nil
+	if len(pages) <= limit {
+		return pages, nil, nil
+	}
+	next := offset + limit
+	return pages[:limit], &next, nil
 }
 
-func (service *Service) ListPublishedFeed(ctx context.Context, limit, offset int, sort string, authorUserIDs []string) ([]domain.FeedPage, error) {
-	pages, err := service.repo.ListPublishedFeed(ctx, limit, offset, sort, authorUserIDs)
+func (service *Service) ListPublishedFeed(ctx context.Context, limit, offset int, sort string, authorUserIDs, excludedOwnerIDs []string, excludeSelf bool, tag, viewerID string) ([]domain.FeedPage, error) {
+	if tag != "" {
+		if tag = slugTag(tag); tag == "" {
+			return []domain.FeedPage{}, nil
+		}
+	}
+	pages, err := service.repo.ListPublishedFeed(ctx, limit, offset, sort, authorUserIDs, excludedOwnerIDs, excludeSelf, tag, viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("list published feed: %w", err)
 	}
@@ -315,14 +829,35 @@ func (service *Service) ListPublishedFeed(ctx context.Context, limit, offset int
 }
 
 func (service *Service) CreateShareLink(ctx context.Context, ownerID string, pageID domain.PageID, access domain.ShareAccess) (domain.PageShareLink, error) {
+	return service.CreateShareLinkWithLimits(ctx, ownerID, pageID, access, nil, nil)
+}
+
+func (service *Service) CreateShareLinkWithLimits(ctx context.Context, ownerID string, pageID domain.PageID, access domain.ShareAccess, expiresAt *time.Time, maxUses *int) (domain.PageShareLink, error) {
 	if pageID == "" {
 		return domain.PageShareLink{}, errs.ErrInvalidInput
 	}
 	if access != domain.ShareAccessView && access != domain.ShareAccessEdit {
 		return domain.PageShareLink{}, errs.ErrInvalidInput
 	}
-	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
-		return domain.PageShareLink{}, err
+	if maxUses != nil && *maxUses < 1 {
+		return domain.PageShareLink{}, fmt.Errorf("%w: max_uses must be at least 1", errs.ErrInvalidInput)
+	}
+	now := service.clock.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return domain.PageShareLink{}, fmt.Errorf("%w: expires_at must be in the future", errs.ErrInvalidInput)
+	}
+	if ownerID == "" {
+		return domain.PageShareLink{}, errs.ErrForbidden
+	}
+	page, err := service.repo.GetByID(ctx, pageID)
+	if err != nil {
+		return domain.PageShareLink{}, fmt.Errorf("get page for share link: %w", err)
+	}
+	if page.OwnerID == nil {
+		return domain.PageShareLink{}, ErrAnonymousPageShare
+	}
+	if *page.OwnerID != ownerID {
+		return domain.PageShareLink{}, errs.ErrForbidden
 	}
 	share := domain.PageShareLink{
 		Token:     uuid.NewString(),
@@ -330,12 +865,63 @@ func (service *Service) CreateShareLink(ctx context.Context, ownerID string, pag
 		Access:    access,
 		CreatedBy: ownerID,
 		Revoked:   false,
-		CreatedAt: service.clock.Now(),
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+		MaxUses:   maxUses,
 	}
-	if err := service.repo.CreateShareLink(ctx, share); err != nil {
-		return domain.PageShareLink{}, fmt.Errorf("create share link: %w", err)
+	if expiresAt == nil && service.shareLinkTTL > 0 {
+		defaultExpiry := now.Add(service.shareLinkTTL)
+		share.ExpiresAt = &defaultExpiry
 	}
-	return share, nil
+	if service.shareCodeLength == 0 {
+		if err := service.repo.CreateShareLink(ctx, share); err != nil {
+			return domain.PageShareLink{}, fmt.Errorf("create share link: %w", err)
+		}
+		return share, nil
+	}
+	return service.createShareLinkWithCode(ctx, share)
+}
+
+func (service *Service) createShareLinkWithCode(ctx context.Context, share domain.PageShareLink) (domain.PageShareLink, error) {
+	for attempt := 0; attempt < maxShareCodeAttempts; attempt++ {
+		code, err := service.newShareCode(service.shareCodeLength)
+		if err != nil {
+			return domain.PageShareLink{}, fmt.Errorf("generate share code: %w", err)
+		}
+		share.Code = code
+		err = service.repo.CreateShareLink(ctx, share)
+		if err == nil {
+			return share, nil
+		}
+		if !errors.Is(err, errs.ErrConflict) {
+			return domain.PageShareLink{}, fmt.Errorf("create share link: %w", err)
+		}
+	}
+	return domain.PageShareLink{}, fmt.Errorf("generate share code: %w", errs.ErrConflict)
+}
+
+func randomShareCode(length int) (string, error) {
+	alphabetSize := big.NewInt(int64(len(shareCodeAlphabet)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		code[i] = shareCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func (service *Service) findShareLink(ctx context.Context, tokenOrCode string) (domain.PageShareLink, error) {
+	share, err := service.repo.GetShareLinkByToken(ctx, tokenOrCode)
+	if err == nil {
+		return share, nil
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		return domain.PageShareLink{}, err
+	}
+	return service.repo.GetShareLinkByCode(ctx, tokenOrCode)
 }
 
 func (service *Service) RevokeShareLink(ctx context.Context, ownerID string, pageID domain.PageID, access domain.ShareAccess) error {
@@ -351,6 +937,52 @@ func (service *Service) RevokeShareLink(ctx context.Context, ownerID string, pag
 	return service.repo.RevokeShareLinksByAccess(ctx, pageID, ownerID, access)
 }
 
+func (service *Service) RevokeShareLinkByToken(ctx context.Context, ownerID string, pageID domain.PageID, token string) error {
+	token = strings.TrimSpace(token)
+	if pageID == "" || ownerID == "" || token == "" {
+		return errs.ErrInvalidInput
+	}
+	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
+		return err
+	}
+	if err := service.repo.RevokeShareLinkByToken(ctx, pageID, ownerID, token); err != nil {
+		return fmt.Errorf("revoke share link: %w", err)
+	}
+	return nil
+}
+
+func (service *Service) ListShareLinks(ctx context.Context, ownerID string, pageID domain.PageID) ([]domain.PageShareLink, error) {
+	if pageID == "" || ownerID == "" {
+		return nil, errs.ErrInvalidInput
+	}
+	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
+		return nil, err
+	}
+	shares, err := service.repo.ListShareLinks(ctx, pageID, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("list share links: %w", err)
+	}
+	return shares, nil
+}
+
+type shareVisitorKey struct{}
+
+// WithShareVisitor makes an anonymous visitor's repeated uses of a share link
+// count once.
+func WithShareVisitor(ctx context.Context, visitorKey string) context.Context {
+	return context.WithValue(ctx, shareVisitorKey{}, visitorKey)
+}
+
+func shareActorKey(ctx context.Context, actorID string) string {
+	if actorID != "" {
+		return "user:" + actorID
+	}
+	if visitor, _ := ctx.Value(shareVisitorKey{}).(string); visitor != "" {
+		return "visitor:" + visitor
+	}
+	return ""
+}
+
 func (service *Service) ResolvePageAccess(ctx context.Context, actorID string, pageID domain.PageID, shareToken string, required domain.ShareAccess) (domain.Page, string, error) {
 	if pageID == "" {
 		return domain.Page{}, "", errs.ErrInvalidInput
@@ -362,25 +994,47 @@ func (service *Service) ResolvePageAccess(ctx context.Context, actorID string, p
 	if actorID != "" && page.OwnerID != nil && *page.OwnerID == actorID {
 		return page, "owner", nil
 	}
+	if actorID != "" {
+		access, err := service.repo.GetCollaboratorAccess(ctx, pageID, actorID)
+		if err != nil && !errors.Is(err, errs.ErrNotFound) {
+			return domain.Page{}, "", fmt.Errorf("resolve collaborator access: %w", err)
+		}
+		// A view collaborator may still edit through an edit link.
+		if err == nil && (access == domain.ShareAccessEdit || required == domain.ShareAccessView) {
+			return page, string(access), nil
+		}
+	}
 
 	shareToken = strings.TrimSpace(shareToken)
 	if shareToken == "" {
-		return domain.Page{}, "", errs.ErrForbidden
+		return domain.Page{}, "", service.noAccess()
 	}
 
-	share, err := service.repo.GetShareLinkByToken(ctx, shareToken)
+	share, err := service.findShareLink(ctx, shareToken)
 	if err != nil {
-		return domain.Page{}, "", errs.ErrForbidden
+		return domain.Page{}, "", service.noAccess()
 	}
-	if share.Revoked || share.PageID != pageID {
-		return domain.Page{}, "", errs.ErrForbidden
+	if share.Revoked || share.Expired(service.clock.Now()) || share.PageID != pageID {
+		return domain.Page{}, "", service.noAccess()
 	}
 	if required == domain.ShareAccessEdit && share.Access != domain.ShareAccessEdit {
 		return domain.Page{}, "", errs.ErrForbidden
 	}
+	// Uses are counted per person, not per check: autosaves, presence and
+	// realtime connections resolve access over and over.
+	if err := service.repo.RecordShareLinkUse(ctx, share.Token, shareActorKey(ctx, actorID)); err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return domain.Page{}, "", service.noAccess()
+		}
+		return domain.Page{}, "", fmt.Errorf("record share link use: %w", err)
+	}
 
+	// Access here comes from the link; the collaborator row only records it,
+	// so failing to write it does not refuse the request.
 	if actorID != "" {
-		_ = service.repo.UpsertCollabUser(ctx, pageID, actorID, string(share.Access))
+		if err := service.repo.UpsertCollabUser(ctx, pageID, actorID, string(share.Access)); err != nil {
+			service.logger.Warn("record page collaborator failed", zap.String("page_id", string(pageID)), zap.String("user_id", actorID), zap.Error(err))
+		}
 	}
 
 	if share.Access == domain.ShareAccessEdit {
@@ -389,15 +1043,100 @@ func (service *Service) ResolvePageAccess(ctx context.Context, actorID string, p
 	return page, "view", nil
 }
 
+func (service *Service) GetPageWithAccess(ctx context.Context, actorID string, pageID domain.PageID, shareToken string, includeArchived bool) (domain.Page, string, error) {
+	page, access, err := service.ResolvePageAccess(ctx, actorID, pageID, shareToken, domain.ShareAccessView)
+	if err != nil {
+		return domain.Page{}, "", err
+	}
+	if page.DeletedAt != nil && !(includeArchived && access == "owner") {
+		return domain.Page{}, "", errs.ErrNotFound
+	}
+	return page, access, nil
+}
+
+func (service *Service) noAccess() error {
+	if service.hidePrivate {
+		return errs.ErrNotFound
+	}
+	return errs.ErrForbidden
+}
+
+func (service *Service) PreviewShareLink(ctx context.Context, shareToken string) (domain.SharePreview, error) {
+	shareToken = strings.TrimSpace(shareToken)
+	if shareToken == "" {
+		return domain.SharePreview{}, errs.ErrInvalidInput
+	}
+	share, err := service.findShareLink(ctx, shareToken)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return domain.SharePreview{}, errs.ErrNotFound
+		}
+		return domain.SharePreview{}, fmt.Errorf("preview share link: %w", err)
+	}
+	if share.Revoked || share.Expired(service.clock.Now()) || share.Exhausted() {
+		return domain.SharePreview{}, errs.ErrNotFound
+	}
+	page, err := service.repo.GetSummaryWithAuthor(ctx, share.PageID)
+	if err != nil {
+		return domain.SharePreview{}, fmt.Errorf("get shared page summary: %w", err)
+	}
+	if page.DeletedAt != nil {
+		return domain.SharePreview{}, errs.ErrNotFound
+	}
+	return domain.SharePreview{
+		PageID:           page.ID,
+		Title:            page.Title,
+		Cover:            page.Cover,
+		Access:           share.Access,
+		OwnerUsername:    page.AuthorUsername,
+		OwnerDisplayName: page.AuthorDisplayName,
+		OwnerAvatarURL:   page.AuthorAvatarURL,
+	}, nil
+}
+
+// ValidateShareLink records nothing about the caller.
+func (service *Service) ValidateShareLink(ctx context.Context, shareToken string) (domain.ShareValidation, error) {
+	shareToken = strings.TrimSpace(shareToken)
+	if shareToken == "" {
+		return domain.ShareValidation{}, errs.ErrInvalidInput
+	}
+	share, err := service.findShareLink(ctx, shareToken)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return domain.ShareValidation{}, nil
+		}
+		return domain.ShareValidation{}, fmt.Errorf("validate share link: %w", err)
+	}
+	validation := domain.ShareValidation{
+		Access:    share.Access,
+		Expired:   share.Expired(service.clock.Now()),
+		Revoked:   share.Revoked,
+		Exhausted: share.Exhausted(),
+	}
+	validation.Valid = !validation.Expired && !validation.Revoked && !validation.Exhausted
+	if validation.Valid {
+		validation.PageID = share.PageID
+	}
+	return validation, nil
+}
+
 func (service *Service) checkOwnership(ctx context.Context, pageID domain.PageID, ownerID string) error {
+	_, err := service.ownedPage(ctx, pageID, ownerID)
+	return err
+}
+
+func (service *Service) ownedPage(ctx context.Context, pageID domain.PageID, ownerID string) (domain.Page, error) {
+	if ownerID == "" {
+		return domain.Page{}, errs.ErrForbidden
+	}
 	page, err := service.repo.GetByID(ctx, pageID)
 	if err != nil {
-		return fmt.Errorf("check ownership: %w", err)
+		return domain.Page{}, fmt.Errorf("check ownership: %w", err)
 	}
 	if page.OwnerID == nil || *page.OwnerID != ownerID {
-		return errs.ErrForbidden
+		return domain.Page{}, errs.ErrForbidden
 	}
-	return nil
+	return page, nil
 }
 
 func (service *Service) GetPublicPage(ctx context.Context, pageID domain.PageID) (domain.Page, error) {
@@ -405,12 +1144,30 @@ func (service *Service) GetPublicPage(ctx context.Context, pageID domain.PageID)
 	if err != nil {
 		return domain.Page{}, err
 	}
-	if !page.Published {
+	if !page.Published || page.DeletedAt != nil {
 		return domain.Page{}, errs.ErrNotFound
 	}
 	return page, nil
 }
 
+func (service *Service) ListPublicBlockTypes(ctx context.Context, pageID domain.PageID) ([]domain.BlockTypeCount, error) {
+	if pageID == "" {
+		return nil, errs.ErrInvalidInput
+	}
+	summary, err := service.repo.GetSummaryWithAuthor(ctx, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("get page summary: %w", err)
+	}
+	if !summary.Published || summary.DeletedAt != nil {
+		return nil, errs.ErrNotFound
+	}
+	counts, err := service.repo.CountBlockTypes(ctx, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("count block types: %w", err)
+	}
+	return counts, nil
+}
+
 func (service *Service) RecordPublicRead(ctx context.Context, pageID domain.PageID, readerKey string) (bool, error) {
 	if pageID == "" || strings.TrimSpace(readerKey) == "" {
 		return false, nil
@@ -422,6 +1179,26 @@ func (service *Service) RecordPublicRead(ctx context.Context, pageID domain.Page
 	return unique, nil
 }
 
+func (service *Service) TrendingForOwner(ctx context.Context, ownerID string) ([]domain.TrendingPage, error) {
+	if ownerID == "" {
+		return nil, errs.ErrForbidden
+	}
+	since := service.clock.Now().Add(-trendingWindow)
+	pages, err := service.repo.TrendingForOwner(ctx, ownerID, since, maxTrendingPages)
+	if err != nil {
+		return nil, fmt.Errorf("list trending pages: %w", err)
+	}
+	return pages, nil
+}
+
+func (service *Service) PlatformStats(ctx context.Context) (domain.PlatformStats, error) {
+	stats, err := service.repo.PlatformStats(ctx)
+	if err != nil {
+		return domain.PlatformStats{}, fmt.Errorf("platform stats: %w", err)
+	}
+	return stats, nil
+}
+
 func (service *Service) GetPublicBlock(ctx context.Context, pageID domain.PageID, blockID string) (domain.Block, domain.Page, error) {
 	if blockID == "" {
 		return domain.Block{}, domain.Page{}, errs.ErrInvalidInput
@@ -446,7 +1223,7 @@ func (service *Service) GetPublicBlockWithAuthor(ctx context.Context, pageID dom
 	if err != nil {
 		return domain.Block{}, domain.FeedPage{}, err
 	}
-	if !page.Published {
+	if !page.Published || page.DeletedAt != nil {
 		return domain.Block{}, domain.FeedPage{}, errs.ErrNotFound
 	}
 	for _, block := range page.Blocks {
@@ -489,20 +1266,33 @@ func (service *Service) CreateProofread(ctx context.Context, pageID domain.PageI
 	return proofread, nil
 }
 
-func (service *Service) ListProofreads(ctx context.Context, pageID domain.PageID) ([]domain.Proofread, error) {
+func (service *Service) ListProofreads(ctx context.Context, pageID domain.PageID, sort string) ([]domain.Proofread, error) {
 	if pageID == "" {
 		return nil, errs.ErrInvalidInput
 	}
 	if _, err := service.GetPublicPage(ctx, pageID); err != nil {
 		return nil, err
 	}
-	proofreads, err := service.repo.ListProofreadsByPageID(ctx, pageID)
+	proofreads, err := service.repo.ListProofreadsByPageID(ctx, pageID, sort)
 	if err != nil {
 		return nil, fmt.Errorf("list proofreads: %w", err)
 	}
 	return proofreads, nil
 }
 
+func (service *Service) SetProofreadPinned(ctx context.Context, ownerID string, pageID domain.PageID, proofreadID domain.ProofreadID, pinned bool) error {
+	if pageID == "" || proofreadID == "" {
+		return errs.ErrInvalidInput
+	}
+	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
+		return err
+	}
+	if err := service.repo.SetProofreadPinned(ctx, pageID, proofreadID, pinned); err != nil {
+		return fmt.Errorf("set proofread pinned: %w", err)
+	}
+	return nil
+}
+
 func (service *Service) GetProofread(ctx context.Context, proofreadID domain.ProofreadID) (domain.Proofread, domain.Page, error) {
 	if proofreadID == "" {
 		return domain.Proofread{}, domain.Page{}, errs.ErrInvalidInput
@@ -518,6 +1308,43 @@ func (service *Service) GetProofread(ctx context.Context, proofreadID domain.Pro
 	return proofread, page, nil
 }
 
+func (service *Service) DeleteProofread(ctx context.Context, ownerID string, pageID domain.PageID, proofreadID domain.ProofreadID) error {
+	if pageID == "" || proofreadID == "" {
+		return errs.ErrInvalidInput
+	}
+	proofread, err := service.repo.GetProofreadByID(ctx, proofreadID)
+	if err != nil {
+		return fmt.Errorf("get proofread by id: %w", err)
+	}
+	if proofread.PageID != pageID {
+		return errs.ErrNotFound
+	}
+	if err := service.checkOwnership(ctx, pageID, ownerID); err != nil {
+		return err
+	}
+	if err := service.repo.DeleteProofread(ctx, pageID, proofreadID); err != nil {
+		return fmt.Errorf("delete proofread: %w", err)
+	}
+	return nil
+}
+
+func (service *Service) VoteProofread(ctx context.Context, proofreadID domain.ProofreadID, voterKey string) (int, error) {
+	if voterKey == "" {
+		return 0, errs.ErrInvalidInput
+	}
+	if _, _, err := service.GetProofread(ctx, proofreadID); err != nil {
+		return 0, err
+	}
+	if _, err := service.repo.VoteProofread(ctx, proofreadID, voterKey); err != nil {
+		return 0, fmt.Errorf("vote proofread: %w", err)
+	}
+	count, err := service.repo.CountVotes(ctx, proofreadID)
+	if err != nil {
+		return 0, fmt.Errorf("count proofread votes: %w", err)
+	}
+	return count, nil
+}
+
 func (service *Service) ListCollabUsers(ctx context.Context, ownerID string, pageID domain.PageID) ([]domain.CollabUser, error) {
 	if pageID == "" {
 		return nil, errs.ErrInvalidInput
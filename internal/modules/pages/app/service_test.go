@@ -3,10 +3,20 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/modules/pages/ports"
+	"github.com/reggieanim/jot/internal/shared/errs"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type fakeClock struct {
@@ -22,6 +32,25 @@ type inMemoryRepo struct {
 	proofreads map[domain.ProofreadID]domain.Proofread
 	reads      map[domain.PageID]map[string]struct{}
 	shares     map[string]domain.PageShareLink
+	shareUses  map[string]map[string]bool
+	revisions  []domain.PageRevision
+	likes      map[domain.PageID]map[string]bool
+	bookmarks  []inMemoryBookmark
+	passwords  map[domain.PageID]string
+	comments   []domain.Comment
+	votes      map[domain.ProofreadID]map[string]bool
+	clock      Clock
+	// readCounter is the site-wide read total RecordOrganicRead would keep.
+	readCounter int64
+
+	collaborators map[domain.PageID]map[string]domain.ShareAccess
+	collabUsers   map[domain.PageID]map[string]domain.CollabUser
+}
+
+type inMemoryBookmark struct {
+	userID string
+	pageID domain.PageID
+	at     time.Time
 }
 
 func newInMemoryRepo() *inMemoryRepo {
@@ -30,6 +59,13 @@ func newInMemoryRepo() *inMemoryRepo {
 		proofreads: map[domain.ProofreadID]domain.Proofread{},
 		reads:      map[domain.PageID]map[string]struct{}{},
 		shares:     map[string]domain.PageShareLink{},
+		shareUses:  map[string]map[string]bool{},
+		likes:      map[domain.PageID]map[string]bool{},
+		passwords:  map[domain.PageID]string{},
+		votes:      map[domain.ProofreadID]map[string]bool{},
+
+		collaborators: map[domain.PageID]map[string]domain.ShareAccess{},
+		collabUsers:   map[domain.PageID]map[string]domain.CollabUser{},
 	}
 }
 
@@ -45,12 +81,25 @@ func (repo *inMemoryRepo) UpdateBlocks(_ context.Context, pageID domain.PageID,
 	return nil
 }
 
-func (repo *inMemoryRepo) UpdateBlocksOptimistic(_ context.Context, pageID domain.PageID, blocks []domain.Block, _ *time.Time) error {
-	return repo.UpdateBlocks(context.Background(), pageID, blocks)
+func (repo *inMemoryRepo) UpdateBlocksOptimistic(_ context.Context, pageID domain.PageID, blocks []domain.Block, stats *domain.ReadingStats, expectedUpdatedAt *time.Time) error {
+	page := repo.store[pageID]
+	if expectedUpdatedAt != nil && !page.UpdatedAt.Equal(*expectedUpdatedAt) {
+		return errs.ErrConflict
+	}
+	page.Blocks = blocks
+	if stats != nil {
+		page.ReadingStats = *stats
+	}
+	page.UpdatedAt = page.UpdatedAt.Add(time.Second)
+	repo.store[pageID] = page
+	return nil
 }
 
-func (repo *inMemoryRepo) UpdatePageMetaOptimistic(_ context.Context, pageID domain.PageID, title string, cover *string, darkMode bool, cinematic bool, mood int, bgColor string, _ *time.Time) error {
+func (repo *inMemoryRepo) UpdatePageMetaOptimistic(_ context.Context, pageID domain.PageID, title string, cover *string, darkMode bool, cinematic bool, mood int, bgColor string, tags []string, _ *time.Time) error {
 	page := repo.store[pageID]
+	if tags != nil {
+		page.Tags = tags
+	}
 	page.Title = title
 	page.Cover = cover
 	page.DarkMode = darkMode
@@ -70,13 +119,163 @@ func (repo *inMemoryRepo) GetByIDWithAuthor(_ context.Context, pageID domain.Pag
 	return domain.FeedPage{Page: page}, nil
 }
 
-func (repo *inMemoryRepo) SetPublished(_ context.Context, pageID domain.PageID, published bool, unlisted bool) error {
+// GetBySlug treats owner IDs as usernames.
+func (repo *inMemoryRepo) GetBySlug(_ context.Context, ownerUsername, slug string) (domain.Page, error) {
+	for _, page := range repo.store {
+		if page.OwnerID != nil && *page.OwnerID == ownerUsername && page.Slug == slug {
+			return page, nil
+		}
+	}
+	return domain.Page{}, errs.ErrNotFound
+}
+
+func (repo *inMemoryRepo) ListTakenSlugs(_ context.Context, ownerID, base string) ([]string, error) {
+	var slugs []string
+	for _, page := range repo.store {
+		if page.OwnerID != nil && *page.OwnerID == ownerID && (page.Slug == base || strings.HasPrefix(page.Slug, base+"-")) {
+			slugs = append(slugs, page.Slug)
+		}
+	}
+	return slugs, nil
+}
+
+func (repo *inMemoryRepo) SetSlug(_ context.Context, pageID domain.PageID, slug string) error {
+	page := repo.store[pageID]
+	for id, other := range repo.store {
+		if id != pageID && other.Slug == slug && other.OwnerID != nil && page.OwnerID != nil && *other.OwnerID == *page.OwnerID {
+			return errs.ErrConflict
+		}
+	}
+	page.Slug = slug
+	repo.store[pageID] = page
+	return nil
+}
+
+func (repo *inMemoryRepo) SetPasswordHash(_ context.Context, pageID domain.PageID, hash *string) error {
+	page := repo.store[pageID]
+	page.PasswordProtected = hash != nil
+	repo.store[pageID] = page
+	if hash == nil {
+		delete(repo.passwords, pageID)
+	} else {
+		repo.passwords[pageID] = *hash
+	}
+	return nil
+}
+
+func (repo *inMemoryRepo) applyPassword(pageID domain.PageID, password *ports.PasswordChange) {
+	if password != nil {
+		_ = repo.SetPasswordHash(context.Background(), pageID, password.Hash)
+	}
+}
+
+func (repo *inMemoryRepo) GetPasswordHash(_ context.Context, pageID domain.PageID) (string, error) {
+	return repo.passwords[pageID], nil
+}
+
+func (repo *inMemoryRepo) SchedulePublish(_ context.Context, pageID domain.PageID, publishAt time.Time, unlisted bool, password *ports.PasswordChange) error {
+	repo.applyPassword(pageID, password)
+	page := repo.store[pageID]
+	page.Published = false
+	page.Unlisted = unlisted
+	page.PublishedAt = nil
+	page.PublishAt = &publishAt
+	repo.store[pageID] = page
+	return nil
+}
+
+func (repo *inMemoryRepo) ListDuePages(_ context.Context, now time.Time) ([]domain.Page, error) {
+	var due []domain.Page
+	for _, page := range repo.store {
+		if page.PublishAt == nil || page.PublishAt.After(now) || page.DeletedAt != nil {
+			continue
+		}
+		due = append(due, page)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].PublishAt.Before(*due[j].PublishAt) })
+	return due, nil
+}
+
+func (repo *inMemoryRepo) PublishScheduled(_ context.Context, pageID domain.PageID, now time.Time) (bool, error) {
+	page, ok := repo.store[pageID]
+	if !ok || page.PublishAt == nil || page.PublishAt.After(now) || page.DeletedAt != nil {
+		return false, nil
+	}
+	page.Published = true
+	page.PublishedAt = &now
+	if page.FirstPublishedAt == nil {
+		page.FirstPublishedAt = &now
+	}
+	page.PublishAt = nil
+	repo.store[pageID] = page
+	return true, nil
+}
+
+func (repo *inMemoryRepo) CreateComment(_ context.Context, comment domain.Comment) error {
+	repo.comments = append(repo.comments, comment)
+	return nil
+}
+
+func (repo *inMemoryRepo) GetComment(_ context.Context, commentID domain.CommentID) (domain.Comment, error) {
+	for _, comment := range repo.comments {
+		if comment.ID == commentID {
+			return comment, nil
+		}
+	}
+	return domain.Comment{}, errs.ErrNotFound
+}
+
+func (repo *inMemoryRepo) ListComments(_ context.Context, pageID domain.PageID) ([]domain.Comment, error) {
+	var comments []domain.Comment
+	for _, comment := range repo.comments {
+		if comment.PageID == pageID {
+			comments = append(comments, comment)
+		}
+	}
+	slices.SortStableFunc(comments, func(a, b domain.Comment) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return comments, nil
+}
+
+// DeleteComment removes the comment and, like the database cascade, its
+// replies.
+func (repo *inMemoryRepo) DeleteComment(_ context.Context, commentID domain.CommentID) error {
+	deleted := map[domain.CommentID]bool{commentID: true}
+	kept := repo.comments[:0]
+	for _, comment := range repo.comments {
+		if deleted[comment.ID] || (comment.ParentID != nil && deleted[*comment.ParentID]) {
+			deleted[comment.ID] = true
+			continue
+		}
+		kept = append(kept, comment)
+	}
+	repo.comments = kept
+	return nil
+}
+
+func (repo *inMemoryRepo) GetSummaryWithAuthor(_ context.Context, pageID domain.PageID) (domain.FeedPage, error) {
+	page, ok := repo.store[pageID]
+	if !ok {
+		return domain.FeedPage{}, errs.ErrNotFound
+	}
+	page.Blocks = nil
+	return domain.FeedPage{Page: page, AuthorUsername: "anonymous", AuthorDisplayName: "Anonymous"}, nil
+}
+
+func (repo *inMemoryRepo) SetPublished(_ context.Context, pageID domain.PageID, published bool, unlisted bool, password *ports.PasswordChange) error {
+	repo.applyPassword(pageID, password)
 	page := repo.store[pageID]
 	page.Published = published
 	page.Unlisted = unlisted
+	page.PublishAt = nil
 	if published {
 		now := time.Now().UTC()
+		if repo.clock != nil {
+			now = repo.clock.Now()
+		}
 		page.PublishedAt = &now
+		if page.FirstPublishedAt == nil {
+			page.FirstPublishedAt = &now
+		}
 	} else {
 		page.PublishedAt = nil
 	}
@@ -84,32 +283,110 @@ func (repo *inMemoryRepo) SetPublished(_ context.Context, pageID domain.PageID,
 	return nil
 }
 
+func (repo *inMemoryRepo) CountPublishedSince(_ context.Context, ownerID string, since time.Time) (int, error) {
+	count := 0
+	for _, page := range repo.store {
+		if page.FirstPublishedAt != nil && !page.FirstPublishedAt.Before(since) && page.OwnerID != nil && *page.OwnerID == ownerID {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (repo *inMemoryRepo) CreateProofread(_ context.Context, proofread domain.Proofread) error {
 	repo.proofreads[proofread.ID] = proofread
 	return nil
 }
 
-func (repo *inMemoryRepo) ListProofreadsByPageID(_ context.Context, pageID domain.PageID) ([]domain.Proofread, error) {
+func (repo *inMemoryRepo) ListProofreadsByPageID(_ context.Context, pageID domain.PageID, sort string) ([]domain.Proofread, error) {
 	items := make([]domain.Proofread, 0)
 	for _, proofread := range repo.proofreads {
 		if proofread.PageID == pageID {
+			proofread.VoteCount = len(repo.votes[proofread.ID])
 			items = append(items, proofread)
 		}
 	}
+	slices.SortFunc(items, func(a, b domain.Proofread) int {
+		if a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+		if sort == "top" && a.VoteCount != b.VoteCount {
+			return b.VoteCount - a.VoteCount
+		}
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
 	return items, nil
 }
 
+func (repo *inMemoryRepo) DeleteProofread(_ context.Context, pageID domain.PageID, proofreadID domain.ProofreadID) error {
+	if proofread, ok := repo.proofreads[proofreadID]; !ok || proofread.PageID != pageID {
+		return errs.ErrNotFound
+	}
+	delete(repo.proofreads, proofreadID)
+	delete(repo.votes, proofreadID)
+	return nil
+}
+
+func (repo *inMemoryRepo) VoteProofread(_ context.Context, proofreadID domain.ProofreadID, voterKey string) (bool, error) {
+	if repo.votes[proofreadID] == nil {
+		repo.votes[proofreadID] = map[string]bool{}
+	}
+	if repo.votes[proofreadID][voterKey] {
+		return false, nil
+	}
+	repo.votes[proofreadID][voterKey] = true
+	return true, nil
+}
+
+func (repo *inMemoryRepo) CountVotes(_ context.Context, proofreadID domain.ProofreadID) (int, error) {
+	return len(repo.votes[proofreadID]), nil
+}
+
+func (repo *inMemoryRepo) SetProofreadPinned(_ context.Context, pageID domain.PageID, proofreadID domain.ProofreadID, pinned bool) error {
+	proofread, ok := repo.proofreads[proofreadID]
+	if !ok || proofread.PageID != pageID {
+		return errs.ErrNotFound
+	}
+	proofread.Pinned = pinned
+	repo.proofreads[proofreadID] = proofread
+	return nil
+}
+
 func (repo *inMemoryRepo) GetProofreadByID(_ context.Context, proofreadID domain.ProofreadID) (domain.Proofread, error) {
-	return repo.proofreads[proofreadID], nil
+	proofread, ok := repo.proofreads[proofreadID]
+	if !ok {
+		return domain.Proofread{}, errs.ErrNotFound
+	}
+	proofread.VoteCount = len(repo.votes[proofreadID])
+	return proofread, nil
 }
 
-func (repo *inMemoryRepo) ListPages(_ context.Context, ownerID string) ([]domain.Page, error) {
+func (repo *inMemoryRepo) ListPages(_ context.Context, ownerID string, status domain.PageStatus, limit, offset int) ([]domain.Page, error) {
 	pages := make([]domain.Page, 0, len(repo.store))
 	for _, page := range repo.store {
+		if status != domain.PageStatusAny && page.Published != (status == domain.PageStatusPublished) {
+			continue
+		}
 		if page.DeletedAt == nil && page.OwnerID != nil && *page.OwnerID == ownerID {
 			pages = append(pages, page)
 		}
 	}
+	sort.Slice(pages, func(i, j int) bool {
+		if !pages[i].UpdatedAt.Equal(pages[j].UpdatedAt) {
+			return pages[i].UpdatedAt.After(pages[j].UpdatedAt)
+		}
+		return pages[i].ID < pages[j].ID
+	})
+	if offset >= len(pages) {
+		return []domain.Page{}, nil
+	}
+	pages = pages[offset:]
+	if limit > 0 && limit < len(pages) {
+		pages = pages[:limit]
+	}
 	return pages, nil
 }
 
@@ -121,11 +398,38 @@ func (repo *inMemoryRepo) DeletePage(_ context.Context, pageID domain.PageID) er
 func (repo *inMemoryRepo) ArchivePage(_ context.Context, pageID domain.PageID) error {
 	page := repo.store[pageID]
 	now := time.Now().UTC()
+	if repo.clock != nil {
+		now = repo.clock.Now()
+	}
 	page.DeletedAt = &now
 	repo.store[pageID] = page
 	return nil
 }
 
+func (repo *inMemoryRepo) CountBlockTypes(_ context.Context, pageID domain.PageID) ([]domain.BlockTypeCount, error) {
+	byType := map[domain.BlockType]int{}
+	for _, block := range repo.store[pageID].Blocks {
+		byType[block.Type]++
+	}
+	counts := make([]domain.BlockTypeCount, 0, len(byType))
+	for blockType, count := range byType {
+		counts = append(counts, domain.BlockTypeCount{Type: blockType, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Type < counts[j].Type })
+	return counts, nil
+}
+
+func (repo *inMemoryRepo) PurgeArchivedOlderThan(_ context.Context, cutoff time.Time) ([]domain.Page, error) {
+	purged := make([]domain.Page, 0)
+	for id, page := range repo.store {
+		if page.DeletedAt != nil && page.DeletedAt.Before(cutoff) {
+			purged = append(purged, page)
+			delete(repo.store, id)
+		}
+	}
+	return purged, nil
+}
+
 func (repo *inMemoryRepo) RestorePage(_ context.Context, pageID domain.PageID) error {
 	page := repo.store[pageID]
 	page.DeletedAt = nil
@@ -143,20 +447,137 @@ func (repo *inMemoryRepo) ListArchivedPages(_ context.Context, ownerID string) (
 	return pages, nil
 }
 
-func (repo *inMemoryRepo) ListPublishedPagesByOwner(_ context.Context, ownerID string) ([]domain.Page, error) {
+func (repo *inMemoryRepo) ListPublishedPagesByOwner(_ context.Context, ownerID, tag string, limit, offset int) ([]domain.Page, error) {
 	pages := make([]domain.Page, 0)
 	for _, page := range repo.store {
-		if page.DeletedAt == nil && page.Published && !page.Unlisted && page.OwnerID != nil && *page.OwnerID == ownerID {
+		if page.DeletedAt == nil && page.Published && !page.Unlisted && page.OwnerID != nil && *page.OwnerID == ownerID && (tag == "" || slices.Contains(page.Tags, tag)) {
 			pages = append(pages, page)
 		}
 	}
+	if offset >= len(pages) {
+		return []domain.Page{}, nil
+	}
+	pages = pages[offset:]
+	if limit > 0 && len(pages) > limit {
+		pages = pages[:limit]
+	}
 	return pages, nil
 }
 
-func (repo *inMemoryRepo) ListPublishedFeed(_ context.Context, limit, offset int, _ string, authorUserIDs []string) ([]domain.FeedPage, error) {
+func (repo *inMemoryRepo) TrendingForOwner(_ context.Context, _ string, _ time.Time, _ int) ([]domain.TrendingPage, error) {
+	return []domain.TrendingPage{}, nil
+}
+
+func (repo *inMemoryRepo) PlatformStats(_ context.Context) (domain.PlatformStats, error) {
+	return domain.PlatformStats{}, nil
+}
+
+func (repo *inMemoryRepo) ListPageIDsAfter(_ context.Context, after domain.PageID, limit int) ([]domain.PageID, error) {
+	ids := make([]domain.PageID, 0)
+	for id := range repo.store {
+		if id > after {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (repo *inMemoryRepo) CorrectReadingStats(_ context.Context, pageID domain.PageID, updatedAt time.Time, stats domain.ReadingStats) (domain.ReadingStats, bool, error) {
+	page := repo.store[pageID]
+	if !page.UpdatedAt.Equal(updatedAt) || page.ReadingStats == stats {
+		return domain.ReadingStats{}, false, nil
+	}
+	previous := page.ReadingStats
+	page.ReadingStats = stats
+	repo.store[pageID] = page
+	return previous, true, nil
+}
+
+func (repo *inMemoryRepo) ReconcileReadCounter(_ context.Context) (int64, int64, error) {
+	var actual int64
+	for _, readers := range repo.reads {
+		actual += int64(len(readers))
+	}
+	previous := repo.readCounter
+	repo.readCounter = actual
+	return previous, actual, nil
+}
+
+func (repo *inMemoryRepo) TrendingTags(_ context.Context, _ time.Time, _ int) ([]domain.TagCount, error) {
+	return []domain.TagCount{}, nil
+}
+
+func (repo *inMemoryRepo) AddBookmark(_ context.Context, userID string, pageID domain.PageID, at time.Time) error {
+	for _, bookmark := range repo.bookmarks {
+		if bookmark.userID == userID && bookmark.pageID == pageID {
+			return nil
+		}
+	}
+	repo.bookmarks = append(repo.bookmarks, inMemoryBookmark{userID: userID, pageID: pageID, at: at})
+	return nil
+}
+
+func (repo *inMemoryRepo) RemoveBookmark(_ context.Context, userID string, pageID domain.PageID) error {
+	repo.bookmarks = slices.DeleteFunc(repo.bookmarks, func(bookmark inMemoryBookmark) bool {
+		return bookmark.userID == userID && bookmark.pageID == pageID
+	})
+	return nil
+}
+
+func (repo *inMemoryRepo) ListBookmarks(_ context.Context, userID string, limit, offset int) ([]domain.BookmarkedPage, error) {
+	pages := make([]domain.BookmarkedPage, 0)
+	for _, bookmark := range repo.bookmarks {
+		if bookmark.userID != userID {
+			continue
+		}
+		page := repo.store[bookmark.pageID]
+		pages = append(pages, domain.BookmarkedPage{FeedPage: domain.FeedPage{Page: page}, BookmarkedAt: bookmark.at})
+	}
+	slices.SortStableFunc(pages, func(a, b domain.BookmarkedPage) int { return b.BookmarkedAt.Compare(a.BookmarkedAt) })
+	if offset >= len(pages) {
+		return []domain.BookmarkedPage{}, nil
+	}
+	return pages[offset:min(offset+limit, len(pages))], nil
+}
+
+func (repo *inMemoryRepo) LikePage(_ context.Context, pageID domain.PageID, userID string) error {
+	if repo.likes[pageID] == nil {
+		repo.likes[pageID] = map[string]bool{}
+	}
+	repo.likes[pageID][userID] = true
+	return nil
+}
+
+func (repo *inMemoryRepo) UnlikePage(_ context.Context, pageID domain.PageID, userID string) error {
+	delete(repo.likes[pageID], userID)
+	return nil
+}
+
+func (repo *inMemoryRepo) CountLikes(_ context.Context, pageID domain.PageID) (int, error) {
+	return len(repo.likes[pageID]), nil
+}
+
+func (repo *inMemoryRepo) HasLiked(_ context.Context, pageID domain.PageID, userID string) (bool, error) {
+	return repo.likes[pageID][userID], nil
+}
+
+func (repo *inMemoryRepo) ListPublishedFeed(_ context.Context, limit, offset int, _ string, authorUserIDs, excludedOwnerIDs []string, excludeSelf bool, tag, viewerID string) ([]domain.FeedPage, error) {
 	all := make([]domain.FeedPage, 0)
 	for _, page := range repo.store {
 		if page.DeletedAt == nil && page.Published && !page.Unlisted {
+			if page.OwnerID != nil && slices.Contains(excludedOwnerIDs, *page.OwnerID) {
+				continue
+			}
+			if excludeSelf && viewerID != "" && page.OwnerID != nil && *page.OwnerID == viewerID {
+				continue
+			}
+			if tag != "" && !slices.Contains(page.Tags, tag) {
+				continue
+			}
 			// Filter by author user IDs if specified
 			if len(authorUserIDs) > 0 {
 				found := false
@@ -184,6 +605,11 @@ func (repo *inMemoryRepo) ListPublishedFeed(_ context.Context, limit, offset int
 }
 
 func (repo *inMemoryRepo) CreateShareLink(_ context.Context, share domain.PageShareLink) error {
+	for _, existing := range repo.shares {
+		if share.Code != "" && existing.Code == share.Code {
+			return errs.ErrConflict
+		}
+	}
 	repo.shares[share.Token] = share
 	return nil
 }
@@ -192,7 +618,59 @@ func (repo *inMemoryRepo) GetShareLinkByToken(_ context.Context, token string) (
 	if share, ok := repo.shares[token]; ok {
 		return share, nil
 	}
-	return domain.PageShareLink{}, nil
+	return domain.PageShareLink{}, errs.ErrNotFound
+}
+
+func (repo *inMemoryRepo) GetShareLinkByCode(_ context.Context, code string) (domain.PageShareLink, error) {
+	for _, share := range repo.shares {
+		if share.Code != "" && share.Code == code {
+			return share, nil
+		}
+	}
+	return domain.PageShareLink{}, errs.ErrNotFound
+}
+
+func (repo *inMemoryRepo) RevokeShareLinkByToken(_ context.Context, pageID domain.PageID, ownerID, token string) error {
+	share, ok := repo.shares[token]
+	if !ok || share.PageID != pageID || share.CreatedBy != ownerID {
+		return errs.ErrNotFound
+	}
+	share.Revoked = true
+	repo.shares[token] = share
+	return nil
+}
+
+func (repo *inMemoryRepo) ListShareLinks(_ context.Context, pageID domain.PageID, ownerID string) ([]domain.PageShareLink, error) {
+	var shares []domain.PageShareLink
+	for _, share := range repo.shares {
+		if share.PageID == pageID && share.CreatedBy == ownerID {
+			shares = append(shares, share)
+		}
+	}
+	sort.Slice(shares, func(i, j int) bool { return shares[i].CreatedAt.After(shares[j].CreatedAt) })
+	return shares, nil
+}
+
+func (repo *inMemoryRepo) RecordShareLinkUse(_ context.Context, token, actorKey string) error {
+	share, ok := repo.shares[token]
+	if !ok {
+		return errs.ErrConflict
+	}
+	if actorKey != "" && repo.shareUses[token][actorKey] {
+		return nil
+	}
+	if share.Exhausted() {
+		return errs.ErrConflict
+	}
+	share.UseCount++
+	repo.shares[token] = share
+	if actorKey != "" {
+		if repo.shareUses[token] == nil {
+			repo.shareUses[token] = map[string]bool{}
+		}
+		repo.shareUses[token][actorKey] = true
+	}
+	return nil
 }
 
 func (repo *inMemoryRepo) RevokeShareLinksByAccess(_ context.Context, pageID domain.PageID, ownerID string, access domain.ShareAccess) error {
@@ -205,6 +683,28 @@ func (repo *inMemoryRepo) RevokeShareLinksByAccess(_ context.Context, pageID dom
 	return nil
 }
 
+func (repo *inMemoryRepo) TransferOwnership(_ context.Context, pageID domain.PageID, fromOwnerID, toOwnerID string) error {
+	page, ok := repo.store[pageID]
+	if !ok || page.OwnerID == nil || *page.OwnerID != fromOwnerID {
+		return errs.ErrNotFound
+	}
+	page.OwnerID = &toOwnerID
+	page.Slug = ""
+	page.Published = false
+	page.PublishedAt = nil
+	page.PublishAt = nil
+	repo.store[pageID] = page
+	delete(repo.collaborators, pageID)
+	delete(repo.collabUsers, pageID)
+	for token, share := range repo.shares {
+		if share.PageID == pageID {
+			share.Revoked = true
+			repo.shares[token] = share
+		}
+	}
+	return nil
+}
+
 func (repo *inMemoryRepo) RecordOrganicRead(_ context.Context, pageID domain.PageID, readerKey string) (bool, error) {
 	if _, ok := repo.reads[pageID]; !ok {
 		repo.reads[pageID] = map[string]struct{}{}
@@ -219,12 +719,119 @@ func (repo *inMemoryRepo) RecordOrganicRead(_ context.Context, pageID domain.Pag
 	return true, nil
 }
 
-func (repo *inMemoryRepo) UpsertCollabUser(_ context.Context, _ domain.PageID, _ string, _ string) error {
+func (repo *inMemoryRepo) UpsertCollabUser(_ context.Context, pageID domain.PageID, userID string, access string) error {
+	if repo.collabUsers[pageID] == nil {
+		repo.collabUsers[pageID] = map[string]domain.CollabUser{}
+	}
+	seenAt := time.Now()
+	if repo.clock != nil {
+		seenAt = repo.clock.Now()
+	}
+	repo.collabUsers[pageID][userID] = domain.CollabUser{UserID: userID, Access: access, LastSeenAt: seenAt}
+	return nil
+}
+
+func (repo *inMemoryRepo) AddCollaborator(_ context.Context, pageID domain.PageID, userID string, access domain.ShareAccess) error {
+	if repo.collaborators[pageID] == nil {
+		repo.collaborators[pageID] = map[string]domain.ShareAccess{}
+	}
+	repo.collaborators[pageID][userID] = access
+	return nil
+}
+
+func (repo *inMemoryRepo) RemoveCollaborator(_ context.Context, pageID domain.PageID, userID string) error {
+	if _, ok := repo.collaborators[pageID][userID]; !ok {
+		return errs.ErrNotFound
+	}
+	delete(repo.collaborators[pageID], userID)
+	return nil
+}
+
+func (repo *inMemoryRepo) GetCollaboratorAccess(_ context.Context, pageID domain.PageID, userID string) (domain.ShareAccess, error) {
+	access, ok := repo.collaborators[pageID][userID]
+	if !ok {
+		return "", errs.ErrNotFound
+	}
+	return access, nil
+}
+
+func (repo *inMemoryRepo) ListCollabUsers(_ context.Context, pageID domain.PageID) ([]domain.CollabUser, error) {
+	users := []domain.CollabUser{}
+	for _, user := range repo.collabUsers[pageID] {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// SaveRevision stamps revisions with the page version so GetRevisionAt can
+// look them up by it.
+func (repo *inMemoryRepo) SaveRevision(_ context.Context, pageID domain.PageID, blocks []domain.Block, editorID string, pageUpdatedAt time.Time) error {
+	revision := domain.PageRevision{
+		ID:         domain.RevisionID(fmt.Sprintf("rev-%d", len(repo.revisions)+1)),
+		PageID:     pageID,
+		BlockCount: len(blocks),
+		Blocks:     blocks,
+		CreatedAt:  pageUpdatedAt,
+	}
+	if editorID != "" {
+		revision.EditorID = &editorID
+	}
+	repo.revisions = append(repo.revisions, revision)
 	return nil
 }
 
-func (repo *inMemoryRepo) ListCollabUsers(_ context.Context, _ domain.PageID) ([]domain.CollabUser, error) {
-	return []domain.CollabUser{}, nil
+func (repo *inMemoryRepo) ListRevisions(_ context.Context, pageID domain.PageID, limit, offset int) ([]domain.PageRevision, error) {
+	items := make([]domain.PageRevision, 0)
+	for i := len(repo.revisions) - 1; i >= 0; i-- {
+		if repo.revisions[i].PageID == pageID {
+			revision := repo.revisions[i]
+			revision.Blocks = nil
+			items = append(items, revision)
+		}
+	}
+	if offset >= len(items) {
+		return []domain.PageRevision{}, nil
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end], nil
+}
+
+func (repo *inMemoryRepo) GetRevision(_ context.Context, pageID domain.PageID, revisionID domain.RevisionID) (domain.PageRevision, error) {
+	for _, revision := range repo.revisions {
+		if revision.ID == revisionID && revision.PageID == pageID {
+			return revision, nil
+		}
+	}
+	return domain.PageRevision{}, errs.ErrNotFound
+}
+
+func (repo *inMemoryRepo) GetRevisionAt(_ context.Context, pageID domain.PageID, at time.Time) (domain.PageRevision, error) {
+	for i := len(repo.revisions) - 1; i >= 0; i-- {
+		if revision := repo.revisions[i]; revision.PageID == pageID && !revision.CreatedAt.After(at) {
+			return revision, nil
+		}
+	}
+	return domain.PageRevision{}, errs.ErrNotFound
+}
+
+func (repo *inMemoryRepo) PruneRevisions(_ context.Context, pageID domain.PageID, keep int) error {
+	kept := make([]domain.PageRevision, 0, len(repo.revisions))
+	seen := 0
+	for i := len(repo.revisions) - 1; i >= 0; i-- {
+		revision := repo.revisions[i]
+		if revision.PageID == pageID {
+			seen++
+			if seen > keep {
+				continue
+			}
+		}
+		kept = append([]domain.PageRevision{revision}, kept...)
+	}
+	repo.revisions = kept
+	return nil
 }
 
 type noOpEvents struct{}
@@ -232,6 +839,23 @@ type noOpEvents struct{}
 func (noOpEvents) PageCreated(_ context.Context, _ domain.Page) error   { return nil }
 func (noOpEvents) BlocksUpdated(_ context.Context, _ domain.Page) error { return nil }
 func (noOpEvents) PageDeleted(_ context.Context, _ domain.Page) error   { return nil }
+func (noOpEvents) PagePublished(_ context.Context, _ domain.Page) error { return nil }
+
+type recordingEvents struct {
+	noOpEvents
+	deleted   []domain.PageID
+	published []domain.PageID
+}
+
+func (events *recordingEvents) PageDeleted(_ context.Context, page domain.Page) error {
+	events.deleted = append(events.deleted, page.ID)
+	return nil
+}
+
+func (events *recordingEvents) PagePublished(_ context.Context, page domain.Page) error {
+	events.published = append(events.published, page.ID)
+	return nil
+}
 
 func TestCreateAndGetPage(t *testing.T) {
 	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
@@ -274,7 +898,7 @@ func TestCreateAnonymousPublishedPage(t *testing.T) {
 		Data:     json.RawMessage(`{"text":"hello anonymously"}`),
 	}}
 
-	page, err := service.CreateAnonymousPublishedPage(context.Background(), "Anon post", nil, blocks, false, true, 65, "")
+	page, err := service.CreateAnonymousPublishedPage(context.Background(), "Anon post", nil, blocks, false, true, 65, "", nil)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -288,4 +912,2244 @@ func TestCreateAnonymousPublishedPage(t *testing.T) {
 	if page.PublishedAt == nil {
 		t.Fatalf("expected published_at to be set")
 	}
+	if page.Unlisted {
+		t.Fatalf("expected anonymous page to be public by default")
+	}
+}
+
+// anonymousBlocks is the least content an anonymous page may be published
+// with.
+func anonymousBlocks() []domain.Block {
+	return []domain.Block{{ID: "b1", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":"hello"}`)}}
+}
+
+func TestCreateAnonymousPublishedPageRequiresContent(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	blank := []domain.Block{
+		{ID: "b1", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":"   \n\t"}`)},
+		{ID: "b2", Type: domain.BlockTypeDivider},
+		{ID: "b3", Type: domain.BlockTypeImage, Data: json.RawMessage(`{}`)},
+	}
+	tests := []struct {
+		name   string
+		title  string
+		blocks []domain.Block
+	}{
+		{name: "no blocks", title: "Anon post"},
+		{name: "blank blocks", title: "Anon post", blocks: blank},
+		{name: "whitespace title", title: "   ", blocks: anonymousBlocks()},
+		{name: "punctuation title", title: "!!!...", blocks: anonymousBlocks()},
+		{name: "trivial title", title: "a.", blocks: anonymousBlocks()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CreateAnonymousPublishedPage(ctx, tt.title, nil, tt.blocks, false, true, 65, "", nil); !errors.Is(err, errs.ErrInvalidInput) {
+				t.Fatalf("expected invalid input, got %v", err)
+			}
+		})
+	}
+	if len(repo.store) != 0 {
+		t.Fatalf("expected rejected pages not to be stored, got %d", len(repo.store))
+	}
+
+	image := []domain.Block{
+		{ID: "b1", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":" "}`)},
+		{ID: "b2", Type: domain.BlockTypeImage, Data: json.RawMessage(`{"url":"https://example.com/a.png"}`)},
+	}
+	if _, err := service.CreateAnonymousPublishedPage(ctx, "Photo", nil, image, false, true, 65, "", nil); err != nil {
+		t.Fatalf("expected an image-only page to be accepted, got %v", err)
+	}
+}
+
+func TestCreateAnonymousPublishedPageUsesConfiguredVisibility(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}, WithAnonymousPagesUnlisted(true))
+
+	page, err := service.CreateAnonymousPublishedPage(ctx, "Anon post", nil, anonymousBlocks(), false, true, 65, "", nil)
+	if err != nil {
+		t.Fatalf("create anonymous page: %v", err)
+	}
+	if !page.Published || !page.Unlisted {
+		t.Fatalf("expected anonymous page to be published unlisted, got published=%v unlisted=%v", page.Published, page.Unlisted)
+	}
+	feed, err := service.ListPublishedFeed(ctx, 10, 0, "new", nil, nil, false, "", "")
+	if err != nil {
+		t.Fatalf("list feed: %v", err)
+	}
+	if len(feed) != 0 {
+		t.Fatalf("expected unlisted anonymous page to stay out of the feed, got %d pages", len(feed))
+	}
+}
+
+func TestCreateShareLinkGeneratesShortCode(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}, WithShareCodeLength(8))
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+
+	share, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessView)
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+	if len(share.Code) != 8 {
+		t.Fatalf("expected 8 char share code, got %q", share.Code)
+	}
+	for _, ch := range share.Code {
+		if !strings.ContainsRune(shareCodeAlphabet, ch) {
+			t.Fatalf("unexpected character %q in share code %q", ch, share.Code)
+		}
+	}
+}
+
+func TestCreateShareLinkRetriesOnCodeCollision(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}, WithShareCodeLength(8))
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	repo.shares["existing-token"] = domain.PageShareLink{Token: "existing-token", Code: "TAKEN123", PageID: page.ID, Access: domain.ShareAccessView}
+
+	candidates := []string{"TAKEN123", "TAKEN123", "FRESH456"}
+	calls := 0
+	service.newShareCode = func(int) (string, error) {
+		code := candidates[calls]
+		calls++
+		return code, nil
+	}
+
+	share, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessEdit)
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+	if share.Code != "FRESH456" {
+		t.Fatalf("expected colliding codes to be skipped, got %q", share.Code)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 generation attempts, got %d", calls)
+	}
+
+	service.newShareCode = func(int) (string, error) { return "TAKEN123", nil }
+	if _, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessView); !errors.Is(err, errs.ErrConflict) {
+		t.Fatalf("expected conflict after exhausting attempts, got %v", err)
+	}
+}
+
+func TestResolvePageAccessAcceptsShareCode(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}, WithShareCodeLength(8))
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	share, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessEdit)
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+
+	for _, credential := range []string{share.Token, share.Code} {
+		_, access, err := service.ResolvePageAccess(ctx, "", page.ID, credential, domain.ShareAccessEdit)
+		if err != nil {
+			t.Fatalf("resolve with %q: %v", credential, err)
+		}
+		if access != "edit" {
+			t.Fatalf("expected edit access with %q, got %s", credential, access)
+		}
+	}
+
+	if _, _, err := service.ResolvePageAccess(ctx, "", page.ID, "NOPE9999", domain.ShareAccessView); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected forbidden for unknown code, got %v", err)
+	}
+}
+
+func TestResolvePageAccessPrivacyPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		hidePrivate bool
+		wantErr     error
+	}{
+		{name: "default reports forbidden", hidePrivate: false, wantErr: errs.ErrForbidden},
+		{name: "hidden reports not found", hidePrivate: true, wantErr: errs.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}, WithPrivatePagesHidden(tt.hidePrivate))
+			page, err := service.CreatePage(ctx, "owner-1", "Private", nil, nil)
+			if err != nil {
+				t.Fatalf("create page: %v", err)
+			}
+			view, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessView)
+			if err != nil {
+				t.Fatalf("create share link: %v", err)
+			}
+
+			for _, actor := range []string{"", "someone-else"} {
+				if _, _, err := service.ResolvePageAccess(ctx, actor, page.ID, "", domain.ShareAccessView); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("actor %q without token: expected %v, got %v", actor, tt.wantErr, err)
+				}
+			}
+			if _, _, err := service.ResolvePageAccess(ctx, "", page.ID, "bogus-token", domain.ShareAccessView); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("unknown token: expected %v, got %v", tt.wantErr, err)
+			}
+			if _, _, err := service.ResolvePageAccess(ctx, "", page.ID, view.Token, domain.ShareAccessEdit); !errors.Is(err, errs.ErrForbidden) {
+				t.Fatalf("view link used for edit: expected forbidden, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGetPublicPageHidesArchivedPage(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	page, err := service.CreatePage(ctx, "owner-1", "Archived", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, true, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if err := service.ArchivePage(ctx, "owner-1", page.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	if _, err := service.GetPublicPage(ctx, page.ID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for archived public page, got %v", err)
+	}
+}
+
+func TestGetPageWithAccessIncludesArchivedForOwner(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	page, err := service.CreatePage(ctx, "owner-1", "Archived", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	share, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessView)
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+	if err := service.ArchivePage(ctx, "owner-1", page.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	got, access, err := service.GetPageWithAccess(ctx, "owner-1", page.ID, "", true)
+	if err != nil {
+		t.Fatalf("owner fetch with include_archived: %v", err)
+	}
+	if access != "owner" || got.DeletedAt == nil {
+		t.Fatalf("expected archived page for owner, got access %q page %+v", access, got)
+	}
+	if _, _, err := service.GetPageWithAccess(ctx, "owner-1", page.ID, "", false); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for owner without include_archived, got %v", err)
+	}
+	if _, _, err := service.GetPageWithAccess(ctx, "", page.ID, share.Token, true); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for share link holder, got %v", err)
+	}
+}
+
+func TestUpdateBlocksRecordsRevisionsWithRetention(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}, WithRevisionRetention(2))
+	page, err := service.CreatePage(ctx, "owner-1", "History", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		blocks := make([]domain.Block, i)
+		for j := range blocks {
+			blocks[j] = domain.Block{ID: fmt.Sprintf("b%d", j), Type: domain.BlockTypeParagraph, Position: j, Data: json.RawMessage(`{}`)}
+		}
+		if err := service.UpdateBlocks(ctx, "owner-1", page.ID, blocks); err != nil {
+			t.Fatalf("update blocks %d: %v", i, err)
+		}
+	}
+
+	revisions, err := service.ListRevisions(ctx, "owner-1", page.ID, 30, 0)
+	if err != nil {
+		t.Fatalf("list revisions: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("expected retention to keep 2 revisions, got %d", len(revisions))
+	}
+	if revisions[0].BlockCount != 3 || revisions[1].BlockCount != 2 {
+		t.Fatalf("expected newest revisions first, got block counts %d, %d", revisions[0].BlockCount, revisions[1].BlockCount)
+	}
+	if revisions[0].EditorID == nil || *revisions[0].EditorID != "owner-1" {
+		t.Fatalf("expected editor owner-1, got %v", revisions[0].EditorID)
+	}
+
+	revision, err := service.GetRevision(ctx, "owner-1", page.ID, revisions[0].ID)
+	if err != nil {
+		t.Fatalf("get revision: %v", err)
+	}
+	if len(revision.Blocks) != 3 {
+		t.Fatalf("expected full snapshot with 3 blocks, got %d", len(revision.Blocks))
+	}
+
+	if _, err := service.ListRevisions(ctx, "someone-else", page.ID, 30, 0); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected forbidden for non-owner, got %v", err)
+	}
+}
+
+func TestPreviewShareLink(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	blocks := []domain.Block{{ID: "b1", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":"secret"}`)}}
+	page, err := service.CreatePage(ctx, "owner-1", "Shared draft", nil, blocks)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	share, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessEdit)
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+
+	preview, err := service.PreviewShareLink(ctx, share.Token)
+	if err != nil {
+		t.Fatalf("preview valid token: %v", err)
+	}
+	if preview.PageID != page.ID || preview.Title != "Shared draft" || preview.Access != domain.ShareAccessEdit {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+
+	if err := service.RevokeShareLink(ctx, "owner-1", page.ID, domain.ShareAccessEdit); err != nil {
+		t.Fatalf("revoke share link: %v", err)
+	}
+	if _, err := service.PreviewShareLink(ctx, share.Token); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for revoked token, got %v", err)
+	}
+	if _, err := service.PreviewShareLink(ctx, "unknown-token"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for unknown token, got %v", err)
+	}
+}
+
+func TestClonePage(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	parentID := "b1"
+	blocks := []domain.Block{
+		{ID: "b1", Type: domain.BlockTypeParagraph, Position: 0, Data: json.RawMessage(`{"text":"hello"}`)},
+		{ID: "b2", ParentID: &parentID, Type: domain.BlockTypeImage, Position: 1, Data: json.RawMessage(`{"url":"https://cdn.example/img.png"}`)},
+	}
+	source, err := service.CreatePage(ctx, "owner-1", "Original", nil, blocks)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", source.ID, true, nil); err != nil {
+		t.Fatalf("publish source: %v", err)
+	}
+
+	clone, err := service.ClonePage(ctx, "owner-1", source.ID)
+	if err != nil {
+		t.Fatalf("clone page: %v", err)
+	}
+	if clone.ID == source.ID {
+		t.Fatalf("expected clone to get a new page id")
+	}
+	if clone.Title != "Copy of Original" {
+		t.Fatalf("expected prefixed title, got %q", clone.Title)
+	}
+	if clone.Published || clone.PublishedAt != nil {
+		t.Fatalf("expected clone to be an unpublished draft")
+	}
+	if len(clone.Blocks) != 2 {
+		t.Fatalf("expected 2 cloned blocks, got %d", len(clone.Blocks))
+	}
+	for i, block := range clone.Blocks {
+		if block.ID == blocks[i].ID {
+			t.Fatalf("expected block %d to get a fresh id", i)
+		}
+		if string(block.Data) != string(blocks[i].Data) {
+			t.Fatalf("expected block data to be carried over, got %s", block.Data)
+		}
+	}
+	if clone.Blocks[1].ParentID == nil || *clone.Blocks[1].ParentID != clone.Blocks[0].ID {
+		t.Fatalf("expected nested block to point at cloned parent, got %v", clone.Blocks[1].ParentID)
+	}
+
+	if _, err := service.ClonePage(ctx, "someone-else", source.ID); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected forbidden clone without share, got %v", err)
+	}
+}
+
+func TestCreateShareLinkRejectsAnonymousPage(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	page, err := service.CreateAnonymousPublishedPage(ctx, "Anon post", nil, anonymousBlocks(), false, true, 65, "", nil)
+	if err != nil {
+		t.Fatalf("create anonymous page: %v", err)
+	}
+
+	for _, access := range []domain.ShareAccess{domain.ShareAccessView, domain.ShareAccessEdit} {
+		_, err := service.CreateShareLink(ctx, "owner-1", page.ID, access)
+		if !errors.Is(err, ErrAnonymousPageShare) {
+			t.Fatalf("expected ErrAnonymousPageShare for %s link, got %v", access, err)
+		}
+		if !errors.Is(err, errs.ErrInvalidInput) {
+			t.Fatalf("expected invalid input for %s link, got %v", access, err)
+		}
+	}
+}
+
+func TestSetPagePublishedRateLimit(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}
+	repo := newInMemoryRepo()
+	repo.clock = clock
+	service := NewService(repo, noOpEvents{}, clock, WithPublishRateLimit(2, time.Hour))
+
+	pages := make([]domain.Page, 3)
+	for i := range pages {
+		page, err := service.CreatePage(ctx, "owner-1", fmt.Sprintf("Post %d", i), nil, nil)
+		if err != nil {
+			t.Fatalf("create page %d: %v", i, err)
+		}
+		pages[i] = page
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.SetPagePublished(ctx, "owner-1", pages[i].ID, true, nil); err != nil {
+			t.Fatalf("publish page %d: %v", i, err)
+		}
+		clock.now = clock.now.Add(10 * time.Minute)
+	}
+
+	if _, err := service.SetPagePublished(ctx, "owner-1", pages[2].ID, true, nil); !errors.Is(err, errs.ErrRateLimited) {
+		t.Fatalf("expected rate limited on third publish, got %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", pages[0].ID, true, nil); err != nil {
+		t.Fatalf("expected re-saving an already published page to bypass the limit, got %v", err)
+	}
+
+	if _, err := service.SetPagePublished(ctx, "owner-1", pages[1].ID, false, nil); err != nil {
+		t.Fatalf("unpublish: %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", pages[2].ID, true, nil); !errors.Is(err, errs.ErrRateLimited) {
+		t.Fatalf("expected unpublishing not to free a publish, got %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", pages[1].ID, true, nil); err != nil {
+		t.Fatalf("expected republishing a page not to count against the limit, got %v", err)
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	if _, err := service.SetPagePublished(ctx, "owner-1", pages[2].ID, true, nil); err != nil {
+		t.Fatalf("expected publish to succeed once the window passes, got %v", err)
+	}
+}
+
+func TestListPagesPaginates(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}
+	service := NewService(newInMemoryRepo(), noOpEvents{}, clock)
+
+	for i := 0; i < 5; i++ {
+		if _, err := service.CreatePage(ctx, "owner-1", fmt.Sprintf("Page %d", i), nil, nil); err != nil {
+			t.Fatalf("create page %d: %v", i, err)
+		}
+		clock.now = clock.now.Add(time.Minute)
+	}
+	if _, err := service.CreatePage(ctx, "owner-2", "Someone else", nil, nil); err != nil {
+		t.Fatalf("create other page: %v", err)
+	}
+
+	first, next, err := service.ListPages(ctx, "owner-1", domain.PageStatusAny, 2, 0)
+	if err != nil {
+		t.Fatalf("list first window: %v", err)
+	}
+	if len(first) != 2 || next == nil || *next != 2 {
+		t.Fatalf("expected 2 pages and next offset 2, got %d pages and %v", len(first), next)
+	}
+	if first[0].Title != "Page 4" {
+		t.Fatalf("expected most recently updated page first, got %q", first[0].Title)
+	}
+
+	last, next, err := service.ListPages(ctx, "owner-1", domain.PageStatusAny, 2, 4)
+	if err != nil {
+		t.Fatalf("list last window: %v", err)
+	}
+	if len(last) != 1 || next != nil {
+		t.Fatalf("expected final window of 1 page with no next offset, got %d pages and %v", len(last), next)
+	}
+
+	all, next, err := service.ListPages(ctx, "owner-1", domain.PageStatusAny, 0, 0)
+	if err != nil {
+		t.Fatalf("list with default limit: %v", err)
+	}
+	if len(all) != 5 || next != nil {
+		t.Fatalf("expected all 5 pages under default limit, got %d pages and %v", len(all), next)
+	}
+}
+
+func TestPurgeArchivedPagesRespectsCutoff(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)}
+	repo := newInMemoryRepo()
+	repo.clock = clock
+	events := &recordingEvents{}
+	service := NewService(repo, events, clock)
+	retention := 30 * 24 * time.Hour
+
+	old, err := service.CreatePage(ctx, "owner-1", "Old", nil, nil)
+	if err != nil {
+		t.Fatalf("create old page: %v", err)
+	}
+	boundary, err := service.CreatePage(ctx, "owner-1", "Boundary", nil, nil)
+	if err != nil {
+		t.Fatalf("create boundary page: %v", err)
+	}
+	live, err := service.CreatePage(ctx, "owner-1", "Live", nil, nil)
+	if err != nil {
+		t.Fatalf("create live page: %v", err)
+	}
+
+	if err := service.ArchivePage(ctx, "owner-1", old.ID); err != nil {
+		t.Fatalf("archive old page: %v", err)
+	}
+	clock.now = clock.now.Add(time.Second)
+	if err := service.ArchivePage(ctx, "owner-1", boundary.ID); err != nil {
+		t.Fatalf("archive boundary page: %v", err)
+	}
+
+	// The boundary page was archived exactly retention ago and must survive.
+	clock.now = clock.now.Add(retention)
+	purged, err := service.PurgeArchivedPages(ctx, retention)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("expected 1 purged page, got %d", purged)
+	}
+	if _, ok := repo.store[old.ID]; ok {
+		t.Fatalf("expected old archived page to be purged")
+	}
+	if _, ok := repo.store[boundary.ID]; !ok {
+		t.Fatalf("expected page archived exactly at the cutoff to be kept")
+	}
+	if _, ok := repo.store[live.ID]; !ok {
+		t.Fatalf("expected live page to be kept")
+	}
+	if len(events.deleted) != 1 || events.deleted[0] != old.ID {
+		t.Fatalf("expected a deletion event for the purged page, got %v", events.deleted)
+	}
+
+	if _, err := service.PurgeArchivedPages(ctx, 0); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input for zero retention, got %v", err)
+	}
+}
+
+func TestListPublicBlockTypesGroupsByType(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	page, err := service.CreatePage(ctx, "owner-1", "Mixed", nil, []domain.Block{
+		{ID: "b1", Type: domain.BlockTypeParagraph, Position: 0, Data: json.RawMessage(`{"text":"one"}`)},
+		{ID: "b2", Type: domain.BlockTypeImage, Position: 1, Data: json.RawMessage(`{"url":"https://cdn/a.png"}`)},
+		{ID: "b3", Type: domain.BlockTypeParagraph, Position: 2, Data: json.RawMessage(`{"text":"two"}`)},
+		{ID: "b4", Type: "embed", Position: 3, Data: json.RawMessage(`{"url":"https://example.com"}`)},
+	})
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+
+	if _, err := service.ListPublicBlockTypes(ctx, page.ID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for unpublished page, got %v", err)
+	}
+
+	if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, true, nil); err != nil {
+		t.Fatalf("publish page: %v", err)
+	}
+	counts, err := service.ListPublicBlockTypes(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("list block types: %v", err)
+	}
+	want := map[domain.BlockType]int{domain.BlockTypeParagraph: 2, domain.BlockTypeImage: 1, "embed": 1}
+	if len(counts) != len(want) {
+		t.Fatalf("expected %d distinct types, got %+v", len(want), counts)
+	}
+	for _, count := range counts {
+		if want[count.Type] != count.Count {
+			t.Fatalf("expected %d blocks of type %q, got %d", want[count.Type], count.Type, count.Count)
+		}
+	}
+}
+
+func TestGalleryItemKindsAreValidated(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	valid := []domain.Block{{
+		ID:   "g1",
+		Type: domain.BlockTypeGallery,
+		Data: json.RawMessage(`{"items":[{"kind":"image","value":"https://cdn/a.png"},{"kind":"text","value":"caption"},{"kind":"embed","value":"https://youtube.com/watch?v=1"}]}`),
+	}}
+	page, err := service.CreatePage(ctx, "owner-1", "Gallery", nil, valid)
+	if err != nil {
+		t.Fatalf("expected known gallery kinds to be accepted, got %v", err)
+	}
+
+	unknown := []domain.Block{{
+		ID:   "g1",
+		Type: domain.BlockTypeGallery,
+		Data: json.RawMessage(`{"items":[{"kind":"image","value":"https://cdn/a.png"},{"kind":"video","value":"https://cdn/b.mp4"}]}`),
+	}}
+	if _, err := service.CreatePage(ctx, "owner-1", "Gallery", nil, unknown); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input creating a page with an unknown kind, got %v", err)
+	}
+	if err := service.UpdateBlocks(ctx, "owner-1", page.ID, unknown); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input updating blocks with an unknown kind, got %v", err)
+	}
+	if err := service.UpdateBlocks(ctx, "owner-1", page.ID, valid); err != nil {
+		t.Fatalf("expected update with known kinds to succeed, got %v", err)
+	}
+}
+
+func TestBlockTypesStrictModeRejectsUnknownTypes(t *testing.T) {
+	ctx := context.Background()
+	blocks := []domain.Block{
+		{ID: "b1", Type: domain.BlockTypeHeading, Data: json.RawMessage(`{"text":"Hi"}`)},
+		{ID: "b2", Type: "poll", Data: json.RawMessage(`{}`)},
+	}
+
+	lenient := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	page, err := lenient.CreatePage(ctx, "owner-1", "Lenient", nil, blocks)
+	if err != nil {
+		t.Fatalf("expected unknown types to pass through when not strict, got %v", err)
+	}
+	if len(page.Blocks) != 2 || page.Blocks[1].Type != "poll" {
+		t.Fatalf("expected unknown block stored as is, got %+v", page.Blocks)
+	}
+
+	strict := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}, WithBlockTypes(true, ""))
+	if _, err := strict.CreatePage(ctx, "owner-1", "Strict", nil, blocks); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input for an unknown type in strict mode, got %v", err)
+	}
+	strictPage, err := strict.CreatePage(ctx, "owner-1", "Strict", nil, blocks[:1])
+	if err != nil {
+		t.Fatalf("expected built-in types to pass in strict mode, got %v", err)
+	}
+	if err := strict.UpdateBlocks(ctx, "owner-1", strictPage.ID, blocks); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input updating with an unknown type in strict mode, got %v", err)
+	}
+
+	registered := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}, WithBlockTypes(true, "poll, table"))
+	if _, err := registered.CreatePage(ctx, "owner-1", "Registered", nil, blocks); err != nil {
+		t.Fatalf("expected registered extra type to pass in strict mode, got %v", err)
+	}
+}
+
+func TestBlockPositionsMustFitStorage(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	page, err := service.CreatePage(ctx, "owner-1", "Positions", nil, []domain.Block{
+		{ID: "b1", Type: domain.BlockTypeParagraph, Position: domain.MaxBlockPosition, Data: json.RawMessage(`{"text":"last"}`)},
+	})
+	if err != nil {
+		t.Fatalf("expected the largest position to be accepted, got %v", err)
+	}
+	tooLarge := []domain.Block{
+		{ID: "b1", Type: domain.BlockTypeParagraph, Position: domain.MaxBlockPosition + 1, Data: json.RawMessage(`{"text":"last"}`)},
+	}
+	if _, err := service.CreatePage(ctx, "owner-1", "Positions", nil, tooLarge); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input for an out-of-range position, got %v", err)
+	}
+	if err := service.UpdateBlocks(ctx, "owner-1", page.ID, tooLarge); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input updating with an out-of-range position, got %v", err)
+	}
+	negative := []domain.Block{
+		{ID: "b1", Type: domain.BlockTypeParagraph, Position: -1, Data: json.RawMessage(`{"text":"first"}`)},
+	}
+	if _, err := service.CreatePage(ctx, "owner-1", "Positions", nil, negative); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input for a negative position, got %v", err)
+	}
+	if err := service.UpdateBlocks(ctx, "owner-1", page.ID, negative); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input updating with a negative position, got %v", err)
+	}
+}
+
+func TestDeletePagesSkipsNonOwnedAndEmitsEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	events := &recordingEvents{}
+	service := NewService(repo, events, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	first, err := service.CreatePage(ctx, "owner-1", "First", nil, nil)
+	if err != nil {
+		t.Fatalf("create first page: %v", err)
+	}
+	second, err := service.CreatePage(ctx, "owner-1", "Second", nil, nil)
+	if err != nil {
+		t.Fatalf("create second page: %v", err)
+	}
+	foreign, err := service.CreatePage(ctx, "owner-2", "Foreign", nil, nil)
+	if err != nil {
+		t.Fatalf("create foreign page: %v", err)
+	}
+
+	results, err := service.DeletePages(ctx, "owner-1", []domain.PageID{first.ID, foreign.ID, second.ID, first.ID})
+	if err != nil {
+		t.Fatalf("delete pages: %v", err)
+	}
+
+	want := map[domain.PageID]domain.PageBatchStatus{
+		first.ID:   domain.PageBatchDone,
+		second.ID:  domain.PageBatchDone,
+		foreign.ID: domain.PageBatchSkipped,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected one result per distinct page, got %+v", results)
+	}
+	for _, result := range results {
+		if want[result.PageID] != result.Status {
+			t.Fatalf("expected %s for %s, got %s", want[result.PageID], result.PageID, result.Status)
+		}
+	}
+
+	if len(events.deleted) != 2 || events.deleted[0] != first.ID || events.deleted[1] != second.ID {
+		t.Fatalf("expected deletion events for both owned pages, got %v", events.deleted)
+	}
+	if _, ok := repo.store[foreign.ID]; !ok {
+		t.Fatalf("expected foreign page to survive")
+	}
+	if _, ok := repo.store[first.ID]; ok {
+		t.Fatalf("expected owned page to be deleted")
+	}
+
+	if _, err := service.DeletePages(ctx, "owner-1", nil); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input for empty batch, got %v", err)
+	}
+}
+
+func TestArchivePagesSkipsNonOwned(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	owned, err := service.CreatePage(ctx, "owner-1", "Draft", nil, nil)
+	if err != nil {
+		t.Fatalf("create owned page: %v", err)
+	}
+	foreign, err := service.CreatePage(ctx, "owner-2", "Foreign", nil, nil)
+	if err != nil {
+		t.Fatalf("create foreign page: %v", err)
+	}
+
+	results, err := service.ArchivePages(ctx, "owner-1", []domain.PageID{owned.ID, foreign.ID, owned.ID})
+	if err != nil {
+		t.Fatalf("archive pages: %v", err)
+	}
+
+	want := map[domain.PageID]domain.PageBatchStatus{
+		owned.ID:   domain.PageBatchDone,
+		foreign.ID: domain.PageBatchSkipped,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected one result per distinct page, got %+v", results)
+	}
+	for _, result := range results {
+		if want[result.PageID] != result.Status {
+			t.Fatalf("expected %s for %s, got %s", want[result.PageID], result.PageID, result.Status)
+		}
+		if (result.Status == domain.PageBatchDone) != (result.Error == "") {
+			t.Fatalf("expected an error message only on pages not done, got %+v", result)
+		}
+	}
+
+	if repo.store[owned.ID].DeletedAt == nil {
+		t.Fatalf("expected owned page to be archived")
+	}
+	if repo.store[foreign.ID].DeletedAt != nil {
+		t.Fatalf("expected foreign page to stay active")
+	}
+}
+
+func TestOwnerOnlyOperationsRejectGuestAndEditActors(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	edit, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessEdit)
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+
+	// Edit-link holders may change blocks, which is all they should reach.
+	if _, err := service.UpdateBlocksRealtimeWithShare(ctx, "editor-1", page.ID, nil, nil, edit.Token); err != nil {
+		t.Fatalf("expected editor to update blocks, got %v", err)
+	}
+
+	operations := map[string]func(actorID string) error{
+		"publish": func(actorID string) error {
+			_, err := service.SetPagePublished(ctx, actorID, page.ID, true, nil)
+			return err
+		},
+		"archive": func(actorID string) error { return service.ArchivePage(ctx, actorID, page.ID) },
+		"restore": func(actorID string) error { return service.RestorePage(ctx, actorID, page.ID) },
+		"delete":  func(actorID string) error { return service.DeletePage(ctx, actorID, page.ID) },
+		"create share link": func(actorID string) error {
+			_, err := service.CreateShareLink(ctx, actorID, page.ID, domain.ShareAccessEdit)
+			return err
+		},
+		"revoke share link": func(actorID string) error {
+			return service.RevokeShareLink(ctx, actorID, page.ID, domain.ShareAccessEdit)
+		},
+		"list revisions": func(actorID string) error {
+			_, err := service.ListRevisions(ctx, actorID, page.ID, 10, 0)
+			return err
+		},
+		"list collaborators": func(actorID string) error {
+			_, err := service.ListCollabUsers(ctx, actorID, page.ID)
+			return err
+		},
+	}
+
+	for name, operation := range operations {
+		for _, actor := range []string{"", "editor-1"} {
+			err := operation(actor)
+			if err == nil {
+				t.Fatalf("%s by actor %q: expected rejection", name, actor)
+			}
+			if !errors.Is(err, errs.ErrForbidden) && !errors.Is(err, errs.ErrInvalidInput) {
+				t.Fatalf("%s by actor %q: expected forbidden or invalid input, got %v", name, actor, err)
+			}
+		}
+	}
+
+	if _, err := service.ClonePage(ctx, "", page.ID); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected guest clone to be rejected, got %v", err)
+	}
+
+	got, err := service.GetPage(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("expected page to survive, got %v", err)
+	}
+	if got.Published || got.DeletedAt != nil {
+		t.Fatalf("expected page to stay unpublished and unarchived, got %+v", got)
+	}
+	if _, _, err := service.ResolvePageAccess(ctx, "", page.ID, edit.Token, domain.ShareAccessEdit); err != nil {
+		t.Fatalf("expected edit link to stay valid, got %v", err)
+	}
+}
+
+func TestListPagesRejectsUnknownStatus(t *testing.T) {
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	if _, _, err := service.ListPages(context.Background(), "owner-1", "archived", 10, 0); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input for unknown status, got %v", err)
+	}
+}
+
+func TestValidateShareLink(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}
+	service := NewService(newInMemoryRepo(), noOpEvents{}, clock, WithShareLinkTTL(24*time.Hour))
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	view, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessView)
+	if err != nil {
+		t.Fatalf("create view link: %v", err)
+	}
+	edit, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessEdit)
+	if err != nil {
+		t.Fatalf("create edit link: %v", err)
+	}
+
+	got, err := service.ValidateShareLink(ctx, view.Token)
+	if err != nil {
+		t.Fatalf("validate view link: %v", err)
+	}
+	if want := (domain.ShareValidation{Valid: true, PageID: page.ID, Access: domain.ShareAccessView}); got != want {
+		t.Fatalf("expected %+v for live link, got %+v", want, got)
+	}
+
+	if err := service.RevokeShareLink(ctx, "owner-1", page.ID, domain.ShareAccessEdit); err != nil {
+		t.Fatalf("revoke edit link: %v", err)
+	}
+	got, err = service.ValidateShareLink(ctx, edit.Token)
+	if err != nil {
+		t.Fatalf("validate revoked link: %v", err)
+	}
+	if want := (domain.ShareValidation{Access: domain.ShareAccessEdit, Revoked: true}); got != want {
+		t.Fatalf("expected %+v for revoked link, got %+v", want, got)
+	}
+
+	clock.now = clock.now.Add(24 * time.Hour)
+	got, err = service.ValidateShareLink(ctx, view.Token)
+	if err != nil {
+		t.Fatalf("validate expired link: %v", err)
+	}
+	if want := (domain.ShareValidation{Access: domain.ShareAccessView, Expired: true}); got != want {
+		t.Fatalf("expected %+v for expired link, got %+v", want, got)
+	}
+	if _, _, err := service.ResolvePageAccess(ctx, "", page.ID, view.Token, domain.ShareAccessView); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected expired link to grant no access, got %v", err)
+	}
+
+	got, err = service.ValidateShareLink(ctx, "unknown-token")
+	if err != nil {
+		t.Fatalf("validate unknown link: %v", err)
+	}
+	if got.Valid {
+		t.Fatalf("expected unknown link to be invalid, got %+v", got)
+	}
+}
+
+func TestDeleteOwnerPagesRemovesAllPagesAndEmitsEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	events := &recordingEvents{}
+	service := NewService(repo, events, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	draft, err := service.CreatePage(ctx, "owner-1", "Draft", nil, nil)
+	if err != nil {
+		t.Fatalf("create draft: %v", err)
+	}
+	archived, err := service.CreatePage(ctx, "owner-1", "Archived", nil, nil)
+	if err != nil {
+		t.Fatalf("create archived page: %v", err)
+	}
+	if err := service.ArchivePage(ctx, "owner-1", archived.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	foreign, err := service.CreatePage(ctx, "owner-2", "Foreign", nil, nil)
+	if err != nil {
+		t.Fatalf("create foreign page: %v", err)
+	}
+
+	deleted, err := service.DeleteOwnerPages(ctx, "owner-1")
+	if err != nil {
+		t.Fatalf("delete owner pages: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 pages deleted, got %d", deleted)
+	}
+	for _, pageID := range []domain.PageID{draft.ID, archived.ID} {
+		if _, ok := repo.store[pageID]; ok {
+			t.Fatalf("expected page %s to be deleted", pageID)
+		}
+	}
+	if _, ok := repo.store[foreign.ID]; !ok {
+		t.Fatal("expected other owners' pages to survive")
+	}
+	if len(events.deleted) != 2 {
+		t.Fatalf("expected a deletion event per page for media cleanup, got %v", events.deleted)
+	}
+}
+
+func TestSetProofreadPinnedRequiresOwner(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	page, err := service.CreatePage(ctx, "owner-1", "Reviewed", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	repo.proofreads["proofread-1"] = domain.Proofread{ID: "proofread-1", PageID: page.ID}
+
+	if err := service.SetProofreadPinned(ctx, "owner-2", page.ID, "proofread-1", true); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected forbidden for non-owner, got %v", err)
+	}
+	if err := service.SetProofreadPinned(ctx, "owner-1", page.ID, "proofread-1", true); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+	if !repo.proofreads["proofread-1"].Pinned {
+		t.Fatal("expected proofread to be pinned")
+	}
+	if err := service.SetProofreadPinned(ctx, "owner-1", page.ID, "missing", true); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for unknown proofread, got %v", err)
+	}
+}
+
+func TestListPublishedFeedExcludesBlockedAuthors(t *testing.T) {
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Now()})
+	ctx := context.Background()
+
+	for _, owner := range []string{"alice", "mallory"} {
+		page, err := service.CreatePage(ctx, owner, "By "+owner, nil, nil)
+		if err != nil {
+			t.Fatalf("create page: %v", err)
+		}
+		if _, err := service.SetPagePublished(ctx, owner, page.ID, true, nil); err != nil {
+			t.Fatalf("publish page: %v", err)
+		}
+	}
+
+	feed, err := service.ListPublishedFeed(ctx, 10, 0, "new", nil, []string{"mallory"}, false, "", "")
+	if err != nil {
+		t.Fatalf("list feed: %v", err)
+	}
+	if len(feed) != 1 || feed[0].OwnerID == nil || *feed[0].OwnerID != "alice" {
+		t.Fatalf("expected only alice's page in the feed, got %+v", feed)
+	}
+}
+
+func TestCreatePageNormalizesTags(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	page, err := service.CreatePageWithSettings(ctx, "owner-1", "Tagged", nil, nil, false, true, 65, "", []string{" Machine Learning! ", "machine-learning", "Go", "---"})
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if !slices.Equal(page.Tags, []string{"machine-learning", "go"}) {
+		t.Fatalf("expected normalized tags, got %v", page.Tags)
+	}
+
+	tooMany := make([]string, maxPageTags+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag-%d", i)
+	}
+	if _, err := service.CreatePageWithSettings(ctx, "owner-1", "Over tagged", nil, nil, false, true, 65, "", tooMany); !errors.Is(err, ErrTooManyTags) {
+		t.Fatalf("expected ErrTooManyTags, got %v", err)
+	}
+}
+
+func TestUpdateBlocksRealtimeMergesDisjointEdits(t *testing.T) {
+	paragraph := func(id, text string) domain.Block {
+		return domain.Block{ID: id, Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":"` + text + `"}`)}
+	}
+	tests := []struct {
+		name         string
+		serverBlocks []domain.Block
+		clientBlocks []domain.Block
+		wantTexts    []string
+		wantConflict bool
+	}{
+		{
+			name:         "disjoint edits are merged",
+			serverBlocks: []domain.Block{paragraph("a", "server"), paragraph("b", "b")},
+			clientBlocks: []domain.Block{paragraph("a", "a"), paragraph("b", "client"), paragraph("c", "new")},
+			wantTexts:    []string{"server", "client", "new"},
+		},
+		{
+			name:         "overlapping edits still conflict",
+			serverBlocks: []domain.Block{paragraph("a", "server"), paragraph("b", "b")},
+			clientBlocks: []domain.Block{paragraph("a", "client"), paragraph("b", "b")},
+			wantConflict: true,
+		},
+		{
+			name:         "editing a block the server removed conflicts",
+			serverBlocks: []domain.Block{paragraph("b", "b")},
+			clientBlocks: []domain.Block{paragraph("a", "client"), paragraph("b", "b")},
+			wantConflict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}, WithConflictMerge(true))
+			page, err := service.CreatePage(ctx, "owner-1", "Merge", nil, nil)
+			if err != nil {
+				t.Fatalf("create page: %v", err)
+			}
+			base, err := service.UpdateBlocksRealtime(ctx, "owner-1", page.ID, []domain.Block{paragraph("a", "a"), paragraph("b", "b")}, nil)
+			if err != nil {
+				t.Fatalf("write base: %v", err)
+			}
+			if _, err := service.UpdateBlocksRealtime(ctx, "owner-1", page.ID, tt.serverBlocks, &base.UpdatedAt); err != nil {
+				t.Fatalf("server edit: %v", err)
+			}
+
+			merged, err := service.UpdateBlocksRealtime(ctx, "owner-1", page.ID, tt.clientBlocks, &base.UpdatedAt)
+			if tt.wantConflict {
+				if !errors.Is(err, errs.ErrConflict) {
+					t.Fatalf("expected conflict, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("stale client edit: %v", err)
+			}
+			texts := make([]string, len(merged.Blocks))
+			for i, block := range merged.Blocks {
+				var data struct{ Text string }
+				_ = json.Unmarshal(block.Data, &data)
+				texts[i] = data.Text
+			}
+			if !slices.Equal(texts, tt.wantTexts) {
+				t.Fatalf("expected merged blocks %v, got %v", tt.wantTexts, texts)
+			}
+		})
+	}
+}
+
+func TestUpdateBlocksRealtimeConflictsWithoutMerge(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	page, err := service.CreatePage(ctx, "owner-1", "No merge", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	base := page.UpdatedAt
+	blocks := []domain.Block{{ID: "a", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{}`)}}
+	if _, err := service.UpdateBlocksRealtime(ctx, "owner-1", page.ID, blocks, &base); err != nil {
+		t.Fatalf("first edit: %v", err)
+	}
+	if _, err := service.UpdateBlocksRealtime(ctx, "owner-1", page.ID, []domain.Block{{ID: "b", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{}`)}}, &base); !errors.Is(err, errs.ErrConflict) {
+		t.Fatalf("expected conflict with merging disabled, got %v", err)
+	}
+}
+
+func TestLikePageIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+	page, err := service.CreatePage(ctx, "owner-1", "Likeable", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.LikePage(ctx, "reader-1", page.ID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected unpublished page to be not found, got %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, true, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		count, err := service.LikePage(ctx, "reader-1", page.ID)
+		if err != nil {
+			t.Fatalf("like %d: %v", i+1, err)
+		}
+		if count != 1 {
+			t.Fatalf("like %d: expected 1 like, got %d", i+1, count)
+		}
+	}
+	if count, err := service.LikePage(ctx, "reader-2", page.ID); err != nil || count != 2 {
+		t.Fatalf("expected second reader to add a like, got %d, %v", count, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		count, err := service.UnlikePage(ctx, "reader-1", page.ID)
+		if err != nil {
+			t.Fatalf("unlike %d: %v", i+1, err)
+		}
+		if count != 1 {
+			t.Fatalf("unlike %d: expected 1 like left, got %d", i+1, count)
+		}
+	}
+	if _, err := service.LikePage(ctx, "", page.ID); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected forbidden without a user, got %v", err)
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}
+	service := NewService(newInMemoryRepo(), noOpEvents{}, clock)
+
+	var published []domain.PageID
+	for _, title := range []string{"First", "Second"} {
+		page, err := service.CreatePage(ctx, "author-1", title, nil, nil)
+		if err != nil {
+			t.Fatalf("create page: %v", err)
+		}
+		if _, err := service.SetPagePublished(ctx, "author-1", page.ID, true, nil); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		published = append(published, page.ID)
+	}
+	draft, err := service.CreatePage(ctx, "author-1", "Draft", nil, nil)
+	if err != nil {
+		t.Fatalf("create draft: %v", err)
+	}
+
+	if err := service.Bookmark(ctx, "reader-1", draft.ID); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected forbidden for another user's draft, got %v", err)
+	}
+	for _, pageID := range published {
+		if err := service.Bookmark(ctx, "reader-1", pageID); err != nil {
+			t.Fatalf("bookmark: %v", err)
+		}
+		clock.now = clock.now.Add(time.Minute)
+	}
+	// Bookmarking again is a no-op and keeps the original position.
+	if err := service.Bookmark(ctx, "reader-1", published[0]); err != nil {
+		t.Fatalf("duplicate bookmark: %v", err)
+	}
+
+	pages, next, err := service.ListBookmarks(ctx, "reader-1", 0, 0)
+	if err != nil {
+		t.Fatalf("list bookmarks: %v", err)
+	}
+	if next != nil || len(pages) != 2 {
+		t.Fatalf("expected 2 bookmarks and no next page, got %d and %v", len(pages), next)
+	}
+	if pages[0].ID != published[1] || pages[1].ID != published[0] {
+		t.Fatalf("expected most recently bookmarked first, got %s, %s", pages[0].ID, pages[1].ID)
+	}
+
+	if err := service.Unbookmark(ctx, "reader-1", published[1]); err != nil {
+		t.Fatalf("unbookmark: %v", err)
+	}
+	if err := service.Unbookmark(ctx, "reader-1", published[1]); err != nil {
+		t.Fatalf("repeated unbookmark: %v", err)
+	}
+	pages, _, err = service.ListBookmarks(ctx, "reader-1", 0, 0)
+	if err != nil {
+		t.Fatalf("list bookmarks: %v", err)
+	}
+	if len(pages) != 1 || pages[0].ID != published[0] {
+		t.Fatalf("expected only the first page to remain bookmarked, got %v", pages)
+	}
+}
+
+func TestEstimateReadingStats(t *testing.T) {
+	long := strings.TrimSpace(strings.Repeat("word ", 230))
+	cases := []struct {
+		name        string
+		blocks      []domain.Block
+		wantWords   int
+		wantMinutes int
+	}{
+		{name: "empty page"},
+		{
+			name: "mixed block types",
+			blocks: []domain.Block{
+				{ID: "b1", Type: domain.BlockTypeHeading, Data: json.RawMessage(`{"text":"A short title"}`)},
+				{ID: "b2", Type: domain.BlockTypeImage, Data: json.RawMessage(`{"url":"https://cdn/a.png","text":"not counted"}`)},
+				{ID: "b3", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":"` + long + `"}`)},
+				{ID: "b4", Type: domain.BlockTypeQuote, Data: json.RawMessage(`{"text":"  two   words "}`)},
+			},
+			wantWords:   235,
+			wantMinutes: 2,
+		},
+		{
+			name: "malformed data is skipped",
+			blocks: []domain.Block{
+				{ID: "b1", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":`)},
+				{ID: "b2", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":42}`)},
+				{ID: "b3", Type: domain.BlockTypeParagraph},
+				{ID: "b4", Type: domain.BlockTypeBullet, Data: json.RawMessage(`{"text":"one"}`)},
+			},
+			wantWords:   1,
+			wantMinutes: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			words, minutes := EstimateReadingStats(tc.blocks)
+			if words != tc.wantWords || minutes != tc.wantMinutes {
+				t.Fatalf("expected %d words and %d minutes, got %d and %d", tc.wantWords, tc.wantMinutes, words, minutes)
+			}
+		})
+	}
+}
+
+func TestUpdateBlocksRefreshesReadingStats(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	page, err := service.CreatePage(ctx, "owner-1", "Stats", nil, []domain.Block{
+		{ID: "b1", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":"three little words"}`)},
+	})
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if page.WordCount != 3 || page.ReadingMinutes != 1 {
+		t.Fatalf("expected 3 words and 1 minute on create, got %+v", page.ReadingStats)
+	}
+
+	if err := service.UpdateBlocks(ctx, "owner-1", page.ID, []domain.Block{}); err != nil {
+		t.Fatalf("update blocks: %v", err)
+	}
+	if stats := repo.store[page.ID].ReadingStats; stats.WordCount != 0 || stats.ReadingMinutes != 0 {
+		t.Fatalf("expected cleared stats after emptying the page, got %+v", stats)
+	}
+}
+
+func TestSlugifyTitle(t *testing.T) {
+	cases := map[string]string{
+		"Hello, World!":           "hello-world",
+		"  Café  Été  ":           "café-été",
+		"東京の夜 2026":               "東京の夜-2026",
+		"Straße — Ünïcödé":        "straße-ünïcödé",
+		"🚀🚀🚀":                     "page",
+		strings.Repeat("ab ", 60): strings.TrimRight(strings.Repeat("ab-", 27), "-"),
+	}
+	for title, want := range cases {
+		if got := slugifyTitle(title); got != want {
+			t.Errorf("slugifyTitle(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestPublishAssignsUniqueSlugsPerOwner(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	publish := func(ownerID, title string) domain.Page {
+		t.Helper()
+		page, err := service.CreatePage(ctx, ownerID, title, nil, nil)
+		if err != nil {
+			t.Fatalf("create page: %v", err)
+		}
+		published, err := service.SetPagePublished(ctx, ownerID, page.ID, true, nil)
+		if err != nil {
+			t.Fatalf("publish page: %v", err)
+		}
+		return published
+	}
+
+	first := publish("owner-1", "Hello World")
+	second := publish("owner-1", "hello, world!")
+	third := publish("owner-1", "Hello World")
+	other := publish("owner-2", "Hello World")
+	for page, want := range map[*domain.Page]string{&first: "hello-world", &second: "hello-world-2", &third: "hello-world-3", &other: "hello-world"} {
+		if page.Slug != want {
+			t.Fatalf("expected slug %q, got %q", want, page.Slug)
+		}
+	}
+
+	if _, err := service.SetPagePublished(ctx, "owner-1", second.ID, false, nil); err != nil {
+		t.Fatalf("unpublish: %v", err)
+	}
+	if _, err := service.GetPublicPageBySlug(ctx, "owner-1", "hello-world-2"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected unpublished page to be hidden, got %v", err)
+	}
+	republished, err := service.SetPagePublished(ctx, "owner-1", second.ID, true, nil)
+	if err != nil {
+		t.Fatalf("republish: %v", err)
+	}
+	if republished.Slug != "hello-world-2" {
+		t.Fatalf("expected republish to keep the slug, got %q", republished.Slug)
+	}
+	found, err := service.GetPublicPageBySlug(ctx, "owner-1", "hello-world-2")
+	if err != nil || found.ID != second.ID {
+		t.Fatalf("expected slug to resolve to the second page, got %v, %v", found.ID, err)
+	}
+}
+
+func TestUpdatePageMetaSlugRegeneration(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		regenerate bool
+		want       string
+	}{
+		{regenerate: false, want: "first-draft"},
+		{regenerate: true, want: "final-title"},
+	} {
+		repo := newInMemoryRepo()
+		service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}, WithSlugRegeneration(tc.regenerate))
+		page, err := service.CreatePage(ctx, "owner-1", "First Draft", nil, nil)
+		if err != nil {
+			t.Fatalf("create page: %v", err)
+		}
+		if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, true, nil); err != nil {
+			t.Fatalf("publish page: %v", err)
+		}
+		updated, err := service.UpdatePageMetaRealtime(ctx, "owner-1", page.ID, "Final Title", nil, false, true, 65, "", nil, nil)
+		if err != nil {
+			t.Fatalf("update meta: %v", err)
+		}
+		if updated.Slug != tc.want {
+			t.Fatalf("regenerate=%v: expected slug %q, got %q", tc.regenerate, tc.want, updated.Slug)
+		}
+	}
+}
+
+func TestShareLinkLimits(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, clock)
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	resolve := func(token string) error {
+		_, _, err := service.ResolvePageAccess(ctx, "", page.ID, token, domain.ShareAccessView)
+		return err
+	}
+
+	t.Run("unlimited", func(t *testing.T) {
+		share, err := service.CreateShareLinkWithLimits(ctx, "owner-1", page.ID, domain.ShareAccessView, nil, nil)
+		if err != nil {
+			t.Fatalf("create link: %v", err)
+		}
+		if share.ExpiresAt != nil || share.MaxUses != nil {
+			t.Fatalf("expected no limits, got %+v", share)
+		}
+		for i := 0; i < 5; i++ {
+			if err := resolve(share.Token); err != nil {
+				t.Fatalf("use %d: %v", i+1, err)
+			}
+		}
+		if uses := repo.shares[share.Token].UseCount; uses != 5 {
+			t.Fatalf("expected 5 recorded uses, got %d", uses)
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		past := clock.now.Add(-time.Minute)
+		if _, err := service.CreateShareLinkWithLimits(ctx, "owner-1", page.ID, domain.ShareAccessView, &past, nil); !errors.Is(err, errs.ErrInvalidInput) {
+			t.Fatalf("expected past expiry to be rejected, got %v", err)
+		}
+		expiresAt := clock.now.Add(time.Hour)
+		share, err := service.CreateShareLinkWithLimits(ctx, "owner-1", page.ID, domain.ShareAccessView, &expiresAt, nil)
+		if err != nil {
+			t.Fatalf("create link: %v", err)
+		}
+		if err := resolve(share.Token); err != nil {
+			t.Fatalf("expected live link to resolve, got %v", err)
+		}
+		start := clock.now
+		defer func() { clock.now = start }()
+		clock.now = expiresAt
+		if err := resolve(share.Token); !errors.Is(err, errs.ErrForbidden) {
+			t.Fatalf("expected expired link to be forbidden, got %v", err)
+		}
+	})
+
+	t.Run("exhaustion", func(t *testing.T) {
+		zero := 0
+		if _, err := service.CreateShareLinkWithLimits(ctx, "owner-1", page.ID, domain.ShareAccessView, nil, &zero); !errors.Is(err, errs.ErrInvalidInput) {
+			t.Fatalf("expected zero max uses to be rejected, got %v", err)
+		}
+		maxUses := 2
+		share, err := service.CreateShareLinkWithLimits(ctx, "owner-1", page.ID, domain.ShareAccessView, nil, &maxUses)
+		if err != nil {
+			t.Fatalf("create link: %v", err)
+		}
+		for i := 0; i < maxUses; i++ {
+			if err := resolve(share.Token); err != nil {
+				t.Fatalf("use %d: %v", i+1, err)
+			}
+		}
+		if err := resolve(share.Token); !errors.Is(err, errs.ErrForbidden) {
+			t.Fatalf("expected exhausted link to be forbidden, got %v", err)
+		}
+		validation, err := service.ValidateShareLink(ctx, share.Token)
+		if err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+		if validation.Valid || !validation.Exhausted {
+			t.Fatalf("expected exhausted validation, got %+v", validation)
+		}
+	})
+}
+
+func TestShareLinkUsesCountOncePerActor(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, clock)
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	maxUses := 2
+	share, err := service.CreateShareLinkWithLimits(ctx, "owner-1", page.ID, domain.ShareAccessView, nil, &maxUses)
+	if err != nil {
+		t.Fatalf("create link: %v", err)
+	}
+	visitor := WithShareVisitor(ctx, "visitor-a")
+	for i := 0; i < 5; i++ {
+		if _, _, err := service.ResolvePageAccess(visitor, "", page.ID, share.Token, domain.ShareAccessView); err != nil {
+			t.Fatalf("visitor check %d: %v", i+1, err)
+		}
+		if _, _, err := service.ResolvePageAccess(ctx, "user-1", page.ID, share.Token, domain.ShareAccessView); err != nil {
+			t.Fatalf("user check %d: %v", i+1, err)
+		}
+	}
+	if uses := repo.shares[share.Token].UseCount; uses != 2 {
+		t.Fatalf("expected one use per actor, got %d", uses)
+	}
+	if _, _, err := service.ResolvePageAccess(WithShareVisitor(ctx, "visitor-b"), "", page.ID, share.Token, domain.ShareAccessView); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected a new visitor to be refused an exhausted link, got %v", err)
+	}
+	if _, _, err := service.ResolvePageAccess(visitor, "", page.ID, share.Token, domain.ShareAccessView); err != nil {
+		t.Fatalf("expected an earlier visitor to keep access, got %v", err)
+	}
+}
+
+func TestListAndRevokeShareLinksByToken(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}
+	service := NewService(newInMemoryRepo(), noOpEvents{}, clock)
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	var tokens []string
+	for _, access := range []domain.ShareAccess{domain.ShareAccessView, domain.ShareAccessView, domain.ShareAccessEdit} {
+		clock.now = clock.now.Add(time.Minute)
+		share, err := service.CreateShareLink(ctx, "owner-1", page.ID, access)
+		if err != nil {
+			t.Fatalf("create link: %v", err)
+		}
+		tokens = append(tokens, share.Token)
+	}
+
+	if _, err := service.ListShareLinks(ctx, "someone-else", page.ID); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected non-owner listing to be forbidden, got %v", err)
+	}
+	if err := service.RevokeShareLinkByToken(ctx, "someone-else", page.ID, tokens[0]); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected non-owner revoke to be forbidden, got %v", err)
+	}
+	if err := service.RevokeShareLinkByToken(ctx, "owner-1", page.ID, "unknown"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected unknown token to be not found, got %v", err)
+	}
+	if err := service.RevokeShareLinkByToken(ctx, "owner-1", page.ID, tokens[0]); err != nil {
+		t.Fatalf("revoke by token: %v", err)
+	}
+
+	shares, err := service.ListShareLinks(ctx, "owner-1", page.ID)
+	if err != nil {
+		t.Fatalf("list links: %v", err)
+	}
+	if len(shares) != 3 {
+		t.Fatalf("expected revoked links to stay listed, got %d links", len(shares))
+	}
+	if shares[0].Token != tokens[2] || shares[2].Token != tokens[0] {
+		t.Fatalf("expected newest link first, got %v", shares)
+	}
+	for _, share := range shares {
+		if want := share.Token == tokens[0]; share.Revoked != want {
+			t.Fatalf("expected only %s to be revoked, got %+v", tokens[0], share)
+		}
+	}
+	if _, _, err := service.ResolvePageAccess(ctx, "", page.ID, tokens[1], domain.ShareAccessView); err != nil {
+		t.Fatalf("expected the other view link to keep working, got %v", err)
+	}
+}
+
+func TestScheduledPublishing(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	events := &recordingEvents{}
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}
+	service := NewService(repo, events, clock)
+
+	page, err := service.CreatePage(ctx, "owner-1", "Launch Notes", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	publishAt := clock.now.Add(time.Hour)
+	scheduled, err := service.SchedulePagePublish(ctx, "owner-1", page.ID, publishAt, nil)
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if scheduled.Published || scheduled.PublishAt == nil || !scheduled.PublishAt.Equal(publishAt) {
+		t.Fatalf("expected unpublished page scheduled for %v, got published=%v publish_at=%v", publishAt, scheduled.Published, scheduled.PublishAt)
+	}
+	if _, err := service.SchedulePagePublish(ctx, "owner-2", page.ID, publishAt, nil); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected another user's schedule to be forbidden, got %v", err)
+	}
+
+	clock.now = publishAt.Add(-time.Second)
+	if published, err := service.PublishDuePages(ctx); err != nil || published != 0 {
+		t.Fatalf("expected nothing due before publish_at, got %d, %v", published, err)
+	}
+	if len(events.published) != 0 {
+		t.Fatalf("expected no events before publish_at, got %v", events.published)
+	}
+
+	clock.now = publishAt
+	if published, err := service.PublishDuePages(ctx); err != nil || published != 1 {
+		t.Fatalf("expected the page to be due at publish_at, got %d, %v", published, err)
+	}
+	if len(events.published) != 1 || events.published[0] != page.ID {
+		t.Fatalf("expected one PagePublished event for %s, got %v", page.ID, events.published)
+	}
+	got, err := service.GetPage(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("get page: %v", err)
+	}
+	if !got.Published || got.PublishAt != nil || got.Slug != "launch-notes" {
+		t.Fatalf("expected published page with slug and no schedule, got %+v", got)
+	}
+	if published, err := service.PublishDuePages(ctx); err != nil || published != 0 {
+		t.Fatalf("expected the page to be published only once, got %d, %v", published, err)
+	}
+
+	if _, err := service.SchedulePagePublish(ctx, "owner-1", page.ID, clock.now.Add(time.Hour), nil); !errors.Is(err, errs.ErrConflict) {
+		t.Fatalf("expected scheduling a published page to conflict, got %v", err)
+	}
+}
+
+func TestScheduledPublishingRespectsPublishLimit(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}
+	repo := newInMemoryRepo()
+	repo.clock = clock
+	events := &recordingEvents{}
+	service := NewService(repo, events, clock, WithPublishRateLimit(1, time.Hour))
+
+	publishAt := clock.now.Add(time.Minute)
+	pages := make([]domain.Page, 2)
+	for i := range pages {
+		page, err := service.CreatePage(ctx, "owner-1", fmt.Sprintf("Scheduled %d", i), nil, nil)
+		if err != nil {
+			t.Fatalf("create page %d: %v", i, err)
+		}
+		if _, err := service.SchedulePagePublish(ctx, "owner-1", page.ID, publishAt.Add(time.Duration(i)*time.Second), nil); err != nil {
+			t.Fatalf("schedule page %d: %v", i, err)
+		}
+		pages[i] = page
+	}
+
+	clock.now = publishAt.Add(time.Minute)
+	if published, err := service.PublishDuePages(ctx); err != nil || published != 1 {
+		t.Fatalf("expected one page published within the limit, got %d, %v", published, err)
+	}
+	held, err := service.GetPage(ctx, pages[1].ID)
+	if err != nil {
+		t.Fatalf("get page: %v", err)
+	}
+	if held.Published || held.PublishAt == nil {
+		t.Fatalf("expected the page over the limit to stay scheduled, got %+v", held)
+	}
+
+	clock.now = clock.now.Add(time.Hour + time.Second)
+	if published, err := service.PublishDuePages(ctx); err != nil || published != 1 {
+		t.Fatalf("expected the held page to publish once the window passes, got %d, %v", published, err)
+	}
+	if len(events.published) != 2 || events.published[1] != pages[1].ID {
+		t.Fatalf("expected PagePublished for both pages in order, got %v", events.published)
+	}
+}
+
+func TestCommentThreads(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}
+	service := NewService(repo, noOpEvents{}, clock)
+
+	page, err := service.CreatePage(ctx, "owner-1", "Discussed", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.CreateComment(ctx, page.ID, "", "Ann", "Too early", nil); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected comments on unpublished pages to be not found, got %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, true, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if _, err := service.CreateComment(ctx, page.ID, "", "", "Who am I?", nil); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected anonymous comment without a name to be rejected, got %v", err)
+	}
+
+	comment := func(userID, authorName, body string, parentID *domain.CommentID) domain.Comment {
+		t.Helper()
+		clock.now = clock.now.Add(time.Minute)
+		created, err := service.CreateComment(ctx, page.ID, userID, authorName, body, parentID)
+		if err != nil {
+			t.Fatalf("create comment %q: %v", body, err)
+		}
+		return created
+	}
+	first := comment("", "Ann", "First", nil)
+	second := comment("reader-1", "Reader", "Second", nil)
+	reply := comment("owner-1", "Owner", "Reply to first", &first.ID)
+	nested := comment("", "Ann", "Reply to reply", &reply.ID)
+	laterReply := comment("reader-1", "Reader", "Another reply to first", &first.ID)
+
+	threads, err := service.ListComments(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("list comments: %v", err)
+	}
+	if len(threads) != 2 || threads[0].ID != first.ID || threads[1].ID != second.ID {
+		t.Fatalf("expected top-level comments oldest first, got %+v", threads)
+	}
+	replies := threads[0].Replies
+	if len(replies) != 2 || replies[0].ID != reply.ID || replies[1].ID != laterReply.ID {
+		t.Fatalf("expected replies oldest first, got %+v", replies)
+	}
+	if len(replies[0].Replies) != 1 || replies[0].Replies[0].ID != nested.ID {
+		t.Fatalf("expected nested reply under the owner's reply, got %+v", replies[0].Replies)
+	}
+
+	other, err := service.CreatePage(ctx, "owner-1", "Elsewhere", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", other.ID, true, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if _, err := service.CreateComment(ctx, other.ID, "", "Ann", "Cross-page reply", &first.ID); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected reply to another page's comment to be rejected, got %v", err)
+	}
+}
+
+func TestDeleteCommentAuthorization(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)})
+
+	page, err := service.CreatePage(ctx, "owner-1", "Discussed", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, true, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	byReader, err := service.CreateComment(ctx, page.ID, "reader-1", "Reader", "Mine", nil)
+	if err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	anonymous, err := service.CreateComment(ctx, page.ID, "", "Ann", "Anonymous", nil)
+	if err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	if _, err := service.CreateComment(ctx, page.ID, "reader-2", "Other", "Reply", &byReader.ID); err != nil {
+		t.Fatalf("create reply: %v", err)
+	}
+
+	if err := service.DeleteComment(ctx, "reader-2", page.ID, byReader.ID); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected another reader to be forbidden, got %v", err)
+	}
+	if err := service.DeleteComment(ctx, "", page.ID, anonymous.ID); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected signed-out delete to be forbidden, got %v", err)
+	}
+	if err := service.DeleteComment(ctx, "reader-1", page.ID, byReader.ID); err != nil {
+		t.Fatalf("expected author to delete their comment, got %v", err)
+	}
+	if err := service.DeleteComment(ctx, "owner-1", page.ID, anonymous.ID); err != nil {
+		t.Fatalf("expected page owner to delete any comment, got %v", err)
+	}
+
+	threads, err := service.ListComments(ctx, page.ID)
+	if err != nil {
+		t.Fatalf("list comments: %v", err)
+	}
+	if len(threads) != 0 {
+		t.Fatalf("expected deleted comments and their replies to be gone, got %+v", threads)
+	}
+}
+
+func TestVoteProofread(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	clock := &fakeClock{now: time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC)}
+	service := NewService(repo, noOpEvents{}, clock)
+
+	page, err := service.CreatePage(ctx, "owner-1", "Reviewed", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, true, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	older, err := service.CreateProofread(ctx, page.ID, "Ann", "Helpful", "", "", nil)
+	if err != nil {
+		t.Fatalf("create proofread: %v", err)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	newer, err := service.CreateProofread(ctx, page.ID, "Bo", "Less helpful", "", "", nil)
+	if err != nil {
+		t.Fatalf("create proofread: %v", err)
+	}
+
+	for _, voterKey := range []string{"user:reader-1", "user:reader-1", "reader:abc"} {
+		if _, err := service.VoteProofread(ctx, older.ID, voterKey); err != nil {
+			t.Fatalf("vote %s: %v", voterKey, err)
+		}
+	}
+	count, err := service.VoteProofread(ctx, newer.ID, "user:reader-1")
+	if err != nil {
+		t.Fatalf("vote newer: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 vote on the newer proofread, got %d", count)
+	}
+	if count, _ := repo.CountVotes(ctx, older.ID); count != 2 {
+		t.Fatalf("expected a repeat vote to count once, got %d votes", count)
+	}
+
+	if _, err := service.VoteProofread(ctx, older.ID, ""); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected an empty voter key to be rejected, got %v", err)
+	}
+	if _, err := service.VoteProofread(ctx, "missing", "user:reader-1"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for an unknown proofread, got %v", err)
+	}
+
+	newest, err := service.ListProofreads(ctx, page.ID, "new")
+	if err != nil {
+		t.Fatalf("list new: %v", err)
+	}
+	if len(newest) != 2 || newest[0].ID != newer.ID {
+		t.Fatalf("expected newest proofread first, got %+v", newest)
+	}
+	top, err := service.ListProofreads(ctx, page.ID, "top")
+	if err != nil {
+		t.Fatalf("list top: %v", err)
+	}
+	if len(top) != 2 || top[0].ID != older.ID || top[0].VoteCount != 2 || top[1].VoteCount != 1 {
+		t.Fatalf("expected most voted proofread first with counts, got %+v", top)
+	}
+
+	if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, false, nil); err != nil {
+		t.Fatalf("unpublish: %v", err)
+	}
+	if _, err := service.VoteProofread(ctx, older.ID, "user:reader-2"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected votes on unpublished pages to be not found, got %v", err)
+	}
+}
+
+// failingDeleteEvents fails every deletion event.
+type failingDeleteEvents struct {
+	noOpEvents
+}
+
+func (failingDeleteEvents) PageDeleted(_ context.Context, _ domain.Page) error {
+	return errors.New("nats: timeout")
+}
+
+func TestDeleteSurfacesUnpublishedDeletionEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, failingDeleteEvents{}, fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)})
+
+	page, err := service.CreatePage(ctx, "owner-1", "Gone", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if err := service.DeletePage(ctx, "owner-1", page.ID); !errors.Is(err, ErrDeleteEventNotPublished) {
+		t.Fatalf("expected the failed deletion event to be returned, got %v", err)
+	}
+	if _, ok := repo.store[page.ID]; ok {
+		t.Fatal("expected the page to be deleted despite the failed event")
+	}
+
+	batched, err := service.CreatePage(ctx, "owner-1", "Batched", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	results, err := service.DeletePages(ctx, "owner-1", []domain.PageID{batched.ID})
+	if !errors.Is(err, ErrDeleteEventNotPublished) {
+		t.Fatalf("expected the failed deletion event to be returned, got %v", err)
+	}
+	if len(results) != 1 || results[0].Status != domain.PageBatchDone {
+		t.Fatalf("expected the page to be reported deleted, got %+v", results)
+	}
+}
+
+func TestDeleteProofreadIsOwnerOnly(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)})
+
+	page, err := service.CreatePage(ctx, "owner-1", "Reviewed", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, true, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	proofread, err := service.CreateProofread(ctx, page.ID, "Troll", "Abusive", "", "", nil)
+	if err != nil {
+		t.Fatalf("create proofread: %v", err)
+	}
+
+	if err := service.DeleteProofread(ctx, "owner-2", page.ID, proofread.ID); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected non-owner to be forbidden, got %v", err)
+	}
+	if _, ok := repo.proofreads[proofread.ID]; !ok {
+		t.Fatal("expected proofread to survive a forbidden delete")
+	}
+	if err := service.DeleteProofread(ctx, "owner-1", page.ID, proofread.ID); err != nil {
+		t.Fatalf("expected owner to delete the proofread, got %v", err)
+	}
+	proofreads, err := service.ListProofreads(ctx, page.ID, "new")
+	if err != nil {
+		t.Fatalf("list proofreads: %v", err)
+	}
+	if len(proofreads) != 0 {
+		t.Fatalf("expected the proofread to be gone, got %+v", proofreads)
+	}
+	if err := service.DeleteProofread(ctx, "owner-1", page.ID, proofread.ID); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for a deleted proofread, got %v", err)
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	usernames := map[string]string{"alice": "owner-1", "bob": "owner-2", "carol": "carol-id"}
+	resolver := UserResolverFunc(func(_ context.Context, username string) (string, error) {
+		if id, ok := usernames[username]; ok {
+			return id, nil
+		}
+		return "", errs.ErrNotFound
+	})
+	events := &recordingEvents{}
+	service := NewService(repo, events, fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}, WithUserResolver(resolver))
+
+	page, err := service.CreatePage(ctx, "owner-1", "Handed Off", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, true, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	link, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessView)
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+
+	if _, err := service.AddCollaborator(ctx, "owner-1", page.ID, "carol", domain.ShareAccessEdit); err != nil {
+		t.Fatalf("add collaborator: %v", err)
+	}
+	events.published = nil
+
+	if _, err := service.TransferOwnership(ctx, "owner-2", page.ID, "bob"); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected a non-owner transfer to be forbidden, got %v", err)
+	}
+	if _, err := service.TransferOwnership(ctx, "owner-1", page.ID, "alice"); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected a transfer to yourself to be rejected, got %v", err)
+	}
+	if _, err := service.TransferOwnership(ctx, "owner-1", page.ID, "nobody"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected an unknown username to be not found, got %v", err)
+	}
+
+	transferred, err := service.TransferOwnership(ctx, "owner-1", page.ID, "bob")
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if transferred.OwnerID == nil || *transferred.OwnerID != "owner-2" {
+		t.Fatalf("expected owner-2 to own the page, got %v", transferred.OwnerID)
+	}
+	if transferred.Published || transferred.Slug != "" {
+		t.Fatalf("expected the page unpublished until its new owner publishes it, got published=%v slug=%q", transferred.Published, transferred.Slug)
+	}
+	if len(events.published) != 1 || events.published[0] != page.ID {
+		t.Fatalf("expected the unpublish announced, got %v", events.published)
+	}
+	if !repo.shares[link.Token].Revoked {
+		t.Fatal("expected existing share links to be revoked")
+	}
+	if _, _, err := service.ResolvePageAccess(ctx, "carol-id", page.ID, "", domain.ShareAccessView); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected the previous owner's collaborators to lose access, got %v", err)
+	}
+	if _, err := service.TransferOwnership(ctx, "owner-1", page.ID, "bob"); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected the previous owner to lose access, got %v", err)
+	}
+}
+
+func TestInvitedCollaboratorAccess(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	usernames := map[string]string{"alice": "owner-1", "bob": "editor-1", "carol": "viewer-1"}
+	resolver := UserResolverFunc(func(_ context.Context, username string) (string, error) {
+		if id, ok := usernames[username]; ok {
+			return id, nil
+		}
+		return "", errs.ErrNotFound
+	})
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}, WithUserResolver(resolver))
+
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	blocks := []domain.Block{{ID: "b1", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":"edited"}`)}}
+	if _, err := service.UpdateBlocksRealtimeWithShare(ctx, "editor-1", page.ID, blocks, nil, ""); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected an uninvited user to be forbidden, got %v", err)
+	}
+
+	if _, err := service.AddCollaborator(ctx, "editor-1", page.ID, "bob", domain.ShareAccessEdit); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected a non-owner to be forbidden from adding collaborators, got %v", err)
+	}
+	if _, err := service.AddCollaborator(ctx, "owner-1", page.ID, "alice", domain.ShareAccessEdit); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected the owner to be rejected as a collaborator, got %v", err)
+	}
+	if _, err := service.AddCollaborator(ctx, "owner-1", page.ID, "nobody", domain.ShareAccessEdit); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected an unknown username to be not found, got %v", err)
+	}
+	if _, err := service.AddCollaborator(ctx, "owner-1", page.ID, "bob", "admin"); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected an unknown access level to be rejected, got %v", err)
+	}
+	if _, err := service.AddCollaborator(ctx, "owner-1", page.ID, "bob", domain.ShareAccessEdit); err != nil {
+		t.Fatalf("add editor: %v", err)
+	}
+	if _, err := service.AddCollaborator(ctx, "owner-1", page.ID, "carol", domain.ShareAccessView); err != nil {
+		t.Fatalf("add viewer: %v", err)
+	}
+
+	updated, err := service.UpdateBlocksRealtimeWithShare(ctx, "editor-1", page.ID, blocks, nil, "")
+	if err != nil {
+		t.Fatalf("expected the edit collaborator to update blocks, got %v", err)
+	}
+	if len(updated.Blocks) != 1 || updated.Blocks[0].ID != "b1" {
+		t.Fatalf("expected the collaborator's blocks to be saved, got %+v", updated.Blocks)
+	}
+	if _, access, err := service.GetPageWithAccess(ctx, "viewer-1", page.ID, "", false); err != nil || access != "view" {
+		t.Fatalf("expected the view collaborator to read the page, got access %q and %v", access, err)
+	}
+	if _, err := service.UpdateBlocksRealtimeWithShare(ctx, "viewer-1", page.ID, blocks, nil, ""); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected the view collaborator to be forbidden from editing, got %v", err)
+	}
+
+	if err := service.RemoveCollaborator(ctx, "owner-1", page.ID, "editor-1"); err != nil {
+		t.Fatalf("remove collaborator: %v", err)
+	}
+	if _, err := service.UpdateBlocksRealtimeWithShare(ctx, "editor-1", page.ID, blocks, nil, ""); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected a removed collaborator to lose access, got %v", err)
+	}
+	if err := service.RemoveCollaborator(ctx, "owner-1", page.ID, "editor-1"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected removing a non-collaborator to be not found, got %v", err)
+	}
+}
+
+func TestResolvePageAccessRecordsSignedInGuests(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)})
+
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	share, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessEdit)
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+
+	if _, _, err := service.ResolvePageAccess(ctx, "", page.ID, share.Token, domain.ShareAccessView); err != nil {
+		t.Fatalf("resolve anonymous access: %v", err)
+	}
+	if _, _, err := service.ResolvePageAccess(ctx, "owner-1", page.ID, "", domain.ShareAccessEdit); err != nil {
+		t.Fatalf("resolve owner access: %v", err)
+	}
+	users, err := service.ListCollabUsers(ctx, "owner-1", page.ID)
+	if err != nil {
+		t.Fatalf("list collab users: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("expected anonymous and owner access to go unrecorded, got %+v", users)
+	}
+
+	if _, _, err := service.ResolvePageAccess(ctx, "guest-1", page.ID, share.Token, domain.ShareAccessEdit); err != nil {
+		t.Fatalf("resolve guest access: %v", err)
+	}
+	users, err = service.ListCollabUsers(ctx, "owner-1", page.ID)
+	if err != nil {
+		t.Fatalf("list collab users: %v", err)
+	}
+	if len(users) != 1 || users[0].UserID != "guest-1" || users[0].Access != string(domain.ShareAccessEdit) {
+		t.Fatalf("expected guest-1 recorded with edit access, got %+v", users)
+	}
+}
+
+// failingRevisionRepo fails every SaveRevision.
+type failingRevisionRepo struct {
+	*inMemoryRepo
+}
+
+func (repo failingRevisionRepo) SaveRevision(context.Context, domain.PageID, []domain.Block, string, time.Time) error {
+	return errors.New("revisions table unavailable")
+}
+
+func TestRevisionFailuresAreLoggedWithoutFailingEdits(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}
+	core, logs := observer.New(zap.WarnLevel)
+	service := NewService(failingRevisionRepo{newInMemoryRepo()}, noOpEvents{}, clock, WithLogger(zap.New(core)))
+
+	page, err := service.CreatePage(ctx, "owner-1", "Notes", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.UpdateBlocksRealtime(ctx, "owner-1", page.ID, []domain.Block{{ID: "a", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":"a"}`)}}, nil); err != nil {
+		t.Fatalf("expected the edit to succeed, got %v", err)
+	}
+
+	entries := logs.FilterMessage("record page revision failed").All()
+	if len(entries) != 1 {
+		t.Fatalf("expected one logged revision failure, got %d", len(entries))
+	}
+	if got := entries[0].ContextMap()["page_id"]; got != string(page.ID) {
+		t.Fatalf("expected page_id %q in the log, got %v", page.ID, got)
+	}
+}
+
+func TestPageUnlockTokens(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}
+	service := NewService(repo, noOpEvents{}, clock)
+	page, err := service.CreatePage(ctx, "owner-1", "Locked", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte("open sesame"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	repo.passwords[page.ID] = string(hash)
+
+	if _, err := service.UnlockPage(ctx, page.ID, "guess"); !errors.Is(err, ErrIncorrectPagePassword) {
+		t.Fatalf("expected a wrong password to be refused, got %v", err)
+	}
+	token, err := service.UnlockPage(ctx, page.ID, "open sesame")
+	if err != nil || token == "" {
+		t.Fatalf("expected an unlock token, got %q, %v", token, err)
+	}
+	if !service.VerifyPageUnlock(ctx, page.ID, token) {
+		t.Fatal("expected the token to unlock its page")
+	}
+	if service.VerifyPageUnlock(ctx, "other-page", token) {
+		t.Fatal("expected the token not to unlock another page")
+	}
+	if service.VerifyPageUnlock(ctx, page.ID, token+"x") {
+		t.Fatal("expected a tampered token to be refused")
+	}
+
+	clock.now = clock.now.Add(pageUnlockTTL)
+	if service.VerifyPageUnlock(ctx, page.ID, token) {
+		t.Fatal("expected the token to expire")
+	}
+
+	clock.now = clock.now.Add(-time.Minute)
+	repo.passwords[page.ID] = "changed"
+	if service.VerifyPageUnlock(ctx, page.ID, token) {
+		t.Fatal("expected a password change to revoke the token")
+	}
+}
+
+func TestPublishPasswordOnlyAppliesWithThePublish(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}, WithPublishRateLimit(1, time.Hour))
+	first, err := service.CreatePage(ctx, "owner-1", "First", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	second, err := service.CreatePage(ctx, "owner-1", "Second", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	password := "open sesame"
+	if _, err := service.SetPagePublishedWithPassword(ctx, "owner-1", first.ID, true, nil, &password); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if repo.passwords[first.ID] == "" {
+		t.Fatal("expected the published page to be protected")
+	}
+
+	if _, err := service.SetPagePublishedWithPassword(ctx, "owner-1", second.ID, true, nil, &password); !errors.Is(err, errs.ErrRateLimited) {
+		t.Fatalf("expected the second publish to be rate limited, got %v", err)
+	}
+	if _, ok := repo.passwords[second.ID]; ok {
+		t.Fatal("expected a refused publish to leave the password unset")
+	}
+	if page := repo.store[second.ID]; page.PasswordProtected || page.Published {
+		t.Fatalf("expected the page to be untouched, got %+v", page)
+	}
+}
+
+func TestGetPublicBlockWithAuthorHidesArchivedPages(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newInMemoryRepo(), noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)})
+	page, err := service.CreatePage(ctx, "owner-1", "Embedded", nil, []domain.Block{
+		{ID: "b1", Type: domain.BlockTypeParagraph, Data: json.RawMessage(`{"text":"quoted"}`)},
+	})
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	if _, err := service.SetPagePublished(ctx, "owner-1", page.ID, true, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if _, _, err := service.GetPublicBlockWithAuthor(ctx, page.ID, "b1"); err != nil {
+		t.Fatalf("expected the published block to be served, got %v", err)
+	}
+
+	if err := service.ArchivePage(ctx, "owner-1", page.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if _, _, err := service.GetPublicBlockWithAuthor(ctx, page.ID, "b1"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected an archived page's block to be hidden, got %v", err)
+	}
+}
+
+type failingCollabRepo struct {
+	*inMemoryRepo
+}
+
+func (repo failingCollabRepo) UpsertCollabUser(context.Context, domain.PageID, string, string) error {
+	return errors.New("collaborators table unavailable")
+}
+
+func TestCollaboratorFailuresAreLoggedWithoutRefusingAccess(t *testing.T) {
+	ctx := context.Background()
+	core, logs := observer.New(zap.WarnLevel)
+	service := NewService(failingCollabRepo{newInMemoryRepo()}, noOpEvents{}, fakeClock{now: time.Date(2026, 2, 12, 9, 0, 0, 0, time.UTC)}, WithLogger(zap.New(core)))
+	page, err := service.CreatePage(ctx, "owner-1", "Shared", nil, nil)
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	share, err := service.CreateShareLink(ctx, "owner-1", page.ID, domain.ShareAccessEdit)
+	if err != nil {
+		t.Fatalf("create share link: %v", err)
+	}
+
+	if _, access, err := service.ResolvePageAccess(ctx, "guest-1", page.ID, share.Token, domain.ShareAccessEdit); err != nil || access != "edit" {
+		t.Fatalf("expected the link to grant edit access, got %q, %v", access, err)
+	}
+	entries := logs.FilterMessage("record page collaborator failed").All()
+	if len(entries) != 1 {
+		t.Fatalf("expected one logged collaborator failure, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["page_id"] != string(page.ID) || fields["user_id"] != "guest-1" {
+		t.Fatalf("expected page and user IDs in the log, got %v", fields)
+	}
+}
+
+func TestReconcileCountersCorrectsDrift(t *testing.T) {
+	ctx := context.Background()
+	repo := newInMemoryRepo()
+	core, logs := observer.New(zap.InfoLevel)
+	service := NewService(repo, noOpEvents{}, fakeClock{now: time.Now()}, WithLogger(zap.New(core)))
+
+	text := json.RawMessage(`{"text":"three words here"}`)
+	blocks := []domain.Block{{ID: "b1", Type: domain.BlockTypeParagraph, Data: text}}
+	for _, id := range []domain.PageID{"p1", "p2", "p3"} {
+		repo.store[id] = domain.Page{ID: id, Blocks: blocks, ReadingStats: domain.ReadingStats{WordCount: 3, ReadingMinutes: 1}}
+	}
+	drifted := repo.store["p2"]
+	drifted.ReadingStats = domain.ReadingStats{WordCount: 99, ReadingMinutes: 7}
+	repo.store["p2"] = drifted
+	repo.reads["p1"] = map[string]struct{}{"reader-1": {}, "reader-2": {}}
+	repo.readCounter = 5
+
+	corrected, err := service.ReconcileCounters(ctx, 2)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if corrected != 2 {
+		t.Fatalf("expected the stats and read counter corrected, got %d corrections", corrected)
+	}
+	if got := repo.store["p2"].ReadingStats; got != (domain.ReadingStats{WordCount: 3, ReadingMinutes: 1}) {
+		t.Fatalf("expected p2's stats recomputed from its blocks, got %+v", got)
+	}
+	if repo.readCounter != 2 {
+		t.Fatalf("expected the read counter reset to 2, got %d", repo.readCounter)
+	}
+	if logs.FilterMessage("corrected page reading stats").FilterField(zap.String("page_id", "p2")).Len() != 1 {
+		t.Fatal("expected the stats correction to be logged")
+	}
+
+	corrected, err = service.ReconcileCounters(ctx, 2)
+	if err != nil {
+		t.Fatalf("second reconcile: %v", err)
+	}
+	if corrected != 0 {
+		t.Fatalf("expected nothing left to correct, got %d corrections", corrected)
+	}
 }
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+const (
+	maxSlugLength   = 80
+	maxSlugAttempts = 3
+	// fallbackSlug is used for titles without a single letter or digit.
+	fallbackSlug = "page"
+)
+
+// slugifyTitle lowercases title and collapses everything but letters and
+// digits into single dashes. Letters outside ASCII are kept, so "Café Été"
+// becomes "café-été".
+func slugifyTitle(title string) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			dash = true
+			continue
+		}
+		if dash && builder.Len() > 0 {
+			builder.WriteByte('-')
+		}
+		dash = false
+		builder.WriteRune(r)
+	}
+	slug := []rune(builder.String())
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+	if trimmed := strings.TrimRight(string(slug), "-"); trimmed != "" {
+		return trimmed
+	}
+	return fallbackSlug
+}
+
+// countAlphanumeric counts the letters and digits in title.
+func countAlphanumeric(title string) int {
+	count := 0
+	for _, r := range title {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// slugMatchesBase reports whether slug is base or base with a numeric suffix.
+func slugMatchesBase(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
+
+// nextFreeSlug returns base, or base-2, base-3, ... for the first one not in
+// taken.
+func nextFreeSlug(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, slug := range taken {
+		used[slug] = true
+	}
+	slug := base
+	for n := 2; used[slug]; n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	return slug
+}
+
+// assignSlug gives an owned page a slug derived from its title that no other
+// page of the owner (or no other page at all, when slugs are globally unique)
+// uses, retrying when a concurrent publish takes it first.
+func (service *Service) assignSlug(ctx context.Context, page domain.Page) error {
+	if page.OwnerID == nil {
+		return nil
+	}
+	base := slugifyTitle(page.Title)
+	for attempt := 0; attempt < maxSlugAttempts; attempt++ {
+		taken, err := service.repo.ListTakenSlugs(ctx, *page.OwnerID, base)
+		if err != nil {
+			return fmt.Errorf("list taken slugs: %w", err)
+		}
+		err = service.repo.SetSlug(ctx, page.ID, nextFreeSlug(base, taken))
+		if errors.Is(err, errs.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("set slug: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("assign slug: %w", errs.ErrConflict)
+}
+
+// GetPublicPageBySlug resolves a published page by its author's username and
+// slug.
+func (service *Service) GetPublicPageBySlug(ctx context.Context, ownerUsername, slug string) (domain.Page, error) {
+	if ownerUsername == "" || slug == "" {
+		return domain.Page{}, errs.ErrInvalidInput
+	}
+	page, err := service.repo.GetBySlug(ctx, ownerUsername, slug)
+	if err != nil {
+		return domain.Page{}, fmt.Errorf("get page by slug: %w", err)
+	}
+	if !page.Published || page.DeletedAt != nil {
+		return domain.Page{}, errs.ErrNotFound
+	}
+	return page, nil
+}
+
+// GetPublicPageByGlobalSlug resolves a published page by its slug alone. It
+// finds nothing unless the repository keeps slugs globally unique.
+func (service *Service) GetPublicPageByGlobalSlug(ctx context.Context, slug string) (domain.Page, error) {
+	if slug == "" {
+		return domain.Page{}, errs.ErrInvalidInput
+	}
+	page, err := service.repo.GetBySlug(ctx, "", slug)
+	if err != nil {
+		return domain.Page{}, fmt.Errorf("get page by slug: %w", err)
+	}
+	if !page.Published || page.DeletedAt != nil {
+		return domain.Page{}, errs.ErrNotFound
+	}
+	return page, nil
+}
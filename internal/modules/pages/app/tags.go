@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+const (
+	maxPageTags        = 10
+	maxTagLength       = 32
+	trendingTagsWindow = 7 * 24 * time.Hour
+	maxTrendingTags    = 20
+)
+
+// ErrTooManyTags is returned when a page is given more than maxPageTags tags.
+var ErrTooManyTags = fmt.Errorf("%w: a page can have at most %d tags", errs.ErrInvalidInput, maxPageTags)
+
+// normalizeTags slugs, dedupes and bounds tags. A nil input stays nil so
+// callers can tell "leave tags alone" from "clear tags".
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		slug := slugTag(tag)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		normalized = append(normalized, slug)
+	}
+	if len(normalized) > maxPageTags {
+		return nil, ErrTooManyTags
+	}
+	return normalized, nil
+}
+
+// slugTag lowercases tag and collapses anything outside a-z and 0-9 into
+// single dashes, e.g. "Machine Learning!" becomes "machine-learning".
+func slugTag(tag string) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(tag) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			dash = false
+			builder.WriteRune(r)
+			continue
+		}
+		dash = true
+	}
+	slug := builder.String()
+	if len(slug) > maxTagLength {
+		slug = strings.TrimRight(slug[:maxTagLength], "-")
+	}
+	return slug
+}
+
+// TrendingTags returns the tags used by the most pages first published in
+// the last week.
+func (service *Service) TrendingTags(ctx context.Context) ([]domain.TagCount, error) {
+	since := service.clock.Now().Add(-trendingTagsWindow)
+	tags, err := service.repo.TrendingTags(ctx, since, maxTrendingTags)
+	if err != nil {
+		return nil, fmt.Errorf("list trending tags: %w", err)
+	}
+	return tags, nil
+}
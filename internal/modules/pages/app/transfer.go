@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+var (
+	errTransferToSelf    = fmt.Errorf("%w: page is already yours", errs.ErrInvalidInput)
+	errUnknownUsername   = fmt.Errorf("%w: no user with that username", errs.ErrNotFound)
+	errUsersUnconfigured = errors.New("user lookup is not configured")
+)
+
+// TransferOwnership hands the current owner's page to the user with
+// newOwnerUsername. Existing share links and collaborators are dropped, and a
+// published or scheduled page is unpublished: it only appears on the new
+// owner's profile once they choose to publish it.
+func (service *Service) TransferOwnership(ctx context.Context, currentOwnerID string, pageID domain.PageID, newOwnerUsername string) (domain.Page, error) {
+	newOwnerUsername = strings.TrimSpace(newOwnerUsername)
+	if pageID == "" || newOwnerUsername == "" {
+		return domain.Page{}, errs.ErrInvalidInput
+	}
+	if service.users == nil {
+		return domain.Page{}, errUsersUnconfigured
+	}
+	page, err := service.ownedPage(ctx, pageID, currentOwnerID)
+	if err != nil {
+		return domain.Page{}, err
+	}
+
+	newOwnerID, err := service.users.UserIDByUsername(ctx, newOwnerUsername)
+	if errors.Is(err, errs.ErrNotFound) {
+		return domain.Page{}, errUnknownUsername
+	}
+	if err != nil {
+		return domain.Page{}, fmt.Errorf("resolve new owner: %w", err)
+	}
+	if newOwnerID == currentOwnerID {
+		return domain.Page{}, errTransferToSelf
+	}
+
+	if err := service.repo.TransferOwnership(ctx, pageID, currentOwnerID, newOwnerID); err != nil {
+		return domain.Page{}, fmt.Errorf("transfer ownership: %w", err)
+	}
+	transferred, err := service.repo.GetByID(ctx, pageID)
+	if err != nil {
+		return domain.Page{}, fmt.Errorf("fetch transferred page: %w", err)
+	}
+	if page.Published {
+		if err := service.events.PagePublished(ctx, transferred); err != nil {
+			return domain.Page{}, fmt.Errorf("publish page unpublished: %w", err)
+		}
+	}
+	if err := service.events.BlocksUpdated(ctx, transferred); err != nil {
+		return domain.Page{}, fmt.Errorf("publish page transferred: %w", err)
+	}
+	return transferred, nil
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+type CommentID string
+
+// Comment is a reader's comment on a published page. UserID is nil for
+// anonymous comments and ParentID is set on replies.
+type Comment struct {
+	ID         CommentID  `json:"id"`
+	PageID     PageID     `json:"page_id"`
+	ParentID   *CommentID `json:"parent_id,omitempty"`
+	UserID     *string    `json:"user_id,omitempty"`
+	AuthorName string     `json:"author_name"`
+	Body       string     `json:"body"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// Replies is filled in when comments are listed as threads.
+	Replies []Comment `json:"replies,omitempty"`
+}
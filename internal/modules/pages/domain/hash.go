@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+)
+
+// ContentHash fingerprints what a reader sees of a page: its meta and its
+// blocks in position order. Counters, timestamps and publication state are
+// left out. Block data and tags are canonicalized first, so a page hashes
+// the same whether its blocks come from the client or from jsonb, and
+// whichever loader filled it in.
+func ContentHash(page Page) string {
+	type hashedBlock struct {
+		ID       string          `json:"id"`
+		ParentID *string         `json:"parent_id"`
+		Type     BlockType       `json:"type"`
+		Data     json.RawMessage `json:"data"`
+	}
+	blocks := make([]Block, len(page.Blocks))
+	copy(blocks, page.Blocks)
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].Position < blocks[j].Position })
+
+	hashed := make([]hashedBlock, len(blocks))
+	for i, block := range blocks {
+		hashed[i] = hashedBlock{ID: block.ID, ParentID: block.ParentID, Type: block.Type, Data: canonicalJSON(block.Data)}
+	}
+
+	tags := append([]string{}, page.Tags...)
+	sort.Strings(tags)
+	cover := page.Cover
+	if cover != nil && *cover == "" {
+		cover = nil
+	}
+
+	content, _ := json.Marshal(struct {
+		Title     string        `json:"title"`
+		Cover     *string       `json:"cover"`
+		DarkMode  bool          `json:"dark_mode"`
+		Cinematic bool          `json:"cinematic"`
+		Mood      int           `json:"mood"`
+		BgColor   string        `json:"bg_color"`
+		Tags      []string      `json:"tags"`
+		Blocks    []hashedBlock `json:"blocks"`
+	}{page.Title, cover, page.DarkMode, page.Cinematic, page.Mood, page.BgColor, tags, hashed})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalJSON re-encodes data with object keys sorted and whitespace
+// removed, the same way jsonb hands it back whatever order it was saved in.
+// Numbers keep their literal form. Empty or invalid data becomes null.
+func canonicalJSON(data json.RawMessage) json.RawMessage {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return json.RawMessage("null")
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return canonical
+}
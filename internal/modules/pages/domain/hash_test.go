@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestContentHash(t *testing.T) {
+	base := func() Page {
+		return Page{
+			ID:    "page-1",
+			Title: "Notes",
+			Mood:  65,
+			Tags:  []string{"go"},
+			Blocks: []Block{
+				{ID: "b1", Type: BlockTypeHeading, Position: 0, Data: json.RawMessage(`{"text":"Intro"}`)},
+				{ID: "b2", Type: BlockTypeParagraph, Position: 1, Data: json.RawMessage(`{"text":"Hello"}`)},
+			},
+		}
+	}
+	hash := ContentHash(base())
+	if len(hash) != 64 {
+		t.Fatalf("expected a hex sha256, got %q", hash)
+	}
+
+	stable := map[string]func(*Page){
+		"unchanged": func(*Page) {},
+		"counters and timestamps": func(page *Page) {
+			page.ReadCount, page.LikeCount, page.UpdatedAt = 10, 3, time.Now()
+			page.Published = true
+		},
+		"block data formatting": func(page *Page) {
+			page.Blocks[1].Data = json.RawMessage("{ \"text\" : \"Hello\" }")
+		},
+		"block slice order": func(page *Page) {
+			page.Blocks[0], page.Blocks[1] = page.Blocks[1], page.Blocks[0]
+		},
+	}
+	for name, mutate := range stable {
+		page := base()
+		mutate(&page)
+		if got := ContentHash(page); got != hash {
+			t.Errorf("%s: expected hash to stay %s, got %s", name, hash, got)
+		}
+	}
+
+	changed := map[string]func(*Page){
+		"block edit": func(page *Page) {
+			page.Blocks[1].Data = json.RawMessage(`{"text":"Hello, world"}`)
+		},
+		"block reorder": func(page *Page) {
+			page.Blocks[0].Position, page.Blocks[1].Position = 1, 0
+		},
+		"block removed": func(page *Page) { page.Blocks = page.Blocks[:1] },
+		"title":         func(page *Page) { page.Title = "Notes 2" },
+		"tags":          func(page *Page) { page.Tags = nil },
+	}
+	for name, mutate := range changed {
+		page := base()
+		mutate(&page)
+		if got := ContentHash(page); got == hash {
+			t.Errorf("%s: expected hash to change", name)
+		}
+	}
+}
+
+func TestContentHashIsCanonical(t *testing.T) {
+	empty := ""
+	tests := []struct {
+		name string
+		a, b Page
+	}{
+		{
+			name: "block data key order",
+			a:    Page{Blocks: []Block{{ID: "b1", Type: BlockTypeHeading, Data: json.RawMessage(`{"text":"Intro","level":2}`)}}},
+			b:    Page{Blocks: []Block{{ID: "b1", Type: BlockTypeHeading, Data: json.RawMessage(`{"level":2,"text":"Intro"}`)}}},
+		},
+		{
+			name: "nested key order and numbers",
+			a:    Page{Blocks: []Block{{ID: "b1", Type: BlockTypeParagraph, Data: json.RawMessage(`{"meta":{"b":1.50,"a":[2,1]}}`)}}},
+			b:    Page{Blocks: []Block{{ID: "b1", Type: BlockTypeParagraph, Data: json.RawMessage(`{"meta":{"a":[2,1],"b":1.50}}`)}}},
+		},
+		{
+			name: "tag order",
+			a:    Page{Tags: []string{"notes", "go"}},
+			b:    Page{Tags: []string{"go", "notes"}},
+		},
+		{name: "nil and empty tags", a: Page{}, b: Page{Tags: []string{}}},
+		{name: "nil and empty cover", a: Page{}, b: Page{Cover: &empty}},
+	}
+	for _, tt := range tests {
+		if ContentHash(tt.a) != ContentHash(tt.b) {
+			t.Errorf("%s: expected equal hashes", tt.name)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// NormalizeBlocks collapses each run of consecutive empty paragraphs among
+// siblings into one and drops empty paragraphs that end a sibling list.
+// Blocks are taken in order. An empty paragraph is one with no text and no
+// other data; paragraphs with children or extra data are kept.
+func NormalizeBlocks(blocks []Block) []Block {
+	parents := make(map[string]bool)
+	for _, block := range blocks {
+		if block.ParentID != nil {
+			parents[*block.ParentID] = true
+		}
+	}
+
+	// Per sibling list, the index in normalized of a pending empty paragraph
+	// that has not yet been followed by content.
+	pendingEmpty := make(map[string]int)
+	normalized := make([]Block, 0, len(blocks))
+	for _, block := range blocks {
+		parent := ""
+		if block.ParentID != nil {
+			parent = *block.ParentID
+		}
+		if block.Type == BlockTypeParagraph && !parents[block.ID] && emptyParagraphData(block.Data) {
+			if _, ok := pendingEmpty[parent]; ok {
+				continue
+			}
+			pendingEmpty[parent] = len(normalized)
+		} else {
+			delete(pendingEmpty, parent)
+		}
+		normalized = append(normalized, block)
+	}
+
+	if len(pendingEmpty) == 0 {
+		return normalized
+	}
+	trailing := make(map[int]bool, len(pendingEmpty))
+	for _, index := range pendingEmpty {
+		trailing[index] = true
+	}
+	kept := normalized[:0]
+	for index, block := range normalized {
+		if !trailing[index] {
+			kept = append(kept, block)
+		}
+	}
+	return kept
+}
+
+func emptyParagraphData(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return true
+	}
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return false
+	}
+	for key, value := range data {
+		if key != "text" {
+			return false
+		}
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil || strings.TrimSpace(text) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeBlocks(t *testing.T) {
+	paragraph := func(id, data string) Block {
+		return Block{ID: id, Type: BlockTypeParagraph, Data: json.RawMessage(data)}
+	}
+	child := func(block Block, parentID string) Block {
+		block.ParentID = &parentID
+		return block
+	}
+
+	tests := []struct {
+		name   string
+		blocks []Block
+		want   []string
+	}{
+		{
+			name: "collapses consecutive empties",
+			blocks: []Block{
+				paragraph("a", `{"text":"one"}`),
+				paragraph("e1", `{"text":""}`),
+				paragraph("e2", `{"text":"  "}`),
+				paragraph("e3", `{}`),
+				paragraph("b", `{"text":"two"}`),
+			},
+			want: []string{"a", "e1", "b"},
+		},
+		{
+			name: "trims trailing empties",
+			blocks: []Block{
+				paragraph("a", `{"text":"one"}`),
+				paragraph("e1", `{"text":""}`),
+				paragraph("e2", `null`),
+			},
+			want: []string{"a"},
+		},
+		{
+			name: "keeps single spacers and other block types",
+			blocks: []Block{
+				paragraph("e0", `{"text":""}`),
+				{ID: "h", Type: BlockTypeHeading, Data: json.RawMessage(`{"text":""}`)},
+				paragraph("e1", `{"text":""}`),
+				{ID: "d", Type: BlockTypeDivider, Data: json.RawMessage(`{}`)},
+				{ID: "d2", Type: BlockTypeDivider, Data: json.RawMessage(`{}`)},
+				paragraph("b", `{"text":"end"}`),
+			},
+			want: []string{"e0", "h", "e1", "d", "d2", "b"},
+		},
+		{
+			name: "keeps paragraphs with extra data or children",
+			blocks: []Block{
+				paragraph("a", `{"text":"one"}`),
+				paragraph("styled", `{"text":"","align":"center"}`),
+				paragraph("e1", `{"text":""}`),
+				paragraph("parent", `{"text":""}`),
+				child(paragraph("c", `{"text":"nested"}`), "parent"),
+				paragraph("b", `{"text":"two"}`),
+			},
+			want: []string{"a", "styled", "e1", "parent", "c", "b"},
+		},
+		{
+			name: "runs are per sibling list",
+			blocks: []Block{
+				{ID: "list", Type: BlockTypeParagraph, Data: json.RawMessage(`{"text":"items"}`)},
+				child(paragraph("c1", `{"text":"first"}`), "list"),
+				child(paragraph("ce1", `{"text":""}`), "list"),
+				paragraph("e1", `{"text":""}`),
+				child(paragraph("ce2", `{"text":""}`), "list"),
+				child(paragraph("c2", `{"text":"second"}`), "list"),
+				child(paragraph("ce3", `{"text":""}`), "list"),
+				paragraph("b", `{"text":"after"}`),
+			},
+			want: []string{"list", "c1", "ce1", "e1", "c2", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, block := range NormalizeBlocks(tt.blocks) {
+				got = append(got, block.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"math"
 	"time"
 )
 
@@ -9,11 +10,89 @@ type PageID string
 
 type BlockType string
 
+// Block types the editor and renderers understand.
 const (
 	BlockTypeParagraph BlockType = "paragraph"
+	BlockTypeHeading   BlockType = "heading"
+	BlockTypeHeading2  BlockType = "heading2"
+	BlockTypeHeading3  BlockType = "heading3"
+	BlockTypeBullet    BlockType = "bullet"
+	BlockTypeNumbered  BlockType = "numbered"
+	BlockTypeQuote     BlockType = "quote"
+	BlockTypeCode      BlockType = "code"
+	BlockTypeDivider   BlockType = "divider"
 	BlockTypeImage     BlockType = "image"
+	BlockTypeGallery   BlockType = "gallery"
+	BlockTypeEmbed     BlockType = "embed"
+	BlockTypeMusic     BlockType = "music"
+	BlockTypeCanvas    BlockType = "canvas"
+	BlockTypePageLink  BlockType = "page_link"
 )
 
+// Known reports whether blockType is one of the built-in block types.
+func (blockType BlockType) Known() bool {
+	switch blockType {
+	case BlockTypeParagraph, BlockTypeHeading, BlockTypeHeading2, BlockTypeHeading3,
+		BlockTypeBullet, BlockTypeNumbered, BlockTypeQuote, BlockTypeCode, BlockTypeDivider,
+		BlockTypeImage, BlockTypeGallery, BlockTypeEmbed, BlockTypeMusic, BlockTypeCanvas, BlockTypePageLink:
+		return true
+	}
+	return false
+}
+
+// PageStatus filters an owner's pages by publication state. The zero value
+// matches every page.
+type PageStatus string
+
+const (
+	PageStatusAny       PageStatus = ""
+	PageStatusDraft     PageStatus = "draft"
+	PageStatusPublished PageStatus = "published"
+)
+
+// PageBatchStatus is the per-page outcome of a bulk operation.
+type PageBatchStatus string
+
+const (
+	PageBatchDone     PageBatchStatus = "done"
+	PageBatchSkipped  PageBatchStatus = "skipped"
+	PageBatchNotFound PageBatchStatus = "not_found"
+	PageBatchFailed   PageBatchStatus = "failed"
+)
+
+// PageBatchResult reports what a bulk operation did to one page.
+type PageBatchResult struct {
+	PageID PageID          `json:"page_id"`
+	Status PageBatchStatus `json:"status"`
+	// Error says why the page was not done.
+	Error string `json:"error,omitempty"`
+}
+
+// GalleryItemKind is the kind of a single card inside a gallery block's
+// data.items array.
+type GalleryItemKind string
+
+const (
+	GalleryItemKindImage GalleryItemKind = "image"
+	GalleryItemKindText  GalleryItemKind = "text"
+	GalleryItemKindEmbed GalleryItemKind = "embed"
+)
+
+// Valid reports whether kind is one the editor and renderers understand.
+func (kind GalleryItemKind) Valid() bool {
+	switch kind {
+	case GalleryItemKindImage, GalleryItemKindText, GalleryItemKindEmbed:
+		return true
+	}
+	return false
+}
+
+// MaxBlockPosition is the largest position a block may have. Positions are
+// whole numbers stored in an INT column and sent as int32 over gRPC; keeping
+// them integers also means they survive JSON in any client exactly, where
+// fractional positions would drift and reorder blocks.
+const MaxBlockPosition = math.MaxInt32
+
 type Block struct {
 	ID       string          `json:"id"`
 	PageID   PageID          `json:"page_id,omitempty"`
@@ -23,26 +102,54 @@ type Block struct {
 	Data     json.RawMessage `json:"data"`
 }
 
+// BlockTypeCount reports how many blocks of a given type a page contains.
+type BlockTypeCount struct {
+	Type  BlockType `json:"type"`
+	Count int       `json:"count"`
+}
+
 type Page struct {
-	ID             PageID     `json:"id"`
-	OwnerID        *string    `json:"owner_id,omitempty"`
-	Title          string     `json:"title"`
-	Cover          *string    `json:"cover,omitempty"`
-	Published      bool       `json:"published"`
-	Unlisted       bool       `json:"unlisted"`
-	PublishedAt    *time.Time `json:"published_at,omitempty"`
-	DarkMode       bool       `json:"dark_mode"`
-	Cinematic      bool       `json:"cinematic"`
-	Mood           int        `json:"mood"`
-	BgColor        string     `json:"bg_color"`
-	Blocks         []Block    `json:"blocks"`
-	ProofreadCount int        `json:"proofread_count"`
-	BlockCount     int        `json:"block_count"`
-	ReadCount      int        `json:"read_count"`
-	HasShareLinks  bool       `json:"has_share_links"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	ID          PageID     `json:"id"`
+	OwnerID     *string    `json:"owner_id,omitempty"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug,omitempty"`
+	Cover       *string    `json:"cover,omitempty"`
+	Published   bool       `json:"published"`
+	Unlisted    bool       `json:"unlisted"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	// FirstPublishedAt is set on the first publish and kept across
+	// unpublish/republish; PublishedAt tracks the latest publish.
+	FirstPublishedAt *time.Time `json:"first_published_at,omitempty"`
+	DarkMode         bool       `json:"dark_mode"`
+	Cinematic        bool       `json:"cinematic"`
+	Mood             int        `json:"mood"`
+	BgColor          string     `json:"bg_color"`
+	Blocks           []Block    `json:"blocks"`
+	Tags             []string   `json:"tags"`
+	ProofreadCount   int        `json:"proofread_count"`
+	BlockCount       int        `json:"block_count"`
+	ReadCount        int        `json:"read_count"`
+	LikeCount        int        `json:"like_count"`
+	HasShareLinks    bool       `json:"has_share_links"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+	// ContentHash is set when the page is loaded with all of its blocks; see
+	// ContentHash.
+	ContentHash string `json:"content_hash,omitempty"`
+	// PasswordProtected pages only show their blocks to readers who supply
+	// the page password.
+	PasswordProtected bool `json:"password_protected,omitempty"`
+	// PublishAt is when a scheduled page will be published; it is cleared
+	// once the page is published or unpublished.
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	ReadingStats
+}
+
+// ReadingStats estimates how long a page takes to read.
+type ReadingStats struct {
+	WordCount      int `json:"word_count"`
+	ReadingMinutes int `json:"reading_minutes"`
 }
 
 // FeedPage extends Page with author info for the public feed.
@@ -51,9 +158,45 @@ type FeedPage struct {
 	AuthorUsername    string `json:"author_username"`
 	AuthorDisplayName string `json:"author_display_name"`
 	AuthorAvatarURL   string `json:"author_avatar_url"`
+	CommentCount      int    `json:"comment_count"`
+	// LikedByMe is only set when the feed is requested by a signed-in user.
+	LikedByMe *bool `json:"liked_by_me,omitempty"`
+}
+
+// BookmarkedPage is a page a reader saved, with when they saved it.
+type BookmarkedPage struct {
+	FeedPage
+	BookmarkedAt time.Time `json:"bookmarked_at"`
+}
+
+// TrendingPage is an author's page with its reads in the trending window.
+type TrendingPage struct {
+	Page
+	RecentReads int `json:"recent_reads"`
+}
+
+// PlatformStats are site-wide totals for the admin dashboard.
+type PlatformStats struct {
+	// TotalUsers is an estimate refreshed when the users table is analyzed.
+	TotalUsers int64 `json:"total_users"`
+	// TotalPages is an estimate, archived pages included, refreshed when the
+	// pages table is analyzed.
+	TotalPages int64 `json:"total_pages"`
+	// PublishedPages is exact and excludes archived pages.
+	PublishedPages int64 `json:"published_pages"`
+	// TotalReads counts every recorded read, repeat visits included, and
+	// keeps the reads of pages deleted since.
+	TotalReads int64 `json:"total_reads"`
+}
+
+// TagCount is a tag with the number of recently published pages using it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Pages int    `json:"pages"`
 }
 
-// CollabUser represents a signed-in user who has accessed a page via share link.
+// CollabUser represents a signed-in user who has accessed a page via share
+// link, or whom the owner added as a collaborator.
 type CollabUser struct {
 	UserID      string    `json:"user_id"`
 	Username    string    `json:"username"`
@@ -61,4 +204,6 @@ type CollabUser struct {
 	AvatarURL   string    `json:"avatar_url"`
 	Access      string    `json:"access"`
 	LastSeenAt  time.Time `json:"last_seen_at"`
+	// Invited collaborators have access without a share link.
+	Invited bool `json:"invited"`
 }
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBlockPositionJSONRoundTrip(t *testing.T) {
+	// 2^24+1 is the first integer a float32 cannot hold; positions must not
+	// pass through any lossy float on the way.
+	for _, position := range []int{0, 1, 1<<24 + 1, MaxBlockPosition} {
+		payload, err := json.Marshal(Block{ID: "b1", Type: BlockTypeParagraph, Position: position})
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		var decoded Block
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatalf("unmarshal %s: %v", payload, err)
+		}
+		if decoded.Position != position {
+			t.Fatalf("expected position %d after round trip, got %d", position, decoded.Position)
+		}
+	}
+}
+
+func TestBlockPositionRejectsFractions(t *testing.T) {
+	var block Block
+	if err := json.Unmarshal([]byte(`{"id":"b1","type":"paragraph","position":1.5}`), &block); err == nil {
+		t.Fatalf("expected a fractional position to be rejected, got %d", block.Position)
+	}
+}
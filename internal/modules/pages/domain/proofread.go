@@ -20,6 +20,8 @@ type Proofread struct {
 	Summary     string                `json:"summary"`
 	Stance      string                `json:"stance"`
 	Annotations []ProofreadAnnotation `json:"annotations"`
+	Pinned      bool                  `json:"pinned"`
+	VoteCount   int                   `json:"vote_count"`
 	CreatedAt   time.Time             `json:"created_at"`
 	UpdatedAt   time.Time             `json:"updated_at"`
 }
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type RevisionID string
+
+// PageRevision is a snapshot of a page's blocks taken after an edit.
+// Blocks is only populated when a single revision is fetched.
+type PageRevision struct {
+	ID         RevisionID `json:"id"`
+	PageID     PageID     `json:"page_id"`
+	EditorID   *string    `json:"editor_id,omitempty"`
+	BlockCount int        `json:"block_count"`
+	Blocks     []Block    `json:"blocks,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
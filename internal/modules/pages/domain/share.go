@@ -11,9 +11,48 @@ const (
 
 type PageShareLink struct {
 	Token     string      `json:"token"`
+	Code      string      `json:"code,omitempty"`
 	PageID    PageID      `json:"page_id"`
 	Access    ShareAccess `json:"access"`
 	CreatedBy string      `json:"created_by"`
 	Revoked   bool        `json:"revoked"`
 	CreatedAt time.Time   `json:"created_at"`
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+	// MaxUses caps successful resolutions of the link; nil is unlimited.
+	MaxUses  *int `json:"max_uses,omitempty"`
+	UseCount int  `json:"use_count"`
+}
+
+// Expired reports whether the link has passed its expiry at now.
+func (share PageShareLink) Expired(now time.Time) bool {
+	return share.ExpiresAt != nil && !now.Before(*share.ExpiresAt)
+}
+
+// Exhausted reports whether the link has been used MaxUses times.
+func (share PageShareLink) Exhausted() bool {
+	return share.MaxUses != nil && share.UseCount >= *share.MaxUses
+}
+
+// ShareValidation reports whether a share link can currently be used, and if
+// not, why. PageID is only set on valid links, so a short code can be
+// resolved to its page.
+type ShareValidation struct {
+	Valid     bool        `json:"valid"`
+	PageID    PageID      `json:"page_id,omitempty"`
+	Access    ShareAccess `json:"access,omitempty"`
+	Expired   bool        `json:"expired"`
+	Revoked   bool        `json:"revoked"`
+	Exhausted bool        `json:"exhausted"`
+}
+
+// SharePreview is the minimal view of a shared page shown on a share landing
+// page before the full page is loaded.
+type SharePreview struct {
+	PageID           PageID      `json:"page_id"`
+	Title            string      `json:"title"`
+	Cover            *string     `json:"cover,omitempty"`
+	Access           ShareAccess `json:"access"`
+	OwnerUsername    string      `json:"owner_username"`
+	OwnerDisplayName string      `json:"owner_display_name"`
+	OwnerAvatarURL   string      `json:"owner_avatar_url"`
 }
@@ -9,5 +9,8 @@ import (
 type PageEvents interface {
 	PageCreated(ctx context.Context, page domain.Page) error
 	BlocksUpdated(ctx context.Context, page domain.Page) error
+	// PagePublished reports a change to the page's published state; page
+	// carries the new state.
+	PagePublished(ctx context.Context, page domain.Page) error
 	PageDeleted(ctx context.Context, page domain.Page) error
 }
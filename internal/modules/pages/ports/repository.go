@@ -7,28 +7,87 @@ import (
 	"github.com/reggieanim/jot/internal/modules/pages/domain"
 )
 
+// PasswordChange sets a page's password hash, or removes it when Hash is nil.
+type PasswordChange struct {
+	Hash *string
+}
+
 type PageRepository interface {
 	Create(ctx context.Context, page domain.Page) error
 	UpdateBlocks(ctx context.Context, pageID domain.PageID, blocks []domain.Block) error
-	UpdateBlocksOptimistic(ctx context.Context, pageID domain.PageID, blocks []domain.Block, expectedUpdatedAt *time.Time) error
-	UpdatePageMetaOptimistic(ctx context.Context, pageID domain.PageID, title string, cover *string, darkMode bool, cinematic bool, mood int, bgColor string, expectedUpdatedAt *time.Time) error
-	SetPublished(ctx context.Context, pageID domain.PageID, published bool, unlisted bool) error
+	UpdateBlocksOptimistic(ctx context.Context, pageID domain.PageID, blocks []domain.Block, stats *domain.ReadingStats, expectedUpdatedAt *time.Time) error
+	UpdatePageMetaOptimistic(ctx context.Context, pageID domain.PageID, title string, cover *string, darkMode bool, cinematic bool, mood int, bgColor string, tags []string, expectedUpdatedAt *time.Time) error
+	// SetPublished also cancels any scheduled publish.
+	SetPublished(ctx context.Context, pageID domain.PageID, published bool, unlisted bool, password *PasswordChange) error
+	SchedulePublish(ctx context.Context, pageID domain.PageID, publishAt time.Time, unlisted bool, password *PasswordChange) error
+	ListDuePages(ctx context.Context, now time.Time) ([]domain.Page, error)
+	PublishScheduled(ctx context.Context, pageID domain.PageID, now time.Time) (bool, error)
+	CountPublishedSince(ctx context.Context, ownerID string, since time.Time) (int, error)
 	GetByID(ctx context.Context, pageID domain.PageID) (domain.Page, error)
 	GetByIDWithAuthor(ctx context.Context, pageID domain.PageID) (domain.FeedPage, error)
-	ListPages(ctx context.Context, ownerID string) ([]domain.Page, error)
-	ListPublishedPagesByOwner(ctx context.Context, ownerID string) ([]domain.Page, error)
-	ListPublishedFeed(ctx context.Context, limit, offset int, sort string, authorUserIDs []string) ([]domain.FeedPage, error)
+	// GetBySlug ignores the owner when ownerUsername is empty.
+	GetBySlug(ctx context.Context, ownerUsername, slug string) (domain.Page, error)
+	ListTakenSlugs(ctx context.Context, ownerID, base string) ([]string, error)
+	SetSlug(ctx context.Context, pageID domain.PageID, slug string) error
+	SetPasswordHash(ctx context.Context, pageID domain.PageID, hash *string) error
+	GetPasswordHash(ctx context.Context, pageID domain.PageID) (string, error)
+	GetSummaryWithAuthor(ctx context.Context, pageID domain.PageID) (domain.FeedPage, error)
+	ListPages(ctx context.Context, ownerID string, status domain.PageStatus, limit, offset int) ([]domain.Page, error)
+	CountBlockTypes(ctx context.Context, pageID domain.PageID) ([]domain.BlockTypeCount, error)
+	PurgeArchivedOlderThan(ctx context.Context, cutoff time.Time) ([]domain.Page, error)
+	ListPageIDsAfter(ctx context.Context, after domain.PageID, limit int) ([]domain.PageID, error)
+	// CorrectReadingStats leaves pages changed after updatedAt alone.
+	CorrectReadingStats(ctx context.Context, pageID domain.PageID, updatedAt time.Time, stats domain.ReadingStats) (domain.ReadingStats, bool, error)
+	ReconcileReadCounter(ctx context.Context) (int64, int64, error)
+	ListPublishedPagesByOwner(ctx context.Context, ownerID, tag string, limit, offset int) ([]domain.Page, error)
+	ListPublishedFeed(ctx context.Context, limit, offset int, sort string, authorUserIDs, excludedOwnerIDs []string, excludeSelf bool, tag, viewerID string) ([]domain.FeedPage, error)
+	TrendingTags(ctx context.Context, since time.Time, limit int) ([]domain.TagCount, error)
+	// CreateShareLink returns errs.ErrConflict when the code is already taken.
 	CreateShareLink(ctx context.Context, share domain.PageShareLink) error
 	GetShareLinkByToken(ctx context.Context, token string) (domain.PageShareLink, error)
+	GetShareLinkByCode(ctx context.Context, code string) (domain.PageShareLink, error)
+	// RecordShareLinkUse counts each actorKey once, or every call when it is
+	// empty, and returns ErrConflict once the link has no uses left.
+	RecordShareLinkUse(ctx context.Context, token, actorKey string) error
+	// TransferOwnership also unpublishes the page, clears its slug and drops
+	// its share links and collaborators.
+	TransferOwnership(ctx context.Context, pageID domain.PageID, fromOwnerID, toOwnerID string) error
 	RevokeShareLinksByAccess(ctx context.Context, pageID domain.PageID, ownerID string, access domain.ShareAccess) error
+	RevokeShareLinkByToken(ctx context.Context, pageID domain.PageID, ownerID, token string) error
+	ListShareLinks(ctx context.Context, pageID domain.PageID, ownerID string) ([]domain.PageShareLink, error)
 	DeletePage(ctx context.Context, pageID domain.PageID) error
 	ArchivePage(ctx context.Context, pageID domain.PageID) error
 	RestorePage(ctx context.Context, pageID domain.PageID) error
 	ListArchivedPages(ctx context.Context, ownerID string) ([]domain.Page, error)
 	RecordOrganicRead(ctx context.Context, pageID domain.PageID, readerKey string) (bool, error)
+	TrendingForOwner(ctx context.Context, ownerID string, since time.Time, limit int) ([]domain.TrendingPage, error)
+	PlatformStats(ctx context.Context) (domain.PlatformStats, error)
+	LikePage(ctx context.Context, pageID domain.PageID, userID string) error
+	UnlikePage(ctx context.Context, pageID domain.PageID, userID string) error
+	CountLikes(ctx context.Context, pageID domain.PageID) (int, error)
+	HasLiked(ctx context.Context, pageID domain.PageID, userID string) (bool, error)
+	AddBookmark(ctx context.Context, userID string, pageID domain.PageID, at time.Time) error
+	RemoveBookmark(ctx context.Context, userID string, pageID domain.PageID) error
+	ListBookmarks(ctx context.Context, userID string, limit, offset int) ([]domain.BookmarkedPage, error)
 	CreateProofread(ctx context.Context, proofread domain.Proofread) error
-	ListProofreadsByPageID(ctx context.Context, pageID domain.PageID) ([]domain.Proofread, error)
+	ListProofreadsByPageID(ctx context.Context, pageID domain.PageID, sort string) ([]domain.Proofread, error)
 	GetProofreadByID(ctx context.Context, proofreadID domain.ProofreadID) (domain.Proofread, error)
+	SetProofreadPinned(ctx context.Context, pageID domain.PageID, proofreadID domain.ProofreadID, pinned bool) error
+	DeleteProofread(ctx context.Context, pageID domain.PageID, proofreadID domain.ProofreadID) error
+	VoteProofread(ctx context.Context, proofreadID domain.ProofreadID, voterKey string) (bool, error)
+	CountVotes(ctx context.Context, proofreadID domain.ProofreadID) (int, error)
+	CreateComment(ctx context.Context, comment domain.Comment) error
+	GetComment(ctx context.Context, commentID domain.CommentID) (domain.Comment, error)
+	ListComments(ctx context.Context, pageID domain.PageID) ([]domain.Comment, error)
+	DeleteComment(ctx context.Context, commentID domain.CommentID) error
 	UpsertCollabUser(ctx context.Context, pageID domain.PageID, userID string, access string) error
 	ListCollabUsers(ctx context.Context, pageID domain.PageID) ([]domain.CollabUser, error)
+	AddCollaborator(ctx context.Context, pageID domain.PageID, userID string, access domain.ShareAccess) error
+	RemoveCollaborator(ctx context.Context, pageID domain.PageID, userID string) error
+	GetCollaboratorAccess(ctx context.Context, pageID domain.PageID, userID string) (domain.ShareAccess, error)
+	SaveRevision(ctx context.Context, pageID domain.PageID, blocks []domain.Block, editorID string, pageUpdatedAt time.Time) error
+	GetRevisionAt(ctx context.Context, pageID domain.PageID, at time.Time) (domain.PageRevision, error)
+	ListRevisions(ctx context.Context, pageID domain.PageID, limit, offset int) ([]domain.PageRevision, error)
+	GetRevision(ctx context.Context, pageID domain.PageID, revisionID domain.RevisionID) (domain.PageRevision, error)
+	PruneRevisions(ctx context.Context, pageID domain.PageID, keep int) error
 }
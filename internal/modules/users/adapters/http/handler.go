@@ -1,6 +1,7 @@
 package httpadapter
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
@@ -8,24 +9,109 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	filesapp "github.com/reggieanim/jot/internal/modules/files/app"
 	"github.com/reggieanim/jot/internal/modules/users/app"
 	"github.com/reggieanim/jot/internal/modules/users/domain"
 	"github.com/reggieanim/jot/internal/platform/auth"
+	"github.com/reggieanim/jot/internal/platform/httputil"
+	"github.com/reggieanim/jot/internal/platform/storage"
 	"github.com/reggieanim/jot/internal/shared/errs"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
+const (
+	refreshCookieName = "jot_refresh"
+	refreshCookiePath = "/v1/auth"
+	// Largest request body read to find the email a rate limit is keyed by.
+	maxRateLimitBodyBytes = 4 << 10
+)
+
 type Handler struct {
 	service     *app.Service
 	jwt         *auth.JWTIssuer
 	logger      *zap.Logger
 	oauthCfg    *oauth2.Config
 	frontendURL string
+	timeout     time.Duration
+	// Bounds avatar uploads instead of timeout; zero disables it
+	uploadTimeout time.Duration
+	authLimiter   httputil.Limiter
+	media         storage.MediaStore
+	// Meters avatars against the uploader's storage quota; nil leaves them unmetered
+	storageQuota *filesapp.StorageQuota
+	// Largest decoded avatar, in megapixels; 0 disables the check
+	maxAvatarMegapixels float64
+	// Accept SVG avatars after sanitizing them instead of rejecting them
+	sanitizeSVG bool
+	// Comma-separated user IDs allowed to use the admin routes
+	adminUserIDs string
+}
+
+// Option configures optional Handler behaviour.
+type Option func(*Handler)
+
+// WithRequestTimeout bounds every users route but the Google OAuth callback
+// to timeout. Zero disables it.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.timeout = timeout
+	}
+}
+
+// WithUploadTimeout bounds avatar uploads to timeout instead of the request
+// timeout. Zero disables it.
+func WithUploadTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.uploadTimeout = timeout
+	}
+}
+
+// WithAuthRateLimiter throttles login, signup and password-reset requests per
+// client IP and per email. Without it those routes are unthrottled.
+func WithAuthRateLimiter(limiter httputil.Limiter) Option {
+	return func(h *Handler) {
+		h.authLimiter = limiter
+	}
+}
+
+// WithAvatarUploads stores uploaded profile pictures in media, rejecting
+// images larger than maxMegapixels once decoded. Without it avatar uploads
+// respond 503.
+func WithAvatarUploads(media storage.MediaStore, maxMegapixels float64) Option {
+	return func(h *Handler) {
+		h.media = media
+		h.maxAvatarMegapixels = maxMegapixels
+	}
+}
+
+// WithStorageQuota counts avatars against their owner's storage quota, and
+// frees the space when an avatar is replaced or removed.
+func WithStorageQuota(quota *filesapp.StorageQuota) Option {
+	return func(h *Handler) {
+		h.storageQuota = quota
+	}
+}
+
+// WithSVGSanitizing accepts SVG avatars after stripping scripts and event
+// handlers from them. Without it SVG avatars are rejected.
+func WithSVGSanitizing(enabled bool) Option {
+	return func(h *Handler) {
+		h.sanitizeSVG = enabled
+	}
+}
+
+// WithAdminUserIDs grants the comma-separated users access to the admin
+// routes, such as impersonation. Without it those routes are not registered.
+func WithAdminUserIDs(userIDs string) Option {
+	return func(h *Handler) {
+		h.adminUserIDs = userIDs
+	}
 }
 
 // --- request / response types ---
@@ -41,20 +127,58 @@ type loginRequest struct {
 	Password string `json:"password"`
 }
 
+// updateProfileRequest fields left out of the body are not changed.
 type updateProfileRequest struct {
-	DisplayName string `json:"display_name"`
-	Bio         string `json:"bio"`
-	AvatarURL   string `json:"avatar_url"`
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
 }
 
 type authResponse struct {
-	Token string      `json:"token"`
-	User  domain.User `json:"user"`
+	Token        string      `json:"token"`
+	RefreshToken string      `json:"refresh_token,omitempty"`
+	User         domain.User `json:"user"`
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type changeUsernameRequest struct {
+	Username string `json:"username"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// publicProfileResponse adds the viewer's relationship to a public profile.
+type publicProfileResponse struct {
+	domain.PublicProfile
+	IsFollowing bool `json:"is_following"`
+	IsSelf      bool `json:"is_self"`
+}
+
+// impersonationResponse carries a short-lived token for acting as User.
+type impersonationResponse struct {
+	Token          string        `json:"token"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	ImpersonatedBy domain.UserID `json:"impersonated_by"`
+	User           domain.User   `json:"user"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 // --- registration ---
 
-func RegisterRoutes(router *gin.Engine, service *app.Service, jwtIssuer *auth.JWTIssuer, logger *zap.Logger, googleClientID, googleClientSecret, googleCallbackURL, frontendURL string) {
+func RegisterRoutes(router *gin.Engine, service *app.Service, jwtIssuer *auth.JWTIssuer, logger *zap.Logger, googleClientID, googleClientSecret, googleCallbackURL, frontendURL string, opts ...Option) {
 	oauthCfg := &oauth2.Config{
 		ClientID:     googleClientID,
 		ClientSecret: googleClientSecret,
@@ -63,34 +187,94 @@ func RegisterRoutes(router *gin.Engine, service *app.Service, jwtIssuer *auth.JW
 		Endpoint:     google.Endpoint,
 	}
 	h := &Handler{service: service, jwt: jwtIssuer, logger: logger, oauthCfg: oauthCfg, frontendURL: frontendURL}
+	for _, opt := range opts {
+		opt(h)
+	}
 
-	v1 := router.Group("/v1")
+	root := router.Group("/v1")
+	v1 := root.Group("", httputil.Timeout(h.timeout))
+	uploads := root.Group("", httputil.Timeout(h.uploadTimeout), auth.Middleware(jwtIssuer))
+	uploads.POST("/auth/me/avatar", h.uploadAvatar)
+	v1.DELETE("/auth/me/avatar", auth.Middleware(jwtIssuer), h.removeAvatar)
 
 	// Public auth routes
-	v1.POST("/auth/signup", h.signup)
-	v1.POST("/auth/login", h.login)
+	throttled := v1.Group("")
+	if h.authLimiter != nil {
+		throttled.Use(httputil.RateLimit(h.authLimiter, authRateLimitKeys))
+	}
+	throttled.POST("/auth/signup", h.signup)
+	throttled.POST("/auth/login", h.login)
+	throttled.POST("/auth/forgot", h.forgotPassword)
 	v1.POST("/auth/logout", h.logout)
+	v1.POST("/auth/refresh", h.refresh)
+	v1.POST("/auth/revoke", h.revoke)
+	v1.POST("/auth/reset", h.resetPassword)
+	v1.GET("/auth/verify", h.confirmEmail)
 	v1.GET("/auth/me", auth.OptionalMiddleware(jwtIssuer), h.me)
 	v1.GET("/auth/google", h.googleLogin)
-	v1.GET("/auth/google/callback", h.googleCallback)
+	// The callback makes two round trips to Google before signing in, which
+	// can outlast the request timeout; a 503 there would lose the one-time code.
+	root.GET("/auth/google/callback", h.googleCallback)
 
 	// Public profile
-	v1.GET("/users/username/:username", h.getPublicProfile)
+	v1.GET("/users/username/:username", auth.OptionalMiddleware(jwtIssuer), h.getPublicProfile)
 
 	// Protected routes
 	protected := v1.Group("")
 	protected.Use(auth.Middleware(jwtIssuer))
 	{
 		protected.PUT("/auth/me", h.updateProfile)
+		protected.PATCH("/auth/me", h.updateProfile)
+		protected.DELETE("/auth/me", h.deleteAccount)
+		protected.PUT("/auth/password", h.changePassword)
+		protected.PUT("/auth/username", h.changeUsername)
+		protected.POST("/auth/verify/send", h.sendVerification)
 
 		protected.POST("/users/:userID/follow", h.follow)
 		protected.DELETE("/users/:userID/follow", h.unfollow)
 		protected.GET("/users/:userID/followers", h.listFollowers)
 		protected.GET("/users/:userID/following", h.listFollowing)
+		protected.GET("/users/:userID/follow-counts", h.followCounts)
 		protected.GET("/users/:userID/is-following", h.isFollowing)
+		protected.POST("/users/:userID/block", h.block)
+		protected.DELETE("/users/:userID/block", h.unblock)
+
+		protected.GET("/notifications/unread-count", h.unreadNotificationCount)
+		protected.POST("/notifications/read", h.markNotificationsRead)
+	}
+
+	// Admin routes
+	if h.adminUserIDs != "" {
+		admin := v1.Group("/admin", auth.Middleware(jwtIssuer), auth.RequireAdmin(h.adminUserIDs))
+		admin.POST("/users/:userID/impersonate", h.impersonate)
 	}
 }
 
+// authRateLimitKeys keys auth throttling by client IP and, when the JSON body
+// names one, by email so a single account cannot be hammered from many
+// addresses. The body is restored for the handler.
+func authRateLimitKeys(c *gin.Context) []string {
+	keys := httputil.ClientIPKey(c)
+	if c.Request.Body == nil {
+		return keys
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRateLimitBodyBytes))
+	if err != nil {
+		return keys
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(body, &req) == nil {
+		if email := strings.TrimSpace(strings.ToLower(req.Email)); email != "" {
+			keys = append(keys, "email:"+email)
+		}
+	}
+	return keys
+}
+
 // --- handlers ---
 
 func (h *Handler) signup(c *gin.Context) {
@@ -100,16 +284,19 @@ func (h *Handler) signup(c *gin.Context) {
 		return
 	}
 
-	// Use name as both display name and derive username from email prefix.
-	username := usernameFromEmail(req.Email)
-	user, token, err := h.service.Signup(c.Request.Context(), req.Email, username, req.Name, req.Password)
+	// The username is derived from the email by the service.
+	user, token, err := h.service.Signup(c.Request.Context(), req.Email, "", req.Name, req.Password)
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
-	setTokenCookie(c, token)
-	c.JSON(http.StatusCreated, authResponse{Token: token, User: user})
+	refreshToken, err := h.startSession(c, user.ID, token)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, authResponse{Token: token, RefreshToken: refreshToken, User: user})
 }
 
 func (h *Handler) login(c *gin.Context) {
@@ -125,8 +312,118 @@ func (h *Handler) login(c *gin.Context) {
 		return
 	}
 
-	setTokenCookie(c, token)
-	c.JSON(http.StatusOK, authResponse{Token: token, User: user})
+	refreshToken, err := h.startSession(c, user.ID, token)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, authResponse{Token: token, RefreshToken: refreshToken, User: user})
+}
+
+// refresh rotates the refresh token from the body or cookie into a new
+// access/refresh pair.
+func (h *Handler) refresh(c *gin.Context) {
+	user, token, refreshToken, err := h.service.Rotate(c.Request.Context(), h.refreshTokenFromRequest(c))
+	if err != nil {
+		if errors.Is(err, errs.ErrUnauthorized) {
+			h.clearSessionCookies(c)
+		}
+		h.handleError(c, err)
+		return
+	}
+	h.setTokenCookie(c, token)
+	h.setRefreshCookie(c, refreshToken)
+	c.JSON(http.StatusOK, authResponse{Token: token, RefreshToken: refreshToken, User: user})
+}
+
+// revoke ends the session behind a refresh token, e.g. to sign out another
+// device. The caller's cookies are cleared only when it is their own token.
+func (h *Handler) revoke(c *gin.Context) {
+	refreshToken := h.refreshTokenFromRequest(c)
+	if err := h.service.Revoke(c.Request.Context(), refreshToken); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if cookie, err := c.Cookie(refreshCookieName); err == nil && cookie == refreshToken {
+		h.clearSessionCookies(c)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// forgotPassword always answers 202 so it cannot be used to discover
+// accounts. The reset token is mailed, never logged or returned.
+func (h *Handler) forgotPassword(c *gin.Context) {
+	var req forgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func (h *Handler) resetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) changePassword(c *gin.Context) {
+	uid, _ := auth.GetUserID(c)
+	var req changePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if err := h.service.ChangePassword(c.Request.Context(), uid, req.CurrentPassword, req.NewPassword); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	// Every refresh token was revoked, this session's included.
+	h.clearSessionCookies(c)
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) sendVerification(c *gin.Context) {
+	uid, _ := auth.GetUserID(c)
+	if err := h.service.SendVerification(c.Request.Context(), uid); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	h.logger.Info("email verification requested", zap.String("user_id", string(uid)))
+	c.Status(http.StatusAccepted)
+}
+
+func (h *Handler) confirmEmail(c *gin.Context) {
+	if err := h.service.ConfirmEmail(c.Request.Context(), c.Query("token")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"email_verified": true})
+}
+
+func (h *Handler) changeUsername(c *gin.Context) {
+	uid, _ := auth.GetUserID(c)
+	var req changeUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if err := h.service.ChangeUsername(c.Request.Context(), uid, req.Username); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
 }
 
 func (h *Handler) me(c *gin.Context) {
@@ -150,13 +447,120 @@ func (h *Handler) updateProfile(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
-	if err := h.service.UpdateProfile(c.Request.Context(), uid, req.DisplayName, req.Bio, req.AvatarURL); err != nil {
+	update := domain.ProfileUpdate{DisplayName: req.DisplayName, Bio: req.Bio}
+	if err := h.service.UpdateProfile(c.Request.Context(), uid, update); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) deleteAccount(c *gin.Context) {
+	uid, _ := auth.GetUserID(c)
+	if err := h.service.DeleteAccount(c.Request.Context(), uid); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	h.clearSessionCookies(c)
+	c.Status(http.StatusNoContent)
+}
+
+// uploadAvatar stores a new profile picture, points avatar_url at it and
+// removes the previous picture if it was stored by us. The previous key comes
+// from the users table, never from a client-supplied URL, so this cannot be
+// used to delete other objects in the bucket.
+func (h *Handler) uploadAvatar(c *gin.Context) {
+	if h.media == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "media storage unavailable"})
+		return
+	}
+	uid, _ := auth.GetUserID(c)
+	upload, ok := httputil.ReadImageUpload(c, h.maxAvatarMegapixels, h.sanitizeSVG)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	size := int64(len(upload.Content))
+	if h.storageQuota != nil {
+		err := h.storageQuota.Reserve(ctx, string(uid), size)
+		if errors.Is(err, filesapp.ErrStorageQuotaExceeded) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "storage quota exceeded"})
+			return
+		}
+		if err != nil {
+			h.logger.Error("reserve storage failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "upload failed"})
+			return
+		}
+	}
+	url, key, err := h.media.UploadAvatar(ctx, upload.FileName, upload.ContentType, upload.Content)
+	if err != nil {
+		h.releaseStorage(ctx, uid, size)
+		h.logger.Warn("upload avatar failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "upload failed"})
+		return
+	}
+	previous, err := h.service.SetAvatar(ctx, uid, url, key)
+	if err != nil {
+		if deleteErr := h.media.DeleteObject(ctx, key); deleteErr != nil {
+			h.logger.Warn("delete orphaned avatar failed", zap.String("key", key), zap.Error(deleteErr))
+		}
+		h.releaseStorage(ctx, uid, size)
+		h.handleError(c, err)
+		return
+	}
+	if h.storageQuota != nil {
+		if err := h.storageQuota.Record(ctx, string(uid), key, size); err != nil {
+			h.logger.Warn("record stored avatar failed", zap.String("key", key), zap.Error(err))
+		}
+	}
+	if previous != key {
+		h.deleteStoredAvatar(ctx, previous)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"avatar_url": url})
+}
+
+// removeAvatar clears the profile picture and deletes it if it was stored by
+// us.
+func (h *Handler) removeAvatar(c *gin.Context) {
+	uid, _ := auth.GetUserID(c)
+	previous, err := h.service.SetAvatar(c.Request.Context(), uid, "", "")
+	if err != nil {
 		h.handleError(c, err)
 		return
 	}
+	h.deleteStoredAvatar(c.Request.Context(), previous)
 	c.Status(http.StatusNoContent)
 }
 
+// deleteStoredAvatar removes a replaced avatar object. Keys outside the
+// avatars prefix are left alone whatever recorded them.
+func (h *Handler) deleteStoredAvatar(ctx context.Context, key string) {
+	if h.media == nil || !strings.HasPrefix(key, storage.AvatarKeyPrefix) {
+		return
+	}
+	if err := h.media.DeleteObject(ctx, key); err != nil {
+		h.logger.Warn("delete previous avatar failed", zap.String("key", key), zap.Error(err))
+		return
+	}
+	if h.storageQuota != nil {
+		if err := h.storageQuota.Forget(ctx, key); err != nil {
+			h.logger.Warn("release previous avatar storage failed", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+func (h *Handler) releaseStorage(ctx context.Context, uid domain.UserID, size int64) {
+	if h.storageQuota == nil {
+		return
+	}
+	if err := h.storageQuota.Release(ctx, string(uid), size); err != nil {
+		h.logger.Warn("release storage failed", zap.String("user_id", string(uid)), zap.Error(err))
+	}
+}
+
 func (h *Handler) getPublicProfile(c *gin.Context) {
 	username := c.Param("username")
 	profile, err := h.service.GetPublicProfile(c.Request.Context(), username)
@@ -164,7 +568,20 @@ func (h *Handler) getPublicProfile(c *gin.Context) {
 		h.handleError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, profile)
+
+	response := publicProfileResponse{PublicProfile: profile}
+	if viewerID, ok := auth.GetUserID(c); ok && viewerID != "" {
+		response.IsSelf = viewerID == profile.ID
+		if !response.IsSelf {
+			following, err := h.service.IsFollowing(c.Request.Context(), viewerID, profile.ID)
+			if err != nil {
+				h.handleError(c, err)
+				return
+			}
+			response.IsFollowing = following
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *Handler) follow(c *gin.Context) {
@@ -187,6 +604,26 @@ func (h *Handler) unfollow(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+func (h *Handler) block(c *gin.Context) {
+	uid, _ := auth.GetUserID(c)
+	blockedID := domain.UserID(c.Param("userID"))
+	if err := h.service.BlockUser(c.Request.Context(), uid, blockedID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) unblock(c *gin.Context) {
+	uid, _ := auth.GetUserID(c)
+	blockedID := domain.UserID(c.Param("userID"))
+	if err := h.service.UnblockUser(c.Request.Context(), uid, blockedID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
 func (h *Handler) listFollowers(c *gin.Context) {
 	targetID := domain.UserID(c.Param("userID"))
 	profiles, err := h.service.ListFollowers(c.Request.Context(), targetID)
@@ -207,6 +644,15 @@ func (h *Handler) listFollowing(c *gin.Context) {
 	c.JSON(http.StatusOK, profiles)
 }
 
+func (h *Handler) followCounts(c *gin.Context) {
+	counts, err := h.service.GetFollowCounts(c.Request.Context(), domain.UserID(c.Param("userID")))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, counts)
+}
+
 func (h *Handler) isFollowing(c *gin.Context) {
 	uid, _ := auth.GetUserID(c)
 	targetID := domain.UserID(c.Param("userID"))
@@ -218,6 +664,53 @@ func (h *Handler) isFollowing(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"following": following})
 }
 
+func (h *Handler) unreadNotificationCount(c *gin.Context) {
+	uid, _ := auth.GetUserID(c)
+	count, err := h.service.CountUnreadNotifications(c.Request.Context(), uid)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+func (h *Handler) markNotificationsRead(c *gin.Context) {
+	uid, _ := auth.GetUserID(c)
+	if err := h.service.MarkNotificationsRead(c.Request.Context(), uid); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// impersonate issues the admin a token for acting as another user. The token
+// is not set as a cookie so the admin's own session is left alone.
+func (h *Handler) impersonate(c *gin.Context) {
+	adminID, _ := auth.GetUserID(c)
+	targetID := domain.UserID(c.Param("userID"))
+	user, token, err := h.service.Impersonate(c.Request.Context(), adminID, targetID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	claims, err := h.jwt.Parse(token)
+	if err != nil || claims.ExpiresAt == nil {
+		h.logger.Error("parse issued impersonation token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	h.logger.Info("impersonation token issued",
+		zap.String("user_id", string(user.ID)),
+		zap.String("impersonated_by", string(adminID)),
+	)
+	c.JSON(http.StatusOK, impersonationResponse{
+		Token:          token,
+		ExpiresAt:      claims.ExpiresAt.Time,
+		ImpersonatedBy: adminID,
+		User:           user,
+	})
+}
+
 // --- helpers ---
 
 func (h *Handler) handleError(c *gin.Context, err error) {
@@ -228,20 +721,62 @@ func (h *Handler) handleError(c *gin.Context, err error) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	case errors.Is(err, errs.ErrConflict):
 		c.JSON(http.StatusConflict, gin.H{"error": "conflict"})
+	case errors.Is(err, errs.ErrUnauthorized):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, errs.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 	default:
 		h.logger.Error("internal error", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 	}
 }
 
-func setTokenCookie(c *gin.Context, token string) {
+func (h *Handler) setTokenCookie(c *gin.Context, token string) {
 	c.SetSameSite(http.SameSiteLaxMode)
-	c.SetCookie("jot_token", token, int((7 * 24 * time.Hour).Seconds()), "/", "", false, true)
+	c.SetCookie("jot_token", token, int(h.jwt.TTL().Seconds()), "/", "", false, true)
 }
 
-func (h *Handler) logout(c *gin.Context) {
+// startSession issues a refresh token for userID and sets both cookies.
+func (h *Handler) startSession(c *gin.Context, userID domain.UserID, token string) (string, error) {
+	refreshToken, err := h.service.IssueRefreshToken(c.Request.Context(), userID)
+	if err != nil {
+		return "", err
+	}
+	h.setTokenCookie(c, token)
+	h.setRefreshCookie(c, refreshToken)
+	return refreshToken, nil
+}
+
+// setRefreshCookie scopes the refresh token to the auth routes so it is not
+// sent with every API request.
+func (h *Handler) setRefreshCookie(c *gin.Context, refreshToken string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(refreshCookieName, refreshToken, int(h.service.RefreshTokenTTL().Seconds()), refreshCookiePath, "", false, true)
+}
+
+func (h *Handler) clearSessionCookies(c *gin.Context) {
 	c.SetSameSite(http.SameSiteLaxMode)
 	c.SetCookie("jot_token", "", -1, "/", "", false, true)
+	c.SetCookie(refreshCookieName, "", -1, refreshCookiePath, "", false, true)
+}
+
+func (h *Handler) refreshTokenFromRequest(c *gin.Context) string {
+	var req refreshRequest
+	if err := c.ShouldBindJSON(&req); err == nil && req.RefreshToken != "" {
+		return req.RefreshToken
+	}
+	cookie, _ := c.Cookie(refreshCookieName)
+	return cookie
+}
+
+func (h *Handler) logout(c *gin.Context) {
+	if refreshToken, err := c.Cookie(refreshCookieName); err == nil && refreshToken != "" {
+		if err := h.service.Revoke(c.Request.Context(), refreshToken); err != nil {
+			h.handleError(c, err)
+			return
+		}
+	}
+	h.clearSessionCookies(c)
 	c.Status(http.StatusNoContent)
 }
 
@@ -301,8 +836,10 @@ func (h *Handler) googleCallback(c *gin.Context) {
 		return
 	}
 
-	setTokenCookie(c, jwtToken)
-	_ = user
+	if _, err := h.startSession(c, user.ID, jwtToken); err != nil {
+		h.handleError(c, err)
+		return
+	}
 	c.Redirect(http.StatusFound, h.frontendURL)
 }
 
@@ -312,13 +849,3 @@ func randomState() string {
 	_, _ = rand.Read(b)
 	return base64.URLEncoding.EncodeToString(b)
 }
-
-// usernameFromEmail derives a username from the email local part.
-func usernameFromEmail(email string) string {
-	for i, ch := range email {
-		if ch == '@' {
-			return email[:i]
-		}
-	}
-	return email
-}
@@ -0,0 +1,401 @@
+package httpadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	filesapp "github.com/reggieanim/jot/internal/modules/files/app"
+	"github.com/reggieanim/jot/internal/modules/users/app"
+	"github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/modules/users/ports"
+	"github.com/reggieanim/jot/internal/platform/auth"
+	"github.com/reggieanim/jot/internal/platform/httputil"
+	"github.com/reggieanim/jot/internal/shared/errs"
+	"go.uber.org/zap"
+)
+
+// profileRepo serves one public profile and a fixed set of follows. Methods
+// the tests do not reach fall through to the nil embedded interface.
+type profileRepo struct {
+	ports.UserRepository
+	profile domain.PublicProfile
+	follows map[domain.UserID]bool
+}
+
+func (repo *profileRepo) GetPublicProfileByUsername(_ context.Context, username string) (domain.PublicProfile, error) {
+	if username != repo.profile.Username {
+		return domain.PublicProfile{}, errs.ErrNotFound
+	}
+	return repo.profile, nil
+}
+
+func (repo *profileRepo) IsFollowing(_ context.Context, followerID, followeeID domain.UserID) (bool, error) {
+	return followeeID == repo.profile.ID && repo.follows[followerID], nil
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func TestGetPublicProfileIncludesViewerRelationship(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &profileRepo{
+		profile: domain.PublicProfile{ID: "alice-id", Username: "alice", DisplayName: "Alice"},
+		follows: map[domain.UserID]bool{"bob-id": true},
+	}
+	jwtIssuer := auth.NewJWTIssuer("test-secret")
+	router := gin.New()
+	RegisterRoutes(router, app.NewService(repo, jwtIssuer, systemClock{}), jwtIssuer, zap.NewNop(), "", "", "", "")
+
+	tests := []struct {
+		name          string
+		viewer        domain.UserID
+		wantFollowing bool
+		wantSelf      bool
+	}{
+		{name: "anonymous"},
+		{name: "self", viewer: "alice-id", wantSelf: true},
+		{name: "follower", viewer: "bob-id", wantFollowing: true},
+		{name: "stranger", viewer: "carol-id"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/users/username/alice", nil)
+			if tc.viewer != "" {
+				token, err := jwtIssuer.Issue(tc.viewer, string(tc.viewer)+"@example.com")
+				if err != nil {
+					t.Fatalf("issue token: %v", err)
+				}
+				request.Header.Set("Authorization", "Bearer "+token)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body["username"] != "alice" || body["display_name"] != "Alice" {
+				t.Fatalf("expected base profile fields, got %v", body)
+			}
+			if body["is_following"] != tc.wantFollowing || body["is_self"] != tc.wantSelf {
+				t.Fatalf("expected is_following=%v is_self=%v, got %v", tc.wantFollowing, tc.wantSelf, body)
+			}
+		})
+	}
+}
+
+// avatarRepo holds a single user whose profile the avatar upload updates.
+type avatarRepo struct {
+	ports.UserRepository
+	user      domain.User
+	avatarKey string
+}
+
+func (repo *avatarRepo) GetByID(_ context.Context, id domain.UserID) (domain.User, error) {
+	if id != repo.user.ID {
+		return domain.User{}, errs.ErrNotFound
+	}
+	return repo.user, nil
+}
+
+func (repo *avatarRepo) SetAvatar(_ context.Context, id domain.UserID, url, key string) (string, error) {
+	if id != repo.user.ID {
+		return "", errs.ErrNotFound
+	}
+	previous := repo.avatarKey
+	repo.user.AvatarURL, repo.avatarKey = url, key
+	return previous, nil
+}
+
+type avatarMediaStore struct {
+	uploads int
+	deleted []string
+}
+
+func (store *avatarMediaStore) UploadImage(_ context.Context, _ string, _ string, _ []byte) (string, string, error) {
+	return "", "", nil
+}
+
+func (store *avatarMediaStore) UploadAudio(_ context.Context, _ string, _ string, _ []byte) (string, string, error) {
+	return "", "", nil
+}
+
+func (store *avatarMediaStore) UploadAvatar(_ context.Context, _ string, _ string, _ []byte) (string, string, error) {
+	store.uploads++
+	return "https://cdn.test/bucket/avatars/new.png", "avatars/new.png", nil
+}
+
+func (store *avatarMediaStore) DeleteObject(_ context.Context, objectKey string) error {
+	store.deleted = append(store.deleted, objectKey)
+	return nil
+}
+
+func (store *avatarMediaStore) ObjectKeyFromURL(rawURL string) string {
+	if key, ok := strings.CutPrefix(rawURL, "https://cdn.test/bucket/"); ok {
+		return key
+	}
+	return ""
+}
+
+func avatarUploadRequest(t *testing.T, token string, content []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="avatar.png"`)
+	header.Set("Content-Type", "image/png")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		t.Fatalf("create part: %v", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		t.Fatalf("write part: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close writer: %v", err)
+	}
+	request := httptest.NewRequest(http.MethodPost, "/v1/auth/me/avatar", &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.Header.Set("Authorization", "Bearer "+token)
+	return request
+}
+
+func TestUploadAvatar(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &avatarRepo{user: domain.User{ID: "alice-id", AvatarURL: "https://cdn.test/bucket/avatars/old.png"}, avatarKey: "avatars/old.png"}
+	media := &avatarMediaStore{}
+	jwtIssuer := auth.NewJWTIssuer("test-secret")
+	router := gin.New()
+	RegisterRoutes(router, app.NewService(repo, jwtIssuer, systemClock{}), jwtIssuer, zap.NewNop(), "", "", "", "",
+		WithAvatarUploads(media, 50))
+	token, err := jwtIssuer.Issue("alice-id", "alice@example.com")
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+
+	var avatar bytes.Buffer
+	if err := png.Encode(&avatar, image.NewRGBA(image.Rect(0, 0, 32, 32))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, avatarUploadRequest(t, token, avatar.Bytes()))
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var body struct {
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.AvatarURL != "https://cdn.test/bucket/avatars/new.png" || repo.user.AvatarURL != body.AvatarURL {
+		t.Fatalf("expected avatar_url updated to the new upload, got body %q and profile %q", body.AvatarURL, repo.user.AvatarURL)
+	}
+	if len(media.deleted) != 1 || media.deleted[0] != "avatars/old.png" {
+		t.Fatalf("expected previous avatar deleted, got %v", media.deleted)
+	}
+
+	oversized := append(avatar.Bytes(), make([]byte, httputil.MaxImageUploadBytes)...)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, avatarUploadRequest(t, token, oversized))
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for oversized file, got %d", recorder.Code)
+	}
+	if media.uploads != 1 {
+		t.Fatalf("expected oversized file not to be stored, got %d uploads", media.uploads)
+	}
+}
+
+func TestUploadAvatarOnlyDeletesStoredAvatars(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// A key outside avatars/ must never be deleted, however it got recorded.
+	repo := &avatarRepo{user: domain.User{ID: "alice-id"}, avatarKey: "images/someone-elses.png"}
+	media := &avatarMediaStore{}
+	jwtIssuer := auth.NewJWTIssuer("test-secret")
+	router := gin.New()
+	RegisterRoutes(router, app.NewService(repo, jwtIssuer, systemClock{}), jwtIssuer, zap.NewNop(), "", "", "", "",
+		WithAvatarUploads(media, 50))
+	token, err := jwtIssuer.Issue("alice-id", "alice@example.com")
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+
+	var avatar bytes.Buffer
+	if err := png.Encode(&avatar, image.NewRGBA(image.Rect(0, 0, 32, 32))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, avatarUploadRequest(t, token, avatar.Bytes()))
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if len(media.deleted) != 0 {
+		t.Fatalf("expected no deletes outside avatars/, got %v", media.deleted)
+	}
+
+	patch := httptest.NewRequest(http.MethodPatch, "/v1/auth/me", strings.NewReader(`{"avatar_url":"https://cdn.test/bucket/images/victim.png"}`))
+	patch.Header.Set("Content-Type", "application/json")
+	patch.Header.Set("Authorization", "Bearer "+token)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, patch)
+	if repo.user.AvatarURL != "https://cdn.test/bucket/avatars/new.png" {
+		t.Fatalf("expected avatar_url not settable through the profile update, got %q", repo.user.AvatarURL)
+	}
+}
+
+// avatarStorageUsage tracks one user's usage for the avatar quota tests.
+type avatarStorageUsage struct {
+	used     int64
+	released []string
+}
+
+func (usage *avatarStorageUsage) Reserve(_ context.Context, _ string, size, limit int64) (bool, error) {
+	if limit > 0 && usage.used+size > limit {
+		return false, nil
+	}
+	usage.used += size
+	return true, nil
+}
+
+func (usage *avatarStorageUsage) Release(_ context.Context, _ string, size int64) error {
+	usage.used -= size
+	return nil
+}
+
+func (usage *avatarStorageUsage) RecordObject(_ context.Context, _, _ string, _ int64) error {
+	return nil
+}
+
+func (usage *avatarStorageUsage) ReleaseObject(_ context.Context, objectKey string) error {
+	usage.released = append(usage.released, objectKey)
+	return nil
+}
+
+func TestUploadAvatarCountsAgainstStorageQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var avatar bytes.Buffer
+	if err := png.Encode(&avatar, image.NewRGBA(image.Rect(0, 0, 32, 32))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	jwtIssuer := auth.NewJWTIssuer("test-secret")
+	token, err := jwtIssuer.Issue("alice-id", "alice@example.com")
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	upload := func(limit int64) (*avatarStorageUsage, *avatarMediaStore, int) {
+		repo := &avatarRepo{user: domain.User{ID: "alice-id"}, avatarKey: "avatars/old.png"}
+		media := &avatarMediaStore{}
+		usage := &avatarStorageUsage{}
+		router := gin.New()
+		RegisterRoutes(router, app.NewService(repo, jwtIssuer, systemClock{}), jwtIssuer, zap.NewNop(), "", "", "", "",
+			WithAvatarUploads(media, 50), WithStorageQuota(filesapp.NewStorageQuota(usage, limit)))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, avatarUploadRequest(t, token, avatar.Bytes()))
+		return usage, media, recorder.Code
+	}
+
+	usage, _, code := upload(0)
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if usage.used != int64(avatar.Len()) {
+		t.Fatalf("expected the avatar metered, got %d bytes used", usage.used)
+	}
+	if len(usage.released) != 1 || usage.released[0] != "avatars/old.png" {
+		t.Fatalf("expected the replaced avatar's storage freed, got %v", usage.released)
+	}
+
+	usage, media, code := upload(int64(avatar.Len()) - 1)
+	if code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 over quota, got %d", code)
+	}
+	if media.uploads != 0 || usage.used != 0 {
+		t.Fatalf("expected nothing stored over quota, got %d uploads and %d bytes", media.uploads, usage.used)
+	}
+}
+
+func TestGoogleCallbackIsExemptFromRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtIssuer := auth.NewJWTIssuer("test-secret")
+	router := gin.New()
+	RegisterRoutes(router, app.NewService(&profileRepo{}, jwtIssuer, systemClock{}), jwtIssuer, zap.NewNop(), "", "", "", "",
+		WithRequestTimeout(time.Nanosecond))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/auth/verify", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected other routes to time out, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/auth/google/callback?state=x", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected the callback to run without the timeout, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+// impersonationRepo serves the users an admin may impersonate.
+type impersonationRepo struct {
+	ports.UserRepository
+	users map[domain.UserID]domain.User
+}
+
+func (repo *impersonationRepo) GetByID(_ context.Context, id domain.UserID) (domain.User, error) {
+	user, ok := repo.users[id]
+	if !ok {
+		return domain.User{}, errs.ErrNotFound
+	}
+	return user, nil
+}
+
+func TestImpersonateReportsTokenExpiry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	jwtIssuer := auth.NewJWTIssuer("test-secret")
+	repo := &impersonationRepo{users: map[domain.UserID]domain.User{
+		"bob-id": {ID: "bob-id", Email: "bob@example.com", Username: "bob"},
+	}}
+	RegisterRoutes(router, app.NewService(repo, jwtIssuer, systemClock{}), jwtIssuer, zap.NewNop(), "", "", "", "",
+		WithAdminUserIDs("admin-id"))
+	adminToken, err := jwtIssuer.Issue("admin-id", "admin@example.com")
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/users/bob-id/impersonate", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	claims, err := jwtIssuer.Parse(body.Token)
+	if err != nil {
+		t.Fatalf("parse impersonation token: %v", err)
+	}
+	if !body.ExpiresAt.Equal(claims.ExpiresAt.Time) {
+		t.Fatalf("expected expires_at %s to match the token's exp %s", body.ExpiresAt, claims.ExpiresAt.Time)
+	}
+}
@@ -0,0 +1,27 @@
+package mail
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// LogMailer writes account emails to the log instead of sending them. The
+// tokens it logs are live credentials, so it is only for development.
+type LogMailer struct {
+	logger *zap.Logger
+}
+
+func NewLogMailer(logger *zap.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) SendEmailVerification(_ context.Context, to string, token string) error {
+	m.logger.Info("email verification (dev mailer)", zap.String("to", to), zap.String("verification_token", token))
+	return nil
+}
+
+func (m *LogMailer) SendPasswordReset(_ context.Context, to string, token string) error {
+	m.logger.Info("password reset (dev mailer)", zap.String("to", to), zap.String("reset_token", token))
+	return nil
+}
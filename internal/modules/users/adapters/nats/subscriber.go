@@ -0,0 +1,137 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	jnats "github.com/nats-io/nats.go"
+	"github.com/reggieanim/jot/internal/modules/users/app"
+	platformnats "github.com/reggieanim/jot/internal/platform/eventbus/nats"
+	"go.uber.org/zap"
+)
+
+// consumerName is the durable JetStream consumer holding the users module's
+// place in the follow event stream.
+const consumerName = "users-follow-notifications"
+
+const (
+	fetchBatch = 16
+	// fetchWait bounds how long one fetch waits for events, and so how long
+	// Stop may wait for the fetch loop.
+	fetchWait = 2 * time.Second
+)
+
+// fetcher is the part of a pull subscription the fetch loop uses.
+type fetcher interface {
+	Fetch(batch int, opts ...jnats.PullOpt) ([]*jnats.Msg, error)
+}
+
+// Subscriber turns user.followed events into follow notifications.
+type Subscriber struct {
+	notifier  *app.FollowNotifier
+	jetstream jnats.JetStreamContext
+	stream    string
+	subject   string
+	logger    *zap.Logger
+	sub       *jnats.Subscription
+	fetcher   fetcher
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func NewSubscriber(notifier *app.FollowNotifier, jetstream jnats.JetStreamContext, stream, subject string, logger *zap.Logger) *Subscriber {
+	return &Subscriber{
+		notifier:  notifier,
+		jetstream: jetstream,
+		stream:    stream,
+		subject:   subject,
+		logger:    logger,
+	}
+}
+
+// Start pulls follow events from a durable consumer. Events are acknowledged
+// once their notification is stored, so those published while the process
+// is down wait in the stream.
+func (s *Subscriber) Start() error {
+	if err := platformnats.EnsureDurableConsumer(s.jetstream, s.stream, consumerName, s.subject); err != nil {
+		return err
+	}
+	sub, err := s.jetstream.PullSubscribe("", consumerName, jnats.Bind(s.stream, consumerName))
+	if err != nil {
+		return fmt.Errorf("bind consumer %s: %w", consumerName, err)
+	}
+	s.sub = sub
+	s.run(sub)
+	s.logger.Info("users subscriber started", zap.String("subject", s.subject), zap.String("consumer", consumerName))
+	return nil
+}
+
+func (s *Subscriber) run(fetcher fetcher) {
+	s.fetcher = fetcher
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.fetchLoop()
+}
+
+func (s *Subscriber) fetchLoop() {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		msgs, err := s.fetcher.Fetch(fetchBatch, jnats.MaxWait(fetchWait))
+		if err != nil && !errors.Is(err, jnats.ErrTimeout) {
+			s.logger.Warn("fetch follow events", zap.Error(err))
+			select {
+			case <-time.After(fetchWait):
+			case <-s.stop:
+				return
+			}
+		}
+		for _, msg := range msgs {
+			s.deliver(msg)
+		}
+	}
+}
+
+// deliver handles msg and acknowledges it. Events whose notification could
+// not be stored are handed back for redelivery.
+func (s *Subscriber) deliver(msg *jnats.Msg) {
+	if err := s.process(msg); err != nil {
+		s.logger.Warn("handle follow event failed", zap.Error(err))
+		_ = msg.Nak()
+		return
+	}
+	_ = msg.Ack()
+}
+
+func (s *Subscriber) process(msg *jnats.Msg) error {
+	var event platformnats.FollowEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil || event.Type != platformnats.FollowedEventType {
+		// Not ours to retry.
+		return nil
+	}
+	return s.notifier.NotifyFollow(context.Background(), event.FollowerID, event.FolloweeID)
+}
+
+// Stop ends the fetch loop and leaves the durable consumer in place, so the
+// next Start resumes where this one stopped.
+func (s *Subscriber) Stop() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+	if s.sub == nil {
+		return nil
+	}
+	err := s.sub.Unsubscribe()
+	s.sub = nil
+	return err
+}
@@ -0,0 +1,59 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	jnats "github.com/nats-io/nats.go"
+	"github.com/reggieanim/jot/internal/modules/users/app"
+	"github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/modules/users/ports"
+	"go.uber.org/zap"
+)
+
+// notificationRepo stores notifications and fails while err is set. Other
+// repository calls panic.
+type notificationRepo struct {
+	ports.UserRepository
+	err           error
+	notifications []domain.Notification
+}
+
+func (repo *notificationRepo) CreateNotification(_ context.Context, notification domain.Notification) error {
+	if repo.err != nil {
+		return repo.err
+	}
+	repo.notifications = append(repo.notifications, notification)
+	return nil
+}
+
+type fixedClock struct{}
+
+func (fixedClock) Now() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+func TestProcessNotifiesFollowee(t *testing.T) {
+	repo := &notificationRepo{}
+	subscriber := NewSubscriber(app.NewFollowNotifier(repo, fixedClock{}), nil, "JOT_EVENTS", "jot.users.follows", zap.NewNop())
+
+	msg := &jnats.Msg{Data: []byte(`{"type":"user.followed","follower_id":"bob","followee_id":"alice"}`)}
+	if err := subscriber.process(msg); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if len(repo.notifications) != 1 {
+		t.Fatalf("expected one notification, got %d", len(repo.notifications))
+	}
+	if n := repo.notifications[0]; n.UserID != "alice" || n.ActorID == nil || *n.ActorID != "bob" || n.Kind != app.NotificationKindFollow {
+		t.Fatalf("expected alice notified of bob's follow, got %+v", n)
+	}
+
+	if err := subscriber.process(&jnats.Msg{Data: []byte(`not json`)}); err != nil {
+		t.Fatalf("expected malformed events to be dropped, got %v", err)
+	}
+
+	repo.err = errors.New("db down")
+	if err := subscriber.process(msg); err == nil {
+		t.Fatalf("expected a storage failure to be returned for redelivery")
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/reggieanim/jot/internal/modules/users/domain"
 	"github.com/reggieanim/jot/internal/shared/errs"
@@ -21,10 +24,13 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 
 func (r *Repository) Create(ctx context.Context, user domain.User) error {
 	_, err := r.pool.Exec(ctx, `
-		INSERT INTO users (id, email, username, display_name, bio, avatar_url, password_hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`, string(user.ID), user.Email, user.Username, user.DisplayName, user.Bio, user.AvatarURL, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+		INSERT INTO users (id, email, username, display_name, bio, avatar_url, password_hash, email_verified, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, string(user.ID), user.Email, user.Username, user.DisplayName, user.Bio, user.AvatarURL, user.PasswordHash, user.EmailVerified, user.CreatedAt, user.UpdatedAt)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("insert user: %w", errs.ErrConflict)
+		}
 		return fmt.Errorf("insert user: %w", err)
 	}
 	return nil
@@ -32,7 +38,7 @@ func (r *Repository) Create(ctx context.Context, user domain.User) error {
 
 func (r *Repository) GetByID(ctx context.Context, id domain.UserID) (domain.User, error) {
 	row := r.pool.QueryRow(ctx, `
-		SELECT id, email, username, display_name, bio, avatar_url, password_hash, created_at, updated_at
+		SELECT id, email, username, display_name, bio, avatar_url, password_hash, email_verified, created_at, updated_at
 		FROM users WHERE id = $1
 	`, string(id))
 	return r.scanUser(row)
@@ -40,7 +46,7 @@ func (r *Repository) GetByID(ctx context.Context, id domain.UserID) (domain.User
 
 func (r *Repository) GetByEmail(ctx context.Context, email string) (domain.User, error) {
 	row := r.pool.QueryRow(ctx, `
-		SELECT id, email, username, display_name, bio, avatar_url, password_hash, created_at, updated_at
+		SELECT id, email, username, display_name, bio, avatar_url, password_hash, email_verified, created_at, updated_at
 		FROM users WHERE email = $1
 	`, email)
 	return r.scanUser(row)
@@ -48,17 +54,29 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (domain.User,
 
 func (r *Repository) GetByUsername(ctx context.Context, username string) (domain.User, error) {
 	row := r.pool.QueryRow(ctx, `
-		SELECT id, email, username, display_name, bio, avatar_url, password_hash, created_at, updated_at
+		SELECT id, email, username, display_name, bio, avatar_url, password_hash, email_verified, created_at, updated_at
 		FROM users WHERE username = $1
 	`, username)
 	return r.scanUser(row)
 }
 
-func (r *Repository) UpdateProfile(ctx context.Context, id domain.UserID, displayName, bio, avatarURL string) error {
-	tag, err := r.pool.Exec(ctx, `
-		UPDATE users SET display_name = $2, bio = $3, avatar_url = $4, updated_at = now()
-		WHERE id = $1
-	`, string(id), displayName, bio, avatarURL)
+func (r *Repository) UpdateProfile(ctx context.Context, id domain.UserID, update domain.ProfileUpdate) error {
+	sets := []string{"updated_at = now()"}
+	args := []any{string(id)}
+	for _, field := range []struct {
+		column string
+		value  *string
+	}{
+		{"display_name", update.DisplayName},
+		{"bio", update.Bio},
+	} {
+		if field.value != nil {
+			args = append(args, *field.value)
+			sets = append(sets, fmt.Sprintf("%s = $%d", field.column, len(args)))
+		}
+	}
+
+	tag, err := r.pool.Exec(ctx, `UPDATE users SET `+strings.Join(sets, ", ")+` WHERE id = $1`, args...)
 	if err != nil {
 		return fmt.Errorf("update profile: %w", err)
 	}
@@ -68,6 +86,142 @@ func (r *Repository) UpdateProfile(ctx context.Context, id domain.UserID, displa
 	return nil
 }
 
+func (r *Repository) SetAvatar(ctx context.Context, id domain.UserID, url, key string) (string, error) {
+	var previous *string
+	err := r.pool.QueryRow(ctx, `
+		UPDATE users u
+		SET avatar_url = $2, avatar_key = NULLIF($3, ''), updated_at = now()
+		FROM (SELECT id, avatar_key FROM users WHERE id = $1 FOR UPDATE) old
+		WHERE u.id = old.id
+		RETURNING old.avatar_key
+	`, string(id), url, key).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", errs.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("set avatar: %w", err)
+	}
+	if previous == nil {
+		return "", nil
+	}
+	return *previous, nil
+}
+
+func (r *Repository) UpdatePasswordHash(ctx context.Context, id domain.UserID, passwordHash string, updatedAt time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updatePasswordHash(ctx, tx, id, passwordHash, updatedAt); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit password update: %w", err)
+	}
+	return nil
+}
+
+// updatePasswordHash sets the password and revokes every live refresh token
+// of the user within tx.
+func updatePasswordHash(ctx context.Context, tx pgx.Tx, id domain.UserID, passwordHash string, updatedAt time.Time) error {
+	tag, err := tx.Exec(ctx, `
+		UPDATE users SET password_hash = $2, updated_at = $3
+		WHERE id = $1
+	`, string(id), passwordHash, updatedAt)
+	if err != nil {
+		return fmt.Errorf("update password hash: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = $2
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, string(id), updatedAt); err != nil {
+		return fmt.Errorf("revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) ChangeUsername(ctx context.Context, id domain.UserID, oldUsername, newUsername string, changedAt time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE users SET username = $2, updated_at = $3
+		WHERE id = $1
+	`, string(id), newUsername, changedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errs.ErrConflict
+		}
+		return fmt.Errorf("update username: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO username_history (username, user_id, released_at)
+		VALUES ($1, $2, $3)
+	`, oldUsername, string(id), changedAt); err != nil {
+		return fmt.Errorf("record username history: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit username change: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetUsernameReleasedSince(ctx context.Context, username string, since time.Time) (domain.UserID, error) {
+	var userID domain.UserID
+	err := r.pool.QueryRow(ctx, `
+		SELECT user_id FROM username_history
+		WHERE username = $1 AND released_at > $2
+		ORDER BY released_at DESC
+		LIMIT 1
+	`, username, since).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errs.ErrNotFound
+		}
+		return "", fmt.Errorf("get username history: %w", err)
+	}
+	return userID, nil
+}
+
+func (r *Repository) DeleteAccount(ctx context.Context, id domain.UserID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM follows WHERE follower_id = $1 OR followee_id = $1`, string(id)); err != nil {
+		return fmt.Errorf("delete follows: %w", err)
+	}
+	// pages.owner_id is ON DELETE SET NULL, which would leave the pages behind
+	// as anonymous public pages.
+	if _, err := tx.Exec(ctx, `DELETE FROM pages WHERE owner_id = $1`, string(id)); err != nil {
+		return fmt.Errorf("delete pages: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, string(id))
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit account deletion: %w", err)
+	}
+	return nil
+}
+
 func (r *Repository) Follow(ctx context.Context, followerID, followeeID domain.UserID) error {
 	_, err := r.pool.Exec(ctx, `
 		INSERT INTO follows (follower_id, followee_id) VALUES ($1, $2)
@@ -100,6 +254,18 @@ func (r *Repository) IsFollowing(ctx context.Context, followerID, followeeID dom
 	return exists, nil
 }
 
+func (r *Repository) CountFollows(ctx context.Context, userID domain.UserID) (domain.FollowCounts, error) {
+	var counts domain.FollowCounts
+	err := r.pool.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM follows WHERE followee_id = $1),
+		       (SELECT COUNT(*) FROM follows WHERE follower_id = $1)
+	`, string(userID)).Scan(&counts.FollowerCount, &counts.FollowCount)
+	if err != nil {
+		return domain.FollowCounts{}, fmt.Errorf("count follows: %w", err)
+	}
+	return counts, nil
+}
+
 func (r *Repository) ListFollowers(ctx context.Context, userID domain.UserID) ([]domain.PublicProfile, error) {
 	rows, err := r.pool.Query(ctx, `
 		SELECT u.id, u.username, u.display_name, u.bio, u.avatar_url,
@@ -134,6 +300,69 @@ func (r *Repository) ListFollowing(ctx context.Context, userID domain.UserID) ([
 	return r.scanProfiles(rows)
 }
 
+func (r *Repository) Block(ctx context.Context, blockerID, blockedID domain.UserID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_blocks (blocker_id, blocked_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, string(blockerID), string(blockedID)); err != nil {
+		return fmt.Errorf("block: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM follows
+		WHERE (follower_id = $1 AND followee_id = $2) OR (follower_id = $2 AND followee_id = $1)
+	`, string(blockerID), string(blockedID)); err != nil {
+		return fmt.Errorf("delete follows: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit block: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Unblock(ctx context.Context, blockerID, blockedID domain.UserID) error {
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2
+	`, string(blockerID), string(blockedID))
+	if err != nil {
+		return fmt.Errorf("unblock: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) IsBlocked(ctx context.Context, blockerID, blockedID domain.UserID) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2)
+	`, string(blockerID), string(blockedID)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("is blocked: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *Repository) ListBlocked(ctx context.Context, blockerID domain.UserID) ([]domain.PublicProfile, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT u.id, u.username, u.display_name, u.bio, u.avatar_url,
+		       (SELECT COUNT(*) FROM follows WHERE followee_id = u.id) AS follower_count,
+		       (SELECT COUNT(*) FROM follows WHERE follower_id = u.id) AS follow_count
+		FROM user_blocks b
+		JOIN users u ON u.id = b.blocked_id
+		WHERE b.blocker_id = $1
+		ORDER BY b.created_at DESC
+	`, string(blockerID))
+	if err != nil {
+		return nil, fmt.Errorf("list blocked: %w", err)
+	}
+	defer rows.Close()
+	return r.scanProfiles(rows)
+}
+
 func (r *Repository) GetPublicProfile(ctx context.Context, userID domain.UserID) (domain.PublicProfile, error) {
 	row := r.pool.QueryRow(ctx, `
 		SELECT u.id, u.username, u.display_name, u.bio, u.avatar_url,
@@ -172,9 +401,216 @@ func (r *Repository) GetPublicProfileByUsername(ctx context.Context, username st
 	return p, nil
 }
 
+func (r *Repository) CreateNotification(ctx context.Context, notification domain.Notification) error {
+	var actorID *string
+	if notification.ActorID != nil {
+		value := string(*notification.ActorID)
+		actorID = &value
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO notifications (id, user_id, kind, actor_id, created_at, read_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, string(notification.ID), string(notification.UserID), notification.Kind, actorID, notification.CreatedAt, notification.ReadAt)
+	if err != nil {
+		return fmt.Errorf("insert notification: %w", err)
+	}
+	return nil
+}
+
+// CountUnreadNotifications is served by the partial idx_notifications_user_unread index.
+func (r *Repository) CountUnreadNotifications(ctx context.Context, userID domain.UserID) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL
+	`, string(userID)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (r *Repository) MarkNotificationsRead(ctx context.Context, userID domain.UserID, readAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE notifications SET read_at = $2
+		WHERE user_id = $1 AND read_at IS NULL
+	`, string(userID), readAt)
+	if err != nil {
+		return fmt.Errorf("mark notifications read: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, token.ID, string(token.UserID), token.FamilyID, token.TokenHash, token.CreatedAt, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("insert refresh token: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, user_id, family_id, token_hash, created_at, expires_at, revoked_at
+		FROM refresh_tokens WHERE token_hash = $1
+	`, tokenHash).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash, &token.CreatedAt, &token.ExpiresAt, &token.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.RefreshToken{}, errs.ErrNotFound
+		}
+		return domain.RefreshToken{}, fmt.Errorf("get refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// RevokeRefreshToken only revokes a live token so two concurrent rotations
+// of the same token cannot both succeed.
+func (r *Repository) RevokeRefreshToken(ctx context.Context, id string, revokedAt time.Time) error {
+	commandTag, err := r.pool.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, revokedAt)
+	if err != nil {
+		return fmt.Errorf("revoke refresh token: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return errs.ErrConflict
+	}
+	return nil
+}
+
+func (r *Repository) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = $2
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID, revokedAt)
+	if err != nil {
+		return fmt.Errorf("revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) CreatePasswordReset(ctx context.Context, reset domain.PasswordReset) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO password_resets (id, user_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, reset.ID, string(reset.UserID), reset.TokenHash, reset.CreatedAt, reset.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("insert password reset: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetPasswordResetByHash(ctx context.Context, tokenHash string) (domain.PasswordReset, error) {
+	var reset domain.PasswordReset
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, user_id, token_hash, created_at, expires_at, used_at
+		FROM password_resets WHERE token_hash = $1
+	`, tokenHash).Scan(&reset.ID, &reset.UserID, &reset.TokenHash, &reset.CreatedAt, &reset.ExpiresAt, &reset.UsedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.PasswordReset{}, errs.ErrNotFound
+		}
+		return domain.PasswordReset{}, fmt.Errorf("get password reset: %w", err)
+	}
+	return reset, nil
+}
+
+func (r *Repository) ResetPassword(ctx context.Context, resetID string, passwordHash string, usedAt time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID domain.UserID
+	err = tx.QueryRow(ctx, `
+		UPDATE password_resets SET used_at = $2
+		WHERE id = $1 AND used_at IS NULL
+		RETURNING user_id
+	`, resetID, usedAt).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrConflict
+		}
+		return fmt.Errorf("use password reset: %w", err)
+	}
+	if err := updatePasswordHash(ctx, tx, userID, passwordHash, usedAt); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit password reset: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) CreateEmailVerification(ctx context.Context, verification domain.EmailVerification) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO email_verifications (id, user_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, verification.ID, string(verification.UserID), verification.TokenHash, verification.CreatedAt, verification.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("insert email verification: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetEmailVerificationByHash(ctx context.Context, tokenHash string) (domain.EmailVerification, error) {
+	var verification domain.EmailVerification
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, user_id, token_hash, created_at, expires_at, used_at
+		FROM email_verifications WHERE token_hash = $1
+	`, tokenHash).Scan(&verification.ID, &verification.UserID, &verification.TokenHash, &verification.CreatedAt, &verification.ExpiresAt, &verification.UsedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.EmailVerification{}, errs.ErrNotFound
+		}
+		return domain.EmailVerification{}, fmt.Errorf("get email verification: %w", err)
+	}
+	return verification, nil
+}
+
+func (r *Repository) VerifyEmail(ctx context.Context, verificationID string, usedAt time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID domain.UserID
+	err = tx.QueryRow(ctx, `
+		UPDATE email_verifications SET used_at = $2
+		WHERE id = $1 AND used_at IS NULL
+		RETURNING user_id
+	`, verificationID, usedAt).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrConflict
+		}
+		return fmt.Errorf("use email verification: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE users SET email_verified = true, updated_at = $2
+		WHERE id = $1
+	`, string(userID), usedAt)
+	if err != nil {
+		return fmt.Errorf("mark email verified: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit email verification: %w", err)
+	}
+	return nil
+}
+
 func (r *Repository) scanUser(row pgx.Row) (domain.User, error) {
 	var u domain.User
-	err := row.Scan(&u.ID, &u.Email, &u.Username, &u.DisplayName, &u.Bio, &u.AvatarURL, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt)
+	err := row.Scan(&u.ID, &u.Email, &u.Username, &u.DisplayName, &u.Bio, &u.AvatarURL, &u.PasswordHash, &u.EmailVerified, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.User{}, errs.ErrNotFound
@@ -195,3 +631,10 @@ func (r *Repository) scanProfiles(rows pgx.Rows) ([]domain.PublicProfile, error)
 	}
 	return profiles, nil
 }
+
+// isUniqueViolation reports whether err is a Postgres unique constraint
+// violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
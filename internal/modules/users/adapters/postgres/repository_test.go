@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/reggieanim/jot/internal/modules/users/domain"
+	platformpostgres "github.com/reggieanim/jot/internal/platform/db/postgres"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+// newTestRepository connects to JOT_TEST_DATABASE_URL and applies the
+// migrations. Tests are skipped when it is unset.
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	databaseURL := os.Getenv("JOT_TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("JOT_TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	dir, err := platformpostgres.ResolveMigrationsDir("../../../../../migrations")
+	if err != nil {
+		t.Fatalf("resolve migrations: %v", err)
+	}
+	if err := platformpostgres.RunMigrations(ctx, pool, dir); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+	return NewRepository(pool)
+}
+
+func TestUpdateProfilePreservesOmittedFields(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	id := uuid.NewString()
+	now := time.Now().UTC()
+	user := domain.User{
+		ID:          domain.UserID(id),
+		Email:       id + "@example.com",
+		Username:    "u" + id[:8],
+		DisplayName: "Original Name",
+		Bio:         "Original bio",
+		AvatarURL:   "https://example.com/original.png",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeleteAccount(context.Background(), user.ID) })
+
+	bio := "Updated bio"
+	if err := repo.UpdateProfile(ctx, user.ID, domain.ProfileUpdate{Bio: &bio}); err != nil {
+		t.Fatalf("update profile: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Bio != bio {
+		t.Fatalf("expected bio %q, got %q", bio, got.Bio)
+	}
+	if got.DisplayName != user.DisplayName || got.AvatarURL != user.AvatarURL {
+		t.Fatalf("expected omitted fields preserved, got display_name=%q avatar_url=%q", got.DisplayName, got.AvatarURL)
+	}
+}
+
+func TestCountFollows(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	newUser := func() domain.UserID {
+		t.Helper()
+		id := uuid.NewString()
+		now := time.Now().UTC()
+		user := domain.User{ID: domain.UserID(id), Email: id + "@example.com", Username: "u" + id[:8], CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.DeleteAccount(context.Background(), user.ID) })
+		return user.ID
+	}
+	alice, bob, carol := newUser(), newUser(), newUser()
+
+	for _, follow := range [][2]domain.UserID{{bob, alice}, {carol, alice}, {alice, bob}} {
+		if err := repo.Follow(ctx, follow[0], follow[1]); err != nil {
+			t.Fatalf("follow: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		user domain.UserID
+		want domain.FollowCounts
+	}{
+		{alice, domain.FollowCounts{FollowerCount: 2, FollowCount: 1}},
+		{bob, domain.FollowCounts{FollowerCount: 1, FollowCount: 1}},
+		{carol, domain.FollowCounts{FollowerCount: 0, FollowCount: 1}},
+	} {
+		got, err := repo.CountFollows(ctx, tc.user)
+		if err != nil {
+			t.Fatalf("count follows: %v", err)
+		}
+		if got != tc.want {
+			t.Fatalf("expected %+v for %s, got %+v", tc.want, tc.user, got)
+		}
+	}
+}
+
+func TestSetAvatarReturnsPreviousStoredKey(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	id := uuid.NewString()
+	now := time.Now().UTC()
+	user := domain.User{ID: domain.UserID(id), Email: id + "@example.com", Username: "u" + id[:8], AvatarURL: "https://example.com/google.png", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeleteAccount(context.Background(), user.ID) })
+
+	previous, err := repo.SetAvatar(ctx, user.ID, "https://cdn.test/avatars/a.png", "avatars/a.png")
+	if err != nil {
+		t.Fatalf("set avatar: %v", err)
+	}
+	if previous != "" {
+		t.Fatalf("expected no stored key for an external avatar, got %q", previous)
+	}
+	previous, err = repo.SetAvatar(ctx, user.ID, "", "")
+	if err != nil {
+		t.Fatalf("clear avatar: %v", err)
+	}
+	if previous != "avatars/a.png" {
+		t.Fatalf("expected the stored key returned, got %q", previous)
+	}
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.AvatarURL != "" {
+		t.Fatalf("expected avatar cleared, got %q", got.AvatarURL)
+	}
+	if _, err := repo.SetAvatar(ctx, domain.UserID(uuid.NewString()), "", ""); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found for an unknown user, got %v", err)
+	}
+}
+
+func TestResetPasswordRevokesRefreshTokensOnce(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	id := uuid.NewString()
+	now := time.Now().UTC()
+	user := domain.User{ID: domain.UserID(id), Email: id + "@example.com", Username: "u" + id[:8], PasswordHash: "old", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeleteAccount(context.Background(), user.ID) })
+
+	refresh := domain.RefreshToken{ID: uuid.NewString(), UserID: user.ID, FamilyID: uuid.NewString(), TokenHash: uuid.NewString(), CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	if err := repo.CreateRefreshToken(ctx, refresh); err != nil {
+		t.Fatalf("create refresh token: %v", err)
+	}
+	reset := domain.PasswordReset{ID: uuid.NewString(), UserID: user.ID, TokenHash: uuid.NewString(), CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	if err := repo.CreatePasswordReset(ctx, reset); err != nil {
+		t.Fatalf("create reset: %v", err)
+	}
+
+	if err := repo.ResetPassword(ctx, reset.ID, "new", now); err != nil {
+		t.Fatalf("reset password: %v", err)
+	}
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.PasswordHash != "new" {
+		t.Fatalf("expected password updated, got %q", got.PasswordHash)
+	}
+	stored, err := repo.GetRefreshTokenByHash(ctx, refresh.TokenHash)
+	if err != nil {
+		t.Fatalf("get refresh token: %v", err)
+	}
+	if stored.RevokedAt == nil {
+		t.Fatalf("expected refresh token revoked with the reset")
+	}
+	if err := repo.ResetPassword(ctx, reset.ID, "again", now); !errors.Is(err, errs.ErrConflict) {
+		t.Fatalf("expected a used reset to conflict, got %v", err)
+	}
+}
+
+func TestCountUnreadNotifications(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	newUser := func() domain.UserID {
+		t.Helper()
+		id := uuid.NewString()
+		now := time.Now().UTC()
+		user := domain.User{ID: domain.UserID(id), Email: id + "@example.com", Username: "u" + id[:8], CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.DeleteAccount(context.Background(), user.ID) })
+		return user.ID
+	}
+	alice, bob := newUser(), newUser()
+
+	expectUnread := func(user domain.UserID, want int) {
+		t.Helper()
+		got, err := repo.CountUnreadNotifications(ctx, user)
+		if err != nil {
+			t.Fatalf("count unread notifications: %v", err)
+		}
+		if got != want {
+			t.Fatalf("expected %d unread for %s, got %d", want, user, got)
+		}
+	}
+	notify := func(user, actor domain.UserID) {
+		t.Helper()
+		notification := domain.Notification{
+			ID:        domain.NotificationID(uuid.NewString()),
+			UserID:    user,
+			Kind:      "follow",
+			ActorID:   &actor,
+			CreatedAt: time.Now().UTC(),
+		}
+		if err := repo.CreateNotification(ctx, notification); err != nil {
+			t.Fatalf("create notification: %v", err)
+		}
+	}
+
+	expectUnread(alice, 0)
+	notify(alice, bob)
+	notify(alice, bob)
+	notify(bob, alice)
+	expectUnread(alice, 2)
+	expectUnread(bob, 1)
+
+	if err := repo.MarkNotificationsRead(ctx, alice, time.Now().UTC()); err != nil {
+		t.Fatalf("mark notifications read: %v", err)
+	}
+	expectUnread(alice, 0)
+	expectUnread(bob, 1)
+
+	notify(alice, bob)
+	expectUnread(alice, 1)
+}
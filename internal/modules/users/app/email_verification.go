@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+const defaultEmailVerificationTTL = 24 * time.Hour
+
+// ErrInvalidVerificationToken covers unknown and expired verification tokens.
+var ErrInvalidVerificationToken = fmt.Errorf("%w: invalid or expired verification token", errs.ErrInvalidInput)
+
+// ErrEmailAlreadyVerified is returned when asking to verify a confirmed email.
+var ErrEmailAlreadyVerified = fmt.Errorf("%w: email already verified", errs.ErrConflict)
+
+// SendVerification creates a single-use token confirming the user's email
+// and mails it to them.
+func (s *Service) SendVerification(ctx context.Context, userID domain.UserID) error {
+	if userID == "" {
+		return errs.ErrInvalidInput
+	}
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("generate verification token: %w", err)
+	}
+	now := s.clock.Now()
+	if err := s.repo.CreateEmailVerification(ctx, domain.EmailVerification{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.verifyTTL),
+	}); err != nil {
+		return fmt.Errorf("create email verification: %w", err)
+	}
+	if s.mailer == nil {
+		return nil
+	}
+	if err := s.mailer.SendEmailVerification(ctx, user.Email, token); err != nil {
+		return fmt.Errorf("send email verification: %w", err)
+	}
+	return nil
+}
+
+// ConfirmEmail marks the email behind a valid verification token as
+// verified. Confirming with an already used token succeeds again once the
+// email is verified, so a repeated click on the same link is harmless.
+func (s *Service) ConfirmEmail(ctx context.Context, token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrInvalidVerificationToken
+	}
+
+	verification, err := s.repo.GetEmailVerificationByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return fmt.Errorf("get email verification: %w", err)
+	}
+	if verification.UsedAt != nil {
+		return s.confirmedBefore(ctx, verification.UserID)
+	}
+	now := s.clock.Now()
+	if !now.Before(verification.ExpiresAt) {
+		return ErrInvalidVerificationToken
+	}
+
+	if err := s.repo.VerifyEmail(ctx, verification.ID, now); err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return s.confirmedBefore(ctx, verification.UserID)
+		}
+		return fmt.Errorf("verify email: %w", err)
+	}
+	return nil
+}
+
+// confirmedBefore accepts a used verification token only if its user's email
+// did end up verified.
+func (s *Service) confirmedBefore(ctx context.Context, userID domain.UserID) error {
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if !user.EmailVerified {
+		return ErrInvalidVerificationToken
+	}
+	return nil
+}
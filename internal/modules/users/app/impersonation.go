@@ -0,0 +1,26 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+// Impersonate issues a short-lived token that lets adminID act as userID for
+// support. Admins cannot impersonate themselves.
+func (s *Service) Impersonate(ctx context.Context, adminID, userID domain.UserID) (domain.User, string, error) {
+	if adminID == "" || userID == "" || adminID == userID {
+		return domain.User{}, "", errs.ErrInvalidInput
+	}
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return domain.User{}, "", err
+	}
+	token, err := s.tokens.IssueImpersonation(user.ID, user.Email, adminID)
+	if err != nil {
+		return domain.User{}, "", fmt.Errorf("issue impersonation token: %w", err)
+	}
+	return user, token, nil
+}
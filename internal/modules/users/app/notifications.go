@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/modules/users/ports"
+)
+
+// NotificationKindFollow marks a "new follower" notification.
+const NotificationKindFollow = "follow"
+
+// FollowListener is told about every successful follow.
+type FollowListener interface {
+	Followed(ctx context.Context, followerID, followeeID domain.UserID)
+}
+
+// FollowNotifier turns follow events into notifications for the followed
+// user. Repeat follows are deduplicated before they reach it, by the event
+// stream's duplicate window.
+type FollowNotifier struct {
+	repo  ports.UserRepository
+	clock Clock
+}
+
+func NewFollowNotifier(repo ports.UserRepository, clock Clock) *FollowNotifier {
+	return &FollowNotifier{repo: repo, clock: clock}
+}
+
+// NotifyFollow tells followeeID that followerID followed them.
+func (n *FollowNotifier) NotifyFollow(ctx context.Context, followerID, followeeID domain.UserID) error {
+	actorID := followerID
+	err := n.repo.CreateNotification(ctx, domain.Notification{
+		ID:        domain.NotificationID(uuid.NewString()),
+		UserID:    followeeID,
+		Kind:      NotificationKindFollow,
+		ActorID:   &actorID,
+		CreatedAt: n.clock.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("create follow notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const defaultPasswordResetTTL = time.Hour
+
+// ErrInvalidResetToken covers unknown, expired and already used reset tokens.
+var ErrInvalidResetToken = fmt.Errorf("%w: invalid or expired reset token", errs.ErrInvalidInput)
+
+// RequestPasswordReset creates a single-use reset token for the account with
+// email and mails it to them. An unknown email is a silent no-op so callers
+// cannot probe which accounts exist.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		return errs.ErrInvalidInput
+	}
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("generate reset token: %w", err)
+	}
+	now := s.clock.Now()
+	if err := s.repo.CreatePasswordReset(ctx, domain.PasswordReset{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.resetTTL),
+	}); err != nil {
+		return fmt.Errorf("create password reset: %w", err)
+	}
+	if s.mailer == nil {
+		return nil
+	}
+	if err := s.mailer.SendPasswordReset(ctx, user.Email, token); err != nil {
+		return fmt.Errorf("send password reset: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password for the account behind a valid reset
+// token, consumes the token and signs the account out everywhere.
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+	if len(newPassword) < minPasswordLength {
+		return errPasswordTooShort
+	}
+
+	reset, err := s.repo.GetPasswordResetByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("get password reset: %w", err)
+	}
+	now := s.clock.Now()
+	if reset.UsedAt != nil || !now.Before(reset.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcryptCost)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	if err := s.repo.ResetPassword(ctx, reset.ID, string(hash), now); err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("reset password: %w", err)
+	}
+	return nil
+}
+
+// ChangePassword replaces the password of a signed-in user after checking
+// their current one, revoking all their refresh tokens so other sessions
+// must sign in again.
+func (s *Service) ChangePassword(ctx context.Context, userID domain.UserID, current, next string) error {
+	if userID == "" || current == "" {
+		return errs.ErrInvalidInput
+	}
+	if len(next) < minPasswordLength {
+		return errPasswordTooShort
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(current)); err != nil {
+		return errs.ErrInvalidInput
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(next), bcryptCost)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	if err := s.repo.UpdatePasswordHash(ctx, userID, string(hash), s.clock.Now()); err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+	return nil
+}
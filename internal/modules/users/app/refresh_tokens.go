@@ -0,0 +1,147 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/shared/errs"
+)
+
+const defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+// ErrInvalidRefreshToken covers unknown, expired, revoked and replayed
+// refresh tokens alike.
+var ErrInvalidRefreshToken = fmt.Errorf("%w: invalid refresh token", errs.ErrUnauthorized)
+
+// RefreshTokenTTL reports how long issued refresh tokens stay valid.
+func (s *Service) RefreshTokenTTL() time.Duration {
+	return s.refreshTTL
+}
+
+// IssueRefreshToken starts a new refresh token family for userID, e.g. on
+// login, and returns the opaque token.
+func (s *Service) IssueRefreshToken(ctx context.Context, userID domain.UserID) (string, error) {
+	if userID == "" {
+		return "", errs.ErrInvalidInput
+	}
+	return s.createRefreshToken(ctx, userID, uuid.NewString())
+}
+
+// Rotate exchanges a live refresh token for a new access token and a new
+// refresh token in the same family. Presenting a token that was already
+// rotated or revoked is treated as theft: the whole family is revoked so
+// neither the attacker nor the victim can keep refreshing.
+func (s *Service) Rotate(ctx context.Context, refreshToken string) (domain.User, string, string, error) {
+	stored, err := s.lookupRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return domain.User{}, "", "", err
+	}
+	now := s.clock.Now()
+	if stored.RevokedAt != nil {
+		if err := s.repo.RevokeRefreshTokenFamily(ctx, stored.FamilyID, now); err != nil {
+			return domain.User{}, "", "", fmt.Errorf("revoke reused refresh token family: %w", err)
+		}
+		return domain.User{}, "", "", ErrInvalidRefreshToken
+	}
+	if !now.Before(stored.ExpiresAt) {
+		return domain.User{}, "", "", ErrInvalidRefreshToken
+	}
+
+	if err := s.repo.RevokeRefreshToken(ctx, stored.ID, now); err != nil {
+		if errors.Is(err, errs.ErrConflict) {
+			// Lost a race with another rotation of the same token.
+			_ = s.repo.RevokeRefreshTokenFamily(ctx, stored.FamilyID, now)
+			return domain.User{}, "", "", ErrInvalidRefreshToken
+		}
+		return domain.User{}, "", "", fmt.Errorf("revoke rotated refresh token: %w", err)
+	}
+
+	user, err := s.repo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return domain.User{}, "", "", fmt.Errorf("get user: %w", err)
+	}
+	access, err := s.tokens.Issue(user.ID, user.Email)
+	if err != nil {
+		return domain.User{}, "", "", fmt.Errorf("issue token: %w", err)
+	}
+	refresh, err := s.createRefreshToken(ctx, user.ID, stored.FamilyID)
+	if err != nil {
+		return domain.User{}, "", "", err
+	}
+	return user, access, refresh, nil
+}
+
+// Revoke ends the session a refresh token belongs to by revoking its whole
+// family. Unknown tokens are ignored so logout is idempotent.
+func (s *Service) Revoke(ctx context.Context, refreshToken string) error {
+	stored, err := s.lookupRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRefreshToken) {
+			return nil
+		}
+		return err
+	}
+	if err := s.repo.RevokeRefreshTokenFamily(ctx, stored.FamilyID, s.clock.Now()); err != nil {
+		return fmt.Errorf("revoke refresh token: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) lookupRefreshToken(ctx context.Context, refreshToken string) (domain.RefreshToken, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return domain.RefreshToken{}, ErrInvalidRefreshToken
+	}
+	stored, err := s.repo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return domain.RefreshToken{}, ErrInvalidRefreshToken
+		}
+		return domain.RefreshToken{}, fmt.Errorf("get refresh token: %w", err)
+	}
+	return stored, nil
+}
+
+func (s *Service) createRefreshToken(ctx context.Context, userID domain.UserID, familyID string) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("generate refresh token: %w", err)
+	}
+
+	now := s.clock.Now()
+	if err := s.repo.CreateRefreshToken(ctx, domain.RefreshToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.refreshTTL),
+	}); err != nil {
+		return "", fmt.Errorf("create refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// newOpaqueToken returns a random URL-safe token; only its hashToken digest
+// is persisted.
+func newOpaqueToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
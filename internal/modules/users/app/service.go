@@ -2,9 +2,12 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/reggieanim/jot/internal/modules/users/domain"
@@ -13,7 +16,24 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-const bcryptCost = 12
+const (
+	bcryptCost        = 12
+	minPasswordLength = 8
+	minUsernameLength = 3
+	maxUsernameLength = 30
+	// How long a released username stays reserved for its previous owner.
+	usernameReuseHold = 30 * 24 * time.Hour
+	// Numeric suffixes tried when a Google-derived username is taken.
+	maxUsernameSuffixAttempts = 50
+	// Profile field limits, in characters, unless configured otherwise.
+	defaultMaxDisplayNameLength = 50
+	defaultMaxBioLength         = 300
+)
+
+// ErrBlocked is returned when following someone who has blocked the caller.
+var ErrBlocked = fmt.Errorf("%w: this user has blocked you", errs.ErrForbidden)
+
+var errPasswordTooShort = fmt.Errorf("%w: password must be at least %d characters", errs.ErrInvalidInput, minPasswordLength)
 
 type Clock interface {
 	Now() time.Time
@@ -22,32 +42,132 @@ type Clock interface {
 // TokenIssuer abstracts JWT generation so the service stays decoupled.
 type TokenIssuer interface {
 	Issue(userID domain.UserID, email string) (string, error)
+	// IssueImpersonation issues a short-lived token for impersonatorID to act
+	// as userID.
+	IssueImpersonation(userID domain.UserID, email string, impersonatorID domain.UserID) (string, error)
+}
+
+// PageRemover deletes the pages an account owns and cleans up their media.
+// The pages module implements it.
+type PageRemover interface {
+	DeleteOwnerPages(ctx context.Context, ownerID string) (int, error)
 }
 
 type Service struct {
-	repo   ports.UserRepository
-	tokens TokenIssuer
-	clock  Clock
+	repo       ports.UserRepository
+	tokens     TokenIssuer
+	clock      Clock
+	refreshTTL time.Duration
+	resetTTL   time.Duration
+	verifyTTL  time.Duration
+	pages      PageRemover
+	follows    FollowListener
+	mailer     ports.Mailer
+
+	maxDisplayNameLength int
+	maxBioLength         int
+}
+
+// Option configures optional Service behaviour.
+type Option func(*Service)
+
+// WithRefreshTokenTTL sets how long refresh tokens stay valid. Zero keeps
+// the 30-day default.
+func WithRefreshTokenTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.refreshTTL = ttl
+		}
+	}
+}
+
+// WithPasswordResetTTL sets how long password reset tokens stay valid. Zero
+// keeps the one-hour default.
+func WithPasswordResetTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.resetTTL = ttl
+		}
+	}
+}
+
+// WithEmailVerificationTTL sets how long email verification tokens stay
+// valid. Zero keeps the 24-hour default.
+func WithEmailVerificationTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.verifyTTL = ttl
+		}
+	}
+}
+
+// WithProfileLimits caps display names and bios at the given number of
+// characters. Zero keeps the default for that field.
+func WithProfileLimits(maxDisplayNameLength, maxBioLength int) Option {
+	return func(s *Service) {
+		if maxDisplayNameLength > 0 {
+			s.maxDisplayNameLength = maxDisplayNameLength
+		}
+		if maxBioLength > 0 {
+			s.maxBioLength = maxBioLength
+		}
+	}
 }
 
-func NewService(repo ports.UserRepository, tokens TokenIssuer, clock Clock) *Service {
-	return &Service{repo: repo, tokens: tokens, clock: clock}
+// WithPageRemover deletes an account's pages, and their media, when the
+// account is deleted. Without it the repository removes the pages but their
+// media is left in storage.
+func WithPageRemover(pages PageRemover) Option {
+	return func(s *Service) {
+		s.pages = pages
+	}
+}
+
+// WithFollowListener is told about every successful follow, e.g. to notify
+// the followed user.
+func WithFollowListener(listener FollowListener) Option {
+	return func(s *Service) {
+		s.follows = listener
+	}
+}
+
+// WithMailer delivers verification and password reset emails. Without it
+// tokens are created but never sent.
+func WithMailer(mailer ports.Mailer) Option {
+	return func(s *Service) {
+		s.mailer = mailer
+	}
 }
 
-// Signup creates a new user account.
+func NewService(repo ports.UserRepository, tokens TokenIssuer, clock Clock, opts ...Option) *Service {
+	s := &Service{repo: repo, tokens: tokens, clock: clock, refreshTTL: defaultRefreshTokenTTL, resetTTL: defaultPasswordResetTTL, verifyTTL: defaultEmailVerificationTTL}
+	s.maxDisplayNameLength, s.maxBioLength = defaultMaxDisplayNameLength, defaultMaxBioLength
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Signup creates a new user account. With an empty username one is derived
+// from the email and de-duplicated.
 func (s *Service) Signup(ctx context.Context, email, username, displayName, password string) (domain.User, string, error) {
 	email = strings.TrimSpace(strings.ToLower(email))
 	username = strings.TrimSpace(strings.ToLower(username))
 	displayName = strings.TrimSpace(displayName)
 
-	if email == "" || username == "" || password == "" {
+	if email == "" || password == "" {
 		return domain.User{}, "", errs.ErrInvalidInput
 	}
-	if len(password) < 8 {
-		return domain.User{}, "", fmt.Errorf("%w: password must be at least 8 characters", errs.ErrInvalidInput)
+	if len(password) < minPasswordLength {
+		return domain.User{}, "", errPasswordTooShort
 	}
-	if len(username) < 3 {
-		return domain.User{}, "", fmt.Errorf("%w: username must be at least 3 characters", errs.ErrInvalidInput)
+	if username != "" {
+		if err := validateUsername(username); err != nil {
+			return domain.User{}, "", err
+		}
+	}
+	if err := s.validateProfile(&displayName, nil); err != nil {
+		return domain.User{}, "", err
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
@@ -56,6 +176,11 @@ func (s *Service) Signup(ctx context.Context, email, username, displayName, pass
 	}
 
 	now := s.clock.Now()
+	if username == "" {
+		if username, err = s.availableUsername(ctx, usernameFromEmail(email), now); err != nil {
+			return domain.User{}, "", err
+		}
+	}
 	user := domain.User{
 		ID:           domain.UserID(uuid.NewString()),
 		Email:        email,
@@ -124,16 +249,21 @@ func (s *Service) LoginOrSignupWithGoogle(ctx context.Context, email, displayNam
 	}
 
 	// New Google user — derive a username and create the account.
-	username := usernameFromEmail(email)
 	now := s.clock.Now()
+	username, err := s.availableUsername(ctx, usernameFromEmail(email), now)
+	if err != nil {
+		return domain.User{}, "", err
+	}
+	// Google has already verified the address.
 	newUser := domain.User{
-		ID:          domain.UserID(uuid.NewString()),
-		Email:       email,
-		Username:    username,
-		DisplayName: displayName,
-		AvatarURL:   avatarURL,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:            domain.UserID(uuid.NewString()),
+		Email:         email,
+		Username:      username,
+		DisplayName:   truncateRunes(strings.TrimSpace(displayName), s.maxDisplayNameLength),
+		AvatarURL:     avatarURL,
+		EmailVerified: true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
 	}
 	if newUser.DisplayName == "" {
 		newUser.DisplayName = username
@@ -151,14 +281,56 @@ func (s *Service) LoginOrSignupWithGoogle(ctx context.Context, email, displayNam
 	return newUser, token, nil
 }
 
-// usernameFromEmail derives a username from the email local part.
+// usernameFromEmail derives a valid username from the email local part. Any
+// +tag and dots are dropped, other disallowed characters become '_', and
+// names that end up too short are prefixed.
 func usernameFromEmail(email string) string {
-	for i, ch := range email {
-		if ch == '@' {
-			return email[:i]
+	local, _, _ := strings.Cut(strings.ToLower(email), "@")
+	local, _, _ = strings.Cut(local, "+")
+
+	var b strings.Builder
+	for _, ch := range local {
+		switch {
+		case isUsernameChar(ch):
+			b.WriteRune(ch)
+		case ch == '.':
+		default:
+			b.WriteByte('_')
 		}
 	}
-	return email
+	username := b.String()
+	if len(username) < minUsernameLength {
+		username = "user_" + username
+	}
+	if len(username) > maxUsernameLength {
+		username = username[:maxUsernameLength]
+	}
+	return username
+}
+
+// availableUsername returns base, or base with the lowest numeric suffix
+// from 2 up, that is neither taken nor held for a previous owner.
+func (s *Service) availableUsername(ctx context.Context, base string, now time.Time) (string, error) {
+	for attempt := 1; attempt <= maxUsernameSuffixAttempts; attempt++ {
+		candidate := base
+		if attempt > 1 {
+			suffix := strconv.Itoa(attempt)
+			candidate = base[:min(len(base), maxUsernameLength-len(suffix))] + suffix
+		}
+
+		if _, err := s.repo.GetByUsername(ctx, candidate); err == nil {
+			continue
+		} else if !errors.Is(err, errs.ErrNotFound) {
+			return "", fmt.Errorf("check username: %w", err)
+		}
+		if _, err := s.repo.GetUsernameReleasedSince(ctx, candidate, now.Add(-usernameReuseHold)); err == nil {
+			continue
+		} else if !errors.Is(err, errs.ErrNotFound) {
+			return "", fmt.Errorf("check username history: %w", err)
+		}
+		return candidate, nil
+	}
+	return "", fmt.Errorf("%w: no free username for %q", errs.ErrConflict, base)
 }
 
 // GetProfile returns the authenticated user's own profile.
@@ -171,9 +343,134 @@ func (s *Service) GetPublicProfile(ctx context.Context, username string) (domain
 	return s.repo.GetPublicProfileByUsername(ctx, username)
 }
 
-// UpdateProfile updates the authenticated user's profile fields.
-func (s *Service) UpdateProfile(ctx context.Context, userID domain.UserID, displayName, bio, avatarURL string) error {
-	return s.repo.UpdateProfile(ctx, userID, displayName, bio, avatarURL)
+// UserIDByUsername returns the ID of the user with username.
+func (s *Service) UserIDByUsername(ctx context.Context, username string) (string, error) {
+	if username == "" {
+		return "", errs.ErrInvalidInput
+	}
+	user, err := s.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return "", fmt.Errorf("get user by username: %w", err)
+	}
+	return string(user.ID), nil
+}
+
+// UpdateProfile changes the authenticated user's profile fields present in
+// update and leaves the rest untouched.
+func (s *Service) UpdateProfile(ctx context.Context, userID domain.UserID, update domain.ProfileUpdate) error {
+	if update.Empty() {
+		return nil
+	}
+	if err := s.validateProfile(update.DisplayName, update.Bio); err != nil {
+		return err
+	}
+	return s.repo.UpdateProfile(ctx, userID, update)
+}
+
+// SetAvatar records an uploaded avatar and returns the key of the stored
+// avatar it replaces, if any.
+func (s *Service) SetAvatar(ctx context.Context, userID domain.UserID, url, key string) (string, error) {
+	previous, err := s.repo.SetAvatar(ctx, userID, url, key)
+	if err != nil {
+		return "", fmt.Errorf("set avatar: %w", err)
+	}
+	return previous, nil
+}
+
+// ChangeUsername renames the user. A username someone else gave up within the
+// reuse hold cannot be claimed, so it cannot be used to impersonate them.
+func (s *Service) ChangeUsername(ctx context.Context, userID domain.UserID, newUsername string) error {
+	newUsername = strings.TrimSpace(strings.ToLower(newUsername))
+	if err := validateUsername(newUsername); err != nil {
+		return err
+	}
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if user.Username == newUsername {
+		return nil
+	}
+
+	if _, err := s.repo.GetByUsername(ctx, newUsername); err == nil {
+		return fmt.Errorf("%w: username is taken", errs.ErrConflict)
+	} else if !errors.Is(err, errs.ErrNotFound) {
+		return fmt.Errorf("check username: %w", err)
+	}
+	now := s.clock.Now()
+	releasedBy, err := s.repo.GetUsernameReleasedSince(ctx, newUsername, now.Add(-usernameReuseHold))
+	if err == nil && releasedBy != userID {
+		return fmt.Errorf("%w: username is taken", errs.ErrConflict)
+	}
+	if err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return fmt.Errorf("check username history: %w", err)
+	}
+
+	if err := s.repo.ChangeUsername(ctx, userID, user.Username, newUsername, now); err != nil {
+		return fmt.Errorf("change username: %w", err)
+	}
+	return nil
+}
+
+// validateUsername mirrors the users_username_format check constraint.
+func validateUsername(username string) error {
+	if len(username) < minUsernameLength || len(username) > maxUsernameLength {
+		return fmt.Errorf("%w: username must be %d to %d characters", errs.ErrInvalidInput, minUsernameLength, maxUsernameLength)
+	}
+	for _, ch := range username {
+		if !isUsernameChar(ch) {
+			return fmt.Errorf("%w: username may only contain lowercase letters, digits and '_'", errs.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+// validateProfile checks the display name and bio that are set against the
+// configured limits.
+func (s *Service) validateProfile(displayName, bio *string) error {
+	if displayName != nil && utf8.RuneCountInString(*displayName) > s.maxDisplayNameLength {
+		return fmt.Errorf("%w: display name must be at most %d characters", errs.ErrInvalidInput, s.maxDisplayNameLength)
+	}
+	if bio != nil && utf8.RuneCountInString(*bio) > s.maxBioLength {
+		return fmt.Errorf("%w: bio must be at most %d characters", errs.ErrInvalidInput, s.maxBioLength)
+	}
+	return nil
+}
+
+// truncateRunes cuts value to at most max characters.
+func truncateRunes(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return strings.TrimSpace(string(runes[:max]))
+}
+
+func isUsernameChar(ch rune) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '_'
+}
+
+// DeleteAccount permanently removes the user together with their follows and
+// pages.
+func (s *Service) DeleteAccount(ctx context.Context, userID domain.UserID) error {
+	if userID == "" {
+		return errs.ErrInvalidInput
+	}
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	// Pages go first, through the pages module, so their media is cleaned up;
+	// the repository then removes the account and anything left in one
+	// transaction.
+	if s.pages != nil {
+		if _, err := s.pages.DeleteOwnerPages(ctx, string(userID)); err != nil {
+			return fmt.Errorf("delete pages: %w", err)
+		}
+	}
+	if err := s.repo.DeleteAccount(ctx, userID); err != nil {
+		return fmt.Errorf("delete account: %w", err)
+	}
+	return nil
 }
 
 // Follow makes followerID follow followeeID.
@@ -184,7 +481,20 @@ func (s *Service) Follow(ctx context.Context, followerID, followeeID domain.User
 	if _, err := s.repo.GetByID(ctx, followeeID); err != nil {
 		return err
 	}
-	return s.repo.Follow(ctx, followerID, followeeID)
+	blocked, err := s.repo.IsBlocked(ctx, followeeID, followerID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	if err := s.repo.Follow(ctx, followerID, followeeID); err != nil {
+		return err
+	}
+	if s.follows != nil {
+		s.follows.Followed(ctx, followerID, followeeID)
+	}
+	return nil
 }
 
 // Unfollow removes the follow relationship.
@@ -192,6 +502,29 @@ func (s *Service) Unfollow(ctx context.Context, followerID, followeeID domain.Us
 	return s.repo.Unfollow(ctx, followerID, followeeID)
 }
 
+// BlockUser blocks blockedID for blockerID: any follows between them are
+// removed, blockedID cannot follow blockerID, and blockedID's pages are left
+// out of blockerID's feed.
+func (s *Service) BlockUser(ctx context.Context, blockerID, blockedID domain.UserID) error {
+	if blockerID == blockedID {
+		return fmt.Errorf("%w: cannot block yourself", errs.ErrInvalidInput)
+	}
+	if _, err := s.repo.GetByID(ctx, blockedID); err != nil {
+		return err
+	}
+	return s.repo.Block(ctx, blockerID, blockedID)
+}
+
+// UnblockUser lifts a block. Unblocking a user who is not blocked is a no-op.
+func (s *Service) UnblockUser(ctx context.Context, blockerID, blockedID domain.UserID) error {
+	return s.repo.Unblock(ctx, blockerID, blockedID)
+}
+
+// ListBlocked returns the people userID has blocked.
+func (s *Service) ListBlocked(ctx context.Context, userID domain.UserID) ([]domain.PublicProfile, error) {
+	return s.repo.ListBlocked(ctx, userID)
+}
+
 // IsFollowing checks if follower follows followee.
 func (s *Service) IsFollowing(ctx context.Context, followerID, followeeID domain.UserID) (bool, error) {
 	return s.repo.IsFollowing(ctx, followerID, followeeID)
@@ -206,3 +539,19 @@ func (s *Service) ListFollowers(ctx context.Context, userID domain.UserID) ([]do
 func (s *Service) ListFollowing(ctx context.Context, userID domain.UserID) ([]domain.PublicProfile, error) {
 	return s.repo.ListFollowing(ctx, userID)
 }
+
+// GetFollowCounts returns userID's follower and following counts without
+// loading either list.
+func (s *Service) GetFollowCounts(ctx context.Context, userID domain.UserID) (domain.FollowCounts, error) {
+	return s.repo.CountFollows(ctx, userID)
+}
+
+// CountUnreadNotifications returns how many of userID's notifications are unread.
+func (s *Service) CountUnreadNotifications(ctx context.Context, userID domain.UserID) (int, error) {
+	return s.repo.CountUnreadNotifications(ctx, userID)
+}
+
+// MarkNotificationsRead marks all of userID's notifications as read.
+func (s *Service) MarkNotificationsRead(ctx context.Context, userID domain.UserID) error {
+	return s.repo.MarkNotificationsRead(ctx, userID, s.clock.Now())
+}
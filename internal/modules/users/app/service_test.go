@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,9 +23,45 @@ func (f fakeTokenIssuer) Issue(userID domain.UserID, email string) (string, erro
 	return "fake-jwt-" + string(userID), nil
 }
 
+func (f fakeTokenIssuer) IssueImpersonation(userID domain.UserID, email string, impersonatorID domain.UserID) (string, error) {
+	return "fake-jwt-" + string(userID) + "-as-" + string(impersonatorID), nil
+}
+
+// recordingMailer keeps the last token mailed to each address.
+type recordingMailer struct {
+	verifications map[string]string
+	resets        map[string]string
+}
+
+func newRecordingMailer() *recordingMailer {
+	return &recordingMailer{verifications: map[string]string{}, resets: map[string]string{}}
+}
+
+func (m *recordingMailer) SendPasswordReset(_ context.Context, to string, token string) error {
+	m.resets[to] = token
+	return nil
+}
+
+func (m *recordingMailer) SendEmailVerification(_ context.Context, to string, token string) error {
+	m.verifications[to] = token
+	return nil
+}
+
 type inMemoryUserRepo struct {
-	users   []domain.User
-	follows []domain.Follow
+	users         []domain.User
+	follows       []domain.Follow
+	notifications []domain.Notification
+	refreshTokens []domain.RefreshToken
+	resets        []domain.PasswordReset
+	verifications []domain.EmailVerification
+	released      []releasedUsername
+	blocks        []domain.Follow
+}
+
+type releasedUsername struct {
+	username   string
+	userID     domain.UserID
+	releasedAt time.Time
 }
 
 func (r *inMemoryUserRepo) Create(_ context.Context, user domain.User) error {
@@ -66,12 +104,84 @@ func (r *inMemoryUserRepo) GetByUsername(_ context.Context, username string) (do
 	return domain.User{}, errs.ErrNotFound
 }
 
-func (r *inMemoryUserRepo) UpdateProfile(_ context.Context, id domain.UserID, displayName, bio, avatarURL string) error {
+func (r *inMemoryUserRepo) UpdateProfile(_ context.Context, id domain.UserID, update domain.ProfileUpdate) error {
+	for i, u := range r.users {
+		if u.ID == id {
+			if update.DisplayName != nil {
+				r.users[i].DisplayName = *update.DisplayName
+			}
+			if update.Bio != nil {
+				r.users[i].Bio = *update.Bio
+			}
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func (r *inMemoryUserRepo) SetAvatar(_ context.Context, id domain.UserID, url, _ string) (string, error) {
+	for i, u := range r.users {
+		if u.ID == id {
+			r.users[i].AvatarURL = url
+			return "", nil
+		}
+	}
+	return "", errs.ErrNotFound
+}
+
+func (r *inMemoryUserRepo) UpdatePasswordHash(_ context.Context, id domain.UserID, passwordHash string, updatedAt time.Time) error {
+	for i, u := range r.users {
+		if u.ID == id {
+			r.users[i].PasswordHash = passwordHash
+			r.users[i].UpdatedAt = updatedAt
+			for j, t := range r.refreshTokens {
+				if t.UserID == id && t.RevokedAt == nil {
+					r.refreshTokens[j].RevokedAt = &updatedAt
+				}
+			}
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func (r *inMemoryUserRepo) ChangeUsername(_ context.Context, id domain.UserID, oldUsername, newUsername string, changedAt time.Time) error {
+	for _, u := range r.users {
+		if u.Username == newUsername && u.ID != id {
+			return errs.ErrConflict
+		}
+	}
+	for i, u := range r.users {
+		if u.ID == id {
+			r.users[i].Username = newUsername
+			r.users[i].UpdatedAt = changedAt
+			r.released = append(r.released, releasedUsername{username: oldUsername, userID: id, releasedAt: changedAt})
+			return nil
+		}
+	}
+	return errs.ErrNotFound
+}
+
+func (r *inMemoryUserRepo) GetUsernameReleasedSince(_ context.Context, username string, since time.Time) (domain.UserID, error) {
+	for i := len(r.released) - 1; i >= 0; i-- {
+		if r.released[i].username == username && r.released[i].releasedAt.After(since) {
+			return r.released[i].userID, nil
+		}
+	}
+	return "", errs.ErrNotFound
+}
+
+func (r *inMemoryUserRepo) DeleteAccount(_ context.Context, id domain.UserID) error {
+	follows := r.follows[:0]
+	for _, f := range r.follows {
+		if f.FollowerID != id && f.FolloweeID != id {
+			follows = append(follows, f)
+		}
+	}
+	r.follows = follows
 	for i, u := range r.users {
 		if u.ID == id {
-			r.users[i].DisplayName = displayName
-			r.users[i].Bio = bio
-			r.users[i].AvatarURL = avatarURL
+			r.users = append(r.users[:i], r.users[i+1:]...)
 			return nil
 		}
 	}
@@ -107,6 +217,19 @@ func (r *inMemoryUserRepo) IsFollowing(_ context.Context, followerID, followeeID
 	return false, nil
 }
 
+func (r *inMemoryUserRepo) CountFollows(_ context.Context, userID domain.UserID) (domain.FollowCounts, error) {
+	var counts domain.FollowCounts
+	for _, f := range r.follows {
+		if f.FolloweeID == userID {
+			counts.FollowerCount++
+		}
+		if f.FollowerID == userID {
+			counts.FollowCount++
+		}
+	}
+	return counts, nil
+}
+
 func (r *inMemoryUserRepo) ListFollowers(_ context.Context, userID domain.UserID) ([]domain.PublicProfile, error) {
 	var result []domain.PublicProfile
 	for _, f := range r.follows {
@@ -135,6 +258,43 @@ func (r *inMemoryUserRepo) ListFollowing(_ context.Context, userID domain.UserID
 	return result, nil
 }
 
+func (r *inMemoryUserRepo) Block(ctx context.Context, blockerID, blockedID domain.UserID) error {
+	if blocked, _ := r.IsBlocked(ctx, blockerID, blockedID); !blocked {
+		r.blocks = append(r.blocks, domain.Follow{FollowerID: blockerID, FolloweeID: blockedID})
+	}
+	_ = r.Unfollow(ctx, blockerID, blockedID)
+	return r.Unfollow(ctx, blockedID, blockerID)
+}
+
+func (r *inMemoryUserRepo) Unblock(_ context.Context, blockerID, blockedID domain.UserID) error {
+	for i, b := range r.blocks {
+		if b.FollowerID == blockerID && b.FolloweeID == blockedID {
+			r.blocks = append(r.blocks[:i], r.blocks[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryUserRepo) IsBlocked(_ context.Context, blockerID, blockedID domain.UserID) (bool, error) {
+	for _, b := range r.blocks {
+		if b.FollowerID == blockerID && b.FolloweeID == blockedID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *inMemoryUserRepo) ListBlocked(_ context.Context, blockerID domain.UserID) ([]domain.PublicProfile, error) {
+	var result []domain.PublicProfile
+	for _, b := range r.blocks {
+		if b.FollowerID == blockerID {
+			result = append(result, domain.PublicProfile{ID: b.FolloweeID})
+		}
+	}
+	return result, nil
+}
+
 func (r *inMemoryUserRepo) GetPublicProfile(_ context.Context, userID domain.UserID) (domain.PublicProfile, error) {
 	for _, u := range r.users {
 		if u.ID == userID {
@@ -153,6 +313,122 @@ func (r *inMemoryUserRepo) GetPublicProfileByUsername(_ context.Context, usernam
 	return domain.PublicProfile{}, errs.ErrNotFound
 }
 
+func (r *inMemoryUserRepo) CreateNotification(_ context.Context, notification domain.Notification) error {
+	r.notifications = append(r.notifications, notification)
+	return nil
+}
+
+func (r *inMemoryUserRepo) CountUnreadNotifications(_ context.Context, userID domain.UserID) (int, error) {
+	count := 0
+	for _, n := range r.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *inMemoryUserRepo) MarkNotificationsRead(_ context.Context, userID domain.UserID, readAt time.Time) error {
+	for i, n := range r.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			r.notifications[i].ReadAt = &readAt
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryUserRepo) CreateRefreshToken(_ context.Context, token domain.RefreshToken) error {
+	r.refreshTokens = append(r.refreshTokens, token)
+	return nil
+}
+
+func (r *inMemoryUserRepo) GetRefreshTokenByHash(_ context.Context, tokenHash string) (domain.RefreshToken, error) {
+	for _, t := range r.refreshTokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return domain.RefreshToken{}, errs.ErrNotFound
+}
+
+func (r *inMemoryUserRepo) RevokeRefreshToken(_ context.Context, id string, revokedAt time.Time) error {
+	for i, t := range r.refreshTokens {
+		if t.ID == id {
+			if t.RevokedAt != nil {
+				return errs.ErrConflict
+			}
+			r.refreshTokens[i].RevokedAt = &revokedAt
+			return nil
+		}
+	}
+	return errs.ErrConflict
+}
+
+func (r *inMemoryUserRepo) RevokeRefreshTokenFamily(_ context.Context, familyID string, revokedAt time.Time) error {
+	for i, t := range r.refreshTokens {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			r.refreshTokens[i].RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryUserRepo) CreatePasswordReset(_ context.Context, reset domain.PasswordReset) error {
+	r.resets = append(r.resets, reset)
+	return nil
+}
+
+func (r *inMemoryUserRepo) GetPasswordResetByHash(_ context.Context, tokenHash string) (domain.PasswordReset, error) {
+	for _, reset := range r.resets {
+		if reset.TokenHash == tokenHash {
+			return reset, nil
+		}
+	}
+	return domain.PasswordReset{}, errs.ErrNotFound
+}
+
+func (r *inMemoryUserRepo) ResetPassword(ctx context.Context, resetID string, passwordHash string, usedAt time.Time) error {
+	for i, reset := range r.resets {
+		if reset.ID == resetID && reset.UsedAt == nil {
+			r.resets[i].UsedAt = &usedAt
+			return r.UpdatePasswordHash(ctx, reset.UserID, passwordHash, usedAt)
+		}
+	}
+	return errs.ErrConflict
+}
+
+func (r *inMemoryUserRepo) CreateEmailVerification(_ context.Context, verification domain.EmailVerification) error {
+	r.verifications = append(r.verifications, verification)
+	return nil
+}
+
+func (r *inMemoryUserRepo) GetEmailVerificationByHash(_ context.Context, tokenHash string) (domain.EmailVerification, error) {
+	for _, verification := range r.verifications {
+		if verification.TokenHash == tokenHash {
+			return verification, nil
+		}
+	}
+	return domain.EmailVerification{}, errs.ErrNotFound
+}
+
+func (r *inMemoryUserRepo) VerifyEmail(_ context.Context, verificationID string, usedAt time.Time) error {
+	for i, verification := range r.verifications {
+		if verification.ID != verificationID || verification.UsedAt != nil {
+			continue
+		}
+		for j, u := range r.users {
+			if u.ID == verification.UserID {
+				r.verifications[i].UsedAt = &usedAt
+				r.users[j].EmailVerified = true
+				r.users[j].UpdatedAt = usedAt
+				return nil
+			}
+		}
+		return errs.ErrNotFound
+	}
+	return errs.ErrConflict
+}
+
 // --- tests ---
 
 func newTestService() (*Service, *inMemoryUserRepo) {
@@ -181,6 +457,26 @@ func TestSignup_Success(t *testing.T) {
 	}
 }
 
+func TestSignup_DerivesUsernameFromEmail(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+
+	first, _, err := svc.Signup(ctx, "John.Doe@example.com", "", "John", "password123")
+	if err != nil {
+		t.Fatalf("expected signup with a dotted email to succeed, got %v", err)
+	}
+	if first.Username != "johndoe" {
+		t.Fatalf("expected sanitized username johndoe, got %q", first.Username)
+	}
+	second, _, err := svc.Signup(ctx, "john.doe@example.org", "", "John", "password123")
+	if err != nil {
+		t.Fatalf("expected second signup to succeed, got %v", err)
+	}
+	if second.Username != "johndoe2" {
+		t.Fatalf("expected de-duplicated username johndoe2, got %q", second.Username)
+	}
+}
+
 func TestSignup_DefaultDisplayName(t *testing.T) {
 	svc, _ := newTestService()
 	user, _, err := svc.Signup(context.Background(), "bob@example.com", "bob", "", "password123")
@@ -335,7 +631,8 @@ func TestUpdateProfile(t *testing.T) {
 	ctx := context.Background()
 	user, _, _ := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
 
-	err := svc.UpdateProfile(ctx, user.ID, "Alice W.", "Hello world", "https://example.com/avatar.png")
+	displayName, bio := "Alice W.", "Hello world"
+	err := svc.UpdateProfile(ctx, user.ID, domain.ProfileUpdate{DisplayName: &displayName, Bio: &bio})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -351,3 +648,645 @@ func TestUpdateProfile(t *testing.T) {
 		t.Errorf("expected bio 'Hello world', got '%s'", updated.Bio)
 	}
 }
+
+func TestUpdateProfileLeavesOmittedFields(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	user, _, _ := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+
+	bio := "Hello world"
+	if err := svc.UpdateProfile(ctx, user.ID, domain.ProfileUpdate{Bio: &bio}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := svc.GetProfile(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.DisplayName != "Alice" {
+		t.Errorf("expected display name to stay 'Alice', got '%s'", updated.DisplayName)
+	}
+	if updated.Bio != bio {
+		t.Errorf("expected bio %q, got %q", bio, updated.Bio)
+	}
+}
+
+func ptr(value string) *string { return &value }
+
+func TestProfileLengthLimits(t *testing.T) {
+	ctx := context.Background()
+	repo := &inMemoryUserRepo{}
+	svc := NewService(repo, fakeTokenIssuer{}, fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, WithProfileLimits(5, 10))
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup at display name limit: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		displayName *string
+		bio         *string
+		wantErr     string
+	}{
+		{name: "display name at limit", displayName: ptr("Alice")},
+		{name: "display name over limit", displayName: ptr("Alice!"), wantErr: "display name must be at most 5 characters"},
+		{name: "multibyte display name at limit", displayName: ptr("Zoë 😀")},
+		{name: "bio at limit", bio: ptr("0123456789")},
+		{name: "bio over limit", bio: ptr("0123456789a"), wantErr: "bio must be at most 10 characters"},
+		{name: "multibyte bio at limit", bio: ptr("héllo wörl")},
+		{name: "empty fields", displayName: ptr(""), bio: ptr("")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := svc.UpdateProfile(ctx, user.ID, domain.ProfileUpdate{DisplayName: tc.displayName, Bio: tc.bio})
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, errs.ErrInvalidInput) || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected invalid input %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	if _, _, err := svc.Signup(ctx, "bob@example.com", "bob", "Bobby B", "password123"); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected signup with a long display name to be rejected, got %v", err)
+	}
+	google, _, err := svc.LoginOrSignupWithGoogle(ctx, "carol@example.com", "Caroline Long-Name", "")
+	if err != nil {
+		t.Fatalf("google signup: %v", err)
+	}
+	if google.DisplayName != "Carol" {
+		t.Fatalf("expected google display name truncated to 'Carol', got %q", google.DisplayName)
+	}
+}
+
+func TestCountUnreadNotifications(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+	alice, _, _ := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	bob, _, _ := svc.Signup(ctx, "bob@example.com", "bob", "Bob", "password123")
+
+	_ = repo.CreateNotification(ctx, domain.Notification{ID: "n1", UserID: alice.ID, Kind: "follow", ActorID: &bob.ID})
+	_ = repo.CreateNotification(ctx, domain.Notification{ID: "n2", UserID: alice.ID, Kind: "follow", ActorID: &bob.ID})
+	_ = repo.CreateNotification(ctx, domain.Notification{ID: "n3", UserID: bob.ID, Kind: "follow", ActorID: &alice.ID})
+
+	count, err := svc.CountUnreadNotifications(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 unread notifications, got %d", count)
+	}
+
+	if err := svc.MarkNotificationsRead(ctx, alice.ID); err != nil {
+		t.Fatalf("mark read error: %v", err)
+	}
+
+	count, _ = svc.CountUnreadNotifications(ctx, alice.ID)
+	if count != 0 {
+		t.Errorf("expected 0 unread notifications after reading, got %d", count)
+	}
+	count, _ = svc.CountUnreadNotifications(ctx, bob.ID)
+	if count != 1 {
+		t.Errorf("expected Bob's notification to stay unread, got %d", count)
+	}
+}
+
+func TestRotate_IssuesNewPair(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+	refresh, err := svc.IssueRefreshToken(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("issue refresh token: %v", err)
+	}
+
+	got, access, next, err := svc.Rotate(ctx, refresh)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if got.ID != user.ID || access == "" || next == "" || next == refresh {
+		t.Fatalf("expected fresh access and refresh tokens for %s, got user %s access %q refresh %q", user.ID, got.ID, access, next)
+	}
+	if _, _, _, err := svc.Rotate(ctx, next); err != nil {
+		t.Fatalf("rotate new token: %v", err)
+	}
+}
+
+func TestRotate_ReuseRevokesFamily(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+	stolen, err := svc.IssueRefreshToken(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("issue refresh token: %v", err)
+	}
+	_, _, next, err := svc.Rotate(ctx, stolen)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+
+	if _, _, _, err := svc.Rotate(ctx, stolen); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected replayed token to be rejected, got %v", err)
+	}
+	if _, _, _, err := svc.Rotate(ctx, next); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected family to be revoked after reuse, got %v", err)
+	}
+}
+
+func TestRotate_RejectsExpiredToken(t *testing.T) {
+	repo := &inMemoryUserRepo{}
+	issued := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := NewService(repo, fakeTokenIssuer{}, fakeClock{now: issued}, WithRefreshTokenTTL(time.Hour))
+	ctx := context.Background()
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+	refresh, err := svc.IssueRefreshToken(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("issue refresh token: %v", err)
+	}
+
+	later := NewService(repo, fakeTokenIssuer{}, fakeClock{now: issued.Add(2 * time.Hour)})
+	if _, _, _, err := later.Rotate(ctx, refresh); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestRevoke_EndsSession(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+	refresh, err := svc.IssueRefreshToken(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("issue refresh token: %v", err)
+	}
+
+	if err := svc.Revoke(ctx, refresh); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, _, _, err := svc.Rotate(ctx, refresh); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected revoked token to be rejected, got %v", err)
+	}
+	if err := svc.Revoke(ctx, "unknown"); err != nil {
+		t.Fatalf("expected revoking an unknown token to be a no-op, got %v", err)
+	}
+}
+
+func TestResetPassword_ChangesPasswordOnce(t *testing.T) {
+	mailer := newRecordingMailer()
+	svc := NewService(&inMemoryUserRepo{}, fakeTokenIssuer{}, fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, WithMailer(mailer))
+	ctx := context.Background()
+	if _, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123"); err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+	user, _, err := svc.Login(ctx, "alice@example.com", "password123")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	refresh, err := svc.IssueRefreshToken(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("issue refresh token: %v", err)
+	}
+	if err := svc.RequestPasswordReset(ctx, "Alice@Example.com"); err != nil {
+		t.Fatalf("request reset: %v", err)
+	}
+	token := mailer.resets["alice@example.com"]
+	if token == "" {
+		t.Fatal("expected the reset token to be mailed")
+	}
+
+	if err := svc.ResetPassword(ctx, token, "new-password"); err != nil {
+		t.Fatalf("reset password: %v", err)
+	}
+	if _, _, _, err := svc.Rotate(ctx, refresh); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected sessions from before the reset to be revoked, got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "alice@example.com", "new-password"); err != nil {
+		t.Fatalf("login with new password: %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "alice@example.com", "password123"); err == nil {
+		t.Fatal("expected old password to stop working")
+	}
+
+	if err := svc.ResetPassword(ctx, token, "another-password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("expected reused token to be rejected, got %v", err)
+	}
+}
+
+func TestResetPassword_RejectsExpiredToken(t *testing.T) {
+	repo := &inMemoryUserRepo{}
+	issued := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mailer := newRecordingMailer()
+	svc := NewService(repo, fakeTokenIssuer{}, fakeClock{now: issued}, WithPasswordResetTTL(time.Hour), WithMailer(mailer))
+	ctx := context.Background()
+	if _, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123"); err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+	if err := svc.RequestPasswordReset(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("request reset: %v", err)
+	}
+	token := mailer.resets["alice@example.com"]
+
+	later := NewService(repo, fakeTokenIssuer{}, fakeClock{now: issued.Add(time.Hour)})
+	if err := later.ResetPassword(ctx, token, "new-password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestResetPassword_RejectsShortPassword(t *testing.T) {
+	mailer := newRecordingMailer()
+	svc := NewService(&inMemoryUserRepo{}, fakeTokenIssuer{}, fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, WithMailer(mailer))
+	ctx := context.Background()
+	if _, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123"); err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+	if err := svc.RequestPasswordReset(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("request reset: %v", err)
+	}
+	token := mailer.resets["alice@example.com"]
+
+	if err := svc.ResetPassword(ctx, token, "short"); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input for short password, got %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "long-enough"); err != nil {
+		t.Fatalf("expected token to survive a rejected attempt, got %v", err)
+	}
+}
+
+func TestRequestPasswordReset_UnknownEmail(t *testing.T) {
+	repo := &inMemoryUserRepo{}
+	mailer := newRecordingMailer()
+	svc := NewService(repo, fakeTokenIssuer{}, fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, WithMailer(mailer))
+	if err := svc.RequestPasswordReset(context.Background(), "nobody@example.com"); err != nil {
+		t.Fatalf("expected silent no-op for unknown email, got %v", err)
+	}
+	if len(repo.resets) != 0 || len(mailer.resets) != 0 {
+		t.Fatalf("expected no reset stored or mailed, got %d stored, %d mailed", len(repo.resets), len(mailer.resets))
+	}
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+
+	refresh, err := svc.IssueRefreshToken(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("issue refresh token: %v", err)
+	}
+
+	if err := svc.ChangePassword(ctx, user.ID, "password123", "new-password"); err != nil {
+		t.Fatalf("change password: %v", err)
+	}
+	if _, _, _, err := svc.Rotate(ctx, refresh); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected existing sessions to be revoked, got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "alice@example.com", "new-password"); err != nil {
+		t.Fatalf("login with new password: %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "alice@example.com", "password123"); err == nil {
+		t.Fatal("expected old password to stop working")
+	}
+}
+
+func TestChangePassword_WrongCurrent(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+
+	if err := svc.ChangePassword(ctx, user.ID, "not-my-password", "new-password"); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input for wrong current password, got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, user.ID, "password123", "short"); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected invalid input for short new password, got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "alice@example.com", "password123"); err != nil {
+		t.Fatalf("expected password to be unchanged, got %v", err)
+	}
+}
+
+func TestConfirmEmail_VerifiesOnceAndIsIdempotent(t *testing.T) {
+	mailer := newRecordingMailer()
+	svc := NewService(&inMemoryUserRepo{}, fakeTokenIssuer{}, fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, WithMailer(mailer))
+	ctx := context.Background()
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+	if user.EmailVerified {
+		t.Fatal("expected new password signup to be unverified")
+	}
+	if err := svc.SendVerification(ctx, user.ID); err != nil {
+		t.Fatalf("send verification: %v", err)
+	}
+	token := mailer.verifications["alice@example.com"]
+	if token == "" {
+		t.Fatal("expected the verification token to be mailed")
+	}
+
+	if err := svc.ConfirmEmail(ctx, token); err != nil {
+		t.Fatalf("confirm email: %v", err)
+	}
+	if err := svc.ConfirmEmail(ctx, token); err != nil {
+		t.Fatalf("expected repeated confirmation to succeed, got %v", err)
+	}
+	profile, err := svc.GetProfile(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get profile: %v", err)
+	}
+	if !profile.EmailVerified {
+		t.Fatal("expected email to be verified")
+	}
+	if err := svc.SendVerification(ctx, user.ID); !errors.Is(err, ErrEmailAlreadyVerified) {
+		t.Fatalf("expected already verified error, got %v", err)
+	}
+}
+
+func TestConfirmEmail_RejectsExpiredToken(t *testing.T) {
+	repo := &inMemoryUserRepo{}
+	issued := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mailer := newRecordingMailer()
+	svc := NewService(repo, fakeTokenIssuer{}, fakeClock{now: issued}, WithEmailVerificationTTL(time.Hour), WithMailer(mailer))
+	ctx := context.Background()
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+	if err := svc.SendVerification(ctx, user.ID); err != nil {
+		t.Fatalf("send verification: %v", err)
+	}
+	token := mailer.verifications["alice@example.com"]
+
+	later := NewService(repo, fakeTokenIssuer{}, fakeClock{now: issued.Add(time.Hour)})
+	if err := later.ConfirmEmail(ctx, token); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+	if profile, _ := later.GetProfile(ctx, user.ID); profile.EmailVerified {
+		t.Fatal("expected email to stay unverified")
+	}
+}
+
+func TestConfirmEmail_UsedTokenOnlySucceedsOnceVerified(t *testing.T) {
+	repo := &inMemoryUserRepo{}
+	mailer := newRecordingMailer()
+	svc := NewService(repo, fakeTokenIssuer{}, fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, WithMailer(mailer))
+	ctx := context.Background()
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup error: %v", err)
+	}
+	if err := svc.SendVerification(ctx, user.ID); err != nil {
+		t.Fatalf("send verification: %v", err)
+	}
+	token := mailer.verifications["alice@example.com"]
+
+	// A token consumed without its user being verified must not report
+	// success, or the email could never be verified.
+	usedAt := time.Now()
+	repo.verifications[0].UsedAt = &usedAt
+	if err := svc.ConfirmEmail(ctx, token); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Fatalf("expected a used token for an unverified email to be rejected, got %v", err)
+	}
+	if profile, _ := svc.GetProfile(ctx, user.ID); profile.EmailVerified {
+		t.Fatal("expected email to stay unverified")
+	}
+}
+
+func TestLoginOrSignupWithGoogle_AutoVerifiesEmail(t *testing.T) {
+	svc, _ := newTestService()
+	user, _, err := svc.LoginOrSignupWithGoogle(context.Background(), "bob@example.com", "Bob", "")
+	if err != nil {
+		t.Fatalf("google signup: %v", err)
+	}
+	if !user.EmailVerified {
+		t.Fatal("expected Google signup to be verified")
+	}
+}
+
+func TestSignup_RejectsInvalidUsername(t *testing.T) {
+	svc, _ := newTestService()
+	for _, username := range []string{"john.doe", "john-doe", "jöhn", "john doe"} {
+		_, _, err := svc.Signup(context.Background(), "john@example.com", username, "John", "password123")
+		if !errors.Is(err, errs.ErrInvalidInput) {
+			t.Fatalf("expected invalid input for %q, got %v", username, err)
+		}
+	}
+}
+
+func TestLoginOrSignupWithGoogle_SanitizesAndDedupesUsername(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	if _, _, err := svc.Signup(ctx, "john@example.com", "johndoe", "John", "password123"); err != nil {
+		t.Fatalf("signup: %v", err)
+	}
+
+	first, _, err := svc.LoginOrSignupWithGoogle(ctx, "John.Doe+news@gmail.com", "John Doe", "")
+	if err != nil {
+		t.Fatalf("google signup: %v", err)
+	}
+	if first.Username != "johndoe2" {
+		t.Fatalf("expected sanitized username with suffix johndoe2, got %q", first.Username)
+	}
+
+	second, _, err := svc.LoginOrSignupWithGoogle(ctx, "johndoe@work.example", "John Doe", "")
+	if err != nil {
+		t.Fatalf("google signup: %v", err)
+	}
+	if second.Username != "johndoe3" {
+		t.Fatalf("expected next free suffix johndoe3, got %q", second.Username)
+	}
+}
+
+func TestChangeUsername_RejectsTakenAndReleasedUsernames(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+	repo.users = append(repo.users,
+		domain.User{ID: "alice-id", Email: "alice@example.com", Username: "alice"},
+		domain.User{ID: "bob-id", Email: "bob@example.com", Username: "bob"},
+	)
+
+	if err := svc.ChangeUsername(ctx, "bob-id", "Alice"); !errors.Is(err, errs.ErrConflict) {
+		t.Fatalf("expected conflict for taken username, got %v", err)
+	}
+
+	if err := svc.ChangeUsername(ctx, "alice-id", "alice_2"); err != nil {
+		t.Fatalf("change username: %v", err)
+	}
+	if user, _ := svc.GetProfile(ctx, "alice-id"); user.Username != "alice_2" {
+		t.Fatalf("expected username alice_2, got %q", user.Username)
+	}
+	if err := svc.ChangeUsername(ctx, "bob-id", "alice"); !errors.Is(err, errs.ErrConflict) {
+		t.Fatalf("expected recently released username to stay reserved, got %v", err)
+	}
+	if err := svc.ChangeUsername(ctx, "alice-id", "alice"); err != nil {
+		t.Fatalf("expected previous owner to reclaim username, got %v", err)
+	}
+}
+
+func TestChangeUsername_RejectsInvalidCharacters(t *testing.T) {
+	svc, repo := newTestService()
+	repo.users = append(repo.users, domain.User{ID: "alice-id", Email: "alice@example.com", Username: "alice"})
+
+	for _, username := range []string{"al", "alice smith", "alice@home", "alice.smith", "alice-smith", "ålice", strings.Repeat("a", 31)} {
+		if err := svc.ChangeUsername(context.Background(), "alice-id", username); !errors.Is(err, errs.ErrInvalidInput) {
+			t.Fatalf("expected invalid input for %q, got %v", username, err)
+		}
+	}
+}
+
+type recordingPageRemover struct {
+	owners []string
+}
+
+func (r *recordingPageRemover) DeleteOwnerPages(_ context.Context, ownerID string) (int, error) {
+	r.owners = append(r.owners, ownerID)
+	return 1, nil
+}
+
+func TestDeleteAccount_RemovesUserFollowsAndPages(t *testing.T) {
+	repo := &inMemoryUserRepo{}
+	pages := &recordingPageRemover{}
+	svc := NewService(repo, fakeTokenIssuer{}, fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, WithPageRemover(pages))
+	ctx := context.Background()
+	repo.users = append(repo.users,
+		domain.User{ID: "alice-id", Email: "alice@example.com", Username: "alice"},
+		domain.User{ID: "bob-id", Email: "bob@example.com", Username: "bob"},
+		domain.User{ID: "carol-id", Email: "carol@example.com", Username: "carol"},
+	)
+	for _, follow := range [][2]domain.UserID{{"alice-id", "bob-id"}, {"bob-id", "alice-id"}, {"bob-id", "carol-id"}} {
+		if err := svc.Follow(ctx, follow[0], follow[1]); err != nil {
+			t.Fatalf("follow: %v", err)
+		}
+	}
+
+	if err := svc.DeleteAccount(ctx, "alice-id"); err != nil {
+		t.Fatalf("delete account: %v", err)
+	}
+
+	if _, err := svc.GetProfile(ctx, "alice-id"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected deleted user to be gone, got %v", err)
+	}
+	if len(repo.follows) != 1 || repo.follows[0].FolloweeID != "carol-id" {
+		t.Fatalf("expected only unrelated follows to remain, got %+v", repo.follows)
+	}
+	if len(pages.owners) != 1 || pages.owners[0] != "alice-id" {
+		t.Fatalf("expected the user's pages to be removed, got %v", pages.owners)
+	}
+	if err := svc.DeleteAccount(ctx, "alice-id"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected not found deleting twice, got %v", err)
+	}
+}
+
+// recordingFollowListener records the follows it is told about.
+type recordingFollowListener struct {
+	follows [][2]domain.UserID
+}
+
+func (listener *recordingFollowListener) Followed(_ context.Context, followerID, followeeID domain.UserID) {
+	listener.follows = append(listener.follows, [2]domain.UserID{followerID, followeeID})
+}
+
+func TestFollowTellsListenerAndNotifies(t *testing.T) {
+	repo := &inMemoryUserRepo{}
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	listener := &recordingFollowListener{}
+	svc := NewService(repo, fakeTokenIssuer{}, clock, WithFollowListener(listener))
+	ctx := context.Background()
+	alice, _, _ := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	bob, _, _ := svc.Signup(ctx, "bob@example.com", "bob", "Bob", "password123")
+
+	if err := svc.Follow(ctx, bob.ID, alice.ID); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if err := svc.Follow(ctx, bob.ID, bob.ID); err == nil {
+		t.Fatalf("expected following yourself to fail")
+	}
+	if len(listener.follows) != 1 || listener.follows[0] != [2]domain.UserID{bob.ID, alice.ID} {
+		t.Fatalf("expected the listener to hear bob follow alice once, got %v", listener.follows)
+	}
+
+	// Repeat follows are dropped by the event stream, so every follow event
+	// that arrives is notified.
+	if err := NewFollowNotifier(repo, clock).NotifyFollow(ctx, bob.ID, alice.ID); err != nil {
+		t.Fatalf("notify follow: %v", err)
+	}
+	if count, _ := svc.CountUnreadNotifications(ctx, alice.ID); count != 1 {
+		t.Fatalf("expected 1 notification, got %d", count)
+	}
+	if n := repo.notifications[0]; n.Kind != NotificationKindFollow || n.ActorID == nil || *n.ActorID != bob.ID {
+		t.Fatalf("expected follow notification from bob, got %+v", n)
+	}
+}
+
+func TestBlockUserPreventsFollow(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	alice, _, _ := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	bob, _, _ := svc.Signup(ctx, "bob@example.com", "bob", "Bob", "password123")
+
+	if err := svc.Follow(ctx, bob.ID, alice.ID); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if err := svc.BlockUser(ctx, alice.ID, bob.ID); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	if following, _ := svc.IsFollowing(ctx, bob.ID, alice.ID); following {
+		t.Fatal("expected block to remove the existing follow")
+	}
+	if err := svc.Follow(ctx, bob.ID, alice.ID); !errors.Is(err, errs.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden following a user who blocked you, got %v", err)
+	}
+
+	if err := svc.UnblockUser(ctx, alice.ID, bob.ID); err != nil {
+		t.Fatalf("unblock: %v", err)
+	}
+	if err := svc.Follow(ctx, bob.ID, alice.ID); err != nil {
+		t.Fatalf("expected follow after unblock, got %v", err)
+	}
+	if err := svc.BlockUser(ctx, alice.ID, alice.ID); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput blocking yourself, got %v", err)
+	}
+}
+
+func TestImpersonate(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	user, _, err := svc.Signup(ctx, "alice@example.com", "alice", "Alice", "password123")
+	if err != nil {
+		t.Fatalf("signup: %v", err)
+	}
+
+	got, token, err := svc.Impersonate(ctx, "admin-1", user.ID)
+	if err != nil {
+		t.Fatalf("impersonate: %v", err)
+	}
+	if got.ID != user.ID || token != "fake-jwt-"+string(user.ID)+"-as-admin-1" {
+		t.Fatalf("expected impersonation token for %s, got %q for %s", user.ID, token, got.ID)
+	}
+	if _, _, err := svc.Impersonate(ctx, user.ID, user.ID); !errors.Is(err, errs.ErrInvalidInput) {
+		t.Fatalf("expected self-impersonation to be rejected, got %v", err)
+	}
+	if _, _, err := svc.Impersonate(ctx, "admin-1", "missing"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected unknown user to be not found, got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// EmailVerification is a single-use, expiring token proving a user controls
+// their email address. Only a hash of the token is stored.
+type EmailVerification struct {
+	ID        string
+	UserID    UserID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
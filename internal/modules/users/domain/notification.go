@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+type NotificationID string
+
+// Notification is an event addressed to a single user, e.g. a new follower.
+type Notification struct {
+	ID        NotificationID `json:"id"`
+	UserID    UserID         `json:"user_id"`
+	Kind      string         `json:"kind"`
+	ActorID   *UserID        `json:"actor_id,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	ReadAt    *time.Time     `json:"read_at,omitempty"`
+}
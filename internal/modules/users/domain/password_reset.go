@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// PasswordReset is a single-use, expiring token allowing a user to set a new
+// password. Only a hash of the token is stored.
+type PasswordReset struct {
+	ID        string
+	UserID    UserID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
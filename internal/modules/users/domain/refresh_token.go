@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// RefreshToken is a persisted, opaque credential exchanged for new access
+// tokens. Only a hash of the token is stored. Tokens rotated from the same
+// login share a FamilyID so a replayed token can revoke the whole chain.
+type RefreshToken struct {
+	ID        string
+	UserID    UserID
+	FamilyID  string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
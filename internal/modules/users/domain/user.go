@@ -5,15 +5,28 @@ import "time"
 type UserID string
 
 type User struct {
-	ID           UserID    `json:"id"`
-	Email        string    `json:"email"`
-	Username     string    `json:"username"`
-	DisplayName  string    `json:"display_name"`
-	Bio          string    `json:"bio"`
-	AvatarURL    string    `json:"avatar_url,omitempty"`
-	PasswordHash string    `json:"-"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            UserID    `json:"id"`
+	Email         string    `json:"email"`
+	Username      string    `json:"username"`
+	DisplayName   string    `json:"display_name"`
+	Bio           string    `json:"bio"`
+	AvatarURL     string    `json:"avatar_url,omitempty"`
+	PasswordHash  string    `json:"-"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ProfileUpdate holds the profile fields to change; nil fields are left as
+// they are.
+type ProfileUpdate struct {
+	DisplayName *string
+	Bio         *string
+}
+
+// Empty reports whether the update changes nothing.
+func (update ProfileUpdate) Empty() bool {
+	return update.DisplayName == nil && update.Bio == nil
 }
 
 // PublicProfile is the view of a user visible to others.
@@ -27,6 +40,12 @@ type PublicProfile struct {
 	FollowCount   int    `json:"follow_count"`
 }
 
+// FollowCounts is how many people follow a user and how many they follow.
+type FollowCounts struct {
+	FollowerCount int `json:"follower_count"`
+	FollowCount   int `json:"follow_count"`
+}
+
 type Follow struct {
 	FollowerID UserID    `json:"follower_id"`
 	FolloweeID UserID    `json:"followee_id"`
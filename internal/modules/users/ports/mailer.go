@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// Mailer delivers account emails carrying single-use tokens.
+type Mailer interface {
+	SendEmailVerification(ctx context.Context, to string, token string) error
+	SendPasswordReset(ctx context.Context, to string, token string) error
+}
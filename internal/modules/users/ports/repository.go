@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/reggieanim/jot/internal/modules/users/domain"
 )
@@ -11,13 +12,63 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id domain.UserID) (domain.User, error)
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
 	GetByUsername(ctx context.Context, username string) (domain.User, error)
-	UpdateProfile(ctx context.Context, id domain.UserID, displayName, bio, avatarURL string) error
+	// DeleteAccount removes the user, their follows and any pages they still
+	// own in one transaction.
+	DeleteAccount(ctx context.Context, id domain.UserID) error
+	// UpdateProfile sets only the fields present in update.
+	UpdateProfile(ctx context.Context, id domain.UserID, update domain.ProfileUpdate) error
+	// SetAvatar points the user's avatar at an uploaded object, or clears it
+	// when url and key are empty, and returns the key of the object it
+	// replaces, or "" if the old avatar was not one we stored.
+	SetAvatar(ctx context.Context, id domain.UserID, url, key string) (previousKey string, err error)
+	// UpdatePasswordHash sets the user's password and revokes all their
+	// refresh tokens in one transaction, so no session outlives the old
+	// password.
+	UpdatePasswordHash(ctx context.Context, id domain.UserID, passwordHash string, updatedAt time.Time) error
+	// ChangeUsername renames the user and records the old username as released.
+	// It returns ErrConflict if the new username is taken.
+	ChangeUsername(ctx context.Context, id domain.UserID, oldUsername, newUsername string, changedAt time.Time) error
+	// GetUsernameReleasedSince returns the user who most recently released
+	// username after since, or ErrNotFound.
+	GetUsernameReleasedSince(ctx context.Context, username string, since time.Time) (domain.UserID, error)
 
 	Follow(ctx context.Context, followerID, followeeID domain.UserID) error
 	Unfollow(ctx context.Context, followerID, followeeID domain.UserID) error
 	IsFollowing(ctx context.Context, followerID, followeeID domain.UserID) (bool, error)
 	ListFollowers(ctx context.Context, userID domain.UserID) ([]domain.PublicProfile, error)
 	ListFollowing(ctx context.Context, userID domain.UserID) ([]domain.PublicProfile, error)
+	CountFollows(ctx context.Context, userID domain.UserID) (domain.FollowCounts, error)
 	GetPublicProfile(ctx context.Context, userID domain.UserID) (domain.PublicProfile, error)
 	GetPublicProfileByUsername(ctx context.Context, username string) (domain.PublicProfile, error)
+
+	// Block records that blockerID blocked blockedID and removes any follows
+	// between them.
+	Block(ctx context.Context, blockerID, blockedID domain.UserID) error
+	Unblock(ctx context.Context, blockerID, blockedID domain.UserID) error
+	IsBlocked(ctx context.Context, blockerID, blockedID domain.UserID) (bool, error)
+	ListBlocked(ctx context.Context, blockerID domain.UserID) ([]domain.PublicProfile, error)
+
+	CreateNotification(ctx context.Context, notification domain.Notification) error
+	CountUnreadNotifications(ctx context.Context, userID domain.UserID) (int, error)
+	MarkNotificationsRead(ctx context.Context, userID domain.UserID, readAt time.Time) error
+
+	CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (domain.RefreshToken, error)
+	// RevokeRefreshToken returns ErrConflict if the token was already revoked.
+	RevokeRefreshToken(ctx context.Context, id string, revokedAt time.Time) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+
+	CreatePasswordReset(ctx context.Context, reset domain.PasswordReset) error
+	GetPasswordResetByHash(ctx context.Context, tokenHash string) (domain.PasswordReset, error)
+	// ResetPassword consumes the reset and, in the same transaction, updates
+	// its user's password and revokes all their refresh tokens. It returns
+	// ErrConflict if the reset was already used.
+	ResetPassword(ctx context.Context, resetID string, passwordHash string, usedAt time.Time) error
+
+	CreateEmailVerification(ctx context.Context, verification domain.EmailVerification) error
+	GetEmailVerificationByHash(ctx context.Context, tokenHash string) (domain.EmailVerification, error)
+	// VerifyEmail consumes the verification and marks its user's email
+	// verified in one transaction. It returns ErrConflict if the verification
+	// was already used.
+	VerifyEmail(ctx context.Context, verificationID string, usedAt time.Time) error
 }
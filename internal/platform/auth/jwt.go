@@ -2,54 +2,176 @@ package auth
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/reggieanim/jot/internal/modules/users/domain"
 )
 
-const tokenExpiry = 7 * 24 * time.Hour // 7 days
+const (
+	defaultTokenTTL = 7 * 24 * time.Hour // 7 days
+	// ImpersonationTTL is how long support impersonation tokens stay valid.
+	ImpersonationTTL = 15 * time.Minute
+)
+
+// JWTConfig configures a JWTIssuer. Issuer and Audience are stamped on every
+// token and required when parsing if set; a zero TTL means 7 days.
+//
+// Algorithm is HS256 (the default, signed with Secret), RS256 or ES256. The
+// asymmetric algorithms sign with PrivateKeyPEM and verify with PublicKeyPEM,
+// so verifiers can be given only the public key. Either key may be omitted:
+// without a private key the issuer can only verify, and the public key is
+// derived from the private key when not given.
+type JWTConfig struct {
+	Secret        string
+	Algorithm     string
+	PrivateKeyPEM []byte
+	PublicKeyPEM  []byte
+	TTL           time.Duration
+	Issuer        string
+	Audience      string
+}
 
 type JWTIssuer struct {
-	secret []byte
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	ttl       time.Duration
+	issuer    string
+	audience  string
+	now       func() time.Time
 }
 
 func NewJWTIssuer(secret string) *JWTIssuer {
-	return &JWTIssuer{secret: []byte(secret)}
+	issuer, _ := NewJWTIssuerWithConfig(JWTConfig{Secret: secret})
+	return issuer
+}
+
+func NewJWTIssuerWithConfig(cfg JWTConfig) (*JWTIssuer, error) {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultTokenTTL
+	}
+	issuer := &JWTIssuer{
+		ttl:      ttl,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		now:      time.Now,
+	}
+
+	switch alg := strings.ToUpper(strings.TrimSpace(cfg.Algorithm)); alg {
+	case "", "HS256":
+		issuer.method = jwt.SigningMethodHS256
+		issuer.signKey = []byte(cfg.Secret)
+		issuer.verifyKey = []byte(cfg.Secret)
+	case "RS256":
+		issuer.method = jwt.SigningMethodRS256
+		if len(cfg.PrivateKeyPEM) > 0 {
+			key, err := jwt.ParseRSAPrivateKeyFromPEM(cfg.PrivateKeyPEM)
+			if err != nil {
+				return nil, fmt.Errorf("parse rsa private key: %w", err)
+			}
+			issuer.signKey, issuer.verifyKey = key, &key.PublicKey
+		}
+		if len(cfg.PublicKeyPEM) > 0 {
+			key, err := jwt.ParseRSAPublicKeyFromPEM(cfg.PublicKeyPEM)
+			if err != nil {
+				return nil, fmt.Errorf("parse rsa public key: %w", err)
+			}
+			issuer.verifyKey = key
+		}
+	case "ES256":
+		issuer.method = jwt.SigningMethodES256
+		if len(cfg.PrivateKeyPEM) > 0 {
+			key, err := jwt.ParseECPrivateKeyFromPEM(cfg.PrivateKeyPEM)
+			if err != nil {
+				return nil, fmt.Errorf("parse ecdsa private key: %w", err)
+			}
+			issuer.signKey, issuer.verifyKey = key, &key.PublicKey
+		}
+		if len(cfg.PublicKeyPEM) > 0 {
+			key, err := jwt.ParseECPublicKeyFromPEM(cfg.PublicKeyPEM)
+			if err != nil {
+				return nil, fmt.Errorf("parse ecdsa public key: %w", err)
+			}
+			issuer.verifyKey = key
+		}
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", cfg.Algorithm)
+	}
+	if issuer.verifyKey == nil {
+		return nil, fmt.Errorf("%s requires a private or public key", issuer.method.Alg())
+	}
+	return issuer, nil
+}
+
+// TTL reports how long issued tokens stay valid.
+func (j *JWTIssuer) TTL() time.Duration {
+	return j.ttl
 }
 
 type Claims struct {
 	UserID string `json:"uid"`
 	Email  string `json:"email"`
+	// ImpersonatedBy is the admin acting as UserID on impersonation tokens.
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func (j *JWTIssuer) Issue(userID domain.UserID, email string) (string, error) {
-	now := time.Now()
-	claims := Claims{
-		UserID: string(userID),
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(tokenExpiry)),
-		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString(j.secret)
+	return j.sign(Claims{UserID: string(userID), Email: email}, j.ttl)
+}
+
+// IssueImpersonation issues a token that lets impersonatorID act as userID
+// for ImpersonationTTL. The token carries the impersonated_by claim so every
+// request made with it can be audited.
+func (j *JWTIssuer) IssueImpersonation(userID domain.UserID, email string, impersonatorID domain.UserID) (string, error) {
+	if impersonatorID == "" {
+		return "", fmt.Errorf("sign token: impersonator is required")
+	}
+	return j.sign(Claims{UserID: string(userID), Email: email, ImpersonatedBy: string(impersonatorID)}, ImpersonationTTL)
+}
+
+func (j *JWTIssuer) sign(claims Claims, ttl time.Duration) (string, error) {
+	now := j.now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    j.issuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+	if j.audience != "" {
+		claims.Audience = jwt.ClaimStrings{j.audience}
+	}
+	if j.signKey == nil {
+		return "", fmt.Errorf("sign token: no %s private key configured", j.method.Alg())
+	}
+	token := jwt.NewWithClaims(j.method, claims)
+	signed, err := token.SignedString(j.signKey)
 	if err != nil {
 		return "", fmt.Errorf("sign token: %w", err)
 	}
 	return signed, nil
 }
 
-// Parse validates and returns the claims from a JWT string.
+// Parse validates and returns the claims from a JWT string, including the
+// issuer and audience when the issuer is configured with them. Tokens signed
+// with any algorithm other than the configured one are rejected, so an HMAC
+// token keyed with a public key cannot pass an RS256 verifier.
 func (j *JWTIssuer) Parse(tokenStr string) (*Claims, error) {
+	opts := []jwt.ParserOption{jwt.WithTimeFunc(j.now), jwt.WithValidMethods([]string{j.method.Alg()})}
+	if j.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(j.issuer))
+	}
+	if j.audience != "" {
+		opts = append(opts, jwt.WithAudience(j.audience))
+	}
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		if t.Method.Alg() != j.method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-		return j.secret, nil
-	})
+		return j.verifyKey, nil
+	}, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("parse token: %w", err)
 	}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func mustIssuer(t *testing.T, cfg JWTConfig) *JWTIssuer {
+	t.Helper()
+	issuer, err := NewJWTIssuerWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("new issuer: %v", err)
+	}
+	return issuer
+}
+
+func rsaKeyPEM(t *testing.T) (privatePEM, publicPEM []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	privatePEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return privatePEM, publicPEM
+}
+
+func TestRS256SignAndVerifyWithPublicKey(t *testing.T) {
+	privatePEM, publicPEM := rsaKeyPEM(t)
+	signer := mustIssuer(t, JWTConfig{Algorithm: "RS256", PrivateKeyPEM: privatePEM})
+	verifier := mustIssuer(t, JWTConfig{Algorithm: "RS256", PublicKeyPEM: publicPEM})
+
+	token, err := signer.Issue("user-1", "user@example.com")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	claims, err := verifier.Parse(token)
+	if err != nil {
+		t.Fatalf("verify with public key: %v", err)
+	}
+	if claims.UserID != "user-1" {
+		t.Fatalf("expected user-1, got %q", claims.UserID)
+	}
+	if _, err := verifier.Issue("user-1", "user@example.com"); err == nil {
+		t.Fatal("expected verify-only issuer to refuse signing")
+	}
+}
+
+func TestRS256VerifierRejectsHS256Token(t *testing.T) {
+	_, publicPEM := rsaKeyPEM(t)
+	verifier := mustIssuer(t, JWTConfig{Algorithm: "RS256", PublicKeyPEM: publicPEM})
+
+	// Alg confusion: an HMAC token keyed with the verifier's public key.
+	forged, err := mustIssuer(t, JWTConfig{Secret: string(publicPEM)}).Issue("user-1", "user@example.com")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if _, err := verifier.Parse(forged); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("expected HS256 token to be rejected, got %v", err)
+	}
+}
+
+func TestNewJWTIssuerWithConfigRejectsUnknownAlgorithm(t *testing.T) {
+	if _, err := NewJWTIssuerWithConfig(JWTConfig{Algorithm: "none"}); err == nil {
+		t.Fatal("expected error for unsupported algorithm")
+	}
+	if _, err := NewJWTIssuerWithConfig(JWTConfig{Algorithm: "RS256"}); err == nil {
+		t.Fatal("expected error for RS256 without keys")
+	}
+}
+
+func TestParseRejectsExpiredToken(t *testing.T) {
+	issuer := mustIssuer(t, JWTConfig{Secret: "test-secret", TTL: time.Hour})
+	issued := time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)
+	issuer.now = func() time.Time { return issued }
+
+	token, err := issuer.Issue("user-1", "user@example.com")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if _, err := issuer.Parse(token); err != nil {
+		t.Fatalf("expected fresh token to parse, got %v", err)
+	}
+
+	issuer.now = func() time.Time { return issued.Add(2 * time.Hour) }
+	if _, err := issuer.Parse(token); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Fatalf("expected expired token error, got %v", err)
+	}
+}
+
+func TestParseRejectsWrongAudienceAndIssuer(t *testing.T) {
+	verifier := mustIssuer(t, JWTConfig{Secret: "test-secret", Issuer: "jot", Audience: "jot-web"})
+
+	tests := []struct {
+		name    string
+		cfg     JWTConfig
+		wantErr error
+	}{
+		{name: "wrong audience", cfg: JWTConfig{Secret: "test-secret", Issuer: "jot", Audience: "jot-admin"}, wantErr: jwt.ErrTokenInvalidAudience},
+		{name: "missing audience", cfg: JWTConfig{Secret: "test-secret", Issuer: "jot"}, wantErr: jwt.ErrTokenRequiredClaimMissing},
+		{name: "wrong issuer", cfg: JWTConfig{Secret: "test-secret", Issuer: "other", Audience: "jot-web"}, wantErr: jwt.ErrTokenInvalidIssuer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := mustIssuer(t, tt.cfg).Issue("user-1", "user@example.com")
+			if err != nil {
+				t.Fatalf("issue: %v", err)
+			}
+			if _, err := verifier.Parse(token); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	token, err := verifier.Issue("user-1", "user@example.com")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	claims, err := verifier.Parse(token)
+	if err != nil {
+		t.Fatalf("expected matching token to parse, got %v", err)
+	}
+	if claims.Issuer != "jot" || len(claims.Audience) != 1 || claims.Audience[0] != "jot-web" {
+		t.Fatalf("unexpected registered claims %+v", claims.RegisteredClaims)
+	}
+}
+
+func TestDefaultTTLIsSevenDays(t *testing.T) {
+	if ttl := NewJWTIssuer("test-secret").TTL(); ttl != 7*24*time.Hour {
+		t.Fatalf("expected 7 day default TTL, got %v", ttl)
+	}
+}
+
+func TestIssueImpersonationCarriesClaimAndShortTTL(t *testing.T) {
+	issuer := mustIssuer(t, JWTConfig{Secret: "test-secret"})
+	issued := time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)
+	issuer.now = func() time.Time { return issued }
+
+	token, err := issuer.IssueImpersonation("user-1", "user@example.com", "admin-1")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	claims, err := issuer.Parse(token)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if claims.UserID != "user-1" || claims.ImpersonatedBy != "admin-1" {
+		t.Fatalf("expected user-1 impersonated by admin-1, got %+v", claims)
+	}
+	if !claims.ExpiresAt.Equal(issued.Add(ImpersonationTTL)) {
+		t.Fatalf("expected expiry after %v, got %v", ImpersonationTTL, claims.ExpiresAt)
+	}
+
+	token, err = issuer.Issue("user-1", "user@example.com")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if claims, err = issuer.Parse(token); err != nil || claims.ImpersonatedBy != "" {
+		t.Fatalf("expected regular token without impersonator, got %+v, %v", claims, err)
+	}
+	if _, err := issuer.IssueImpersonation("user-1", "user@example.com", ""); err == nil {
+		t.Fatal("expected an impersonator to be required")
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/reggieanim/jot/internal/modules/users/domain"
+	"go.uber.org/zap"
 )
 
 const (
@@ -13,6 +14,9 @@ const (
 	UserIDKey = "auth_user_id"
 	// UserEmailKey is the gin context key for the authenticated user's email.
 	UserEmailKey = "auth_user_email"
+	// ImpersonatorKey is the gin context key for the admin acting as the
+	// user, set only for impersonation tokens.
+	ImpersonatorKey = "auth_impersonator_id"
 )
 
 // Middleware returns a gin middleware that validates JWTs.
@@ -31,8 +35,7 @@ func Middleware(issuer *JWTIssuer) gin.HandlerFunc {
 			return
 		}
 
-		c.Set(UserIDKey, domain.UserID(claims.UserID))
-		c.Set(UserEmailKey, claims.Email)
+		setClaims(c, claims)
 		c.Next()
 	}
 }
@@ -50,8 +53,59 @@ func OptionalMiddleware(issuer *JWTIssuer) gin.HandlerFunc {
 			c.Next()
 			return
 		}
-		c.Set(UserIDKey, domain.UserID(claims.UserID))
-		c.Set(UserEmailKey, claims.Email)
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+func setClaims(c *gin.Context, claims *Claims) {
+	c.Set(UserIDKey, domain.UserID(claims.UserID))
+	c.Set(UserEmailKey, claims.Email)
+	if claims.ImpersonatedBy != "" {
+		c.Set(ImpersonatorKey, domain.UserID(claims.ImpersonatedBy))
+	}
+}
+
+// AuditImpersonation logs every request made with an impersonation token,
+// naming both the impersonated user and the admin behind it. Register it on
+// the router so it wraps the routes that run Middleware.
+func AuditImpersonation(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		impersonator, ok := GetImpersonator(c)
+		if !ok {
+			return
+		}
+		uid, _ := GetUserID(c)
+		logger.Info("impersonated request",
+			zap.String("user_id", string(uid)),
+			zap.String("impersonated_by", string(impersonator)),
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.FullPath()),
+			zap.Int("status", c.Writer.Status()),
+		)
+	}
+}
+
+// RequireAdmin rejects requests from users outside the comma-separated
+// adminUserIDs with 403, as well as impersonated requests. It must run after
+// Middleware. With no admins configured every request is rejected.
+func RequireAdmin(adminUserIDs string) gin.HandlerFunc {
+	admins := make(map[domain.UserID]bool)
+	for _, id := range strings.Split(adminUserIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[domain.UserID(id)] = true
+		}
+	}
+	return func(c *gin.Context) {
+		if _, impersonated := GetImpersonator(c); impersonated {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			return
+		}
+		if uid, ok := GetUserID(c); !ok || !admins[uid] {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			return
+		}
 		c.Next()
 	}
 }
@@ -66,6 +120,17 @@ func GetUserID(c *gin.Context) (domain.UserID, bool) {
 	return uid, ok
 }
 
+// GetImpersonator reads the admin behind an impersonation token from the gin
+// context.
+func GetImpersonator(c *gin.Context) (domain.UserID, bool) {
+	v, exists := c.Get(ImpersonatorKey)
+	if !exists {
+		return "", false
+	}
+	uid, ok := v.(domain.UserID)
+	return uid, ok
+}
+
 func extractToken(c *gin.Context) string {
 	// 1. Authorization: Bearer <token>
 	header := c.GetHeader("Authorization")
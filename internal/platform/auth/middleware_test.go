@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAuditImpersonationRecordsImpersonator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	issuer := NewJWTIssuer("test-secret")
+	core, logs := observer.New(zap.InfoLevel)
+
+	router := gin.New()
+	router.Use(AuditImpersonation(zap.New(core)))
+	router.PUT("/auth/me", Middleware(issuer), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/admin/ping", Middleware(issuer), RequireAdmin("admin-1"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(method, path, token string) int {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	regular, err := issuer.Issue("user-1", "user@example.com")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if code := request(http.MethodPut, "/auth/me", regular); code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", code)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("expected regular requests not to be audited, got %v", logs.All())
+	}
+
+	impersonated, err := issuer.IssueImpersonation("user-1", "user@example.com", "admin-1")
+	if err != nil {
+		t.Fatalf("issue impersonation: %v", err)
+	}
+	if code := request(http.MethodPut, "/auth/me", impersonated); code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", code)
+	}
+	entries := logs.TakeAll()
+	if len(entries) != 1 {
+		t.Fatalf("expected one audit entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["user_id"] != "user-1" || fields["impersonated_by"] != "admin-1" || fields["path"] != "/auth/me" || fields["status"] != int64(http.StatusNoContent) {
+		t.Fatalf("unexpected audit fields %v", fields)
+	}
+
+	// An admin impersonating another admin must not inherit admin access.
+	asAdmin, err := issuer.IssueImpersonation("admin-1", "admin@example.com", "admin-2")
+	if err != nil {
+		t.Fatalf("issue impersonation: %v", err)
+	}
+	if code := request(http.MethodGet, "/admin/ping", asAdmin); code != http.StatusForbidden {
+		t.Fatalf("expected impersonated admin request to get 403, got %d", code)
+	}
+	if logs.Len() != 1 {
+		t.Fatalf("expected rejected impersonated request to be audited, got %d entries", logs.Len())
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadRequiresJWTSecretOutsideDev(t *testing.T) {
+	t.Setenv("JOT_ENV", "production")
+	t.Setenv("JOT_DATABASE_URL", "postgres://jot:secret@db:5432/jot")
+	t.Setenv("JOT_S3_SECRET_KEY", "s3-secret")
+	t.Setenv("JOT_READ_KEY_SALT", "read-key-salt")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "JOT_JWT_SECRET") {
+		t.Fatalf("expected an error naming JOT_JWT_SECRET, got %v", err)
+	}
+
+	t.Setenv("JOT_JWT_SECRET", "a-real-signing-key")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected production config with secrets set to load, got %v", err)
+	}
+	if cfg.JWTSecret != "a-real-signing-key" {
+		t.Fatalf("expected configured secret, got %q", cfg.JWTSecret)
+	}
+}
+
+func TestLoadAllowsDefaultsInDev(t *testing.T) {
+	t.Setenv("JOT_ENV", "dev")
+
+	if _, err := Load(); err != nil {
+		t.Fatalf("expected dev defaults to load, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	production := func() Config {
+		return Config{
+			Environment:  "production",
+			DatabaseURL:  "postgres://jot:secret@db:5432/jot",
+			S3SecretKey:  "s3-secret",
+			JWTAlgorithm: "HS256",
+			JWTSecret:    "a-real-signing-key",
+			ReadKeySalt:  "read-key-salt",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(*Config)
+		wantErr string
+	}{
+		{name: "valid", edit: func(*Config) {}},
+		{name: "default jwt secret", edit: func(cfg *Config) { cfg.JWTSecret = defaultJWTSecret }, wantErr: "JOT_JWT_SECRET"},
+		{name: "empty jwt secret", edit: func(cfg *Config) { cfg.JWTSecret = "" }, wantErr: "JOT_JWT_SECRET"},
+		{name: "asymmetric jwt without keys", edit: func(cfg *Config) { cfg.JWTAlgorithm = "RS256"; cfg.JWTSecret = "" }, wantErr: "JOT_JWT_PRIVATE_KEY"},
+		{name: "asymmetric jwt with key", edit: func(cfg *Config) {
+			cfg.JWTAlgorithm = "RS256"
+			cfg.JWTSecret = ""
+			cfg.JWTPrivateKeyPath = "/keys/jwt.pem"
+		}},
+		{name: "default s3 secret", edit: func(cfg *Config) { cfg.S3SecretKey = defaultS3SecretKey }, wantErr: "JOT_S3_SECRET_KEY"},
+		{name: "empty read key salt", edit: func(cfg *Config) { cfg.ReadKeySalt = "" }, wantErr: "JOT_READ_KEY_SALT"},
+		{name: "google without secret", edit: func(cfg *Config) { cfg.GoogleClientID = "client" }, wantErr: "GOOGLE_CLIENT_SECRET"},
+		{name: "google with dev callback", edit: func(cfg *Config) {
+			cfg.GoogleClientID, cfg.GoogleClientSecret = "client", "secret"
+			cfg.GoogleCallbackURL, cfg.FrontendURL = defaultGoogleCallbackURL, "https://jot.example.com"
+		}, wantErr: "GOOGLE_CALLBACK_URL"},
+		{name: "dev defaults", edit: func(cfg *Config) {
+			cfg.Environment = "dev"
+			cfg.JWTSecret, cfg.S3SecretKey, cfg.DatabaseURL = defaultJWTSecret, defaultS3SecretKey, defaultDatabaseURL
+			cfg.ReadKeySalt = ""
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := production()
+			tt.edit(&cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error naming %s, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
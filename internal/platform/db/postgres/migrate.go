@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -46,9 +47,22 @@ func ResolveMigrationsDir(preferred string) (string, error) {
 	return "", fmt.Errorf("no migrations directory found (checked: %s)", strings.Join(candidates, ", "))
 }
 
-// RunMigrations executes all .sql files in the given directory in sorted order.
-// Each file is executed inside a single transaction. Errors from individual
-// statements (e.g. "column already exists") are logged but do not abort the run.
+// migrationLockID keys the advisory lock that keeps instances starting
+// together from applying the same migration twice.
+const migrationLockID = 7262381
+
+// lastUntrackedMigration is the newest file that shipped before
+// schema_migrations existed. Databases from that era ran every file up to and
+// including it on each boot, so adopting one records those files as applied.
+const lastUntrackedMigration = "0037_add_user_storage.sql"
+
+// RunMigrations applies the .sql files in migrationsDir, in sorted order, that
+// schema_migrations has no record of. Each file runs in its own transaction
+// and is recorded when it commits; the first failure stops the run.
+//
+// Databases migrated before schema_migrations existed are adopted by recording
+// the files up to lastUntrackedMigration without running them; everything
+// newer is then applied as usual.
 func RunMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsDir string) error {
 	entries, err := os.ReadDir(migrationsDir)
 	if err != nil {
@@ -63,21 +77,91 @@ func RunMigrations(ctx context.Context, pool *pgxpool.Pool, migrationsDir string
 	}
 	sort.Strings(files)
 
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("lock migrations: %w", err)
+	}
+	defer conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	if _, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			filename   TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	applied := make(map[string]bool)
+	rows, err := conn.Query(ctx, `SELECT filename FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("list applied migrations: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan applied migration: %w", err)
+		}
+		applied[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list applied migrations: %w", err)
+	}
+
+	var untracked bool
+	if len(applied) == 0 {
+		if err := conn.QueryRow(ctx, `SELECT to_regclass('pages') IS NOT NULL`).Scan(&untracked); err != nil {
+			return fmt.Errorf("check existing schema: %w", err)
+		}
+	}
+	if untracked {
+		for _, name := range untrackedMigrations(files) {
+			if _, err := conn.Exec(ctx, `INSERT INTO schema_migrations (filename) VALUES ($1) ON CONFLICT DO NOTHING`, name); err != nil {
+				return fmt.Errorf("record migration %s: %w", name, err)
+			}
+			applied[name] = true
+		}
+		fmt.Printf("  adopted untracked database through %s\n", lastUntrackedMigration)
+	}
+
 	for _, name := range files {
-		path := filepath.Join(migrationsDir, name)
-		sql, err := os.ReadFile(path)
+		if applied[name] {
+			continue
+		}
+		sql, err := os.ReadFile(filepath.Join(migrationsDir, name))
 		if err != nil {
 			return fmt.Errorf("read migration %s: %w", name, err)
 		}
 
-		_, execErr := pool.Exec(ctx, string(sql))
-		if execErr != nil {
-			// Non-fatal: log and continue (handles "column already exists" etc.)
-			fmt.Printf("  migration %s: %v (non-fatal, continuing)\n", name, execErr)
-			continue
+		err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, string(sql)); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (filename) VALUES ($1)`, name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("apply migration %s: %w", name, err)
 		}
 		fmt.Printf("  migration %s: applied\n", name)
 	}
 
 	return nil
 }
+
+// untrackedMigrations returns the prefix of the sorted files that predates
+// schema_migrations.
+func untrackedMigrations(files []string) []string {
+	n := sort.SearchStrings(files, lastUntrackedMigration)
+	if n < len(files) && files[n] == lastUntrackedMigration {
+		n++
+	}
+	return files[:n]
+}
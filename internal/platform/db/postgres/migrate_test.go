@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newTestPool connects to JOT_TEST_DATABASE_URL with the repository's
+// migrations applied, so schema_migrations is already tracking. Tests are
+// skipped when it is unset.
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	databaseURL := os.Getenv("JOT_TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("JOT_TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	dir, err := ResolveMigrationsDir("../../../../migrations")
+	if err != nil {
+		t.Fatalf("resolve migrations: %v", err)
+	}
+	if err := RunMigrations(ctx, pool, dir); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+	return pool
+}
+
+func TestRunMigrationsAppliesEachFileOnce(t *testing.T) {
+	pool := newTestPool(t)
+	ctx := context.Background()
+
+	suffix := time.Now().UnixNano()
+	table := fmt.Sprintf("migrate_test_%d", suffix)
+	name := fmt.Sprintf("0001_migrate_test_%d.sql", suffix)
+	if _, err := pool.Exec(ctx, `CREATE TABLE `+table+` (n INT)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DROP TABLE IF EXISTS `+table)
+		_, _ = pool.Exec(context.Background(), `DELETE FROM schema_migrations WHERE filename = $1`, name)
+	})
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(`INSERT INTO `+table+` (n) VALUES (1);`), 0o600); err != nil {
+		t.Fatalf("write migration: %v", err)
+	}
+	for range 2 {
+		if err := RunMigrations(ctx, pool, dir); err != nil {
+			t.Fatalf("run migrations: %v", err)
+		}
+	}
+
+	var runs int
+	if err := pool.QueryRow(ctx, `SELECT count(*) FROM `+table).Scan(&runs); err != nil {
+		t.Fatalf("count runs: %v", err)
+	}
+	if runs != 1 {
+		t.Fatalf("expected the migration to run once, ran %d times", runs)
+	}
+}
+
+func TestRunMigrationsFailsOnBrokenFile(t *testing.T) {
+	pool := newTestPool(t)
+	ctx := context.Background()
+
+	name := fmt.Sprintf("0001_broken_%d.sql", time.Now().UnixNano())
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM schema_migrations WHERE filename = $1`, name)
+	})
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(`SELECT * FROM no_such_table;`), 0o600); err != nil {
+		t.Fatalf("write migration: %v", err)
+	}
+
+	if err := RunMigrations(ctx, pool, dir); err == nil {
+		t.Fatalf("expected a broken migration to fail the run")
+	}
+	var recorded bool
+	if err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE filename = $1)`, name).Scan(&recorded); err != nil {
+		t.Fatalf("check record: %v", err)
+	}
+	if recorded {
+		t.Fatalf("expected a failed migration not to be recorded")
+	}
+}
+
+func TestUntrackedMigrationsStopAtBaseline(t *testing.T) {
+	files := []string{"0001_init.sql", "0036_x.sql", lastUntrackedMigration, "0038_y.sql", "0039_z.sql"}
+	got := untrackedMigrations(files)
+	if len(got) != 3 || got[len(got)-1] != lastUntrackedMigration {
+		t.Fatalf("expected files through %s, got %v", lastUntrackedMigration, got)
+	}
+	if got := untrackedMigrations([]string{"0001_init.sql", "0002_x.sql"}); len(got) != 2 {
+		t.Fatalf("expected an older tree to adopt every file, got %v", got)
+	}
+}
@@ -5,17 +5,21 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type Pool struct {
 	*pgxpool.Pool
 }
 
-func NewPool(ctx context.Context, databaseURL string) (*Pool, error) {
+func NewPool(ctx context.Context, databaseURL string, opts ...PoolOption) (*Pool, error) {
 	config, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("parse pg config: %w", err)
 	}
+	for _, opt := range opts {
+		opt(config)
+	}
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("create pg pool: %w", err)
@@ -26,3 +30,46 @@ func NewPool(ctx context.Context, databaseURL string) (*Pool, error) {
 	}
 	return &Pool{Pool: pool}, nil
 }
+
+// RegisterMetrics exports the pool's connection statistics to registerer,
+// read from the pool on every scrape.
+func (pool *Pool) RegisterMetrics(registerer prometheus.Registerer) error {
+	if err := registerer.Register(poolCollector{pool: pool.Pool}); err != nil {
+		return fmt.Errorf("register pool metrics: %w", err)
+	}
+	return nil
+}
+
+var (
+	poolConnectionsDesc = prometheus.NewDesc("jot_db_pool_connections", "Postgres pool connections by state.", []string{"state"}, nil)
+	poolAcquiresDesc    = prometheus.NewDesc("jot_db_pool_acquires_total", "Postgres pool connection acquisitions by outcome.", []string{"outcome"}, nil)
+)
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func (collector poolCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- poolConnectionsDesc
+	descs <- poolAcquiresDesc
+}
+
+func (collector poolCollector) Collect(metrics chan<- prometheus.Metric) {
+	stat := collector.pool.Stat()
+	for state, count := range map[string]int32{
+		"acquired":     stat.AcquiredConns(),
+		"idle":         stat.IdleConns(),
+		"constructing": stat.ConstructingConns(),
+		"total":        stat.TotalConns(),
+		"max":          stat.MaxConns(),
+	} {
+		metrics <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(count), state)
+	}
+	for outcome, count := range map[string]int64{
+		"acquired": stat.AcquireCount(),
+		"waited":   stat.EmptyAcquireCount(),
+		"canceled": stat.CanceledAcquireCount(),
+	} {
+		metrics <- prometheus.MustNewConstMetric(poolAcquiresDesc, prometheus.CounterValue, float64(count), outcome)
+	}
+}
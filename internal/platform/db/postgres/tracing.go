@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/reggieanim/jot/internal/platform/db/postgres"
+	// maxTracedSQL caps the statement recorded on a span; bulk inserts can
+	// build very long SQL.
+	maxTracedSQL = 1024
+)
+
+// PoolOption adjusts the pool configuration before it connects.
+type PoolOption func(*pgxpool.Config)
+
+// WithQueryTracing records a client span for every query, parented to the
+// span already in the query's context.
+func WithQueryTracing() PoolOption {
+	return func(config *pgxpool.Config) {
+		config.ConnConfig.Tracer = QueryTracer{}
+	}
+}
+
+// QueryTracer is a pgx.QueryTracer that wraps each query in an OpenTelemetry
+// span. The tracer provider is looked up per query so it follows whatever is
+// registered globally.
+type QueryTracer struct{}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := sqlOperation(data.SQL)
+	ctx, _ = otel.Tracer(tracerName).Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(truncateSQL(data.SQL)),
+		),
+	)
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
+
+// sqlOperation returns the statement's leading keyword, upper-cased, for the
+// span name.
+func sqlOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
+
+func truncateSQL(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if len(sql) <= maxTracedSQL {
+		return sql
+	}
+	return sql[:maxTracedSQL] + "..."
+}
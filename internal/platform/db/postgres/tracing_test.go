@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestQueryTracerRecordsChildSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracesdk.NewTracerProvider(tracesdk.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "GET /v1/pages/:pageID")
+	tracer := QueryTracer{}
+
+	queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "  select id from pages where id = $1"})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+
+	longSQL := "insert into blocks values " + strings.Repeat("($1), ", 400)
+	queryCtx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: longSQL})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: errors.New("unique violation")})
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 2 query spans and the parent, got %d", len(spans))
+	}
+	selectSpan, insertSpan := spans[0], spans[1]
+	if selectSpan.Name != "postgres SELECT" {
+		t.Fatalf("expected span named by operation, got %q", selectSpan.Name)
+	}
+	if selectSpan.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("expected query span to be a child of the request span")
+	}
+	for _, attr := range selectSpan.Attributes {
+		if attr.Key == semconv.DBQueryTextKey && attr.Value.AsString() != "select id from pages where id = $1" {
+			t.Fatalf("unexpected statement attribute %q", attr.Value.AsString())
+		}
+	}
+	if insertSpan.Status.Code != codes.Error {
+		t.Fatalf("expected failed query span to carry an error status")
+	}
+	for _, attr := range insertSpan.Attributes {
+		if attr.Key == semconv.DBQueryTextKey && len(attr.Value.AsString()) > maxTracedSQL+3 {
+			t.Fatalf("expected statement truncated, got %d bytes", len(attr.Value.AsString()))
+		}
+	}
+}
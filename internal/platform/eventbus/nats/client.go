@@ -1,7 +1,10 @@
 package nats
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	jnats "github.com/nats-io/nats.go"
 )
@@ -19,13 +22,91 @@ func Connect(url string) (*jnats.Conn, jnats.JetStreamContext, error) {
 	return connection, jetstream, nil
 }
 
-func EnsureStream(jetstream jnats.JetStreamContext, streamName, subject string) error {
-	_, err := jetstream.StreamInfo(streamName)
+// StreamOption configures optional EnsureStream behaviour.
+type StreamOption func(*jnats.StreamConfig)
+
+// WithStreamSubjects also captures subjects in the stream.
+func WithStreamSubjects(subjects ...string) StreamOption {
+	return func(config *jnats.StreamConfig) {
+		for _, subject := range subjects {
+			if !slices.Contains(config.Subjects, subject) {
+				config.Subjects = append(config.Subjects, subject)
+			}
+		}
+	}
+}
+
+// WithDuplicateWindow makes the stream drop a message whose Nats-Msg-Id
+// header repeats one stored within window. Zero keeps the server default.
+func WithDuplicateWindow(window time.Duration) StreamOption {
+	return func(config *jnats.StreamConfig) {
+		if window > 0 {
+			config.Duplicates = window
+		}
+	}
+}
+
+// EnsureStream creates the stream over subject and its per-page wildcard, or
+// brings an existing stream created before per-page subjects or opts up to
+// date.
+func EnsureStream(jetstream jnats.JetStreamContext, streamName, subject string, opts ...StreamOption) error {
+	opts = append([]StreamOption{WithStreamSubjects(subject, PageWildcardSubject(subject))}, opts...)
+
+	info, err := jetstream.StreamInfo(streamName)
 	if err == nil {
+		config := info.Config
+		config.Subjects = slices.Clone(info.Config.Subjects)
+		for _, opt := range opts {
+			opt(&config)
+		}
+		if slices.Equal(config.Subjects, info.Config.Subjects) && config.Duplicates == info.Config.Duplicates {
+			return nil
+		}
+		if _, err := jetstream.UpdateStream(&config); err != nil {
+			return fmt.Errorf("update stream: %w", err)
+		}
 		return nil
 	}
-	if _, err := jetstream.AddStream(&jnats.StreamConfig{Name: streamName, Subjects: []string{subject}}); err != nil {
+	config := jnats.StreamConfig{Name: streamName}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if _, err := jetstream.AddStream(&config); err != nil {
 		return fmt.Errorf("add stream: %w", err)
 	}
 	return nil
 }
+
+// EnsureDurableConsumer creates the durable pull consumer name on stream over
+// subjects, or updates an existing one's subjects. A new consumer starts with
+// the messages published after it was created; afterwards it resumes from its
+// last acknowledged message, including across restarts. Bind to it with
+// jnats.Bind so unsubscribing never deletes it.
+func EnsureDurableConsumer(jetstream jnats.JetStreamContext, stream, name string, subjects ...string) error {
+	info, err := jetstream.ConsumerInfo(stream, name)
+	if err == nil {
+		if slices.Equal(info.Config.FilterSubjects, subjects) {
+			return nil
+		}
+		config := info.Config
+		config.FilterSubject = ""
+		config.FilterSubjects = subjects
+		if _, err := jetstream.UpdateConsumer(stream, &config); err != nil {
+			return fmt.Errorf("update consumer %s subjects: %w", name, err)
+		}
+		return nil
+	}
+	if !errors.Is(err, jnats.ErrConsumerNotFound) {
+		return fmt.Errorf("look up consumer %s: %w", name, err)
+	}
+	_, err = jetstream.AddConsumer(stream, &jnats.ConsumerConfig{
+		Durable:        name,
+		AckPolicy:      jnats.AckExplicitPolicy,
+		DeliverPolicy:  jnats.DeliverNewPolicy,
+		FilterSubjects: subjects,
+	})
+	if err != nil {
+		return fmt.Errorf("add consumer %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	jnats "github.com/nats-io/nats.go"
+	"github.com/reggieanim/jot/internal/modules/users/domain"
+	"go.uber.org/zap"
+)
+
+// FollowedEventType marks a user.followed event.
+const FollowedEventType = "user.followed"
+
+// followAckWait bounds how long a follow waits for JetStream to store its
+// event.
+const followAckWait = 5 * time.Second
+
+// FollowEvent is published on the follow subject for every successful follow.
+type FollowEvent struct {
+	Type       string        `json:"type"`
+	FollowerID domain.UserID `json:"follower_id"`
+	FolloweeID domain.UserID `json:"followee_id"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+// FollowEventsPublisher publishes follows to JetStream. Every event for the
+// same follower and followee carries the same Nats-Msg-Id, so the stream's
+// duplicate window drops repeat follows and unfollow-then-refollow loops
+// across restarts and replicas.
+type FollowEventsPublisher struct {
+	jetstream jnats.JetStreamContext
+	subject   string
+	logger    *zap.Logger
+}
+
+func NewFollowEventsPublisher(jetstream jnats.JetStreamContext, subject string, logger *zap.Logger) *FollowEventsPublisher {
+	return &FollowEventsPublisher{jetstream: jetstream, subject: subject, logger: logger}
+}
+
+// Followed publishes a user.followed event. Failures are logged, since the
+// follow itself has already succeeded.
+func (publisher *FollowEventsPublisher) Followed(ctx context.Context, followerID, followeeID domain.UserID) {
+	if err := publisher.publish(ctx, followerID, followeeID); err != nil {
+		publisher.logger.Warn("publish follow event failed",
+			zap.String("follower_id", string(followerID)),
+			zap.String("followee_id", string(followeeID)),
+			zap.Error(err),
+		)
+	}
+}
+
+func (publisher *FollowEventsPublisher) publish(ctx context.Context, followerID, followeeID domain.UserID) error {
+	payload, err := json.Marshal(FollowEvent{
+		Type:       FollowedEventType,
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		Timestamp:  time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal follow event: %w", err)
+	}
+	msg := jnats.NewMsg(publisher.subject)
+	msg.Data = payload
+	msg.Header.Set(jnats.MsgIdHdr, FollowMsgID(followerID, followeeID))
+
+	ackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), followAckWait)
+	defer cancel()
+	if _, err := publisher.jetstream.PublishMsg(msg, jnats.Context(ackCtx)); err != nil {
+		return fmt.Errorf("publish follow event: %w", err)
+	}
+	return nil
+}
+
+// FollowMsgID is the JetStream deduplication ID of follows from followerID
+// to followeeID.
+func FollowMsgID(followerID, followeeID domain.UserID) string {
+	return "follow:" + string(followerID) + ":" + string(followeeID)
+}
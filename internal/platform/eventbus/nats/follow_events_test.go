@@ -0,0 +1,50 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	jnats "github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// msgRecordingJetStream captures messages published with PublishMsg.
+type msgRecordingJetStream struct {
+	jnats.JetStreamContext
+	msgs []*jnats.Msg
+}
+
+func (js *msgRecordingJetStream) PublishMsg(msg *jnats.Msg, _ ...jnats.PubOpt) (*jnats.PubAck, error) {
+	js.msgs = append(js.msgs, msg)
+	return &jnats.PubAck{}, nil
+}
+
+func TestFollowedRepeatsShareMsgID(t *testing.T) {
+	js := &msgRecordingJetStream{}
+	publisher := NewFollowEventsPublisher(js, "jot.users.follows", zap.NewNop())
+	ctx := context.Background()
+
+	publisher.Followed(ctx, "bob", "alice")
+	publisher.Followed(ctx, "bob", "alice")
+	publisher.Followed(ctx, "carol", "alice")
+
+	if len(js.msgs) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(js.msgs))
+	}
+	first, repeat, other := js.msgs[0].Header.Get(jnats.MsgIdHdr), js.msgs[1].Header.Get(jnats.MsgIdHdr), js.msgs[2].Header.Get(jnats.MsgIdHdr)
+	if first == "" || first != repeat {
+		t.Fatalf("expected a refollow to reuse the message ID, got %q and %q", first, repeat)
+	}
+	if other == first {
+		t.Fatalf("expected another follower to get its own message ID, got %q", other)
+	}
+
+	var event FollowEvent
+	if err := json.Unmarshal(js.msgs[0].Data, &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if js.msgs[0].Subject != "jot.users.follows" || event.Type != FollowedEventType || event.FollowerID != "bob" || event.FolloweeID != "alice" {
+		t.Fatalf("unexpected event %+v on %s", event, js.msgs[0].Subject)
+	}
+}
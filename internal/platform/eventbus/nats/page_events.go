@@ -7,12 +7,45 @@ import (
 	"time"
 
 	jnats "github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/reggieanim/jot/internal/modules/pages/domain"
 )
 
 type PageEventsPublisher struct {
 	jetstream jnats.JetStreamContext
 	subject   string
+	syncAck   bool
+	ackWait   time.Duration
+	published *prometheus.CounterVec
+}
+
+// PublisherOption configures optional PageEventsPublisher behaviour.
+type PublisherOption func(*PageEventsPublisher)
+
+// WithSyncAck makes each publish wait at most wait for JetStream to
+// acknowledge the event, returning an error when it is not stored in time.
+// With enabled false events are published asynchronously and only errors
+// raised before sending are returned. Publishing is synchronous by default.
+func WithSyncAck(enabled bool, wait time.Duration) PublisherOption {
+	return func(publisher *PageEventsPublisher) {
+		publisher.syncAck = enabled
+		if wait > 0 {
+			publisher.ackWait = wait
+		}
+	}
+}
+
+// WithPublishMetrics counts published events by type and result in
+// registerer. Asynchronous publishes count as successful once handed to the
+// client.
+func WithPublishMetrics(registerer prometheus.Registerer) PublisherOption {
+	return func(publisher *PageEventsPublisher) {
+		publisher.published = promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Name: "jot_nats_publish_total",
+			Help: "Page events published to NATS.",
+		}, []string{"event", "result"})
+	}
 }
 
 type pageEvent struct {
@@ -21,29 +54,70 @@ type pageEvent struct {
 	Timestamp time.Time   `json:"timestamp"`
 }
 
-func NewPageEventsPublisher(jetstream jnats.JetStreamContext, subject string) *PageEventsPublisher {
-	return &PageEventsPublisher{jetstream: jetstream, subject: subject}
+func NewPageEventsPublisher(jetstream jnats.JetStreamContext, subject string, opts ...PublisherOption) *PageEventsPublisher {
+	publisher := &PageEventsPublisher{jetstream: jetstream, subject: subject, syncAck: true}
+	for _, opt := range opts {
+		opt(publisher)
+	}
+	return publisher
 }
 
-func (publisher *PageEventsPublisher) PageCreated(_ context.Context, page domain.Page) error {
-	return publisher.publish("page.created", page)
+func (publisher *PageEventsPublisher) PageCreated(ctx context.Context, page domain.Page) error {
+	return publisher.publish(ctx, "page.created", page)
 }
 
-func (publisher *PageEventsPublisher) BlocksUpdated(_ context.Context, page domain.Page) error {
-	return publisher.publish("page.blocks.updated", page)
+func (publisher *PageEventsPublisher) BlocksUpdated(ctx context.Context, page domain.Page) error {
+	return publisher.publish(ctx, "page.blocks.updated", page)
 }
 
-func (publisher *PageEventsPublisher) PageDeleted(_ context.Context, page domain.Page) error {
-	return publisher.publish("page.deleted", page)
+// PagePublished emits page.published or page.unpublished depending on the
+// page's current state.
+func (publisher *PageEventsPublisher) PagePublished(ctx context.Context, page domain.Page) error {
+	if page.Published {
+		return publisher.publish(ctx, "page.published", page)
+	}
+	return publisher.publish(ctx, "page.unpublished", page)
 }
 
-func (publisher *PageEventsPublisher) publish(eventType string, page domain.Page) error {
+func (publisher *PageEventsPublisher) PageDeleted(ctx context.Context, page domain.Page) error {
+	return publisher.publish(ctx, "page.deleted", page)
+}
+
+func (publisher *PageEventsPublisher) publish(ctx context.Context, eventType string, page domain.Page) error {
+	err := publisher.send(ctx, eventType, page)
+	if publisher.published != nil {
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		publisher.published.WithLabelValues(eventType, result).Inc()
+	}
+	return err
+}
+
+func (publisher *PageEventsPublisher) send(ctx context.Context, eventType string, page domain.Page) error {
 	payload, err := json.Marshal(pageEvent{Type: eventType, Page: page, Timestamp: time.Now().UTC()})
 	if err != nil {
 		return fmt.Errorf("marshal page event: %w", err)
 	}
-	if _, err := publisher.jetstream.Publish(publisher.subject, payload); err != nil {
-		return fmt.Errorf("publish page event: %w", err)
+	subject := PageSubject(publisher.subject, string(page.ID))
+	if !publisher.syncAck {
+		if _, err := publisher.jetstream.PublishAsync(subject, payload); err != nil {
+			return fmt.Errorf("publish page event: %w", err)
+		}
+		return nil
+	}
+
+	var opts []jnats.PubOpt
+	if publisher.ackWait > 0 {
+		// The caller's context may be done already, e.g. for a request whose
+		// client went away; the event must still be stored.
+		ackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publisher.ackWait)
+		defer cancel()
+		opts = append(opts, jnats.Context(ackCtx))
+	}
+	if _, err := publisher.jetstream.Publish(subject, payload, opts...); err != nil {
+		return fmt.Errorf("publish %s event: %w", eventType, err)
 	}
 	return nil
 }
@@ -0,0 +1,123 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	jnats "github.com/nats-io/nats.go"
+	"github.com/reggieanim/jot/internal/modules/pages/domain"
+)
+
+// recordingJetStream captures published payloads. Only Publish is
+// implemented; any other JetStream call panics.
+type recordingJetStream struct {
+	jnats.JetStreamContext
+	subjects []string
+	payloads [][]byte
+}
+
+func (js *recordingJetStream) Publish(subject string, data []byte, _ ...jnats.PubOpt) (*jnats.PubAck, error) {
+	js.subjects = append(js.subjects, subject)
+	js.payloads = append(js.payloads, data)
+	return &jnats.PubAck{}, nil
+}
+
+// failingJetStream fails every synchronous publish with err and counts
+// asynchronous ones.
+type failingJetStream struct {
+	jnats.JetStreamContext
+	err   error
+	async int
+}
+
+func (js *failingJetStream) Publish(_ string, _ []byte, _ ...jnats.PubOpt) (*jnats.PubAck, error) {
+	return nil, js.err
+}
+
+func (js *failingJetStream) PublishAsync(_ string, _ []byte, _ ...jnats.PubOpt) (jnats.PubAckFuture, error) {
+	js.async++
+	return nil, nil
+}
+
+func TestPagePublishedEmitsPublishType(t *testing.T) {
+	tests := []struct {
+		name      string
+		published bool
+		wantType  string
+	}{
+		{name: "published", published: true, wantType: "page.published"},
+		{name: "unpublished", published: false, wantType: "page.unpublished"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js := &recordingJetStream{}
+			publisher := NewPageEventsPublisher(js, "jot.pages.events")
+
+			page := domain.Page{ID: "page-1", Title: "Hello", Published: tt.published}
+			if err := publisher.PagePublished(context.Background(), page); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+			if len(js.payloads) != 1 || js.subjects[0] != "jot.pages.events.page-1" {
+				t.Fatalf("expected one message on jot.pages.events.page-1, got %v", js.subjects)
+			}
+
+			var event pageEvent
+			if err := json.Unmarshal(js.payloads[0], &event); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			if event.Type != tt.wantType {
+				t.Fatalf("expected type %q, got %q", tt.wantType, event.Type)
+			}
+			if event.Page.ID != page.ID {
+				t.Fatalf("expected page %q, got %q", page.ID, event.Page.ID)
+			}
+		})
+	}
+}
+
+func TestPublishUsesPerPageSubject(t *testing.T) {
+	js := &recordingJetStream{}
+	publisher := NewPageEventsPublisher(js, "jot.pages.events")
+
+	if err := publisher.BlocksUpdated(context.Background(), domain.Page{ID: "8f14e45f-ceea-4e7a-9f3b-2a1d6c0b7e21"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if err := publisher.PageCreated(context.Background(), domain.Page{ID: "bad.id"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	want := []string{"jot.pages.events.8f14e45f-ceea-4e7a-9f3b-2a1d6c0b7e21", "jot.pages.events"}
+	if len(js.subjects) != len(want) {
+		t.Fatalf("expected %d messages, got %v", len(want), js.subjects)
+	}
+	for i := range want {
+		if js.subjects[i] != want[i] {
+			t.Fatalf("message %d: expected subject %q, got %q", i, want[i], js.subjects[i])
+		}
+	}
+	if PageWildcardSubject("jot.pages.events") != "jot.pages.events.>" {
+		t.Fatalf("unexpected wildcard subject %q", PageWildcardSubject("jot.pages.events"))
+	}
+}
+
+func TestSyncAckPropagatesPublishFailure(t *testing.T) {
+	js := &failingJetStream{err: jnats.ErrTimeout}
+	publisher := NewPageEventsPublisher(js, "jot.pages.events", WithSyncAck(true, time.Second))
+
+	err := publisher.PageDeleted(context.Background(), domain.Page{ID: "page-1"})
+	if !errors.Is(err, jnats.ErrTimeout) {
+		t.Fatalf("expected the publish failure to be returned, got %v", err)
+	}
+
+	async := NewPageEventsPublisher(js, "jot.pages.events", WithSyncAck(false, 0))
+	if err := async.PageDeleted(context.Background(), domain.Page{ID: "page-1"}); err != nil {
+		t.Fatalf("expected an asynchronous publish to return before the ack, got %v", err)
+	}
+	if js.async != 1 {
+		t.Fatalf("expected one asynchronous publish, got %d", js.async)
+	}
+}
@@ -0,0 +1,82 @@
+package nats
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	jnats "github.com/nats-io/nats.go"
+)
+
+// historyFetchWait bounds how long ReadPageHistory waits for a full batch;
+// a page with fewer stored events returns what arrived by then.
+const historyFetchWait = time.Second
+
+// SubscribePageStream delivers pageID's events from the JetStream stream to
+// msgs through an ephemeral ordered consumer. When afterSeq is non-zero,
+// delivery starts with the first message stored after that stream sequence,
+// replaying anything published since; otherwise only new messages arrive.
+//
+// A single consumer covers both the per-page and legacy base subjects, so
+// messages arrive in stream-sequence order with no gap or duplicate between
+// replayed and live messages. Replay is bounded by the stream's retention.
+func SubscribePageStream(jetstream jnats.JetStreamContext, stream, base, pageID string, afterSeq uint64, msgs chan *jnats.Msg) (unsubscribe func(), err error) {
+	subjects := []string{base}
+	if subject := PageSubject(base, pageID); subject != base {
+		subjects = append(subjects, subject)
+	}
+
+	start := jnats.DeliverNew()
+	if afterSeq > 0 {
+		start = jnats.StartSequence(afterSeq + 1)
+	}
+
+	subscription, err := jetstream.ChanSubscribe("", msgs,
+		jnats.BindStream(stream),
+		jnats.ConsumerFilterSubjects(subjects...),
+		jnats.OrderedConsumer(),
+		start,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("subscribe stream %s: %w", stream, err)
+	}
+	return func() { _ = subscription.Unsubscribe() }, nil
+}
+
+// StreamSequence reports the JetStream stream sequence of msg. ok is false
+// for messages delivered by a core NATS subscription.
+func StreamSequence(msg *jnats.Msg) (seq uint64, ok bool) {
+	metadata, err := msg.Metadata()
+	if err != nil {
+		return 0, false
+	}
+	return metadata.Sequence.Stream, true
+}
+
+// ReadPageHistory returns up to limit of pageID's events stored in the
+// JetStream stream, oldest first, beginning at start (e.g. jnats.StartSequence
+// or jnats.StartTime). It reads through an ephemeral pull consumer that is
+// removed once the batch is fetched.
+func ReadPageHistory(jetstream jnats.JetStreamContext, stream, base, pageID string, start jnats.SubOpt, limit int) ([]*jnats.Msg, error) {
+	subjects := []string{base}
+	if subject := PageSubject(base, pageID); subject != base {
+		subjects = append(subjects, subject)
+	}
+
+	subscription, err := jetstream.PullSubscribe("", "",
+		jnats.BindStream(stream),
+		jnats.ConsumerFilterSubjects(subjects...),
+		jnats.AckNone(),
+		start,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("subscribe stream %s: %w", stream, err)
+	}
+	defer func() { _ = subscription.Unsubscribe() }()
+
+	msgs, err := subscription.Fetch(limit, jnats.MaxWait(historyFetchWait))
+	if err != nil && !errors.Is(err, jnats.ErrTimeout) {
+		return nil, fmt.Errorf("fetch stream %s history: %w", stream, err)
+	}
+	return msgs, nil
+}
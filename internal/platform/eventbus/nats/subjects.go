@@ -0,0 +1,58 @@
+package nats
+
+import (
+	"fmt"
+	"strings"
+
+	jnats "github.com/nats-io/nats.go"
+)
+
+// PageSubject returns the subject carrying events for a single page, e.g.
+// jot.pages.events.<pageID>. IDs that are not a valid subject token fall
+// back to the base subject.
+func PageSubject(base, pageID string) string {
+	if pageID == "" || strings.ContainsAny(pageID, ".*> \t\r\n") {
+		return base
+	}
+	return base + "." + pageID
+}
+
+// PageWildcardSubject matches every per-page subject under base.
+func PageWildcardSubject(base string) string {
+	return base + ".>"
+}
+
+// SubscribePage delivers messages for pageID to msgs. It also listens on the
+// legacy base subject so events from publishers that predate per-page
+// subjects still arrive; callers must keep filtering by page ID.
+func SubscribePage(conn *jnats.Conn, base, pageID string, msgs chan *jnats.Msg) (unsubscribe func(), err error) {
+	subjects := []string{base}
+	if subject := PageSubject(base, pageID); subject != base {
+		subjects = append(subjects, subject)
+	}
+	return subscribeAll(conn, subjects, msgs)
+}
+
+// SubscribeAllPages delivers messages for every page to msgs, including
+// those still published on the legacy base subject.
+func SubscribeAllPages(conn *jnats.Conn, base string, msgs chan *jnats.Msg) (unsubscribe func(), err error) {
+	return subscribeAll(conn, []string{base, PageWildcardSubject(base)}, msgs)
+}
+
+func subscribeAll(conn *jnats.Conn, subjects []string, msgs chan *jnats.Msg) (func(), error) {
+	subscriptions := make([]*jnats.Subscription, 0, len(subjects))
+	unsubscribe := func() {
+		for _, subscription := range subscriptions {
+			_ = subscription.Unsubscribe()
+		}
+	}
+	for _, subject := range subjects {
+		subscription, err := conn.ChanSubscribe(subject, msgs)
+		if err != nil {
+			unsubscribe()
+			return nil, fmt.Errorf("subscribe %s: %w", subject, err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return unsubscribe, nil
+}
@@ -0,0 +1,16 @@
+package httputil
+
+import "github.com/gin-gonic/gin"
+
+// ContentSecurityPolicy sets the Content-Security-Policy header to policy on
+// every response in a route group. Routes serving content readers open
+// directly, such as public pages, use it to restrict script sources and
+// framing. An empty policy sets no header.
+func ContentSecurityPolicy(policy string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if policy != "" {
+			ctx.Header("Content-Security-Policy", policy)
+		}
+		ctx.Next()
+	}
+}
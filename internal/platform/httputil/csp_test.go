@@ -0,0 +1,42 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestContentSecurityPolicyOnlyOnPublicRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const policy = "default-src 'self'; frame-ancestors 'none'"
+	router := gin.New()
+	ok := func(ctx *gin.Context) { ctx.JSON(http.StatusOK, gin.H{}) }
+	router.Group("/public", ContentSecurityPolicy(policy)).GET("/pages/1", ok)
+	router.GET("/pages/1", ok)
+
+	public := httptest.NewRecorder()
+	router.ServeHTTP(public, httptest.NewRequest(http.MethodGet, "/public/pages/1", nil))
+	if got := public.Header().Get("Content-Security-Policy"); got != policy {
+		t.Fatalf("expected public route policy %q, got %q", policy, got)
+	}
+
+	api := httptest.NewRecorder()
+	router.ServeHTTP(api, httptest.NewRequest(http.MethodGet, "/pages/1", nil))
+	if got := api.Header().Get("Content-Security-Policy"); got != "" {
+		t.Fatalf("expected no policy on API route, got %q", got)
+	}
+}
+
+func TestContentSecurityPolicyEmptyDisables(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/public/pages/1", ContentSecurityPolicy(""), func(ctx *gin.Context) { ctx.Status(http.StatusNoContent) })
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/public/pages/1", nil))
+	if _, set := recorder.Header()["Content-Security-Policy"]; set {
+		t.Fatalf("expected no header for empty policy, got %q", recorder.Header().Get("Content-Security-Policy"))
+	}
+}
@@ -0,0 +1,58 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrDisconnected is returned by a Pinger whose dependency is not connected.
+var ErrDisconnected = errors.New("disconnected")
+
+// Pinger checks that a dependency is reachable.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingerFunc adapts a function to a Pinger.
+type PingerFunc func(ctx context.Context) error
+
+func (f PingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Readiness pings each named dependency, allowing each timeout, and responds
+// 200 when all are reachable or 503 otherwise, with each dependency's status.
+// Unlike /healthz it fails while the app cannot serve requests.
+func Readiness(timeout time.Duration, checks map[string]Pinger) gin.HandlerFunc {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return func(ctx *gin.Context) {
+		statuses := make(map[string]string, len(names))
+		ready := true
+		for _, name := range names {
+			pingCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+			err := checks[name].Ping(pingCtx)
+			cancel()
+			if err != nil {
+				statuses[name] = "down"
+				ready = false
+				continue
+			}
+			statuses[name] = "ok"
+		}
+
+		if !ready {
+			ctx.JSON(503, gin.H{"status": "unavailable", "checks": statuses})
+			return
+		}
+		ctx.JSON(200, gin.H{"status": "ok", "checks": statuses})
+	}
+}
@@ -0,0 +1,73 @@
+package httputil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadinessReportsEachDependency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	healthy := PingerFunc(func(context.Context) error { return nil })
+	failing := PingerFunc(func(context.Context) error { return errors.New("connection refused") })
+	hanging := PingerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	tests := []struct {
+		name   string
+		checks map[string]Pinger
+		code   int
+		want   map[string]string
+	}{
+		{
+			name:   "all up",
+			checks: map[string]Pinger{"postgres": healthy, "nats": healthy},
+			code:   http.StatusOK,
+			want:   map[string]string{"postgres": "ok", "nats": "ok"},
+		},
+		{
+			name:   "postgres down",
+			checks: map[string]Pinger{"postgres": failing, "nats": healthy},
+			code:   http.StatusServiceUnavailable,
+			want:   map[string]string{"postgres": "down", "nats": "ok"},
+		},
+		{
+			name:   "ping times out",
+			checks: map[string]Pinger{"postgres": healthy, "nats": hanging},
+			code:   http.StatusServiceUnavailable,
+			want:   map[string]string{"postgres": "ok", "nats": "down"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/readyz", Readiness(20*time.Millisecond, tt.checks))
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if recorder.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, recorder.Code, recorder.Body.String())
+			}
+			var body struct {
+				Checks map[string]string `json:"checks"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			for name, status := range tt.want {
+				if body.Checks[name] != status {
+					t.Fatalf("expected %s %q, got %q", name, status, body.Checks[name])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package httputil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/reggieanim/jot/internal/platform/storage"
+)
+
+// MaxImageUploadBytes caps the size of an uploaded image.
+const MaxImageUploadBytes = 15 << 20
+
+// ImageUpload is an image read from a multipart "file" field.
+type ImageUpload struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// ReadImageUpload reads the "file" form field and checks it is a non-empty
+// image of at most MaxImageUploadBytes whose dimensions fit maxMegapixels.
+// SVGs are rejected unless sanitizeSVG is set, in which case they are passed
+// through storage.SanitizeSVG. On failure it writes the error response and
+// returns false.
+func ReadImageUpload(ctx *gin.Context, maxMegapixels float64, sanitizeSVG bool) (ImageUpload, bool) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return ImageUpload{}, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid file"})
+		return ImageUpload{}, false
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, MaxImageUploadBytes+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "could not read file"})
+		return ImageUpload{}, false
+	}
+	if len(content) > MaxImageUploadBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "image too large (max 15MB)"})
+		return ImageUpload{}, false
+	}
+	if len(content) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "empty file"})
+		return ImageUpload{}, false
+	}
+
+	contentType := strings.TrimSpace(fileHeader.Header.Get("Content-Type"))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "only image uploads are allowed"})
+		return ImageUpload{}, false
+	}
+	if isSVG(fileHeader.Filename, contentType, content) {
+		if !sanitizeSVG {
+			ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "svg uploads are not allowed"})
+			return ImageUpload{}, false
+		}
+		sanitized, err := storage.SanitizeSVG(content)
+		if err != nil {
+			ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "unreadable svg"})
+			return ImageUpload{}, false
+		}
+		return ImageUpload{FileName: fileHeader.Filename, ContentType: "image/svg+xml", Content: sanitized}, true
+	}
+	if err := checkImageDimensions(content, maxMegapixels); err != nil {
+		if errors.Is(err, errImageTooManyPixels) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("image dimensions too large (max %g megapixels)", maxMegapixels)})
+			return ImageUpload{}, false
+		}
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "unreadable image"})
+		return ImageUpload{}, false
+	}
+
+	return ImageUpload{FileName: fileHeader.Filename, ContentType: contentType, Content: content}, true
+}
+
+// isSVG reports whether an upload is, claims to be or is named like an SVG,
+// so a document mislabelled as another image type can't slip past the check.
+func isSVG(fileName, contentType string, content []byte) bool {
+	if strings.HasPrefix(strings.ToLower(contentType), "image/svg") {
+		return true
+	}
+	ext := strings.ToLower(path.Ext(fileName))
+	if ext == ".svg" || ext == ".svgz" {
+		return true
+	}
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), " \t\r\n")
+	return bytes.HasPrefix(trimmed, []byte("<")) && bytes.Contains(bytes.ToLower(trimmed), []byte("<svg"))
+}
+
+var (
+	errImageTooManyPixels = errors.New("image dimensions exceed limit")
+	errImageUnreadable    = errors.New("image header could not be decoded")
+)
+
+// checkImageDimensions reads only the image header and rejects images whose
+// decoded bitmap would exceed maxMegapixels, so a tiny compressed file can't
+// expand into a huge allocation later. Formats the standard library can't
+// decode (webp, svg, ...) are let through unchanged.
+func checkImageDimensions(content []byte, maxMegapixels float64) error {
+	if maxMegapixels <= 0 {
+		return nil
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil
+		}
+		return errImageUnreadable
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return errImageUnreadable
+	}
+	pixels := float64(cfg.Width) * float64(cfg.Height)
+	if pixels > maxMegapixels*1_000_000 {
+		return errImageTooManyPixels
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package httputil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pngHeader returns a PNG signature plus a valid IHDR chunk declaring the
+// given dimensions and no pixel data: a few dozen bytes that claim to be a
+// width×height bitmap.
+func pngHeader(width, height uint32) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'})
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:4], width)
+	binary.BigEndian.PutUint32(ihdr[4:8], height)
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 2 // truecolor RGB
+
+	chunk := append([]byte("IHDR"), ihdr...)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	buf.Write(chunk)
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestCheckImageDimensionsRejectsDecompressionBomb(t *testing.T) {
+	bomb := pngHeader(50_000, 50_000)
+	if len(bomb) > 64 {
+		t.Fatalf("expected crafted header to be tiny, got %d bytes", len(bomb))
+	}
+	if err := checkImageDimensions(bomb, 50); !errors.Is(err, errImageTooManyPixels) {
+		t.Fatalf("expected too many pixels error, got %v", err)
+	}
+}
+
+func TestCheckImageDimensionsAllowsNormalImage(t *testing.T) {
+	var buf bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	img.Set(0, 0, color.White)
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	if err := checkImageDimensions(buf.Bytes(), 50); err != nil {
+		t.Fatalf("expected small image to pass, got %v", err)
+	}
+	if err := checkImageDimensions(pngHeader(50_000, 50_000), 0); err != nil {
+		t.Fatalf("expected zero limit to disable the check, got %v", err)
+	}
+}
+
+func TestCheckImageDimensionsSkipsUnknownFormats(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="10" height="10"></svg>`)
+	if err := checkImageDimensions(svg, 50); err != nil {
+		t.Fatalf("expected unknown format to pass through, got %v", err)
+	}
+	truncated := pngHeader(100, 100)[:20]
+	if err := checkImageDimensions(truncated, 50); !errors.Is(err, errImageUnreadable) {
+		t.Fatalf("expected truncated png header to be unreadable, got %v", err)
+	}
+}
+
+func TestReadImageUploadSVGPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	malicious := `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script><circle r="4"/></svg>`
+	read := func(contentType string, sanitizeSVG bool) (*httptest.ResponseRecorder, ImageUpload, bool) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="logo.png"`)
+		header.Set("Content-Type", contentType)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			t.Fatalf("create part: %v", err)
+		}
+		part.Write([]byte(malicious))
+		writer.Close()
+
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/upload", &body)
+		ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+		upload, ok := ReadImageUpload(ctx, 50, sanitizeSVG)
+		return recorder, upload, ok
+	}
+
+	for _, contentType := range []string{"image/svg+xml", "image/png"} {
+		if recorder, _, ok := read(contentType, false); ok || recorder.Code != http.StatusUnsupportedMediaType {
+			t.Fatalf("expected %s svg to be rejected by default, got %d", contentType, recorder.Code)
+		}
+	}
+
+	_, upload, ok := read("image/png", true)
+	if !ok {
+		t.Fatal("expected svg to be accepted when sanitizing")
+	}
+	if upload.ContentType != "image/svg+xml" || strings.Contains(string(upload.Content), "script") || !strings.Contains(string(upload.Content), "<circle") {
+		t.Fatalf("expected sanitized svg, got %s %s", upload.ContentType, upload.Content)
+	}
+}
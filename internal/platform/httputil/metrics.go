@@ -0,0 +1,38 @@
+package httputil
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RequestMetrics counts requests and records their latency per method and
+// route. Requests matching no route share the route label "unmatched" so
+// probing random paths cannot grow the series without bound.
+func RequestMetrics(registerer prometheus.Registerer) gin.HandlerFunc {
+	factory := promauto.With(registerer)
+	requests := factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "jot_http_requests_total",
+		Help: "HTTP requests handled.",
+	}, []string{"method", "route", "status"})
+	durations := factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "jot_http_request_duration_seconds",
+		Help:    "HTTP request latency.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		requests.WithLabelValues(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status())).Inc()
+		durations.WithLabelValues(ctx.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
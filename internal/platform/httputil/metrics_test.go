@@ -0,0 +1,53 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestRequestMetricsCountsRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+	router := gin.New()
+	router.Use(RequestMetrics(registry))
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+	router.GET("/pages/:pageID", func(ctx *gin.Context) {
+		ctx.JSON(200, gin.H{"id": ctx.Param("pageID")})
+	})
+
+	scrape := func() string {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected 200 from /metrics, got %d", recorder.Code)
+		}
+		return recorder.Body.String()
+	}
+	const series = `jot_http_requests_total{method="GET",route="/pages/:pageID",status="200"}`
+
+	if strings.Contains(scrape(), series) {
+		t.Fatalf("expected no page requests counted yet")
+	}
+	for _, path := range []string{"/pages/a", "/pages/b"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	body := scrape()
+	if !strings.Contains(body, series+" 2\n") {
+		t.Fatalf("expected both page requests counted under their route, got:\n%s", body)
+	}
+	if !strings.Contains(body, `jot_http_request_duration_seconds_count{method="GET",route="/pages/:pageID"} 2`) {
+		t.Fatalf("expected page request latencies recorded, got:\n%s", body)
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+	if body := scrape(); !strings.Contains(body, `route="unmatched",status="404"} 1`) {
+		t.Fatalf("expected unmatched requests grouped, got:\n%s", body)
+	}
+}
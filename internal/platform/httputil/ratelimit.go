@@ -0,0 +1,118 @@
+package httputil
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limiter decides whether the caller identified by key may make another
+// request. When it may not, retryAfter is how long until it could.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration)
+}
+
+// TokenBucket is an in-memory Limiter giving each key a bucket of burst tokens
+// that refills at rate tokens per second. It only limits within one process.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a limiter allowing burst requests at once per key and
+// rate requests per second after that.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (limiter *TokenBucket) Allow(_ context.Context, key string) (bool, time.Duration) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.now()
+	limiter.sweep(now)
+
+	b, ok := limiter.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limiter.burst, last: now}
+		limiter.buckets[key] = b
+	}
+	b.tokens = math.Min(limiter.burst, b.tokens+now.Sub(b.last).Seconds()*limiter.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if limiter.rate <= 0 {
+		return false, time.Hour
+	}
+	wait := (1 - b.tokens) / limiter.rate
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely, which behave exactly
+// like a fresh bucket, so idle keys do not accumulate.
+func (limiter *TokenBucket) sweep(now time.Time) {
+	if limiter.rate <= 0 {
+		return
+	}
+	refill := time.Duration(limiter.burst / limiter.rate * float64(time.Second))
+	if now.Sub(limiter.lastSweep) < refill {
+		return
+	}
+	limiter.lastSweep = now
+	for key, b := range limiter.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(limiter.buckets, key)
+		}
+	}
+}
+
+// RateLimit rejects a request with 429 and a Retry-After header when the
+// limiter denies any of the keys returned for it. Empty keys are ignored.
+func RateLimit(limiter Limiter, keys func(ctx *gin.Context) []string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var retryAfter time.Duration
+		for _, key := range keys(ctx) {
+			if key == "" {
+				continue
+			}
+			if allowed, wait := limiter.Allow(ctx.Request.Context(), key); !allowed && wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+		if retryAfter > 0 {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			ctx.Header("Retry-After", strconv.Itoa(seconds))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// ClientIPKey keys rate limits by the client's IP address.
+func ClientIPKey(ctx *gin.Context) []string {
+	return []string{"ip:" + ctx.ClientIP()}
+}
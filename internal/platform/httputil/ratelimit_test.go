@@ -0,0 +1,85 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (clock *fakeClock) Now() time.Time { return clock.now }
+
+func newTestBucket(rate float64, burst int) (*TokenBucket, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 2, 12, 0, 0, 0, 0, time.UTC)}
+	limiter := NewTokenBucket(rate, burst)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func TestTokenBucketAllowsBurstThenRefills(t *testing.T) {
+	ctx := context.Background()
+	limiter, clock := newTestBucket(0.5, 3)
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.Allow(ctx, "ip:1.2.3.4"); !allowed {
+			t.Fatalf("request %d: expected burst to be allowed", i+1)
+		}
+	}
+	allowed, retryAfter := limiter.Allow(ctx, "ip:1.2.3.4")
+	if allowed {
+		t.Fatal("expected request beyond burst to be denied")
+	}
+	if retryAfter != 2*time.Second {
+		t.Fatalf("expected retry after 2s, got %s", retryAfter)
+	}
+	if allowed, _ := limiter.Allow(ctx, "ip:5.6.7.8"); !allowed {
+		t.Fatal("expected other keys to have their own bucket")
+	}
+
+	clock.now = clock.now.Add(2 * time.Second)
+	if allowed, _ := limiter.Allow(ctx, "ip:1.2.3.4"); !allowed {
+		t.Fatal("expected one token after refill")
+	}
+	if allowed, _ := limiter.Allow(ctx, "ip:1.2.3.4"); allowed {
+		t.Fatal("expected only one token to have refilled")
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.Allow(ctx, "ip:1.2.3.4"); !allowed {
+			t.Fatalf("request %d: expected full burst after idle period", i+1)
+		}
+	}
+	if allowed, _ := limiter.Allow(ctx, "ip:1.2.3.4"); allowed {
+		t.Fatal("expected refill to be capped at burst")
+	}
+}
+
+func TestRateLimitRespondsWithRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter, _ := newTestBucket(0.1, 1)
+	router := gin.New()
+	router.POST("/login", RateLimit(limiter, ClientIPKey), func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodPost, "/login", nil))
+	if first.Code != http.StatusNoContent {
+		t.Fatalf("expected first request through, got %d", first.Code)
+	}
+
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, httptest.NewRequest(http.MethodPost, "/login", nil))
+	if second.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", second.Code)
+	}
+	if got := second.Header().Get("Retry-After"); got != "10" {
+		t.Fatalf("expected Retry-After 10, got %q", got)
+	}
+}
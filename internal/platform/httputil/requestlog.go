@@ -0,0 +1,85 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/reggieanim/jot/internal/platform/auth"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+type requestIDContextKey struct{}
+
+// RequestID returns the ID RequestLogging assigned to the request behind ctx,
+// or "" outside such a request.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// RequestLogging tags each request with an ID, taken from the caller's
+// X-Request-ID when it is reasonable and generated otherwise, echoes it on the
+// response and logs one line per request once it completes. The route pattern
+// is logged rather than the raw path, so share tokens and other secrets in
+// URLs stay out of the logs; only unmatched requests log their raw path.
+// Health probes are not logged.
+func RequestLogging(logger *zap.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		ctx.Header(RequestIDHeader, id)
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), requestIDContextKey{}, id))
+
+		start := time.Now()
+		ctx.Next()
+
+		path := ctx.FullPath()
+		if path == "/healthz" || path == "/readyz" {
+			return
+		}
+		status := ctx.Writer.Status()
+		if path == "" && status == http.StatusNotFound {
+			path = ctx.Request.URL.Path
+		}
+		fields := []zap.Field{
+			zap.String("request_id", id),
+			zap.String("method", ctx.Request.Method),
+			zap.String("path", path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", ctx.ClientIP()),
+		}
+		if uid, ok := auth.GetUserID(ctx); ok {
+			fields = append(fields, zap.String("user_id", string(uid)))
+		}
+		if status >= http.StatusInternalServerError {
+			logger.Error("request", fields...)
+			return
+		}
+		logger.Info("request", fields...)
+	}
+}
+
+// validRequestID accepts caller-supplied IDs of printable ASCII up to a
+// modest length, so they cannot forge log lines or bloat them.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
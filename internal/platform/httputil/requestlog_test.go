@@ -0,0 +1,74 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	userdomain "github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/platform/auth"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestLogging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zap.InfoLevel)
+	router := gin.New()
+	router.Use(RequestLogging(zap.New(core)))
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(auth.UserIDKey, userdomain.UserID("user-1"))
+	})
+	var seen string
+	router.GET("/pages/:pageID", func(ctx *gin.Context) {
+		seen = RequestID(ctx.Request.Context())
+		ctx.Status(http.StatusNoContent)
+	})
+	router.GET("/healthz", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/pages/page-1", nil))
+	id := recorder.Header().Get(RequestIDHeader)
+	if id == "" || id != seen {
+		t.Fatalf("expected generated request ID on the response and in the context, got %q and %q", id, seen)
+	}
+
+	entries := logs.FilterMessage("request").AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("expected one request log line, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["request_id"] != id || fields["path"] != "/pages/:pageID" || fields["status"] != int64(http.StatusNoContent) || fields["user_id"] != "user-1" {
+		t.Fatalf("unexpected log fields %+v", fields)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/pages/page-1", nil)
+	request.Header.Set(RequestIDHeader, "upstream-123")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if got := recorder.Header().Get(RequestIDHeader); got != "upstream-123" {
+		t.Fatalf("expected caller's request ID propagated, got %q", got)
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/pages/page-1", nil)
+	request.Header.Set(RequestIDHeader, "forged\nline")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if got := recorder.Header().Get(RequestIDHeader); got == "forged\nline" || got == "" {
+		t.Fatalf("expected an invalid request ID replaced, got %q", got)
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if got := logs.FilterMessage("request").Len(); got != 3 {
+		t.Fatalf("expected /healthz not to be logged, got %d request lines", got)
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing/page", nil))
+	entries = logs.FilterMessage("request").AllUntimed()
+	if got := entries[len(entries)-1].ContextMap()["path"]; got != "/missing/page" {
+		t.Fatalf("expected unmatched requests to log their raw path, got %v", got)
+	}
+}
@@ -20,8 +20,8 @@ func NewRouter(corsOrigins string) *gin.Engine {
 	router := gin.New()
 	router.Use(cors.New(cors.Config{
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Set-Cookie"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Page-Password", "X-Page-Unlock", RequestIDHeader},
+		ExposeHeaders:    []string{"Set-Cookie", "X-Page-Unlock", RequestIDHeader},
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
 			return allowed[strings.ToLower(strings.TrimSpace(origin))]
@@ -29,6 +29,7 @@ func NewRouter(corsOrigins string) *gin.Engine {
 		MaxAge: 12 * time.Hour,
 	}))
 	router.Use(gin.Recovery())
+	router.Use(Tracing())
 	router.GET("/healthz", func(ctx *gin.Context) {
 		ctx.JSON(200, gin.H{"status": "ok"})
 	})
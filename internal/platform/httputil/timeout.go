@@ -0,0 +1,69 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout bounds every request in a route group to d by giving it a context
+// deadline. Handlers must pass ctx.Request.Context() down so slow work is
+// cancelled; once the deadline passes, anything the handler still writes is
+// discarded and the client gets 503 instead. Responses already started before
+// the deadline are left alone. Zero disables the timeout.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if d <= 0 {
+			ctx.Next()
+			return
+		}
+
+		deadlineCtx, cancel := context.WithTimeout(ctx.Request.Context(), d)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(deadlineCtx)
+
+		writer := &timeoutWriter{ResponseWriter: ctx.Writer, ctx: deadlineCtx}
+		ctx.Writer = writer
+		ctx.Next()
+		ctx.Writer = writer.ResponseWriter
+
+		if errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) && !ctx.Writer.Written() {
+			ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "request timed out"})
+		}
+	}
+}
+
+// timeoutWriter drops body writes once its context's deadline has passed so
+// Timeout can replace a late response with 503.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+}
+
+func (writer *timeoutWriter) expired() bool {
+	return !writer.ResponseWriter.Written() && errors.Is(writer.ctx.Err(), context.DeadlineExceeded)
+}
+
+func (writer *timeoutWriter) Write(data []byte) (int, error) {
+	if writer.expired() {
+		return len(data), nil
+	}
+	return writer.ResponseWriter.Write(data)
+}
+
+func (writer *timeoutWriter) WriteString(s string) (int, error) {
+	if writer.expired() {
+		return len(s), nil
+	}
+	return writer.ResponseWriter.WriteString(s)
+}
+
+func (writer *timeoutWriter) WriteHeaderNow() {
+	if writer.expired() {
+		return
+	}
+	writer.ResponseWriter.WriteHeaderNow()
+}
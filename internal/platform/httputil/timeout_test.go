@@ -0,0 +1,53 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTimeoutRouter(d time.Duration, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("", Timeout(d))
+	group.GET("/work", handler)
+	return router
+}
+
+func TestTimeoutReturns503ForSlowHandler(t *testing.T) {
+	router := newTimeoutRouter(20*time.Millisecond, func(ctx *gin.Context) {
+		select {
+		case <-ctx.Request.Context().Done():
+			ctx.JSON(500, gin.H{"error": ctx.Request.Context().Err().Error()})
+		case <-time.After(time.Second):
+			ctx.JSON(200, gin.H{"status": "done"})
+		}
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/work", nil))
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", recorder.Code)
+	}
+	if !strings.Contains(recorder.Body.String(), "request timed out") {
+		t.Fatalf("expected timeout body, got %q", recorder.Body.String())
+	}
+}
+
+func TestTimeoutPassesFastHandler(t *testing.T) {
+	router := newTimeoutRouter(time.Second, func(ctx *gin.Context) {
+		ctx.JSON(200, gin.H{"status": "done"})
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/work", nil))
+
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "done") {
+		t.Fatalf("expected 200 with handler body, got %d %q", recorder.Code, recorder.Body.String())
+	}
+}
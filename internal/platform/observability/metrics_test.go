@@ -0,0 +1,36 @@
+package observability
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetricsServerServesRegistry(t *testing.T) {
+	registry := NewMetricsRegistry()
+	events := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "jot_events_total", Help: "Events seen."}, []string{"kind"})
+	registry.MustRegister(events)
+	events.WithLabelValues("created").Inc()
+
+	server := NewMetricsServer("127.0.0.1:0", registry)
+	recorder := httptest.NewRecorder()
+	server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	body := recorder.Body.String()
+	for _, want := range []string{`jot_events_total{kind="created"} 1`, "go_goroutines "} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in:\n%s", want, body)
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/pages", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected only /metrics to be served, got %d", recorder.Code)
+	}
+}
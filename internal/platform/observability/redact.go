@@ -0,0 +1,88 @@
+package observability
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeys are field keys whose values are always masked.
+var sensitiveKeys = map[string]bool{
+	"authorization":      true,
+	"password":           true,
+	"token":              true,
+	"share":              true,
+	"share_token":        true,
+	"refresh_token":      true,
+	"reset_token":        true,
+	"verification_token": true,
+	"email":              true,
+}
+
+var (
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	bearerPattern     = regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`)
+	queryTokenPattern = regexp.MustCompile(`(?i)\b(share|share_token|token|refresh_token|code)=[^&\s"']+`)
+)
+
+// WithRedaction returns a logger that masks sensitive values before they are
+// written: fields with a sensitive key, plus emails, bearer credentials and
+// token query parameters found in messages, string fields and errors.
+func WithRedaction(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return redactingCore{Core: core}
+	}))
+}
+
+type redactingCore struct {
+	zapcore.Core
+}
+
+func (core redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactingCore{Core: core.Core.With(redactFields(fields))}
+}
+
+func (core redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(entry.Level) {
+		return checked.AddCore(entry, core)
+	}
+	return checked
+}
+
+func (core redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = RedactText(entry.Message)
+	return core.Core.Write(entry, redactFields(fields))
+}
+
+// RedactText masks emails, bearer credentials and token query parameters in s.
+func RedactText(s string) string {
+	s = emailPattern.ReplaceAllString(s, redactedValue)
+	s = bearerPattern.ReplaceAllString(s, "$1 "+redactedValue)
+	return queryTokenPattern.ReplaceAllString(s, "$1="+redactedValue)
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		switch {
+		case sensitiveKeys[strings.ToLower(field.Key)]:
+			redacted[i] = zap.String(field.Key, redactedValue)
+		case field.Type == zapcore.StringType:
+			redacted[i] = zap.String(field.Key, RedactText(field.String))
+		case field.Type == zapcore.ErrorType:
+			if err, ok := field.Interface.(error); ok {
+				redacted[i] = zap.NamedError(field.Key, errors.New(RedactText(err.Error())))
+			} else {
+				redacted[i] = field
+			}
+		default:
+			redacted[i] = field
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,55 @@
+package observability
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const shareToken = "7f9c2d4e-1b3a-4c5d-8e6f-0a1b2c3d4e5f"
+
+func TestWithRedactionMasksShareToken(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := WithRedaction(zap.New(core))
+
+	logger.Info("opened /v1/pages/page-1?share="+shareToken,
+		zap.String("share_token", shareToken),
+		zap.String("email", "alice@example.com"),
+		zap.Error(errors.New("resolve Bearer abc.def.ghi for bob@example.com")),
+		zap.String("page_id", "page-1"),
+	)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if strings.Contains(entry.Message, shareToken) {
+		t.Fatalf("expected share token masked in message, got %q", entry.Message)
+	}
+	fields := entry.ContextMap()
+	for key, value := range fields {
+		text, _ := value.(string)
+		if strings.Contains(text, shareToken) || strings.Contains(text, "@example.com") || strings.Contains(text, "abc.def.ghi") {
+			t.Fatalf("expected %s to be masked, got %q", key, text)
+		}
+	}
+	if fields["page_id"] != "page-1" {
+		t.Fatalf("expected unrelated fields untouched, got %v", fields["page_id"])
+	}
+}
+
+func TestWithRedactionMasksFieldsAddedWithWith(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := WithRedaction(zap.New(core)).With(zap.String("share", shareToken))
+
+	logger.Info("request")
+
+	if got := logs.All()[0].ContextMap()["share"]; got != redactedValue {
+		t.Fatalf("expected share field masked, got %v", got)
+	}
+}
@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/reggieanim/jot/internal/modules/users/domain"
+	"github.com/reggieanim/jot/internal/platform/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type userIDKey struct{}
+
+// UnaryAuthInterceptor authenticates calls carrying a JWT in the
+// "authorization" (Bearer) or "jot_token" metadata. Calls without a token
+// proceed anonymously; calls with an invalid token are rejected.
+func UnaryAuthInterceptor(issuer *auth.JWTIssuer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, issuer)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor is the streaming counterpart of UnaryAuthInterceptor.
+func StreamAuthInterceptor(issuer *auth.JWTIssuer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), issuer)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// UserIDFromContext returns the user authenticated by the interceptors.
+func UserIDFromContext(ctx context.Context) (domain.UserID, bool) {
+	uid, ok := ctx.Value(userIDKey{}).(domain.UserID)
+	return uid, ok && uid != ""
+}
+
+// ContextWithUserID returns a copy of ctx authenticated as uid.
+func ContextWithUserID(ctx context.Context, uid domain.UserID) context.Context {
+	return context.WithValue(ctx, userIDKey{}, uid)
+}
+
+func authenticate(ctx context.Context, issuer *auth.JWTIssuer) (context.Context, error) {
+	tokenStr := tokenFromMetadata(ctx)
+	if tokenStr == "" {
+		return ctx, nil
+	}
+	claims, err := issuer.Parse(tokenStr)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	if claims.ImpersonatedBy != "" {
+		// Impersonated requests are only audited over HTTP.
+		return nil, status.Error(codes.PermissionDenied, "impersonation tokens are not accepted")
+	}
+	return ContextWithUserID(ctx, domain.UserID(claims.UserID)), nil
+}
+
+func tokenFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, header := range md.Get("authorization") {
+		if strings.HasPrefix(header, "Bearer ") {
+			return strings.TrimPrefix(header, "Bearer ")
+		}
+	}
+	if values := md.Get("jot_token"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authenticatedStream) Context() context.Context {
+	return stream.ctx
+}
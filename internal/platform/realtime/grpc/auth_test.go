@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reggieanim/jot/internal/platform/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func callUnary(t *testing.T, issuer *auth.JWTIssuer, md metadata.MD) (string, error) {
+	t.Helper()
+	ctx := context.Background()
+	if md != nil {
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+	interceptor := UnaryAuthInterceptor(issuer)
+	resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test"}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		uid, _ := UserIDFromContext(ctx)
+		return string(uid), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.(string), nil
+}
+
+func TestUnaryAuthInterceptor(t *testing.T) {
+	issuer := auth.NewJWTIssuer("test-secret")
+	token, err := issuer.Issue("user-1", "user@example.com")
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+
+	uid, err := callUnary(t, issuer, metadata.Pairs("authorization", "Bearer "+token))
+	if err != nil || uid != "user-1" {
+		t.Fatalf("expected bearer token to authenticate user-1, got %q, %v", uid, err)
+	}
+
+	uid, err = callUnary(t, issuer, metadata.Pairs("jot_token", token))
+	if err != nil || uid != "user-1" {
+		t.Fatalf("expected jot_token metadata to authenticate user-1, got %q, %v", uid, err)
+	}
+
+	uid, err = callUnary(t, issuer, nil)
+	if err != nil || uid != "" {
+		t.Fatalf("expected anonymous call to pass through without a user, got %q, %v", uid, err)
+	}
+
+	forged, err := auth.NewJWTIssuer("other-secret").Issue("user-1", "user@example.com")
+	if err != nil {
+		t.Fatalf("issue forged token: %v", err)
+	}
+	if _, err := callUnary(t, issuer, metadata.Pairs("jot_token", forged)); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for invalid token, got %v", err)
+	}
+}
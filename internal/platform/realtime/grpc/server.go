@@ -3,13 +3,17 @@ package grpc
 import (
 	"net"
 
+	"github.com/reggieanim/jot/internal/platform/auth"
 	"google.golang.org/grpc"
 	grpcHealth "google.golang.org/grpc/health"
 	healthv1 "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-func NewServer() *grpc.Server {
-	server := grpc.NewServer()
+func NewServer(issuer *auth.JWTIssuer) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryAuthInterceptor(issuer)),
+		grpc.StreamInterceptor(StreamAuthInterceptor(issuer)),
+	)
 	healthServer := grpcHealth.NewServer()
 	healthv1.RegisterHealthServer(server, healthServer)
 	return server
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"mime"
 	"path"
 	"strings"
@@ -16,6 +17,7 @@ import (
 type MediaStore interface {
 	UploadImage(ctx context.Context, fileName string, contentType string, content []byte) (url string, key string, err error)
 	UploadAudio(ctx context.Context, fileName string, contentType string, content []byte) (url string, key string, err error)
+	UploadAvatar(ctx context.Context, fileName string, contentType string, content []byte) (url string, key string, err error)
 	DeleteObject(ctx context.Context, objectKey string) error
 	ObjectKeyFromURL(rawURL string) string
 }
@@ -72,33 +74,24 @@ func NewS3MediaStore(endpoint, accessKey, secretKey, bucket string, useSSL bool,
 }
 
 func (store *S3MediaStore) UploadImage(ctx context.Context, fileName string, contentType string, content []byte) (string, string, error) {
-	if len(content) == 0 {
-		return "", "", fmt.Errorf("empty file")
-	}
+	return store.upload(ctx, "images", fileName, contentType, content)
+}
 
-	ext := strings.ToLower(path.Ext(fileName))
-	if ext == "" {
-		extensions, err := mime.ExtensionsByType(contentType)
-		if err == nil && len(extensions) > 0 {
-			ext = strings.ToLower(extensions[0])
-		}
-	}
-	if ext == "" {
-		ext = ".bin"
-	}
+func (store *S3MediaStore) UploadAudio(ctx context.Context, fileName string, contentType string, content []byte) (string, string, error) {
+	return store.upload(ctx, "audio", fileName, contentType, content)
+}
 
-	objectKey := fmt.Sprintf("images/%s%s", uuid.NewString(), ext)
-	_, err := store.client.PutObject(ctx, store.bucket, objectKey, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{
-		ContentType: contentType,
-	})
-	if err != nil {
-		return "", "", fmt.Errorf("upload object: %w", err)
-	}
+// AvatarKeyPrefix starts the key of every uploaded profile picture.
+const AvatarKeyPrefix = "avatars/"
 
-	return store.publicBaseURL + "/" + objectKey, objectKey, nil
+// UploadAvatar stores a profile picture under AvatarKeyPrefix.
+func (store *S3MediaStore) UploadAvatar(ctx context.Context, fileName string, contentType string, content []byte) (string, string, error) {
+	return store.upload(ctx, strings.TrimSuffix(AvatarKeyPrefix, "/"), fileName, contentType, content)
 }
 
-func (store *S3MediaStore) UploadAudio(ctx context.Context, fileName string, contentType string, content []byte) (string, string, error) {
+// upload stores content under prefix with a random name keeping the file's
+// extension, and returns its public URL and object key.
+func (store *S3MediaStore) upload(ctx context.Context, prefix, fileName, contentType string, content []byte) (string, string, error) {
 	if len(content) == 0 {
 		return "", "", fmt.Errorf("empty file")
 	}
@@ -114,7 +107,7 @@ func (store *S3MediaStore) UploadAudio(ctx context.Context, fileName string, con
 		ext = ".bin"
 	}
 
-	objectKey := fmt.Sprintf("audio/%s%s", uuid.NewString(), ext)
+	objectKey := fmt.Sprintf("%s/%s%s", prefix, uuid.NewString(), ext)
 	_, err := store.client.PutObject(ctx, store.bucket, objectKey, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{
 		ContentType: contentType,
 	})
@@ -145,3 +138,40 @@ func (store *S3MediaStore) ObjectKeyFromURL(rawURL string) string {
 	}
 	return ""
 }
+
+// GetObject returns the content stored under objectKey.
+func (store *S3MediaStore) GetObject(ctx context.Context, objectKey string) ([]byte, error) {
+	object, err := store.client.GetObject(ctx, store.bucket, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get object %s: %w", objectKey, err)
+	}
+	defer object.Close()
+	content, err := io.ReadAll(object)
+	if err != nil {
+		return nil, fmt.Errorf("read object %s: %w", objectKey, err)
+	}
+	return content, nil
+}
+
+// ObjectExists reports whether an object is stored under objectKey.
+func (store *S3MediaStore) ObjectExists(ctx context.Context, objectKey string) (bool, error) {
+	_, err := store.client.StatObject(ctx, store.bucket, objectKey, minio.StatObjectOptions{})
+	if err == nil {
+		return true, nil
+	}
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return false, nil
+	}
+	return false, fmt.Errorf("stat object %s: %w", objectKey, err)
+}
+
+// PutObject stores content under objectKey, replacing any existing object.
+func (store *S3MediaStore) PutObject(ctx context.Context, objectKey, contentType string, content []byte) error {
+	_, err := store.client.PutObject(ctx, store.bucket, objectKey, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return fmt.Errorf("put object %s: %w", objectKey, err)
+	}
+	return nil
+}
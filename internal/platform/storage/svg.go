@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidSVG is returned when an SVG cannot be parsed or has no <svg> root.
+var ErrInvalidSVG = errors.New("invalid svg")
+
+// svgBlockedElements are dropped along with everything inside them.
+var svgBlockedElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"handler":       true,
+	"listener":      true,
+}
+
+// SanitizeSVG re-serializes an SVG document without scripts, foreignObject
+// and other embedding elements, event handler attributes, script URLs in
+// links, animations that rewrite links or handlers, doctypes, comments and
+// processing instructions.
+func SanitizeSVG(content []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = true
+
+	var out bytes.Buffer
+	depth, skipDepth := 0, 0
+	sawRoot := false
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSVG, err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+			if skipDepth > 0 {
+				continue
+			}
+			if depth == 1 {
+				if sawRoot || !strings.EqualFold(token.Name.Local, "svg") {
+					return nil, fmt.Errorf("%w: root element must be a single <svg>", ErrInvalidSVG)
+				}
+				sawRoot = true
+			}
+			if svgBlockedElements[strings.ToLower(token.Name.Local)] || rewritesUnsafeAttr(token) {
+				skipDepth = depth
+				continue
+			}
+			out.WriteByte('<')
+			out.WriteString(qualifiedName(token.Name))
+			for _, attr := range token.Attr {
+				if unsafeSVGAttr(attr) {
+					continue
+				}
+				out.WriteByte(' ')
+				out.WriteString(qualifiedName(attr.Name))
+				out.WriteString(`="`)
+				xml.EscapeText(&out, []byte(attr.Value))
+				out.WriteByte('"')
+			}
+			out.WriteByte('>')
+		case xml.EndElement:
+			if skipDepth == 0 {
+				out.WriteString("</" + qualifiedName(token.Name) + ">")
+			} else if skipDepth == depth {
+				skipDepth = 0
+			}
+			depth--
+		case xml.CharData:
+			if skipDepth == 0 && depth > 0 {
+				xml.EscapeText(&out, token)
+			}
+		}
+	}
+	if !sawRoot || depth != 0 {
+		return nil, fmt.Errorf("%w: missing <svg> root", ErrInvalidSVG)
+	}
+	return out.Bytes(), nil
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// unsafeSVGAttr reports event handlers and links that would run script.
+func unsafeSVGAttr(attr xml.Attr) bool {
+	local := strings.ToLower(attr.Name.Local)
+	if strings.HasPrefix(local, "on") {
+		return true
+	}
+	if local == "href" || local == "src" || local == "action" || local == "formaction" {
+		return unsafeSVGURL(attr.Value)
+	}
+	return false
+}
+
+func unsafeSVGURL(value string) bool {
+	normalized := strings.ToLower(strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, value))
+	if strings.HasPrefix(normalized, "data:") {
+		return !strings.HasPrefix(normalized, "data:image/") || strings.HasPrefix(normalized, "data:image/svg")
+	}
+	return strings.HasPrefix(normalized, "javascript:") || strings.HasPrefix(normalized, "vbscript:")
+}
+
+// rewritesUnsafeAttr reports animation elements such as <set> whose target is
+// a link or an event handler, since they can inject script after load.
+func rewritesUnsafeAttr(element xml.StartElement) bool {
+	switch strings.ToLower(element.Name.Local) {
+	case "set", "animate", "animatemotion", "animatetransform":
+	default:
+		return false
+	}
+	for _, attr := range element.Attr {
+		if strings.EqualFold(attr.Name.Local, "attributeName") {
+			target := strings.ToLower(strings.TrimSpace(attr.Value))
+			if i := strings.IndexByte(target, ':'); i >= 0 {
+				target = target[i+1:]
+			}
+			return target == "href" || strings.HasPrefix(target, "on")
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeSVGStripsScripts(t *testing.T) {
+	malicious := []byte(`<?xml version="1.0"?>
+<!DOCTYPE svg>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" onload="alert(1)" width="10">
+  <script type="text/javascript">alert(document.cookie)</script>
+  <!-- <script>alert(2)</script> -->
+  <foreignObject><body xmlns="http://www.w3.org/1999/xhtml"><iframe src="https://evil.example"></iframe></body></foreignObject>
+  <a xlink:href=" java&#x09;script:alert(3)"><rect onClick="alert(4)" width="5" height="5" fill="red"/></a>
+  <a href="https://example.com"><set attributeName="href" to="javascript:alert(5)"/><text x="1">safe &amp; sound</text></a>
+  <image href="data:text/html;base64,PHNjcmlwdD4="/>
+  <animate attributeName="opacity" from="0" to="1"/>
+</svg>`)
+
+	sanitized, err := SanitizeSVG(malicious)
+	if err != nil {
+		t.Fatalf("sanitize: %v", err)
+	}
+	lower := strings.ToLower(string(sanitized))
+	for _, banned := range []string{"<script", "alert", "onload", "onclick", "foreignobject", "iframe", "javascript", "data:text", "<!", "<?"} {
+		if strings.Contains(lower, banned) {
+			t.Fatalf("expected %q to be stripped, got %s", banned, sanitized)
+		}
+	}
+	for _, kept := range []string{`<rect width="5" height="5" fill="red">`, `href="https://example.com"`, "safe &amp; sound", `<animate attributeName="opacity"`} {
+		if !strings.Contains(string(sanitized), kept) {
+			t.Fatalf("expected %q to survive, got %s", kept, sanitized)
+		}
+	}
+	if again, err := SanitizeSVG(sanitized); err != nil || !bytes.Equal(again, sanitized) {
+		t.Fatalf("expected sanitized output to be stable, got %s, %v", again, err)
+	}
+}
+
+func TestSanitizeSVGRejectsInvalidDocuments(t *testing.T) {
+	for name, content := range map[string]string{
+		"not xml":        "\x89PNG\r\n",
+		"html root":      `<html><script>alert(1)</script></html>`,
+		"unclosed":       `<svg><g>`,
+		"two roots":      `<svg></svg><svg></svg>`,
+		"entity payload": `<svg>&xxe;</svg>`,
+	} {
+		if _, err := SanitizeSVG([]byte(content)); !errors.Is(err, ErrInvalidSVG) {
+			t.Errorf("%s: expected ErrInvalidSVG, got %v", name, err)
+		}
+	}
+}
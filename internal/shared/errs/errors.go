@@ -7,4 +7,6 @@ var (
 	ErrInvalidInput = errors.New("invalid input")
 	ErrConflict     = errors.New("conflict")
 	ErrForbidden    = errors.New("forbidden")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrUnauthorized = errors.New("unauthorized")
 )
@@ -0,0 +1,7 @@
+-- Short human-friendly codes for share links (e.g. /e/AbC12xyz)
+ALTER TABLE page_share_links
+    ADD COLUMN IF NOT EXISTS code TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_page_share_links_code
+    ON page_share_links (code)
+    WHERE code IS NOT NULL;
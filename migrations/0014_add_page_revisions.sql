@@ -0,0 +1,11 @@
+-- Snapshot of a page's blocks after each successful block update
+CREATE TABLE IF NOT EXISTS page_revisions (
+    id          TEXT PRIMARY KEY,
+    page_id     TEXT NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    editor_id   TEXT REFERENCES users(id) ON DELETE SET NULL,
+    blocks      JSONB NOT NULL DEFAULT '[]'::jsonb,
+    block_count INT NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_revisions_page_created ON page_revisions (page_id, created_at DESC);
@@ -0,0 +1,11 @@
+-- First publish time survives unpublish/republish; published_at tracks the latest
+ALTER TABLE pages
+    ADD COLUMN IF NOT EXISTS first_published_at TIMESTAMPTZ;
+
+UPDATE pages
+SET first_published_at = published_at
+WHERE first_published_at IS NULL AND published_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_pages_first_published_at
+    ON pages (first_published_at DESC)
+    WHERE deleted_at IS NULL AND published = true;
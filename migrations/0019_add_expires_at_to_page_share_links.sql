@@ -0,0 +1,3 @@
+-- Optional expiry for share links; NULL never expires
+ALTER TABLE page_share_links
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
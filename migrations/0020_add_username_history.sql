@@ -0,0 +1,8 @@
+-- Usernames a user has given up, so they are not immediately claimable by others
+CREATE TABLE IF NOT EXISTS username_history (
+    username    TEXT NOT NULL,
+    user_id     TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    released_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_username_history_username ON username_history (username, released_at DESC);
@@ -0,0 +1,2 @@
+-- Owners can pin proofreads so they are listed first
+ALTER TABLE proofreads ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT false;
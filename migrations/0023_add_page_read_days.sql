@@ -0,0 +1,9 @@
+-- Reads per page per UTC day, the series trending pages are ranked from
+CREATE TABLE IF NOT EXISTS page_read_days (
+    page_id TEXT NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    day     DATE NOT NULL,
+    reads   INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (page_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_read_days_day ON page_read_days (day);
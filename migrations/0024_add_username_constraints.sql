@@ -0,0 +1,13 @@
+-- Usernames are unique regardless of case and limited to a-z, 0-9 and '_'.
+-- The check is NOT VALID so existing usernames are kept; new and renamed
+-- usernames must match it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (lower(username));
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'users_username_format') THEN
+        ALTER TABLE users ADD CONSTRAINT users_username_format
+            CHECK (username ~ '^[a-z0-9_]{3,30}$') NOT VALID;
+    END IF;
+END
+$$;
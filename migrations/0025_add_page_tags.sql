@@ -0,0 +1,8 @@
+-- Normalized topic tags attached to pages
+CREATE TABLE IF NOT EXISTS page_tags (
+    page_id TEXT NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (page_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_tags_tag ON page_tags (tag);
@@ -0,0 +1,4 @@
+-- Page version each revision snapshots, used to find the base of a stale edit
+ALTER TABLE page_revisions ADD COLUMN IF NOT EXISTS page_updated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_page_revisions_page_version ON page_revisions (page_id, page_updated_at DESC);
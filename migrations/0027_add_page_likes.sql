@@ -0,0 +1,9 @@
+-- Signed-in users' likes on published pages
+CREATE TABLE IF NOT EXISTS page_likes (
+    page_id    TEXT NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    user_id    TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (page_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_likes_user ON page_likes (user_id);
@@ -0,0 +1,9 @@
+-- Pages readers saved for later
+CREATE TABLE IF NOT EXISTS page_bookmarks (
+    user_id    TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    page_id    TEXT NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, page_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_bookmarks_user_created ON page_bookmarks (user_id, created_at DESC);
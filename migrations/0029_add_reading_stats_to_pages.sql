@@ -0,0 +1,3 @@
+-- Word count and reading-time estimate, recomputed whenever blocks are saved
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS word_count INT NOT NULL DEFAULT 0;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS reading_minutes INT NOT NULL DEFAULT 0;
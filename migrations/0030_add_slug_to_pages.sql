@@ -0,0 +1,3 @@
+-- Human-friendly public URL segment, unique per owner, assigned on first publish
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS slug TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pages_owner_slug ON pages (owner_id, slug) WHERE slug IS NOT NULL;
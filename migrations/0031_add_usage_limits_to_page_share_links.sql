@@ -0,0 +1,4 @@
+-- Optional usage cap for share links; NULL allows unlimited uses
+ALTER TABLE page_share_links
+    ADD COLUMN IF NOT EXISTS max_uses INT,
+    ADD COLUMN IF NOT EXISTS use_count INT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Optional bcrypt hash readers must match to see a published page's blocks
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS password_hash TEXT;
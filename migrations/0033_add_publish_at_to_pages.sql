@@ -0,0 +1,3 @@
+-- Scheduled publish time; the publish scheduler flips due pages to published
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_pages_publish_at ON pages (publish_at) WHERE publish_at IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Lightweight reader comments on published pages, threaded through parent_id
+CREATE TABLE IF NOT EXISTS page_comments (
+    id          TEXT PRIMARY KEY,
+    page_id     TEXT NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    parent_id   TEXT REFERENCES page_comments(id) ON DELETE CASCADE,
+    user_id     TEXT REFERENCES users(id) ON DELETE SET NULL,
+    author_name TEXT NOT NULL,
+    body        TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_comments_page_created ON page_comments (page_id, created_at);
@@ -0,0 +1,7 @@
+-- One vote per reader on a proofread; voter_key is a user ID or a hashed reader key
+CREATE TABLE IF NOT EXISTS proofread_votes (
+    proofread_id TEXT NOT NULL REFERENCES proofreads(id) ON DELETE CASCADE,
+    voter_key    TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (proofread_id, voter_key)
+);
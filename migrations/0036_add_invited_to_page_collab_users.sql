@@ -0,0 +1,2 @@
+-- Collaborators added by the owner, as opposed to recorded share link visitors
+ALTER TABLE page_collab_users ADD COLUMN IF NOT EXISTS invited BOOLEAN NOT NULL DEFAULT false;
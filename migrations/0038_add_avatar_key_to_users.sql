@@ -0,0 +1,3 @@
+-- Storage key of the avatar this server uploaded, so replacing it only ever
+-- deletes our own object
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key TEXT;
@@ -0,0 +1,39 @@
+-- Rewrite usernames that predate users_username_format the way new usernames
+-- are derived (lower case, dots dropped, other characters become '_', short
+-- names prefixed with user_), adding the lowest free numeric suffix on
+-- collision. Old usernames go to username_history so they are held for their
+-- owner. The check can then be validated and no longer fails every UPDATE of
+-- a legacy row.
+DO $$
+DECLARE
+    legacy    RECORD;
+    base      TEXT;
+    candidate TEXT;
+    attempt   INT;
+BEGIN
+    FOR legacy IN
+        SELECT id, username FROM users
+        WHERE username !~ '^[a-z0-9_]{3,30}$'
+        ORDER BY created_at, id
+    LOOP
+        base := regexp_replace(replace(lower(legacy.username), '.', ''), '[^a-z0-9_]', '_', 'g');
+        IF length(base) < 3 THEN
+            base := 'user_' || base;
+        END IF;
+        base := left(base, 30);
+
+        candidate := base;
+        attempt := 1;
+        WHILE EXISTS (SELECT 1 FROM users WHERE lower(username) = candidate AND id <> legacy.id)
+           OR EXISTS (SELECT 1 FROM username_history WHERE username = candidate AND user_id <> legacy.id) LOOP
+            attempt := attempt + 1;
+            candidate := left(base, 30 - length(attempt::text)) || attempt::text;
+        END LOOP;
+
+        INSERT INTO username_history (username, user_id) VALUES (legacy.username, legacy.id);
+        UPDATE users SET username = candidate WHERE id = legacy.id;
+    END LOOP;
+END
+$$;
+
+ALTER TABLE users VALIDATE CONSTRAINT users_username_format;
@@ -0,0 +1,8 @@
+-- Who has used each capped share link, so a link's uses count people rather
+-- than every authorization check made on their behalf
+CREATE TABLE IF NOT EXISTS page_share_link_uses (
+    token         TEXT NOT NULL REFERENCES page_share_links(token) ON DELETE CASCADE,
+    actor_key     TEXT NOT NULL,
+    first_used_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (token, actor_key)
+);
@@ -0,0 +1,3 @@
+-- Look up and check slugs across every owner when slugs are globally unique.
+-- Not a unique index: per-owner mode lets different owners share a slug.
+CREATE INDEX IF NOT EXISTS idx_pages_slug ON pages (slug) WHERE slug IS NOT NULL;
@@ -0,0 +1,18 @@
+-- Every URL a page points at, from its cover and anywhere in its blocks' data,
+-- so media cleanup can check whether another page still uses an object
+-- without scanning block JSON. reverse(url) lets "URL ends with /<key>"
+-- lookups use an index.
+CREATE TABLE IF NOT EXISTS page_media_refs (
+    page_id TEXT NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    url     TEXT NOT NULL,
+    PRIMARY KEY (page_id, url)
+);
+CREATE INDEX IF NOT EXISTS idx_page_media_refs_reverse_url ON page_media_refs (reverse(url) text_pattern_ops);
+
+INSERT INTO page_media_refs (page_id, url)
+SELECT id, cover FROM pages WHERE cover ~ '^https?://'
+UNION
+SELECT b.page_id, value #>> '{}'
+FROM blocks b, jsonb_path_query(b.data, 'strict $.**') AS value
+WHERE jsonb_typeof(value) = 'string' AND value #>> '{}' ~ '^https?://'
+ON CONFLICT DO NOTHING;
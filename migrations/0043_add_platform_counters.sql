@@ -0,0 +1,9 @@
+-- Site-wide running totals for the admin stats, so they need no table scans
+CREATE TABLE IF NOT EXISTS platform_counters (
+    name  TEXT PRIMARY KEY,
+    value BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO platform_counters (name, value)
+SELECT 'reads', coalesce(sum(read_count), 0) FROM page_reads
+ON CONFLICT (name) DO NOTHING;
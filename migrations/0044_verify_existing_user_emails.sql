@@ -0,0 +1,7 @@
+-- Accounts from before email verification count as verified: Google sign-ins,
+-- which have no password, and every account never sent a verification token
+UPDATE users u
+SET email_verified = true
+WHERE u.email_verified = false
+  AND (u.password_hash = ''
+       OR NOT EXISTS (SELECT 1 FROM email_verifications v WHERE v.user_id = u.id));
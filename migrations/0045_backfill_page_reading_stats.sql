@@ -0,0 +1,16 @@
+-- Word counts and reading times for pages last saved before they were stored,
+-- counted the way the service does: words in the text of textual blocks, at
+-- 220 words per minute rounded up
+WITH stats AS (
+    SELECT b.page_id,
+           sum((SELECT count(*) FROM regexp_matches(b.data->>'text', '\S+', 'g')))::int AS words
+    FROM blocks b
+    WHERE b.type IN ('paragraph', 'heading', 'heading2', 'heading3', 'bullet', 'numbered', 'quote', 'code')
+      AND jsonb_typeof(b.data->'text') = 'string'
+    GROUP BY b.page_id
+)
+UPDATE pages p
+SET word_count = stats.words,
+    reading_minutes = (stats.words + 219) / 220
+FROM stats
+WHERE stats.page_id = p.id AND p.word_count = 0;
@@ -497,6 +497,7 @@ type UpdateBlocksRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PageId        string                 `protobuf:"bytes,1,opt,name=page_id,json=pageId,proto3" json:"page_id,omitempty"`
 	Blocks        []*Block               `protobuf:"bytes,2,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	BaseUpdatedAt string                 `protobuf:"bytes,3,opt,name=base_updated_at,json=baseUpdatedAt,proto3" json:"base_updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -545,6 +546,13 @@ func (x *UpdateBlocksRequest) GetBlocks() []*Block {
 	return nil
 }
 
+func (x *UpdateBlocksRequest) GetBaseUpdatedAt() string {
+	if x != nil {
+		return x.BaseUpdatedAt
+	}
+	return ""
+}
+
 type UpdateBlocksResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Page          *Page                  `protobuf:"bytes,1,opt,name=page,proto3" json:"page,omitempty"`
@@ -627,10 +635,11 @@ const file_proto_jot_pages_v1_pages_proto_rawDesc = "" +
 	"\x0eGetPageRequest\x12\x17\n" +
 	"\apage_id\x18\x01 \x01(\tR\x06pageId\"9\n" +
 	"\x0fGetPageResponse\x12&\n" +
-	"\x04page\x18\x01 \x01(\v2\x12.jot.pages.v1.PageR\x04page\"[\n" +
+	"\x04page\x18\x01 \x01(\v2\x12.jot.pages.v1.PageR\x04page\"\x83\x01\n" +
 	"\x13UpdateBlocksRequest\x12\x17\n" +
 	"\apage_id\x18\x01 \x01(\tR\x06pageId\x12+\n" +
-	"\x06blocks\x18\x02 \x03(\v2\x13.jot.pages.v1.BlockR\x06blocks\">\n" +
+	"\x06blocks\x18\x02 \x03(\v2\x13.jot.pages.v1.BlockR\x06blocks\x12&\n" +
+	"\x0fbase_updated_at\x18\x03 \x01(\tR\rbaseUpdatedAt\">\n" +
 	"\x14UpdateBlocksResponse\x12&\n" +
 	"\x04page\x18\x01 \x01(\v2\x12.jot.pages.v1.PageR\x04page2_\n" +
 	"\rPagesRealtime\x12N\n" +
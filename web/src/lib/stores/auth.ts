@@ -17,20 +17,95 @@ export type AuthUser = {
 export const user = writable<AuthUser | null>(null);
 export const authLoading = writable(true);
 
+// Access tokens are short-lived; the refresh cookie renews them. Refresh this
+// long before the access token expires, or on this interval when its expiry
+// is unknown (a session restored from cookies alone).
+const REFRESH_MARGIN_MS = 60_000;
+const REFRESH_FALLBACK_MS = 10 * 60_000;
+
+let refreshTimer: ReturnType<typeof setTimeout> | null = null;
+let refreshing: Promise<boolean> | null = null;
+
+function tokenExpiry(token: string | undefined): number | null {
+	try {
+		const payload = JSON.parse(atob(token!.split('.')[1].replace(/-/g, '+').replace(/_/g, '/')));
+		return typeof payload.exp === 'number' ? payload.exp * 1000 : null;
+	} catch {
+		return null;
+	}
+}
+
+function scheduleRefresh(token?: string) {
+	if (typeof window === 'undefined') return;
+	if (refreshTimer) clearTimeout(refreshTimer);
+	const expiry = tokenExpiry(token);
+	const delay = expiry ? Math.max(expiry - Date.now() - REFRESH_MARGIN_MS, 0) : REFRESH_FALLBACK_MS;
+	refreshTimer = setTimeout(() => void refreshSession(), delay);
+}
+
+function stopRefresh() {
+	if (refreshTimer) clearTimeout(refreshTimer);
+	refreshTimer = null;
+}
+
+/**
+ * Trades the refresh cookie for a new access token. Concurrent callers share
+ * one request, since each refresh token can only be used once.
+ */
+export function refreshSession(): Promise<boolean> {
+	if (!refreshing) {
+		refreshing = (async () => {
+			try {
+				const res = await fetch(`${apiUrl}/v1/auth/refresh`, { method: 'POST', credentials: 'include' });
+				if (!res.ok) {
+					stopRefresh();
+					if (res.status === 401) user.set(null);
+					return false;
+				}
+				const data = await res.json();
+				user.set(data.user);
+				scheduleRefresh(data.token);
+				return true;
+			} catch {
+				return false;
+			} finally {
+				refreshing = null;
+			}
+		})();
+	}
+	return refreshing;
+}
+
+/**
+ * fetch for authenticated API calls: sends cookies and, when the access token
+ * has expired, refreshes the session and retries the request once.
+ */
+export async function apiFetch(input: string, init: RequestInit = {}): Promise<Response> {
+	const res = await fetch(input, { credentials: 'include', ...init });
+	if (res.status !== 401) return res;
+	if (!(await refreshSession())) return res;
+	return fetch(input, { credentials: 'include', ...init });
+}
+
 /** Called once on app mount — checks the cookie-based session */
 export async function fetchMe(): Promise<AuthUser | null> {
 	try {
-		const res = await fetch(`${apiUrl}/v1/auth/me`, { credentials: 'include' });
-		if (res.status === 204) {
-			user.set(null);
-			return null;
+		let res = await fetch(`${apiUrl}/v1/auth/me`, { credentials: 'include' });
+		if (res.status === 204 || res.status === 401) {
+			// The access cookie may have expired while the refresh cookie lives on.
+			if (!(await refreshSession())) {
+				user.set(null);
+				return null;
+			}
+			res = await fetch(`${apiUrl}/v1/auth/me`, { credentials: 'include' });
 		}
-		if (!res.ok) {
+		if (!res.ok || res.status === 204) {
 			user.set(null);
 			return null;
 		}
 		const data: AuthUser = await res.json();
 		user.set(data);
+		if (!refreshTimer) scheduleRefresh();
 		return data;
 	} catch {
 		user.set(null);
@@ -53,6 +128,7 @@ export async function login(email: string, password: string): Promise<AuthUser>
 	}
 	const data = await res.json();
 	user.set(data.user);
+	scheduleRefresh(data.token);
 	return data.user;
 }
 
@@ -69,6 +145,7 @@ export async function signup(name: string, email: string, password: string): Pro
 	}
 	const data = await res.json();
 	user.set(data.user);
+	scheduleRefresh(data.token);
 	return data.user;
 }
 
@@ -81,5 +158,6 @@ export async function logout() {
 	} catch {
 		// best-effort — clear UI state regardless
 	}
+	stopRefresh();
 	user.set(null);
 }
@@ -0,0 +1,20 @@
+import { env } from '$env/dynamic/public';
+import { error, redirect } from '@sveltejs/kit';
+import type { PageServerLoad } from './$types';
+
+export const load: PageServerLoad = async ({ params, fetch }) => {
+	const apiUrl = env.PUBLIC_API_URL || 'http://localhost:8080';
+	const code = encodeURIComponent(params.code);
+
+	const res = await fetch(`${apiUrl}/v1/share/${code}/validate`);
+	if (!res.ok) {
+		throw error(404, 'Share link not found');
+	}
+
+	const validation = await res.json();
+	if (!validation.valid || !validation.page_id) {
+		throw error(404, 'Share link not found');
+	}
+
+	throw redirect(307, `/editor?pageId=${encodeURIComponent(validation.page_id)}&share=${code}`);
+};